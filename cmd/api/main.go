@@ -12,38 +12,77 @@ import (
 	"time"
 
 	"github.com/ety001/sps-fund-watcher/internal/api"
-	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/chain"
+	"github.com/ety001/sps-fund-watcher/internal/config"
+	"github.com/ety001/sps-fund-watcher/internal/logging"
+	"github.com/ety001/sps-fund-watcher/internal/profiling"
+	"github.com/ety001/sps-fund-watcher/internal/reporting"
 	"github.com/ety001/sps-fund-watcher/internal/storage"
-	"gopkg.in/yaml.v3"
+	"github.com/ety001/sps-fund-watcher/internal/version"
 )
 
 func main() {
 	configPath := flag.String("config", "configs/config.yaml", "Path to configuration file")
+	showVersion := flag.Bool("version", false, "Print version information and exit")
+	var configFlags config.Flags
+	configFlags.Register(flag.CommandLine)
 	flag.Parse()
 
+	if *showVersion {
+		fmt.Println(version.String("api"))
+		return
+	}
+
 	// Load configuration
-	config, err := loadConfig(*configPath)
+	config, err := configFlags.Load(*configPath)
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	logging.Setup(config.Logging)
+	logging.SetLabels(config.Instance.Labels())
+	version.LogBanner("api", config.Summary())
+
+	// Optional profiling endpoints on a private address
+	if addr := config.Pprof.APIListenAddr(); addr != "" {
+		if err := profiling.Start(addr); err != nil {
+			log.Fatalf("Failed to start pprof: %v", err)
+		}
+	}
 
-	// Initialize MongoDB storage
-	mongoStorage, err := storage.NewMongoDB(config.MongoDB.URI, config.MongoDB.Database)
-	if err != nil {
-		log.Fatalf("Failed to initialize MongoDB: %v", err)
+	// Optional error reporting
+	if err := reporting.Setup(config.ErrorReporting, "api", config.Instance.Labels()); err != nil {
+		log.Fatalf("Failed to initialize error reporting: %v", err)
 	}
-	defer mongoStorage.Close()
+	defer reporting.Close()
+	defer reporting.Recover()
+
+	// One handler per watched chain, each with its own database and node
+	handlers := make([]*api.Handler, 0, len(config.Chains)+1)
+	for _, chainConfig := range config.ChainConfigs() {
+		// Initialize MongoDB storage
+		mongoStorage, err := storage.NewMongoDB(chainConfig.MongoDB.URI, chainConfig.MongoDB.Database)
+		if err != nil {
+			log.Fatalf("Failed to initialize MongoDB: %v", err)
+		}
+		defer mongoStorage.Close()
+		mongoStorage.SetSlowQueryThreshold(time.Duration(chainConfig.MongoDB.SlowQueryMS) * time.Millisecond)
 
-	// Create indexes
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	if err := mongoStorage.CreateIndexes(ctx); err != nil {
-		log.Printf("Warning: failed to create indexes: %v", err)
+		// Create indexes
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := mongoStorage.CreateIndexes(ctx); err != nil {
+			log.Printf("Warning: failed to create indexes: %v", err)
+		}
+		cancel()
+
+		client, err := chain.NewClient(chainConfig.Steem)
+		if err != nil {
+			log.Fatalf("Failed to initialize chain client: %v", err)
+		}
+		handlers = append(handlers, api.NewHandler(mongoStorage, chainConfig, client))
 	}
 
-	// Setup API handler and routes
-	handler := api.NewHandler(mongoStorage, config)
-	router := api.SetupRoutes(handler)
+	// Setup API routes
+	router := api.SetupRoutes(handlers[0], handlers[1:]...)
 
 	// Setup server
 	addr := fmt.Sprintf("%s:%s", config.API.Host, config.API.Port)
@@ -67,7 +106,7 @@ func main() {
 
 	log.Println("Shutting down server...")
 
-	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatalf("Server forced to shutdown: %v", err)
@@ -75,17 +114,3 @@ func main() {
 
 	log.Println("Server exited")
 }
-
-func loadConfig(path string) (*models.Config, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
-	}
-
-	var config models.Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config: %w", err)
-	}
-
-	return &config, nil
-}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/chain"
+	"github.com/ety001/sps-fund-watcher/internal/config"
+	"github.com/ety001/sps-fund-watcher/internal/enrich"
+	"github.com/ety001/sps-fund-watcher/internal/logging"
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/reporting"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+	"github.com/ety001/sps-fund-watcher/internal/sync"
+	"github.com/ety001/sps-fund-watcher/internal/version"
+)
+
+// accountList collects accounts from repeated or comma-separated -account flags
+type accountList []string
+
+func (a *accountList) String() string {
+	return strings.Join(*a, ",")
+}
+
+func (a *accountList) Set(value string) error {
+	for _, account := range strings.Split(value, ",") {
+		if account = strings.TrimSpace(account); account != "" {
+			*a = append(*a, account)
+		}
+	}
+	return nil
+}
+
+// importer fills the operations collection from the history of accounts
+// The history tells which blocks touch an account; those blocks are then processed exactly like the
+// sync service does, so imported operations have the same identity as synced ones and later syncs,
+// compensator runs and verify treat them as their own
+type importer struct {
+	steemAPI  chain.Client
+	storage   *storage.MongoDB
+	processor *sync.BlockProcessor
+	workers   int
+}
+
+func main() {
+	var accountFlags accountList
+	flag.Var(&accountFlags, "account", "Account to import (comma-separated or repeatable; default: steem.accounts)")
+	startBlock := flag.Int64("start", 0, "Skip history before this block")
+	workers := flag.Int("workers", 4, "Blocks fetched concurrently")
+	setSyncState := flag.Bool("set-sync-state", true, "When no sync state is stored yet, start the sync service after the imported history")
+	showVersion := flag.Bool("version", false, "Print version information and exit")
+	var configFlags config.Flags
+	configFlags.Register(flag.CommandLine)
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(version.String("bootstrap"))
+		return
+	}
+
+	// Get config file path from remaining arguments
+	args := flag.Args()
+	if len(args) == 0 {
+		log.Fatal("Config file path is required")
+	}
+	configPath := args[0]
+
+	if *workers < 1 {
+		log.Fatal("-workers must be at least 1")
+	}
+
+	// Load configuration
+	config, err := configFlags.Load(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	logging.Setup(config.Logging)
+	logging.SetLabels(config.Instance.Labels())
+	version.LogBanner("bootstrap", config.Summary())
+
+	// Optional error reporting
+	if err := reporting.Setup(config.ErrorReporting, "bootstrap", config.Instance.Labels()); err != nil {
+		log.Fatalf("Failed to initialize error reporting: %v", err)
+	}
+	defer reporting.Close()
+	defer reporting.Recover()
+
+	accounts := accountFlags
+	if len(accounts) == 0 {
+		accounts = config.Steem.Accounts
+	}
+	accounts = normalized(accounts)
+	if len(accounts) == 0 {
+		log.Fatal("No accounts to import: steem.accounts is empty and -account is not set")
+	}
+	for _, account := range accounts {
+		if err := models.ValidateAccountName(account); err != nil {
+			log.Fatalf("Invalid account: %v", err)
+		}
+	}
+
+	// Initialize Steem API client
+	steemAPI, err := chain.NewClient(config.Steem)
+	if err != nil {
+		log.Fatalf("Failed to initialize chain client: %v", err)
+	}
+	log.Printf("Steem API initialized: %s", config.Steem.APIURL)
+
+	// Initialize MongoDB storage
+	mongoStorage, err := storage.NewMongoDB(config.MongoDB.URI, config.MongoDB.Database)
+	if err != nil {
+		log.Fatalf("Failed to initialize MongoDB: %v", err)
+	}
+	defer mongoStorage.Close()
+	mongoStorage.SetSlowQueryThreshold(time.Duration(config.MongoDB.SlowQueryMS) * time.Millisecond)
+	log.Printf("MongoDB initialized: %s/%s", config.MongoDB.URI, config.MongoDB.Database)
+
+	ctx := context.Background()
+	indexCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	if err := mongoStorage.CreateIndexes(indexCtx); err != nil {
+		log.Printf("Warning: failed to create indexes: %v", err)
+	}
+	cancel()
+
+	// Operations of every configured account are kept from the fetched blocks, like the sync service
+	// does; notifications are never sent for history
+	tracked := normalized(append(append([]string{}, config.Steem.Accounts...), accounts...))
+	processor := sync.NewBlockProcessor(mongoStorage, nil, []models.TelegramUserConfig{}, tracked, "")
+	processor.SetSamplingRules(config.Steem.Sampling)
+	processor.SetAccountPaths(config.Steem.AccountPaths)
+	processor.SetFallbackExtraction(config.Steem.FallbackExtraction)
+	processor.SetMentionDetection(config.Steem.DetectMentions)
+	processor.SetStoragePolicies(config.Steem.StoragePolicies)
+	enrichment, err := enrich.NewPipeline(config.Enrichment, steemAPI)
+	if err != nil {
+		log.Fatalf("Failed to initialize enrichment: %v", err)
+	}
+	processor.SetEnrichment(enrichment)
+
+	// History is imported up to the last irreversible block, so nothing imported can be forked out
+	dgp, err := steemAPI.GetDynamicGlobalProperties()
+	if err != nil {
+		log.Fatalf("Failed to get dynamic global properties: %v", err)
+	}
+	headBlock := int64(dgp.LastIrreversibleBlockNum)
+
+	imp := &importer{steemAPI: steemAPI, storage: mongoStorage, processor: processor, workers: *workers}
+	started := time.Now()
+
+	blocks := make(map[int64]bool)
+	for _, account := range accounts {
+		found, err := imp.historyBlocks(account, *startBlock, headBlock)
+		if err != nil {
+			log.Fatalf("Failed to read history of %s: %v", account, err)
+		}
+		log.Printf("%s: %d block(s) with operations up to block %d", account, len(found), headBlock)
+		for _, blockNum := range found {
+			blocks[blockNum] = true
+		}
+	}
+
+	ordered := make([]int64, 0, len(blocks))
+	for blockNum := range blocks {
+		ordered = append(ordered, blockNum)
+	}
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i] < ordered[j] })
+
+	perAccount, err := imp.importBlocks(ctx, ordered)
+	if err != nil {
+		log.Fatalf("Import failed: %v", err)
+	}
+
+	// The history lists every block touching an account, so the imported range counts as scanned
+	// and compensator -auto doesn't rescan it
+	if err := mongoStorage.AddCoverage(ctx, accounts, max(*startBlock, 1), headBlock); err != nil {
+		log.Printf("Warning: failed to record coverage: %v", err)
+	}
+
+	if *setSyncState {
+		state, err := mongoStorage.GetSyncState(ctx)
+		switch {
+		case err != nil:
+			log.Printf("Warning: failed to read sync state: %v", err)
+		case state.LastBlock > 0:
+			log.Printf("Sync state already at block %d, leaving it unchanged", state.LastBlock)
+		default:
+			if err := mongoStorage.UpdateSyncState(ctx, headBlock, headBlock); err != nil {
+				log.Printf("Warning: failed to set sync state: %v", err)
+			} else {
+				log.Printf("Sync state set to block %d; the sync service continues from there", headBlock)
+			}
+		}
+	}
+
+	log.Printf("Import completed in %s: %d block(s) processed", time.Since(started).Round(time.Second), len(ordered))
+	for _, account := range tracked {
+		if perAccount[account] > 0 {
+			log.Printf("  %s: %d operations saved", account, perAccount[account])
+		}
+	}
+}
+
+// historyBlocks pages backwards through the history of account and returns the blocks in
+// [start, head] holding its operations, in ascending order
+func (imp *importer) historyBlocks(account string, start, head int64) ([]int64, error) {
+	seen := make(map[int64]bool)
+	from := int64(-1)
+	for page := 1; ; page++ {
+		limit := chain.MaxHistoryPage
+		if from >= 0 && from < int64(limit) {
+			limit = int(from)
+		}
+		entries, err := chain.AccountHistory(imp.steemAPI, account, from, limit)
+		if err != nil {
+			return nil, err
+		}
+		if len(entries) == 0 {
+			break
+		}
+
+		reachedStart := false
+		for _, entry := range entries {
+			blockNum := int64(entry.Op.BlockNumber)
+			if blockNum < start {
+				reachedStart = true
+				continue
+			}
+			if blockNum <= head {
+				seen[blockNum] = true
+			}
+		}
+
+		oldest := entries[0].Seq
+		if reachedStart || oldest <= 0 {
+			break
+		}
+		from = oldest - 1
+		if page%10 == 0 {
+			log.Printf("%s: read history down to entry %d (%d blocks so far)", account, oldest, len(seen))
+		}
+	}
+
+	blocks := make([]int64, 0, len(seen))
+	for blockNum := range seen {
+		blocks = append(blocks, blockNum)
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i] < blocks[j] })
+	return blocks, nil
+}
+
+// importBlocks fetches and stores the operations of blocks, workers blocks at a time
+func (imp *importer) importBlocks(ctx context.Context, blocks []int64) (map[string]int, error) {
+	perAccount := make(map[string]int)
+	total := 0
+	for offset := 0; offset < len(blocks); offset += imp.workers {
+		chunk := blocks[offset:min(offset+imp.workers, len(blocks))]
+
+		type fetched struct {
+			operations []*models.Operation
+			err        error
+		}
+		results := make([]fetched, len(chunk))
+		done := make(chan struct{}, len(chunk))
+		for i, blockNum := range chunk {
+			go func() {
+				defer func() { done <- struct{}{} }()
+				results[i].operations, results[i].err = imp.fetchBlock(ctx, blockNum)
+			}()
+		}
+		for range chunk {
+			<-done
+		}
+
+		for i, blockNum := range chunk {
+			if results[i].err != nil {
+				return nil, fmt.Errorf("block %d: %w", blockNum, results[i].err)
+			}
+			operations, err := imp.processor.ApplySampling(ctx, results[i].operations)
+			if err != nil {
+				return nil, fmt.Errorf("failed to apply sampling for block %d: %w", blockNum, err)
+			}
+			if len(operations) == 0 {
+				continue
+			}
+			imp.processor.Enrich(ctx, operations)
+			if err := imp.storage.InsertOperations(ctx, imp.processor.ApplyStoragePolicy(operations)); err != nil {
+				return nil, fmt.Errorf("failed to insert operations for block %d: %w", blockNum, err)
+			}
+			total += len(operations)
+			for _, op := range operations {
+				perAccount[op.Account]++
+			}
+		}
+
+		if processed := offset + len(chunk); processed%1000 < len(chunk) || processed == len(blocks) {
+			log.Printf("Progress: %d/%d blocks processed, %d operations saved", processed, len(blocks), total)
+		}
+	}
+	return perAccount, nil
+}
+
+// fetchBlock extracts the operations of tracked accounts from one block
+func (imp *importer) fetchBlock(ctx context.Context, blockNum int64) ([]*models.Operation, error) {
+	ops, err := imp.steemAPI.GetOpsInBlock(uint(blockNum), false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get operations: %w", err)
+	}
+	operations, err := imp.processor.ProcessOperations(ctx, ops)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process operations: %w", err)
+	}
+	if err := sync.AttachBlockHeader(imp.steemAPI, blockNum, operations); err != nil {
+		return nil, err
+	}
+	models.SetSource(operations, models.SourceImport)
+	return operations, nil
+}
+
+// normalized returns accounts sorted and without duplicates
+func normalized(accounts []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, account := range accounts {
+		if !seen[account] {
+			seen[account] = true
+			result = append(result, account)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
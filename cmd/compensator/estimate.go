@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+)
+
+// Run estimation
+const (
+	// Throughput assumed until a run has completed (batches of get_ops_in_blocks with a short pause)
+	defaultBlocksPerSecond = 50
+	// Completed runs averaged for the throughput
+	throughputSampleJobs = 10
+	// Shorter runs are dominated by start-up and left out of the throughput
+	minSampleDuration = time.Minute
+)
+
+// estimate is the expected cost of a run
+type estimate struct {
+	blocks          int64
+	blocksPerSecond float64
+	measured        bool  // blocksPerSecond comes from earlier runs rather than the default
+	documents       int64 // -1 when none of the accounts has stored history to go by
+}
+
+// duration returns the expected run time
+func (e estimate) duration() time.Duration {
+	return time.Duration(float64(e.blocks) / e.blocksPerSecond * float64(time.Second))
+}
+
+// remaining returns the part of [startBlock, endBlock] a resumed run still has to process
+func (c *compensator) remaining(ctx context.Context, accounts []string, startBlock, endBlock int64) (models.BlockRange, bool) {
+	saved, err := c.storage.GetCompensatorJob(ctx, models.CompensatorJobID(accounts, startBlock, endBlock))
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return models.BlockRange{Start: startBlock, End: endBlock}, true
+	case err != nil:
+		log.Printf("Warning: failed to load checkpoint for the estimate: %v", err)
+		return models.BlockRange{Start: startBlock, End: endBlock}, true
+	case saved.Completed:
+		return models.BlockRange{}, false
+	default:
+		return models.BlockRange{Start: saved.LastProcessedBlock + 1, End: endBlock}, true
+	}
+}
+
+// estimateRun estimates a run over ranges from the throughput of recent runs and the number of
+// operations stored per scanned block of each account
+func (c *compensator) estimateRun(ctx context.Context, accounts []string, ranges []models.BlockRange) estimate {
+	e := estimate{blocksPerSecond: defaultBlocksPerSecond, documents: -1}
+	for _, r := range ranges {
+		e.blocks += r.End - r.Start + 1
+	}
+
+	jobs, err := c.storage.RecentCompensatorJobs(ctx, throughputSampleJobs)
+	if err != nil {
+		log.Printf("Warning: failed to load recent runs for the estimate: %v", err)
+	}
+	var sampleBlocks int64
+	var sampleTime time.Duration
+	for _, job := range jobs {
+		// Scheduled jobs start counting when they are queued, not when they run
+		elapsed := job.UpdatedAt.Sub(job.StartedAt)
+		if job.Scheduled || elapsed < minSampleDuration {
+			continue
+		}
+		sampleBlocks += job.EndBlock - job.StartBlock + 1
+		sampleTime += elapsed
+	}
+	if sampleBlocks > 0 {
+		e.blocksPerSecond = float64(sampleBlocks) / sampleTime.Seconds()
+		e.measured = true
+	}
+
+	// Accounts without coverage (e.g. just added) don't contribute to the document count
+	var perBlock float64
+	for _, account := range accounts {
+		covered, err := c.storage.GetCoverage(ctx, account)
+		if err != nil {
+			log.Printf("Warning: failed to load coverage of %s for the estimate: %v", account, err)
+			continue
+		}
+		var scanned int64
+		for _, r := range covered {
+			scanned += r.End - r.Start + 1
+		}
+		if scanned == 0 {
+			continue
+		}
+		stored, err := c.storage.CountOperations(ctx, storage.OperationQuery{Account: account})
+		if err != nil {
+			log.Printf("Warning: failed to count operations of %s for the estimate: %v", account, err)
+			continue
+		}
+		perBlock += float64(stored) / float64(scanned)
+		e.documents = 0
+	}
+	if e.documents == 0 {
+		e.documents = int64(perBlock * float64(e.blocks))
+	}
+	return e
+}
+
+// logEstimate prints an estimate
+func logEstimate(e estimate) {
+	source := "assumed"
+	if e.measured {
+		source = "measured over recent runs"
+	}
+	documents := "unknown (no stored history of these accounts)"
+	if e.documents >= 0 {
+		documents = fmt.Sprintf("about %d", e.documents)
+	}
+	log.Printf("Estimate: %d blocks, %s at %.0f blocks/s (%s), %s operations", e.blocks, formatDuration(e.duration()), e.blocksPerSecond, source, documents)
+}
+
+// formatDuration rounds long durations to the minute and adds days past one day
+func formatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return d.Round(time.Second).String()
+	}
+	s := d.Round(time.Minute).String()
+	if d >= 24*time.Hour {
+		s += fmt.Sprintf(" (%.1f days)", d.Hours()/24)
+	}
+	return s
+}
+
+// confirm decides whether a run may start: runs up to threshold blocks and runs started with -yes
+// always may; larger runs are confirmed at the terminal, or refused when stdin is not a terminal
+func confirm(e estimate, threshold int64, yes bool) bool {
+	if e.blocks <= threshold || yes {
+		return true
+	}
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		log.Printf("The run covers %d blocks, more than compensator.confirm_blocks (%d); rerun with -yes to start it", e.blocks, threshold)
+		return false
+	}
+
+	fmt.Fprintf(os.Stderr, "The run covers %d blocks, more than compensator.confirm_blocks (%d). Start it? [y/N] ", e.blocks, threshold)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -2,26 +2,86 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
-	"os"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/ety001/sps-fund-watcher/internal/chain"
+	"github.com/ety001/sps-fund-watcher/internal/config"
+	"github.com/ety001/sps-fund-watcher/internal/enrich"
+	"github.com/ety001/sps-fund-watcher/internal/logging"
 	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/profiling"
+	"github.com/ety001/sps-fund-watcher/internal/reporting"
 	"github.com/ety001/sps-fund-watcher/internal/storage"
 	"github.com/ety001/sps-fund-watcher/internal/sync"
-	"github.com/steemit/steemgosdk"
-	"gopkg.in/yaml.v3"
+	"github.com/ety001/sps-fund-watcher/internal/version"
 )
 
+// accountList collects accounts from repeated or comma-separated -account flags
+type accountList []string
+
+func (a *accountList) String() string {
+	return strings.Join(*a, ",")
+}
+
+func (a *accountList) Set(value string) error {
+	for _, account := range strings.Split(value, ",") {
+		if account = strings.TrimSpace(account); account != "" {
+			*a = append(*a, account)
+		}
+	}
+	return nil
+}
+
+// normalized returns the accounts sorted and without duplicates
+func (a accountList) normalized() []string {
+	seen := make(map[string]bool)
+	var accounts []string
+	for _, account := range a {
+		if !seen[account] {
+			seen[account] = true
+			accounts = append(accounts, account)
+		}
+	}
+	sort.Strings(accounts)
+	return accounts
+}
+
+// compensator fetches historical operations for a set of accounts
+type compensator struct {
+	steemAPI  chain.Client
+	storage   *storage.MongoDB
+	processor *sync.BlockProcessor
+	batchSize int64
+}
+
 func main() {
 	// Parse command line flags
-	account := flag.String("account", "", "Account name to compensate")
+	var accountFlags accountList
+	flag.Var(&accountFlags, "account", "Account name to compensate (comma-separated or repeatable for several accounts)")
 	startBlock := flag.Int64("start", 0, "Start block number")
 	endBlock := flag.Int64("end", 0, "End block number")
+	resume := flag.Bool("resume", false, "Continue from the saved checkpoint of an interrupted run with the same accounts and range")
+	auto := flag.Bool("auto", false, "Detect and fill coverage gaps between steem.start_block and the last irreversible block (accounts default to steem.accounts)")
+	scheduled := flag.Bool("scheduled", false, "Run the repairs queued by the sync service (e.g. after a balance mismatch)")
+	yes := flag.Bool("yes", false, "Start runs larger than compensator.confirm_blocks without asking")
+	estimateOnly := flag.Bool("estimate", false, "Print the estimated blocks, duration and operations of the run and exit")
+	pprofAddr := flag.String("pprof", "", "Serve net/http/pprof on this address during the run, e.g. 127.0.0.1:6062")
+	showVersion := flag.Bool("version", false, "Print version information and exit")
+	var configFlags config.Flags
+	configFlags.Register(flag.CommandLine)
 	flag.Parse()
 
+	if *showVersion {
+		fmt.Println(version.String("compensator"))
+		return
+	}
+
 	// Get config file path from remaining arguments
 	args := flag.Args()
 	if len(args) == 0 {
@@ -30,30 +90,66 @@ func main() {
 	configPath := args[0]
 
 	// Validate inputs
-	if *account == "" {
-		log.Fatal("Account name is required (use -account flag)")
+	accounts := accountFlags.normalized()
+	if *auto && *scheduled {
+		log.Fatal("-auto cannot be combined with -scheduled")
 	}
-	if *startBlock <= 0 {
-		log.Fatal("Start block must be greater than 0 (use -start flag)")
-	}
-	if *endBlock <= 0 {
-		log.Fatal("End block must be greater than 0 (use -end flag)")
-	}
-	if *startBlock > *endBlock {
-		log.Fatalf("Start block (%d) must be less than or equal to end block (%d)", *startBlock, *endBlock)
+	if *auto || *scheduled {
+		if *startBlock != 0 || *endBlock != 0 {
+			log.Fatal("-start and -end cannot be combined with -auto or -scheduled")
+		}
+		if *scheduled && len(accounts) > 0 {
+			log.Fatal("-account cannot be combined with -scheduled")
+		}
+	} else {
+		if len(accounts) == 0 {
+			log.Fatal("Account name is required (use -account flag)")
+		}
+		if *startBlock <= 0 {
+			log.Fatal("Start block must be greater than 0 (use -start flag)")
+		}
+		if *endBlock <= 0 {
+			log.Fatal("End block must be greater than 0 (use -end flag)")
+		}
+		if *startBlock > *endBlock {
+			log.Fatalf("Start block (%d) must be less than or equal to end block (%d)", *startBlock, *endBlock)
+		}
 	}
 
-	log.Printf("Compensator started: account=%s, start=%d, end=%d, config=%s", *account, *startBlock, *endBlock, configPath)
-
 	// Load configuration
-	config, err := loadConfig(configPath)
+	config, err := configFlags.Load(configPath)
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	logging.Setup(config.Logging)
+	logging.SetLabels(config.Instance.Labels())
+	version.LogBanner("compensator", config.Summary())
+
+	if *pprofAddr != "" {
+		if err := profiling.Start(*pprofAddr); err != nil {
+			log.Fatalf("Failed to start pprof: %v", err)
+		}
+	}
+
+	// Optional error reporting
+	if err := reporting.Setup(config.ErrorReporting, "compensator", config.Instance.Labels()); err != nil {
+		log.Fatalf("Failed to initialize error reporting: %v", err)
+	}
+	defer reporting.Close()
+	defer reporting.Recover()
+
+	if *auto && len(accounts) == 0 {
+		accounts = accountList(config.Steem.Accounts).normalized()
+		if len(accounts) == 0 {
+			log.Fatal("No accounts to compensate: steem.accounts is empty and -account is not set")
+		}
+	}
 
 	// Initialize Steem API client
-	client := steemgosdk.GetClient(config.Steem.APIURL)
-	steemAPI := client.GetAPI()
+	steemAPI, err := chain.NewClient(config.Steem)
+	if err != nil {
+		log.Fatalf("Failed to initialize chain client: %v", err)
+	}
 	log.Printf("Steem API initialized: %s", config.Steem.APIURL)
 
 	// Initialize MongoDB storage
@@ -62,6 +158,7 @@ func main() {
 		log.Fatalf("Failed to initialize MongoDB: %v", err)
 	}
 	defer mongoStorage.Close()
+	mongoStorage.SetSlowQueryThreshold(time.Duration(config.MongoDB.SlowQueryMS) * time.Millisecond)
 	log.Printf("MongoDB initialized: %s/%s", config.MongoDB.URI, config.MongoDB.Database)
 
 	// Create indexes
@@ -71,17 +168,45 @@ func main() {
 		log.Printf("Warning: failed to create indexes: %v", err)
 	}
 
-	// Initialize block processor with only the target account
+	// Scheduled jobs name their own accounts; the processor tracks all of them
+	var jobs []models.CompensatorJob
+	if *scheduled {
+		jobs, err = mongoStorage.ScheduledCompensatorJobs(ctx)
+		if err != nil {
+			log.Fatalf("Failed to load scheduled jobs: %v", err)
+		}
+		if len(jobs) == 0 {
+			log.Printf("No scheduled compensator jobs")
+			return
+		}
+		for _, job := range jobs {
+			accounts = append(accounts, job.Accounts...)
+		}
+		accounts = accountList(accounts).normalized()
+	}
+
+	// Initialize block processor with only the target accounts
 	// Pass nil for Telegram client since we don't want notifications for historical data
 	// Use empty user configs since we don't need notifications
 	userConfigs := []models.TelegramUserConfig{} // Empty = no notification rules
 	processor := sync.NewBlockProcessor(
 		mongoStorage,
-		nil,          // No Telegram client
-		userConfigs,  // No notification rules
-		[]string{*account}, // Only track the specified account
-		"",           // No message template
+		nil,         // No Telegram client
+		userConfigs, // No notification rules
+		accounts,    // Only track the specified accounts
+		"",          // No message template
 	)
+	// Apply the same sampling rules as the sync service
+	processor.SetSamplingRules(config.Steem.Sampling)
+	processor.SetAccountPaths(config.Steem.AccountPaths)
+	processor.SetFallbackExtraction(config.Steem.FallbackExtraction)
+	processor.SetMentionDetection(config.Steem.DetectMentions)
+	processor.SetStoragePolicies(config.Steem.StoragePolicies)
+	enrichment, err := enrich.NewPipeline(config.Enrichment, steemAPI)
+	if err != nil {
+		log.Fatalf("Failed to initialize enrichment: %v", err)
+	}
+	processor.SetEnrichment(enrichment)
 
 	// Process blocks
 	batchSize := config.Steem.BatchSize
@@ -90,50 +215,195 @@ func main() {
 	}
 	log.Printf("Using batch size: %d", batchSize)
 
+	c := &compensator{
+		steemAPI:  steemAPI,
+		storage:   mongoStorage,
+		processor: processor,
+		batchSize: batchSize,
+	}
 	ctx = context.Background()
-	totalBlocks := *endBlock - *startBlock + 1
-	log.Printf("Processing %d blocks from %d to %d", totalBlocks, *startBlock, *endBlock)
 
-	currentBlock := *startBlock
-	totalOperations := 0
-	processedBlocks := 0
+	// Estimate the blocks still to process before starting, so large runs can be stopped in time
+	var gaps, pending []models.BlockRange
+	switch {
+	case *scheduled:
+		for _, job := range jobs {
+			pending = append(pending, models.BlockRange{Start: job.LastProcessedBlock + 1, End: job.EndBlock})
+		}
+	case *auto:
+		gaps = c.findGaps(ctx, accounts, config.Steem.StartBlock)
+		if len(gaps) == 0 {
+			log.Printf("No coverage gaps found for %s", strings.Join(accounts, ","))
+			return
+		}
+		pending = gaps
+	default:
+		pending = []models.BlockRange{{Start: *startBlock, End: *endBlock}}
+	}
+	if *resume && !*scheduled {
+		resumed := pending[:0:0]
+		for _, r := range pending {
+			if left, ok := c.remaining(ctx, accounts, r.Start, r.End); ok {
+				resumed = append(resumed, left)
+			}
+		}
+		pending = resumed
+	}
+	e := c.estimateRun(ctx, accounts, pending)
+	logEstimate(e)
+	if *estimateOnly {
+		return
+	}
+	// Scheduled repairs are queued by the sync service and run unattended
+	if !*scheduled && !confirm(e, config.Compensator.ConfirmThreshold(), *yes) {
+		log.Fatal("Compensation not started")
+	}
+
+	if *scheduled {
+		for _, job := range jobs {
+			log.Printf("Running scheduled job %s (%s)", job.ID, job.Reason)
+			c.run(ctx, job.Accounts, job.StartBlock, job.EndBlock, true)
+		}
+		log.Printf("Scheduled compensation completed: ran %d job(s)", len(jobs))
+		return
+	}
+
+	if !*auto {
+		log.Printf("Compensator started: accounts=%s, start=%d, end=%d, config=%s", strings.Join(accounts, ","), *startBlock, *endBlock, configPath)
+		c.run(ctx, accounts, *startBlock, *endBlock, *resume)
+		return
+	}
 
-	for currentBlock <= *endBlock {
+	for _, gap := range gaps {
+		log.Printf("Compensating gap: blocks %d to %d (%d blocks)", gap.Start, gap.End, gap.End-gap.Start+1)
+		c.run(ctx, accounts, gap.Start, gap.End, *resume)
+	}
+	log.Printf("Auto compensation completed: filled %d gap(s)", len(gaps))
+}
+
+// findGaps returns the block ranges between startBlock and the last irreversible block
+// that were not scanned for at least one of the accounts
+func (c *compensator) findGaps(ctx context.Context, accounts []string, startBlock int64) []models.BlockRange {
+	dgp, err := c.steemAPI.GetDynamicGlobalProperties()
+	if err != nil {
+		log.Fatalf("Failed to get dynamic global properties: %v", err)
+	}
+	lastIrreversible := int64(dgp.LastIrreversibleBlockNum)
+	if startBlock <= 0 {
+		startBlock = 1
+	}
+	log.Printf("Checking coverage of %s from block %d to %d", strings.Join(accounts, ","), startBlock, lastIrreversible)
+
+	// One pass over the union of all gaps serves every account
+	var gaps []models.BlockRange
+	for _, account := range accounts {
+		covered, err := c.storage.GetCoverage(ctx, account)
+		if err != nil {
+			log.Fatalf("Failed to load coverage for %s: %v", account, err)
+		}
+		missing := models.MissingRanges(startBlock, lastIrreversible, covered)
+		for _, gap := range missing {
+			log.Printf("  %s: missing blocks %d to %d", account, gap.Start, gap.End)
+		}
+		gaps = append(gaps, missing...)
+	}
+	return models.MergeRanges(gaps)
+}
+
+// run compensates accounts over [startBlock, endBlock], checkpointing after every batch
+func (c *compensator) run(ctx context.Context, accounts []string, startBlock, endBlock int64, resume bool) {
+	totalBlocks := endBlock - startBlock + 1
+	log.Printf("Processing %d blocks from %d to %d", totalBlocks, startBlock, endBlock)
+
+	// Progress is checkpointed after every batch so an interrupted run can be resumed
+	now := time.Now()
+	job := &models.CompensatorJob{
+		ID:                 models.CompensatorJobID(accounts, startBlock, endBlock),
+		Accounts:           accounts,
+		StartBlock:         startBlock,
+		EndBlock:           endBlock,
+		LastProcessedBlock: startBlock - 1,
+		StartedAt:          now,
+		UpdatedAt:          now,
+	}
+	if resume {
+		saved, err := c.storage.GetCompensatorJob(ctx, job.ID)
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			log.Printf("No checkpoint found for job %s, starting from block %d", job.ID, startBlock)
+		case err != nil:
+			log.Fatalf("Failed to load checkpoint: %v", err)
+		case saved.Completed:
+			log.Printf("Job %s already completed at %v (%d operations saved)", job.ID, saved.UpdatedAt, saved.Operations)
+			return
+		default:
+			saved.Accounts = accounts
+			job = saved
+			log.Printf("Resuming job %s from block %d (%d operations saved so far)", job.ID, job.LastProcessedBlock+1, job.Operations)
+		}
+	}
+
+	currentBlock := job.LastProcessedBlock + 1
+	totalOperations := int(job.Operations)
+	processedBlocks := int(currentBlock - startBlock)
+	perAccount := make(map[string]int)
+
+	// A single pass over the range serves all accounts
+	for currentBlock <= endBlock {
 		// Calculate batch end
-		batchEnd := currentBlock + batchSize - 1
-		if batchEnd > *endBlock {
-			batchEnd = *endBlock
+		batchEnd := currentBlock + c.batchSize - 1
+		if batchEnd > endBlock {
+			batchEnd = endBlock
 		}
 
 		log.Printf("Fetching operations for blocks %d to %d...", currentBlock, batchEnd)
 
 		// Get all operations (both regular and virtual) in batch using GetOpsInBlocks
 		// This is more efficient than calling GetBlocks + GetOpsInBlocks separately
-		opsMap, err := steemAPI.GetOpsInBlocks(uint(currentBlock), uint(batchEnd+1), false)
+		opsMap, err := c.steemAPI.GetOpsInBlocks(uint(currentBlock), uint(batchEnd+1), false)
 		if err != nil {
 			log.Fatalf("Failed to get operations for blocks %d to %d: %v", currentBlock, batchEnd, err)
 		}
 		log.Printf("Retrieved operations for %d blocks", len(opsMap))
 
-		// Process each block in the batch
+		// Process all operations (regular + virtual) of the batch, then fetch the headers of the
+		// blocks with matches together
+		extracted := make(map[int64][]*models.Operation)
+		for blockNum, ops := range opsMap {
+			if len(ops) == 0 {
+				continue
+			}
+			operations, err := c.processor.ProcessOperations(ctx, ops)
+			if err != nil {
+				log.Fatalf("Failed to process operations for block %d: %v", blockNum, err)
+			}
+			extracted[int64(blockNum)] = operations
+		}
+		if err := sync.AttachBlockHeaders(c.steemAPI, extracted); err != nil {
+			log.Fatalf("Failed to get headers of blocks %d to %d: %v", currentBlock, batchEnd, err)
+		}
+
+		// Store each block in the batch
 		for i := currentBlock; i <= batchEnd; i++ {
 			blockNum := int64(i)
+			operations := extracted[blockNum]
 
-			// Process all operations (regular + virtual) for this block
-			var operations []*models.Operation
-			if ops, ok := opsMap[uint(blockNum)]; ok && len(ops) > 0 {
-				operations, err = processor.ProcessOperations(ctx, ops)
-				if err != nil {
-					log.Fatalf("Failed to process operations for block %d: %v", blockNum, err)
-				}
+			models.SetSource(operations, models.CompensatorSource(job.ID))
+			operations, err = c.processor.ApplySampling(ctx, operations)
+			if err != nil {
+				log.Fatalf("Failed to apply sampling for block %d: %v", blockNum, err)
 			}
 
 			// Store operations (InsertOperations handles duplicates via upsert)
 			if len(operations) > 0 {
-				if err := mongoStorage.InsertOperations(ctx, operations); err != nil {
+				c.processor.Enrich(ctx, operations)
+				if err := c.storage.InsertOperations(ctx, c.processor.ApplyStoragePolicy(operations)); err != nil {
 					log.Fatalf("Failed to insert operations for block %d: %v", blockNum, err)
 				}
 				totalOperations += len(operations)
+				for _, op := range operations {
+					perAccount[op.Account]++
+				}
 				log.Printf("Block %d: saved %d operations (regular + virtual)", blockNum, len(operations))
 			}
 
@@ -143,25 +413,31 @@ func main() {
 			}
 		}
 
+		if err := c.storage.AddCoverage(ctx, accounts, currentBlock, batchEnd); err != nil {
+			log.Printf("Warning: failed to record coverage for blocks %d to %d: %v", currentBlock, batchEnd, err)
+		}
+
+		job.LastProcessedBlock = batchEnd
+		job.Operations = int64(totalOperations)
+		job.UpdatedAt = time.Now()
+		if err := c.storage.SaveCompensatorJob(ctx, job); err != nil {
+			log.Printf("Warning: failed to save checkpoint at block %d: %v", batchEnd, err)
+		}
+
 		currentBlock = batchEnd + 1
 
 		// Small delay to avoid overwhelming the API
 		time.Sleep(100 * time.Millisecond)
 	}
 
-	log.Printf("Compensation completed: processed %d blocks, saved %d operations for account %s", processedBlocks, totalOperations, *account)
-}
-
-func loadConfig(path string) (*models.Config, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+	job.Completed = true
+	job.UpdatedAt = time.Now()
+	if err := c.storage.SaveCompensatorJob(ctx, job); err != nil {
+		log.Printf("Warning: failed to mark job %s completed: %v", job.ID, err)
 	}
 
-	var config models.Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config: %w", err)
+	log.Printf("Compensation completed: processed %d blocks, saved %d operations for %d account(s)", processedBlocks, totalOperations, len(accounts))
+	for _, account := range accounts {
+		log.Printf("  %s: %d operations saved in this run", account, perAccount[account])
 	}
-
-	return &config, nil
 }
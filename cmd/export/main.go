@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/config"
+	"github.com/ety001/sps-fund-watcher/internal/export"
+	"github.com/ety001/sps-fund-watcher/internal/logging"
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	querydsl "github.com/ety001/sps-fund-watcher/internal/query"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+	"github.com/ety001/sps-fund-watcher/internal/version"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// accountList collects accounts from repeated or comma-separated -account flags
+type accountList []string
+
+func (a *accountList) String() string {
+	return strings.Join(*a, ",")
+}
+
+func (a *accountList) Set(value string) error {
+	for _, account := range strings.Split(value, ",") {
+		if account = strings.TrimSpace(account); account != "" {
+			*a = append(*a, account)
+		}
+	}
+	return nil
+}
+
+func main() {
+	// Parse command line flags
+	var accounts accountList
+	flag.Var(&accounts, "account", "Account to export (comma-separated or repeatable; default all accounts)")
+	opType := flag.String("type", "", "Only export this operation type")
+	startBlock := flag.Int64("start", 0, "First block to export (default unbounded)")
+	endBlock := flag.Int64("end", 0, "Last block to export (default unbounded)")
+	expr := flag.String("q", "", "Filter expression, same syntax as the API's q parameter")
+	format := flag.String("format", "", "Output format: csv or jsonl (default from -output extension, else csv)")
+	output := flag.String("output", "", "Output file (default stdout)")
+	showVersion := flag.Bool("version", false, "Print version information and exit")
+	var configFlags config.Flags
+	configFlags.Register(flag.CommandLine)
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(version.String("export"))
+		return
+	}
+
+	// Get config file path from remaining arguments
+	args := flag.Args()
+	if len(args) == 0 {
+		log.Fatal("Config file path is required")
+	}
+	configPath := args[0]
+
+	if *startBlock > 0 && *endBlock > 0 && *startBlock > *endBlock {
+		log.Fatalf("Start block (%d) must be less than or equal to end block (%d)", *startBlock, *endBlock)
+	}
+
+	if *format == "" {
+		*format = export.FormatCSV
+		if ext := strings.TrimPrefix(filepath.Ext(*output), "."); ext == export.FormatJSONL || ext == "ndjson" {
+			*format = export.FormatJSONL
+		}
+	}
+
+	query := storage.OperationQuery{OpType: *opType, StartBlock: *startBlock, EndBlock: *endBlock}
+	var conditions bson.A
+	switch len(accounts) {
+	case 0:
+	case 1:
+		query.Account = accounts[0]
+	default:
+		conditions = append(conditions, bson.M{"account": bson.M{"$in": []string(accounts)}})
+	}
+	if *expr != "" {
+		filter, err := querydsl.Parse(*expr)
+		if err != nil {
+			log.Fatalf("Invalid filter expression: %v", err)
+		}
+		conditions = append(conditions, filter)
+	}
+	if len(conditions) > 0 {
+		query.Filter = bson.M{"$and": conditions}
+	}
+
+	// Load configuration
+	config, err := configFlags.Load(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	logging.Setup(config.Logging)
+	version.LogBanner("export", config.Summary())
+
+	// Initialize MongoDB storage
+	mongoStorage, err := storage.NewMongoDB(config.MongoDB.URI, config.MongoDB.Database)
+	if err != nil {
+		log.Fatalf("Failed to initialize MongoDB: %v", err)
+	}
+	defer mongoStorage.Close()
+	mongoStorage.SetSlowQueryThreshold(time.Duration(config.MongoDB.SlowQueryMS) * time.Millisecond)
+
+	// Open the destination
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.Fatalf("Failed to create output file: %v", err)
+		}
+		defer file.Close()
+		out = file
+	}
+	buffered := bufio.NewWriter(out)
+
+	writer, err := export.NewWriter(buffered, *format)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("Exporting operations as %s (accounts=%s, type=%s, blocks %d-%d)", *format, accounts.String(), *opType, *startBlock, *endBlock)
+	var count int64
+	err = mongoStorage.StreamOperations(context.Background(), query, func(op *models.Operation) error {
+		if err := writer.Write(op); err != nil {
+			return err
+		}
+		count++
+		if count%100000 == 0 {
+			log.Printf("Progress: %d operations exported", count)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("Export failed after %d operations: %v", count, err)
+	}
+
+	if err := writer.Flush(); err != nil {
+		log.Fatalf("Failed to write output: %v", err)
+	}
+	if err := buffered.Flush(); err != nil {
+		log.Fatalf("Failed to write output: %v", err)
+	}
+	log.Printf("Export completed: %d operations", count)
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/config"
+	"github.com/ety001/sps-fund-watcher/internal/enrich"
+	"github.com/ety001/sps-fund-watcher/internal/logging"
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+	"github.com/ety001/sps-fund-watcher/internal/version"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// updateBatch is how many operations are updated per write
+const updateBatch = 500
+
+// backfill values stored operations with the daily prices
+type backfill struct {
+	storage *storage.MongoDB
+	prices  map[string]map[string]float64 // USD price by asset and day
+	dryRun  bool
+
+	pending      map[string]map[string]interface{}
+	valued       int
+	unpriced     int                        // Operations on days without a price for their asset
+	unpricedDays map[string]map[string]bool // Those days by asset
+}
+
+func main() {
+	// Parse command line flags
+	from := flag.String("from", "", "First day (YYYY-MM-DD, UTC) of operations to value (defaults to the first operation without usd_value)")
+	to := flag.String("to", "", "Last day (YYYY-MM-DD, UTC) of operations to value (defaults to the last operation without usd_value)")
+	overwrite := flag.Bool("overwrite", false, "Also revalue operations that already have usd_value, e.g. valued at the price when they were stored")
+	csvFile := flag.String("csv", "", "Read daily prices from a CSV file of date,usd[,asset] lines instead of CoinGecko")
+	refetch := flag.Bool("refetch", false, "Fetch prices from CoinGecko even for days that already have a stored price")
+	dryRun := flag.Bool("dry-run", false, "Only count the operations that would be valued")
+	showVersion := flag.Bool("version", false, "Print version information and exit")
+	var configFlags config.Flags
+	configFlags.Register(flag.CommandLine)
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(version.String("price-backfill"))
+		return
+	}
+
+	// Get config file path from remaining arguments
+	args := flag.Args()
+	if len(args) == 0 {
+		log.Fatal("Config file path is required")
+	}
+	configPath := args[0]
+
+	// Load configuration
+	config, err := configFlags.Load(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	logging.Setup(config.Logging)
+	logging.SetLabels(config.Instance.Labels())
+	version.LogBanner("price-backfill", config.Summary())
+
+	// Initialize MongoDB storage
+	mongoStorage, err := storage.NewMongoDB(config.MongoDB.URI, config.MongoDB.Database)
+	if err != nil {
+		log.Fatalf("Failed to initialize MongoDB: %v", err)
+	}
+	defer mongoStorage.Close()
+	mongoStorage.SetSlowQueryThreshold(time.Duration(config.MongoDB.SlowQueryMS) * time.Millisecond)
+	mongoStorage.SetChain(config.Steem.Chain)
+	if err := mongoStorage.CreateIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create indexes: %v", err)
+	}
+	log.Printf("MongoDB initialized: %s/%s", config.MongoDB.URI, config.MongoDB.Database)
+
+	ctx := context.Background()
+	query := storage.OperationQuery{}
+	if !*overwrite {
+		query.Filter = bson.M{"enrichment.usd_value": bson.M{"$exists": false}}
+	}
+	if *from != "" {
+		if query.From, err = time.Parse(time.DateOnly, *from); err != nil {
+			log.Fatalf("Invalid -from %q: expected YYYY-MM-DD", *from)
+		}
+	}
+	if *to != "" {
+		if query.To, err = time.Parse(time.DateOnly, *to); err != nil {
+			log.Fatalf("Invalid -to %q: expected YYYY-MM-DD", *to)
+		}
+		query.To = query.To.AddDate(0, 0, 1)
+	}
+
+	first, last, err := mongoStorage.OperationTimeRange(ctx, query)
+	if errors.Is(err, storage.ErrNotFound) {
+		log.Printf("No operations to value")
+		return
+	}
+	if err != nil {
+		log.Fatalf("Failed to get the operation time range: %v", err)
+	}
+	firstDay, lastDay := first.UTC().Format(time.DateOnly), last.UTC().Format(time.DateOnly)
+
+	// Prices are stored, so reruns and later ranges only fetch the days they are missing
+	if *csvFile != "" {
+		file, err := os.Open(*csvFile)
+		if err != nil {
+			log.Fatalf("Failed to open price file: %v", err)
+		}
+		prices, err := enrich.ReadDailyPrices(file)
+		file.Close()
+		if err != nil {
+			log.Fatalf("Failed to read price file: %v", err)
+		}
+		if err := mongoStorage.SaveDailyPrices(ctx, prices); err != nil {
+			log.Fatalf("Failed to save prices: %v", err)
+		}
+		log.Printf("Imported %d daily prices from %s", len(prices), *csvFile)
+	}
+	prices := make(map[string]map[string]float64)
+	for _, asset := range enrich.HistoryAssets {
+		assetPrices, err := mongoStorage.GetDailyPrices(ctx, asset, firstDay, lastDay)
+		if err != nil {
+			log.Fatalf("Failed to load %s prices: %v", asset, err)
+		}
+		prices[asset] = assetPrices
+		if *csvFile != "" {
+			continue
+		}
+		missingFrom, missingTo, missing := missingDays(assetPrices, first, last, *refetch)
+		if !missing {
+			continue
+		}
+		log.Printf("Fetching daily %s prices from %s to %s", asset, missingFrom.Format(time.DateOnly), missingTo.Format(time.DateOnly))
+		fetched, err := enrich.FetchDailyPrices(ctx, config.Enrichment.PriceHistory, asset, missingFrom, missingTo)
+		if err != nil {
+			log.Fatalf("Failed to fetch %s prices: %v", asset, err)
+		}
+		if err := mongoStorage.SaveDailyPrices(ctx, fetched); err != nil {
+			log.Fatalf("Failed to save prices: %v", err)
+		}
+		for _, price := range fetched {
+			assetPrices[price.Date] = price.USD
+		}
+	}
+
+	b := &backfill{
+		storage:      mongoStorage,
+		prices:       prices,
+		dryRun:       *dryRun,
+		pending:      make(map[string]map[string]interface{}),
+		unpricedDays: make(map[string]map[string]bool),
+	}
+	log.Printf("Valuing operations from %s to %s with %d daily STEEM and %d daily SBD prices", firstDay, lastDay, len(prices["STEEM"]), len(prices["SBD"]))
+	if err := mongoStorage.StreamOperations(ctx, query, b.value); err != nil {
+		log.Fatalf("Failed to value operations: %v", err)
+	}
+	if err := b.flush(ctx); err != nil {
+		log.Fatalf("Failed to value operations: %v", err)
+	}
+
+	for _, asset := range enrich.HistoryAssets {
+		if days := b.unpricedDays[asset]; len(days) > 0 {
+			log.Printf("No %s price for %d days with operations: %s", asset, len(days), dayRanges(days))
+		}
+	}
+	if *dryRun {
+		log.Printf("Dry run: %d operations would be valued, %d operations have no price for their day", b.valued, b.unpriced)
+		return
+	}
+	log.Printf("Price backfill completed: %d operations valued, %d operations have no price for their day", b.valued, b.unpriced)
+}
+
+// missingDays returns the range of days from first to last without a price, all of them with
+// refetch; days in between that already have one are fetched again
+func missingDays(prices map[string]float64, first, last time.Time, refetch bool) (time.Time, time.Time, bool) {
+	var from, to time.Time
+	start := first.UTC().Truncate(24 * time.Hour)
+	for day := start; !day.After(last.UTC()); day = day.AddDate(0, 0, 1) {
+		if _, ok := prices[day.Format(time.DateOnly)]; ok && !refetch {
+			continue
+		}
+		if from.IsZero() {
+			from = day
+		}
+		to = day
+	}
+	return from, to, !from.IsZero()
+}
+
+// dayRanges lists days in order, joining consecutive ones into ranges,
+// e.g. "2019-06-01..2019-06-03, 2019-06-07"
+func dayRanges(days map[string]bool) string {
+	sorted := make([]string, 0, len(days))
+	for day := range days {
+		sorted = append(sorted, day)
+	}
+	sort.Strings(sorted)
+
+	var ranges []string
+	for i := 0; i < len(sorted); {
+		j := i
+		for j+1 < len(sorted) && nextDay(sorted[j]) == sorted[j+1] {
+			j++
+		}
+		if j == i {
+			ranges = append(ranges, sorted[i])
+		} else {
+			ranges = append(ranges, sorted[i]+".."+sorted[j])
+		}
+		i = j + 1
+	}
+	return strings.Join(ranges, ", ")
+}
+
+// nextDay returns the YYYY-MM-DD day after day
+func nextDay(day string) string {
+	t, err := time.Parse(time.DateOnly, day)
+	if err != nil {
+		return ""
+	}
+	return t.AddDate(0, 0, 1).Format(time.DateOnly)
+}
+
+// value sets usd_value and usd_price with the market price of the operation's asset on the
+// operation's day
+func (b *backfill) value(op *models.Operation) error {
+	asset, ok := models.OperationAmount(op.OpData)
+	if !ok {
+		return nil
+	}
+	prices, ok := b.prices[asset.Symbol]
+	if !ok {
+		return nil
+	}
+	day := op.Timestamp.UTC().Format(time.DateOnly)
+	price, ok := prices[day]
+	if !ok {
+		b.unpriced++
+		if b.unpricedDays[asset.Symbol] == nil {
+			b.unpricedDays[asset.Symbol] = make(map[string]bool)
+		}
+		b.unpricedDays[asset.Symbol][day] = true
+		return nil
+	}
+	fields := map[string]interface{}{"usd_value": asset.Amount * price, "usd_price": price}
+
+	b.valued++
+	if b.dryRun {
+		return nil
+	}
+	b.pending[op.ID] = fields
+	if len(b.pending) >= updateBatch {
+		return b.flush(context.Background())
+	}
+	return nil
+}
+
+// flush writes the pending updates
+func (b *backfill) flush(ctx context.Context) error {
+	if err := b.storage.SetEnrichmentFields(ctx, b.pending); err != nil {
+		return err
+	}
+	b.pending = make(map[string]map[string]interface{})
+	return nil
+}
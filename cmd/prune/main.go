@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/config"
+	"github.com/ety001/sps-fund-watcher/internal/logging"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+	"github.com/ety001/sps-fund-watcher/internal/version"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "Only count the operations that would be deleted")
+	showVersion := flag.Bool("version", false, "Print version information and exit")
+	var configFlags config.Flags
+	configFlags.Register(flag.CommandLine)
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(version.String("prune"))
+		return
+	}
+
+	// Get config file path from remaining arguments
+	args := flag.Args()
+	if len(args) == 0 {
+		log.Fatal("Config file path is required")
+	}
+	configPath := args[0]
+
+	// Load configuration
+	config, err := configFlags.Load(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	logging.Setup(config.Logging)
+	version.LogBanner("prune", config.Summary())
+
+	if !config.Retention.Enabled() {
+		log.Printf("No retention configured (retention.days and all rule days are 0), nothing to prune")
+		return
+	}
+
+	// Initialize MongoDB storage
+	mongoStorage, err := storage.NewMongoDB(config.MongoDB.URI, config.MongoDB.Database)
+	if err != nil {
+		log.Fatalf("Failed to initialize MongoDB: %v", err)
+	}
+	defer mongoStorage.Close()
+	mongoStorage.SetSlowQueryThreshold(time.Duration(config.MongoDB.SlowQueryMS) * time.Millisecond)
+	log.Printf("MongoDB initialized: %s/%s", config.MongoDB.URI, config.MongoDB.Database)
+
+	count, err := mongoStorage.PruneOperations(context.Background(), config.Retention, time.Now(), *dryRun)
+	if err != nil {
+		log.Fatalf("Failed to prune operations: %v", err)
+	}
+
+	if *dryRun {
+		log.Printf("Dry run: %d operations are past their retention period", count)
+		return
+	}
+	log.Printf("Pruned %d operations past their retention period", count)
+}
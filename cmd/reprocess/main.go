@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/chain"
+	"github.com/ety001/sps-fund-watcher/internal/config"
+	"github.com/ety001/sps-fund-watcher/internal/enrich"
+	"github.com/ety001/sps-fund-watcher/internal/logging"
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/plugin"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+	"github.com/ety001/sps-fund-watcher/internal/sync"
+	"github.com/ety001/sps-fund-watcher/internal/version"
+)
+
+// accountList collects accounts from repeated or comma-separated -account flags
+type accountList []string
+
+func (a *accountList) String() string {
+	return strings.Join(*a, ",")
+}
+
+func (a *accountList) Set(value string) error {
+	for _, account := range strings.Split(value, ",") {
+		if account = strings.TrimSpace(account); account != "" {
+			*a = append(*a, account)
+		}
+	}
+	return nil
+}
+
+// reprocessor replays archived blocks through the block processor
+type reprocessor struct {
+	steemAPI  chain.Client
+	storage   *storage.MongoDB
+	processor *sync.BlockProcessor
+
+	blocks     int
+	operations int
+	headers    int // Blocks whose header had to be fetched from the node
+}
+
+func main() {
+	// Parse command line flags
+	var accountFlags accountList
+	flag.Var(&accountFlags, "account", "Account to extract (comma-separated or repeatable; defaults to steem.accounts and the auto-tracked accounts)")
+	startBlock := flag.Int64("start", 0, "Start block number (defaults to the first archived block)")
+	endBlock := flag.Int64("end", 0, "End block number (defaults to the last archived block)")
+	showVersion := flag.Bool("version", false, "Print version information and exit")
+	var configFlags config.Flags
+	configFlags.Register(flag.CommandLine)
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(version.String("reprocess"))
+		return
+	}
+
+	// Get config file path from remaining arguments
+	args := flag.Args()
+	if len(args) == 0 {
+		log.Fatal("Config file path is required")
+	}
+	configPath := args[0]
+
+	// Load configuration
+	config, err := configFlags.Load(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	logging.Setup(config.Logging)
+	logging.SetLabels(config.Instance.Labels())
+	version.LogBanner("reprocess", config.Summary())
+
+	// The node is only asked for headers of blocks archived without one
+	steemAPI, err := chain.NewClient(config.Steem)
+	if err != nil {
+		log.Fatalf("Failed to initialize chain client: %v", err)
+	}
+
+	// Initialize MongoDB storage
+	mongoStorage, err := storage.NewMongoDB(config.MongoDB.URI, config.MongoDB.Database)
+	if err != nil {
+		log.Fatalf("Failed to initialize MongoDB: %v", err)
+	}
+	defer mongoStorage.Close()
+	mongoStorage.SetSlowQueryThreshold(time.Duration(config.MongoDB.SlowQueryMS) * time.Millisecond)
+	mongoStorage.SetChain(config.Steem.Chain)
+	log.Printf("MongoDB initialized: %s/%s", config.MongoDB.URI, config.MongoDB.Database)
+
+	ctx := context.Background()
+	first, last, err := mongoStorage.ArchivedBlockRange(ctx)
+	if errors.Is(err, storage.ErrNotFound) {
+		log.Fatal("No archived blocks (set archive.mode for the sync service to archive blocks)")
+	}
+	if err != nil {
+		log.Fatalf("Failed to get the archived block range: %v", err)
+	}
+	if *startBlock <= 0 {
+		*startBlock = first
+	}
+	if *endBlock <= 0 {
+		*endBlock = last
+	}
+	if *startBlock > *endBlock {
+		log.Fatalf("Start block (%d) must be less than or equal to end block (%d)", *startBlock, *endBlock)
+	}
+
+	// Extract operations exactly like the sync service; no notifications are sent
+	accounts := []string(accountFlags)
+	if len(accounts) == 0 {
+		accounts = config.Steem.Accounts
+	}
+	processor := sync.NewBlockProcessor(mongoStorage, nil, []models.TelegramUserConfig{}, accounts, "")
+	if len(accountFlags) == 0 {
+		autoTracked, err := mongoStorage.ListAutoTracked(ctx)
+		if err != nil {
+			log.Fatalf("Failed to load auto-tracked accounts: %v", err)
+		}
+		var names []string
+		for _, account := range autoTracked {
+			names = append(names, account.Account)
+		}
+		processor.SetAutoTrackedAccounts(names)
+	}
+	processor.SetSamplingRules(config.Steem.Sampling)
+	processor.SetAccountPaths(config.Steem.AccountPaths)
+	processor.SetFallbackExtraction(config.Steem.FallbackExtraction)
+	processor.SetMentionDetection(config.Steem.DetectMentions)
+	processor.SetStoragePolicies(config.Steem.StoragePolicies)
+	enrichment, err := enrich.NewPipeline(config.Enrichment, steemAPI)
+	if err != nil {
+		log.Fatalf("Failed to initialize enrichment: %v", err)
+	}
+	processor.SetEnrichment(enrichment)
+	plugins := plugin.NewRunner(config.Plugins)
+	defer plugins.Close()
+	processor.SetPlugins(plugins)
+
+	batchSize := config.Steem.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100 // Default batch size
+	}
+
+	r := &reprocessor{
+		steemAPI:  steemAPI,
+		storage:   mongoStorage,
+		processor: processor,
+	}
+	log.Printf("Reprocessing archived blocks %d to %d", *startBlock, *endBlock)
+	for current := *startBlock; current <= *endBlock; current += batchSize {
+		batchEnd := min(current+batchSize-1, *endBlock)
+		if err := r.reprocess(ctx, current, batchEnd); err != nil {
+			log.Fatalf("Failed to reprocess blocks %d to %d: %v", current, batchEnd, err)
+		}
+		log.Printf("Progress: blocks up to %d reprocessed, %d archived blocks, %d operations saved", batchEnd, r.blocks, r.operations)
+	}
+
+	log.Printf("Reprocessing completed: %d archived blocks, %d operations saved, %d headers fetched from the node",
+		r.blocks, r.operations, r.headers)
+}
+
+// reprocess extracts and saves the operations of the archived blocks in [startBlock, endBlock]
+// Operations are upserted, so existing records get the new fields and keep first_seen_at and source
+func (r *reprocessor) reprocess(ctx context.Context, startBlock, endBlock int64) error {
+	blocks, err := r.storage.GetArchivedBlocks(ctx, startBlock, endBlock)
+	if err != nil {
+		return err
+	}
+
+	extracted := make(map[int64][]*models.Operation)
+	withoutHeader := make(map[int64][]*models.Operation)
+	for i := range blocks {
+		block := &blocks[i]
+		ops, err := sync.ArchivedOperations(block)
+		if err != nil {
+			return err
+		}
+		operations, err := r.processor.ProcessOperations(ctx, ops)
+		if err != nil {
+			return fmt.Errorf("failed to process operations for block %d: %w", block.BlockNum, err)
+		}
+		if len(operations) == 0 {
+			continue
+		}
+		if !sync.SetArchivedBlockHeader(block, operations) {
+			withoutHeader[block.BlockNum] = operations
+		}
+		extracted[block.BlockNum] = operations
+	}
+	r.blocks += len(blocks)
+
+	// Blocks archived in "blocks" mode only have a header if they had matches at the time
+	if err := sync.AttachBlockHeaders(r.steemAPI, withoutHeader); err != nil {
+		return err
+	}
+	r.headers += len(withoutHeader)
+
+	for _, block := range blocks {
+		operations := extracted[block.BlockNum]
+		if len(operations) == 0 {
+			continue
+		}
+		models.SetSource(operations, models.SourceReprocess)
+
+		// Aggregate counts were recorded when the block was first synced
+		kept := r.processor.SampleOperations(operations)
+		if len(kept) == 0 {
+			continue
+		}
+
+		r.processor.Enrich(ctx, kept)
+		if err := r.storage.InsertOperations(ctx, r.processor.ApplyStoragePolicy(kept)); err != nil {
+			return fmt.Errorf("failed to save operations for block %d: %w", block.BlockNum, err)
+		}
+		r.operations += len(kept)
+	}
+	return nil
+}
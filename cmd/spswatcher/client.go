@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultAPIURL is used when neither -api nor SPSWATCHER_API is set
+const defaultAPIURL = "http://localhost:8080"
+
+// apiClient calls the watcher's HTTP API
+type apiClient struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+	stream  *http.Client // Without a timeout, for long-lived event streams
+}
+
+// errStreamUnsupported is returned by streamEvents when the API has no event stream (older servers)
+var errStreamUnsupported = errors.New("API does not serve event streams")
+
+// serverEvent is one Server-Sent Event
+type serverEvent struct {
+	id    string
+	event string
+	data  string
+}
+
+// apiFlags registers the flags shared by commands that talk to the API
+func apiFlags(fs *flag.FlagSet) (baseURL, apiKey *string) {
+	baseURL = fs.String("api", envOr("SPSWATCHER_API", defaultAPIURL), "API base URL, including api.base_path if set (env SPSWATCHER_API)")
+	apiKey = fs.String("api-key", os.Getenv("SPSWATCHER_API_KEY"), "API key sent as X-API-Key (env SPSWATCHER_API_KEY)")
+	return baseURL, apiKey
+}
+
+func newAPIClient(baseURL, apiKey string) *apiClient {
+	return &apiClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		http:    &http.Client{Timeout: 30 * time.Second},
+		stream:  &http.Client{},
+	}
+}
+
+// getJSON requests path below /api/v1 and decodes the JSON response into out
+func (c *apiClient) getJSON(path string, query url.Values, out interface{}) error {
+	u := c.baseURL + "/api/v1" + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("%s returned %s: %s", u, resp.Status, apiErr.Error)
+		}
+		return fmt.Errorf("%s returned %s", u, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response of %s: %w", u, err)
+	}
+	return nil
+}
+
+// streamEvents reads the Server-Sent Events stream at path below /api/v1 and calls fn for each event
+// lastEventID, when set, is sent as Last-Event-ID. It returns when the stream ends, ctx is done or fn fails
+func (c *apiClient) streamEvents(ctx context.Context, path string, query url.Values, lastEventID string, fn func(serverEvent) error) error {
+	u := c.baseURL + "/api/v1" + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+
+	resp, err := c.stream.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errStreamUnsupported
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("%s returned %s: %s", u, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024) // Operations with large op_data are single long lines
+	var event serverEvent
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			// A blank line dispatches the event; retry-only blocks have no data
+			if event.data != "" {
+				if err := fn(event); err != nil {
+					return err
+				}
+			}
+			event = serverEvent{}
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			event.id = value
+		case "event":
+			event.event = value
+		case "data":
+			if event.data != "" {
+				event.data += "\n"
+			}
+			event.data += value
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("failed to read event stream: %w", err)
+	}
+	return nil
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
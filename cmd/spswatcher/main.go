@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ety001/sps-fund-watcher/internal/version"
+)
+
+// command is a spswatcher subcommand; it parses its own flags from args
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{name: "tail", summary: "Follow new operations of an account", run: runTail},
+	{name: "ops", summary: "List stored operations of an account", run: runOps},
+	{name: "status", summary: "Check the sync lag; exits 0 (OK), 1 (WARNING), 2 (CRITICAL) or 3 (UNKNOWN)", run: runStatus},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: spswatcher <command> [flags]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun 'spswatcher <command> -h' for the flags of a command.\n")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+	switch name {
+	case "-version", "--version", "version":
+		fmt.Println(version.String("spswatcher"))
+		return
+	case "-h", "-help", "--help", "help":
+		usage()
+		return
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == name {
+			if err := cmd.run(os.Args[2:]); err != nil {
+				var exit *exitError
+				if errors.As(err, &exit) {
+					fmt.Println(exit.message)
+					os.Exit(exit.code)
+				}
+				fmt.Fprintf(os.Stderr, "spswatcher %s: %v\n", name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "spswatcher: unknown command %q\n\n", name)
+	usage()
+	os.Exit(2)
+}
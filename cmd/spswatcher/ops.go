@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/config"
+	"github.com/ety001/sps-fund-watcher/internal/export"
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	querydsl "github.com/ety001/sps-fund-watcher/internal/query"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+)
+
+// opsPageSize is the page size used when collecting operations; the API caps it at 100
+const opsPageSize = 100
+
+// Output formats of the ops command besides the export formats
+const formatTable = "table"
+
+// opsQuery selects the operations listed by the ops command
+type opsQuery struct {
+	account string
+	opTypes []string
+	expr    string
+	from    time.Time
+	to      time.Time
+	limit   int
+}
+
+func runOps(args []string) error {
+	fs := flag.NewFlagSet("ops", flag.ExitOnError)
+	baseURL, apiKey := apiFlags(fs)
+	account := fs.String("account", "", "Account to query (required)")
+	opTypes := fs.String("type", "", "Only list these operation types (comma-separated)")
+	expr := fs.String("q", "", "Filter expression, same syntax as the API's q parameter")
+	since := fs.String("since", "", "Only operations since this age (e.g. 7d, 12h) or time (YYYY-MM-DD or RFC3339)")
+	until := fs.String("until", "", "Only operations before this age or time")
+	limit := fs.Int("limit", 100, "Maximum number of operations (newest first)")
+	format := fs.String("format", formatTable, "Output format: table, csv or jsonl")
+	direct := fs.Bool("direct", false, "Read MongoDB directly instead of calling the API (needs -config)")
+	configPath := fs.String("config", "", "Config file for -direct")
+	_ = fs.Parse(args)
+
+	if *account == "" {
+		return errors.New("-account is required")
+	}
+	if err := models.ValidateAccountName(*account); err != nil {
+		return err
+	}
+	if *limit < 1 {
+		return errors.New("-limit must be at least 1")
+	}
+	switch *format {
+	case formatTable, export.FormatCSV, export.FormatJSONL:
+	default:
+		return fmt.Errorf("unknown format %q (use table, csv or jsonl)", *format)
+	}
+
+	query := opsQuery{account: *account, expr: *expr, limit: *limit}
+	if *opTypes != "" {
+		for _, opType := range strings.Split(*opTypes, ",") {
+			if opType = strings.TrimSpace(opType); opType != "" {
+				query.opTypes = append(query.opTypes, opType)
+			}
+		}
+	}
+	now := time.Now().UTC()
+	var err error
+	if query.from, err = parseSince(*since, now); err != nil {
+		return fmt.Errorf("invalid -since: %w", err)
+	}
+	if query.to, err = parseSince(*until, now); err != nil {
+		return fmt.Errorf("invalid -until: %w", err)
+	}
+
+	var ops []models.Operation
+	if *direct {
+		if *configPath == "" {
+			return errors.New("-direct needs -config")
+		}
+		ops, err = queryOpsDirect(*configPath, query)
+	} else {
+		ops, err = queryOpsAPI(newAPIClient(*baseURL, *apiKey), query)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *format == formatTable {
+		return printOpsTable(ops)
+	}
+	writer, err := export.NewWriter(os.Stdout, *format)
+	if err != nil {
+		return err
+	}
+	for i := range ops {
+		if err := writer.Write(&ops[i]); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// parseSince parses an age relative to now ("7d", "12h", "30m") or an absolute time
+// An empty value returns the zero time
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if age, err := time.ParseDuration(value); err == nil && age >= 0 {
+		return now.Add(-age), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%q is neither an age like 7d or 12h nor a date", value)
+}
+
+// queryOpsAPI pages through the operations endpoint until limit operations are collected
+func queryOpsAPI(client *apiClient, query opsQuery) ([]models.Operation, error) {
+	params := url.Values{"page_size": {strconv.Itoa(opsPageSize)}}
+	if len(query.opTypes) > 0 {
+		params.Set("type", strings.Join(query.opTypes, ","))
+	}
+	if query.expr != "" {
+		params.Set("q", query.expr)
+	}
+	if !query.from.IsZero() {
+		params.Set("from", query.from.Format(time.RFC3339))
+	}
+	if !query.to.IsZero() {
+		params.Set("to", query.to.Format(time.RFC3339))
+	}
+
+	var ops []models.Operation
+	for page := 1; len(ops) < query.limit; page++ {
+		params.Set("page", strconv.Itoa(page))
+		var result models.OperationResponse
+		if err := client.getJSON("/accounts/"+url.PathEscape(query.account)+"/operations", params, &result); err != nil {
+			return nil, err
+		}
+		ops = append(ops, result.Operations...)
+		if !result.HasMore {
+			break
+		}
+	}
+	if len(ops) > query.limit {
+		ops = ops[:query.limit]
+	}
+	return ops, nil
+}
+
+// queryOpsDirect runs the same query against MongoDB
+func queryOpsDirect(configPath string, query opsQuery) ([]models.Operation, error) {
+	config, err := config.Load(configPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	mongoStorage, err := storage.NewMongoDB(config.MongoDB.URI, config.MongoDB.Database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize MongoDB: %w", err)
+	}
+	defer mongoStorage.Close()
+
+	storageQuery := storage.OperationQuery{Account: query.account, OpTypes: query.opTypes, From: query.from, To: query.to}
+	if query.expr != "" {
+		filter, err := querydsl.Parse(query.expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -q: %w", err)
+		}
+		storageQuery.Filter = filter
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	var ops []models.Operation
+	for page := 1; len(ops) < query.limit; page++ {
+		result, err := mongoStorage.QueryOperations(ctx, storageQuery, page, opsPageSize)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, result.Operations...)
+		if !result.HasMore {
+			break
+		}
+	}
+	if len(ops) > query.limit {
+		ops = ops[:query.limit]
+	}
+	return ops, nil
+}
+
+// printOpsTable prints operations as aligned columns
+func printOpsTable(ops []models.Operation) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tBLOCK\tTYPE\tFROM\tTO\tAMOUNT\tMEMO")
+	for _, op := range ops {
+		from, _ := op.OpData["from"].(string)
+		to, _ := op.OpData["to"].(string)
+		if to == "" {
+			to, _ = op.OpData["receiver"].(string)
+		}
+		var amount string
+		if asset, ok := models.OperationAmount(op.OpData); ok {
+			amount = asset.String()
+		}
+		memo, _ := op.OpData["memo"].(string)
+		if len(memo) > maxDetailLength {
+			memo = memo[:maxDetailLength] + "…"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t%s\n", op.Timestamp.UTC().Format("2006-01-02 15:04:05"),
+			op.BlockNum, op.OpType, orDash(from), orDash(to), orDash(amount), strings.ReplaceAll(memo, "\n", " "))
+	}
+	return w.Flush()
+}
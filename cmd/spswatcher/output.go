@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+)
+
+// ANSI colors used by the terminal output
+const (
+	colorReset  = "\033[0m"
+	colorBold   = "\033[1m"
+	colorDim    = "\033[2m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorCyan   = "\033[36m"
+)
+
+// opPrinter prints operations as one line each
+type opPrinter struct {
+	out   io.Writer
+	color bool
+}
+
+// newOpPrinter colors output only on terminals and when NO_COLOR is unset
+func newOpPrinter(out *os.File, noColor bool) *opPrinter {
+	color := !noColor && os.Getenv("NO_COLOR") == ""
+	if info, err := out.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		color = false
+	}
+	return &opPrinter{out: out, color: color}
+}
+
+func (p *opPrinter) paint(color, s string) string {
+	if !p.color || s == "" {
+		return s
+	}
+	return color + s + colorReset
+}
+
+// Print writes "<time> #<block> <type> <parties> <amount> <details>" seen from op.Account
+func (p *opPrinter) Print(op *models.Operation) {
+	from, _ := op.OpData["from"].(string)
+	to, _ := op.OpData["to"].(string)
+	if to == "" {
+		to, _ = op.OpData["receiver"].(string)
+	}
+
+	typeColor := colorCyan
+	switch {
+	case op.OpType == "proposal_pay":
+		typeColor = colorYellow
+	case to == op.Account && from != op.Account:
+		typeColor = colorGreen
+	case from == op.Account && to != "" && to != op.Account:
+		typeColor = colorRed
+	}
+
+	fields := []string{
+		p.paint(colorDim, op.Timestamp.UTC().Format("2006-01-02 15:04:05")),
+		p.paint(colorDim, fmt.Sprintf("#%d", op.BlockNum)),
+		p.paint(typeColor, fmt.Sprintf("%-22s", op.OpType)),
+	}
+	if from != "" || to != "" {
+		fields = append(fields, fmt.Sprintf("%s → %s", orDash(from), orDash(to)))
+	} else {
+		fields = append(fields, op.Account)
+	}
+	if amount, ok := models.OperationAmount(op.OpData); ok {
+		fields = append(fields, p.paint(colorBold, amount.String()))
+	}
+	if details := opDetails(op.OpData); details != "" {
+		fields = append(fields, p.paint(colorDim, details))
+	}
+	if op.Unconfirmed {
+		fields = append(fields, p.paint(colorYellow, "(unconfirmed)"))
+	}
+
+	fmt.Fprintln(p.out, strings.Join(fields, "  "))
+}
+
+// summarizedFields are left out of the details because the line already shows them
+var summarizedFields = map[string]bool{
+	"from": true, "to": true, "receiver": true,
+	"amount": true, "payment": true, "additional_funds": true,
+	"body": true, "json_metadata": true,
+}
+
+// maxDetailLength truncates long detail values such as memos
+const maxDetailLength = 60
+
+// opDetails formats the remaining op_data fields as sorted key=value pairs
+func opDetails(opData map[string]interface{}) string {
+	keys := make([]string, 0, len(opData))
+	for key := range opData {
+		if !summarizedFields[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		var value string
+		switch v := opData[key].(type) {
+		case string:
+			value = v
+		default:
+			encoded, _ := json.Marshal(v)
+			value = string(encoded)
+		}
+		if value == "" || value == `""` || value == "[]" || value == "{}" {
+			continue
+		}
+		if len(value) > maxDetailLength {
+			value = value[:maxDetailLength] + "…"
+		}
+		parts = append(parts, key+"="+value)
+	}
+	return strings.Join(parts, " ")
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/chain"
+	"github.com/ety001/sps-fund-watcher/internal/config"
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+)
+
+// Exit codes of the status command, following the Nagios plugin convention
+const (
+	statusOK       = 0
+	statusWarning  = 1
+	statusCritical = 2
+	statusUnknown  = 3
+)
+
+var statusLabels = map[int]string{
+	statusOK:       "OK",
+	statusWarning:  "WARNING",
+	statusCritical: "CRITICAL",
+	statusUnknown:  "UNKNOWN",
+}
+
+// exitError ends spswatcher with a specific exit code after printing its message to stdout
+type exitError struct {
+	code    int
+	message string
+}
+
+func (e *exitError) Error() string {
+	return e.message
+}
+
+// statusResult is an instance's sync position, as reported by the API or read directly
+type statusResult struct {
+	lastBlock             int64
+	headBlock             int64
+	lastIrreversibleBlock int64
+	lag                   int64
+}
+
+func runStatus(args []string) error {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	baseURL, apiKey := apiFlags(fs)
+	maxLag := fs.Int64("max-lag", 100, "Critical when the sync is more than this many blocks behind")
+	warnLag := fs.Int64("warn-lag", 0, "Warning when the sync is more than this many blocks behind (0 disables)")
+	direct := fs.Bool("direct", false, "Check MongoDB and the node directly instead of calling the API (needs -config)")
+	configPath := fs.String("config", "", "Config file for -direct")
+	timeout := fs.Duration("timeout", 30*time.Second, "Give up and report CRITICAL after this long")
+	_ = fs.Parse(args)
+
+	if *maxLag < 0 || *warnLag < 0 {
+		return &exitError{code: statusUnknown, message: "UNKNOWN - -max-lag and -warn-lag must not be negative"}
+	}
+
+	type outcome struct {
+		result statusResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		var o outcome
+		if *direct {
+			o.result, o.err = statusDirect(*configPath, *timeout)
+		} else {
+			o.result, o.err = statusAPI(newAPIClient(*baseURL, *apiKey))
+		}
+		done <- o
+	}()
+
+	var o outcome
+	select {
+	case o = <-done:
+	case <-time.After(*timeout):
+		o.err = fmt.Errorf("no answer within %s", *timeout)
+	}
+
+	code := statusOK
+	var message string
+	switch {
+	case o.err != nil:
+		code = statusCritical
+		message = o.err.Error()
+	default:
+		r := o.result
+		message = fmt.Sprintf("lag %d blocks (last synced %d, head %d, irreversible %d)", r.lag, r.lastBlock, r.headBlock, r.lastIrreversibleBlock)
+		switch {
+		case r.lag > *maxLag:
+			code = statusCritical
+		case *warnLag > 0 && r.lag > *warnLag:
+			code = statusWarning
+		}
+		// Performance data for Nagios-compatible monitors; an unset warning threshold stays empty
+		warn := ""
+		if *warnLag > 0 {
+			warn = fmt.Sprint(*warnLag)
+		}
+		message += fmt.Sprintf(" | lag=%d;%s;%d", r.lag, warn, *maxLag)
+	}
+
+	line := statusLabels[code] + " - " + message
+	if code == statusOK {
+		fmt.Println(line)
+		return nil
+	}
+	return &exitError{code: code, message: line}
+}
+
+// statusAPI reads the sync lag from the API's status endpoint
+// The API answering at all shows it is up; it answers with an error when MongoDB is unreachable
+func statusAPI(client *apiClient) (statusResult, error) {
+	var status struct {
+		Sync  *models.SyncState `json:"sync"`
+		Chain *struct {
+			HeadBlock             int64 `json:"head_block"`
+			LastIrreversibleBlock int64 `json:"last_irreversible_block"`
+			Lag                   int64 `json:"lag"`
+		} `json:"chain"`
+		ChainError string `json:"chain_error"`
+	}
+	if err := client.getJSON("/status", nil, &status); err != nil {
+		return statusResult{}, err
+	}
+	if status.Chain == nil {
+		return statusResult{}, fmt.Errorf("API cannot reach the Steem node: %s", status.ChainError)
+	}
+
+	result := statusResult{
+		headBlock:             status.Chain.HeadBlock,
+		lastIrreversibleBlock: status.Chain.LastIrreversibleBlock,
+		lag:                   status.Chain.Lag,
+	}
+	if status.Sync != nil {
+		result.lastBlock = status.Sync.LastBlock
+	}
+	return result, nil
+}
+
+// statusDirect reads the sync state from MongoDB and the chain position from the node
+func statusDirect(configPath string, timeout time.Duration) (statusResult, error) {
+	if configPath == "" {
+		return statusResult{}, errors.New("-direct needs -config")
+	}
+	config, err := config.Load(configPath, nil)
+	if err != nil {
+		return statusResult{}, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	mongoStorage, err := storage.NewMongoDB(config.MongoDB.URI, config.MongoDB.Database)
+	if err != nil {
+		return statusResult{}, fmt.Errorf("MongoDB is unreachable: %w", err)
+	}
+	defer mongoStorage.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	syncState, err := mongoStorage.GetSyncState(ctx)
+	if err != nil {
+		return statusResult{}, fmt.Errorf("MongoDB is unreachable: %w", err)
+	}
+
+	client, err := chain.NewClient(config.Steem)
+	if err != nil {
+		return statusResult{}, err
+	}
+	dgp, err := client.GetDynamicGlobalProperties()
+	if err != nil {
+		return statusResult{}, fmt.Errorf("Steem node is unreachable: %w", err)
+	}
+
+	head, lib := int64(dgp.HeadBlockNumber), int64(dgp.LastIrreversibleBlockNum)
+	return statusResult{
+		lastBlock:             syncState.LastBlock,
+		headBlock:             head,
+		lastIrreversibleBlock: lib,
+		lag:                   syncState.Lag(config.Steem.SyncMode, head, lib),
+	}, nil
+}
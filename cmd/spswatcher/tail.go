@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+)
+
+// tailPageSize is the page size requested while polling; the API caps it at 100
+const tailPageSize = 100
+
+// tailMaxPages bounds how far back one poll pages when many operations arrived since the last one
+const tailMaxPages = 10
+
+// tailer follows an account's operations and prints the ones it has not printed yet
+type tailer struct {
+	client *apiClient
+	path   string
+	filter url.Values // type and q
+
+	lastBlock int64
+	seen      map[string]bool // IDs printed for lastBlock
+}
+
+func runTail(args []string) error {
+	fs := flag.NewFlagSet("tail", flag.ExitOnError)
+	baseURL, apiKey := apiFlags(fs)
+	account := fs.String("account", "", "Account to follow (required)")
+	opTypes := fs.String("type", "", "Only show these operation types (comma-separated)")
+	expr := fs.String("q", "", "Filter expression, same syntax as the API's q parameter")
+	lines := fs.Int("n", 10, "Number of recent operations to show before following")
+	interval := fs.Duration("interval", 3*time.Second, "Reconnect delay, and poll interval for APIs without event streams")
+	asJSON := fs.Bool("json", false, "Print operations as JSON lines")
+	noColor := fs.Bool("no-color", false, "Disable colors (also disabled by NO_COLOR or when not writing to a terminal)")
+	_ = fs.Parse(args)
+
+	if *account == "" {
+		return errors.New("-account is required")
+	}
+	if err := models.ValidateAccountName(*account); err != nil {
+		return err
+	}
+	if *interval < time.Second {
+		return errors.New("-interval must be at least 1s")
+	}
+
+	filter := url.Values{}
+	if *opTypes != "" {
+		filter.Set("type", *opTypes)
+	}
+	if *expr != "" {
+		filter.Set("q", *expr)
+	}
+
+	t := &tailer{
+		client: newAPIClient(*baseURL, *apiKey),
+		path:   "/accounts/" + url.PathEscape(*account),
+		filter: filter,
+		seen:   make(map[string]bool),
+	}
+
+	printer := newOpPrinter(os.Stdout, *noColor)
+	show := func(op *models.Operation) {
+		if *asJSON {
+			encoded, _ := json.Marshal(op)
+			fmt.Println(string(encoded))
+			return
+		}
+		printer.Print(op)
+	}
+
+	// Show the most recent operations, then only newer ones
+	recent, err := t.poll(true)
+	if err != nil {
+		return err
+	}
+	if len(recent) > *lines {
+		recent = recent[len(recent)-*lines:]
+	}
+	for i := range recent {
+		show(&recent[i])
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Resume the event stream after the newest operation shown
+	lastEventID := streamPosition(recent)
+
+	for {
+		err := t.client.streamEvents(ctx, t.path+"/events", t.filter, lastEventID, func(event serverEvent) error {
+			if event.id != "" {
+				lastEventID = event.id
+			}
+			switch event.event {
+			case "error":
+				return fmt.Errorf("event stream failed: %s", event.data)
+			case "operation":
+				var op models.Operation
+				if err := json.Unmarshal([]byte(event.data), &op); err != nil {
+					return fmt.Errorf("failed to decode event: %w", err)
+				}
+				if t.markPrinted(&op) {
+					show(&op)
+				}
+			}
+			return nil
+		})
+		if ctx.Err() != nil {
+			return nil
+		}
+		if errors.Is(err, errStreamUnsupported) {
+			return t.follow(ctx, *interval, show)
+		}
+		if err != nil {
+			// Keep following through API restarts
+			fmt.Fprintf(os.Stderr, "spswatcher tail: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(*interval):
+		}
+	}
+}
+
+// follow polls the operations endpoint, for APIs that predate the event stream
+func (t *tailer) follow(ctx context.Context, interval time.Duration, show func(*models.Operation)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			ops, err := t.poll(false)
+			if err != nil {
+				// Keep following through API restarts
+				fmt.Fprintf(os.Stderr, "spswatcher tail: %v\n", err)
+				continue
+			}
+			for i := range ops {
+				show(&ops[i])
+			}
+		}
+	}
+}
+
+// markPrinted records op as printed and reports whether it was new
+func (t *tailer) markPrinted(op *models.Operation) bool {
+	if op.BlockNum < t.lastBlock || (op.BlockNum == t.lastBlock && t.seen[op.ID]) {
+		return false
+	}
+	if op.BlockNum > t.lastBlock {
+		t.lastBlock = op.BlockNum
+		t.seen = make(map[string]bool)
+	}
+	t.seen[op.ID] = true
+	return true
+}
+
+// poll returns the operations not printed yet, oldest first
+// The first poll only reads one page, since it just seeds the recent operations
+func (t *tailer) poll(first bool) ([]models.Operation, error) {
+	var fresh []models.Operation
+	for page := 1; page <= tailMaxPages; page++ {
+		query := url.Values{"page_size": {strconv.Itoa(tailPageSize)}, "page": {strconv.Itoa(page)}}
+		for key, values := range t.filter {
+			query[key] = values
+		}
+
+		var result models.OperationResponse
+		if err := t.client.getJSON(t.path+"/operations", query, &result); err != nil {
+			return nil, err
+		}
+
+		reachedSeen := false
+		for _, op := range result.Operations {
+			if op.BlockNum < t.lastBlock || (op.BlockNum == t.lastBlock && t.seen[op.ID]) {
+				reachedSeen = true
+				continue
+			}
+			fresh = append(fresh, op)
+		}
+		if first || reachedSeen || !result.HasMore {
+			break
+		}
+	}
+
+	// The API lists newest first
+	sort.SliceStable(fresh, func(i, j int) bool {
+		if fresh[i].BlockNum != fresh[j].BlockNum {
+			return fresh[i].BlockNum < fresh[j].BlockNum
+		}
+		return fresh[i].OpInTrx < fresh[j].OpInTrx
+	})
+
+	for i := range fresh {
+		t.markPrinted(&fresh[i])
+	}
+	return fresh, nil
+}
+
+// streamPosition returns the event ID of the last of ops in the stream's (block, ID) order,
+// or "" for no operations, which starts the stream at the newest stored operation
+func streamPosition(ops []models.Operation) string {
+	var last *models.Operation
+	for i := range ops {
+		op := &ops[i]
+		// Operation IDs are fixed-length hex, so they compare like the IDs themselves
+		if last == nil || op.BlockNum > last.BlockNum || (op.BlockNum == last.BlockNum && op.ID > last.ID) {
+			last = op
+		}
+	}
+	if last == nil {
+		return ""
+	}
+	return strconv.FormatInt(last.BlockNum, 10) + "-" + last.ID
+}
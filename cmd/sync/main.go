@@ -10,16 +10,29 @@ import (
 	"path/filepath"
 	"syscall"
 
+	"github.com/ety001/sps-fund-watcher/internal/config"
+	"github.com/ety001/sps-fund-watcher/internal/logging"
 	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/profiling"
+	"github.com/ety001/sps-fund-watcher/internal/reporting"
 	"github.com/ety001/sps-fund-watcher/internal/sync"
-	"gopkg.in/yaml.v3"
+	"github.com/ety001/sps-fund-watcher/internal/version"
 )
 
 func main() {
 	configPath := flag.String("config", "configs/config.yaml", "Path to configuration file")
+	showVersion := flag.Bool("version", false, "Print version information and exit")
 	lockFile := flag.String("lockfile", "", "Path to lock file (default: /tmp/sps-fund-watcher-sync.lock)")
+	once := flag.Bool("once", false, "Catch up to the latest block once and exit instead of running as a service (for cron jobs)")
+	var configFlags config.Flags
+	configFlags.Register(flag.CommandLine)
 	flag.Parse()
 
+	if *showVersion {
+		fmt.Println(version.String("sync"))
+		return
+	}
+
 	// Determine lock file path
 	lockFilePath := *lockFile
 	if lockFilePath == "" {
@@ -35,29 +48,51 @@ func main() {
 	log.Printf("Lock acquired: %s", lockFilePath)
 
 	// Load configuration
-	config, err := loadConfig(*configPath)
+	config, err := configFlags.Load(*configPath)
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	logging.Setup(config.Logging)
+	logging.SetLabels(config.Instance.Labels())
+	version.LogBanner("sync", config.Summary())
+
+	// Optional profiling endpoints on a private address
+	if addr := config.Pprof.SyncListenAddr(); addr != "" {
+		if err := profiling.Start(addr); err != nil {
+			log.Fatalf("Failed to start pprof: %v", err)
+		}
+	}
+
+	// Optional error reporting
+	if err := reporting.Setup(config.ErrorReporting, "sync", config.Instance.Labels()); err != nil {
+		log.Fatalf("Failed to initialize error reporting: %v", err)
+	}
+	defer reporting.Close()
+	defer reporting.Recover()
 
 	// Log Telegram configuration format
 	telegramUsers, useNewFormat := models.NormalizeTelegramConfig(&config.Telegram)
 	if useNewFormat {
 		log.Printf("Using new multi-rule Telegram configuration with %d rules", len(telegramUsers))
 		for i, user := range telegramUsers {
-			log.Printf("  Rule %d: name=%s, accounts=%v, operations=%v, filters=%v",
-				i+1, user.Name, user.Accounts, user.NotifyOperations, user.OperationFilters)
+			log.Printf("  Rule %d: name=%s, accounts=%v, operations=%v, account_operations=%v, filters=%v",
+				i+1, user.Name, user.Accounts, user.NotifyOperations, user.AccountOperations, user.OperationFilters)
 		}
 	} else {
 		log.Printf("Using legacy Telegram configuration (converted to 1 rule)")
 	}
 
-	// Create syncer
-	syncer, err := sync.NewSyncer(config)
-	if err != nil {
-		log.Fatalf("Failed to create syncer: %v", err)
+	// One syncer per watched chain, each with its own node, database and sync state
+	chainConfigs := config.ChainConfigs()
+	syncers := make([]*sync.Syncer, 0, len(chainConfigs))
+	for _, chainConfig := range chainConfigs {
+		syncer, err := sync.NewSyncer(chainConfig)
+		if err != nil {
+			log.Fatalf("Failed to create syncer%s: %v", chainSuffix(chainConfig), err)
+		}
+		defer syncer.Close()
+		syncers = append(syncers, syncer)
 	}
-	defer syncer.Close()
 
 	// Setup signal handling
 	ctx, cancel := context.WithCancel(context.Background())
@@ -66,39 +101,61 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	// Start syncer in goroutine
-	errChan := make(chan error, 1)
-	go func() {
-		if err := syncer.Start(ctx); err != nil {
-			errChan <- err
-		}
-	}()
+	// Start syncers in goroutines
+	type syncerError struct {
+		config *models.Config
+		err    error
+	}
+	errChan := make(chan syncerError, len(syncers))
+	doneChan := make(chan struct{}, len(syncers))
+	for i, syncer := range syncers {
+		go func() {
+			defer reporting.Recover()
+			if *once {
+				if err := syncer.RunOnce(ctx); err != nil {
+					errChan <- syncerError{config: chainConfigs[i], err: err}
+					return
+				}
+				doneChan <- struct{}{}
+				return
+			}
+			if err := syncer.Start(ctx); err != nil {
+				errChan <- syncerError{config: chainConfigs[i], err: err}
+			}
+		}()
+	}
 
-	// Wait for signal or error
-	select {
-	case sig := <-sigChan:
-		log.Printf("Received signal: %v", sig)
-		syncer.Stop()
-		cancel()
-	case err := <-errChan:
-		log.Fatalf("Syncer error: %v", err)
+	// Wait for signal, error or the end of the single passes of all chains
+	for caughtUp := 0; caughtUp < len(syncers); {
+		select {
+		case sig := <-sigChan:
+			log.Printf("Received signal: %v", sig)
+			for _, syncer := range syncers {
+				syncer.Stop()
+			}
+			cancel()
+			caughtUp = len(syncers)
+		case <-doneChan:
+			if caughtUp++; caughtUp == len(syncers) {
+				log.Println("Caught up, exiting")
+			}
+		case failed := <-errChan:
+			// log.Fatalf skips deferred calls, so send the report first
+			reporting.Report(failed.err, "node_url", failed.config.Steem.APIURL, "chain", failed.config.Steem.Chain)
+			reporting.Close()
+			log.Fatalf("Syncer error%s: %v", chainSuffix(failed.config), failed.err)
+		}
 	}
 
 	log.Println("Sync service stopped")
 }
 
-func loadConfig(path string) (*models.Config, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
-	}
-
-	var config models.Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config: %w", err)
+// chainSuffix names the chain of a syncer in messages when several chains are watched
+func chainSuffix(config *models.Config) string {
+	if config.Steem.Chain == "" {
+		return ""
 	}
-
-	return &config, nil
+	return fmt.Sprintf(" (chain %s)", config.Steem.Chain)
 }
 
 // acquireLock acquires an exclusive file lock to prevent multiple instances
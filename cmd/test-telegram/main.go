@@ -4,23 +4,32 @@ import (
 	"flag"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
-	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/config"
+	"github.com/ety001/sps-fund-watcher/internal/logging"
 	"github.com/ety001/sps-fund-watcher/internal/telegram"
-	"gopkg.in/yaml.v3"
+	"github.com/ety001/sps-fund-watcher/internal/version"
 )
 
 func main() {
 	configPath := flag.String("config", "configs/config.temp.yaml", "Path to configuration file")
+	showVersion := flag.Bool("version", false, "Print version information and exit")
+	var configFlags config.Flags
+	configFlags.Register(flag.CommandLine)
 	flag.Parse()
 
+	if *showVersion {
+		fmt.Println(version.String("test-telegram"))
+		return
+	}
+
 	// Load configuration
-	config, err := loadConfig(*configPath)
+	config, err := configFlags.Load(*configPath)
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	logging.Setup(config.Logging)
 
 	// Check if Telegram is enabled
 	if !config.Telegram.Enabled {
@@ -37,6 +46,14 @@ func main() {
 
 	// Create Telegram client
 	client := telegram.NewClient(config.Telegram.BotToken, config.Telegram.ChannelID)
+	client.SetParseMode(config.Telegram.ParseMode)
+	client.SetMessageThreadID(config.Telegram.MessageThreadID)
+	if err := client.SetProxy(config.Telegram.Proxy); err != nil {
+		log.Fatalf("Failed to configure Telegram proxy: %v", err)
+	}
+	blockURL, txURL, accountURL := config.Telegram.Explorer.URLs()
+	client.SetExplorer(telegram.Explorer{BlockURL: blockURL, TxURL: txURL, AccountURL: accountURL})
+	formatter := client.Formatter()
 
 	// Prepare test operation data
 	testOpData := map[string]interface{}{
@@ -50,7 +67,7 @@ func main() {
 	var message string
 	if config.Telegram.MessageTemplate != "" {
 		// Use custom template
-		message = telegram.FormatOperationMessageWithTemplate(
+		message = formatter.OperationMessageWithTemplate(
 			config.Telegram.MessageTemplate,
 			"test-account",
 			"transfer",
@@ -61,7 +78,7 @@ func main() {
 		log.Println("Using custom message template")
 	} else {
 		// Use default template
-		message = telegram.FormatOperationMessage(
+		message = formatter.OperationMessage(
 			"test-account",
 			"transfer",
 			testOpData,
@@ -78,24 +95,11 @@ func main() {
 
 	// Send message
 	log.Printf("Sending test message to Telegram channel %s...", config.Telegram.ChannelID)
-	if err := client.SendMessage(message); err != nil {
+	// Explorer buttons as configured under telegram.explorer
+	buttons := client.Explorer().Buttons(123456789, "", "test-account")
+	if err := client.SendMessageWithButtons(message, buttons); err != nil {
 		log.Fatalf("Failed to send message: %v", err)
 	}
 
 	log.Println("✅ Test message sent successfully!")
 }
-
-func loadConfig(path string) (*models.Config, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
-	}
-
-	var config models.Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
-	}
-
-	return &config, nil
-}
-
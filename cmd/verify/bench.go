@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"log"
+	"runtime"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/chain"
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/sync"
+	"github.com/steemit/steemutil/protocol"
+)
+
+// runBenchmark measures BlockProcessor throughput on a fetched block range,
+// with the typed fast path and with the JSON round trip for every operation
+func runBenchmark(steemAPI chain.Client, config *models.Config, accounts []string, startBlock, endBlock int64, rounds int) {
+	// Fetch once so the measurement excludes network time
+	var blocks [][]*protocol.OperationObject
+	total := 0
+	for current := startBlock; current <= endBlock; current += 100 {
+		last := min(current+99, endBlock)
+		opsMap, err := steemAPI.GetOpsInBlocks(uint(current), uint(last+1), false)
+		if err != nil {
+			log.Fatalf("Failed to get operations for blocks %d to %d: %v", current, last, err)
+		}
+		for blockNum := current; blockNum <= last; blockNum++ {
+			if ops := opsMap[uint(blockNum)]; len(ops) > 0 {
+				blocks = append(blocks, ops)
+				total += len(ops)
+			}
+		}
+	}
+	log.Printf("Benchmarking %d operations from blocks %d to %d, %d rounds", total, startBlock, endBlock, rounds)
+
+	processor := sync.NewBlockProcessor(nil, nil, []models.TelegramUserConfig{}, accounts, "")
+	processor.SetAccountPaths(config.Steem.AccountPaths)
+	processor.SetFallbackExtraction(config.Steem.FallbackExtraction)
+	processor.SetMentionDetection(config.Steem.DetectMentions)
+	processor.SetStoragePolicies(config.Steem.StoragePolicies)
+	var fastRate float64
+	for _, fast := range []bool{true, false} {
+		processor.SetFastPath(fast)
+		name := "json"
+		if fast {
+			name = "fast"
+		}
+
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		extracted := 0
+		start := time.Now()
+		for round := 0; round < rounds; round++ {
+			for _, ops := range blocks {
+				operations, err := processor.ProcessOperations(context.Background(), ops)
+				if err != nil {
+					log.Fatalf("Failed to process operations: %v", err)
+				}
+				extracted += len(operations)
+			}
+		}
+		elapsed := time.Since(start)
+		runtime.ReadMemStats(&after)
+
+		processed := float64(total * rounds)
+		rate := processed / elapsed.Seconds()
+		log.Printf("%s path: %d operations in %s (%.0f ops/s, %.1f allocs/op, %.0f B/op, %d extracted)",
+			name, total*rounds, elapsed.Round(time.Millisecond), rate,
+			float64(after.Mallocs-before.Mallocs)/processed, float64(after.TotalAlloc-before.TotalAlloc)/processed, extracted)
+		if fast {
+			fastRate = rate
+		} else if rate > 0 {
+			log.Printf("Fast path speedup: %.1fx", fastRate/rate)
+		}
+	}
+}
@@ -0,0 +1,361 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/chain"
+	"github.com/ety001/sps-fund-watcher/internal/config"
+	"github.com/ety001/sps-fund-watcher/internal/logging"
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+	"github.com/ety001/sps-fund-watcher/internal/sync"
+	"github.com/ety001/sps-fund-watcher/internal/version"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// accountList collects accounts from repeated or comma-separated -account flags
+type accountList []string
+
+func (a *accountList) String() string {
+	return strings.Join(*a, ",")
+}
+
+func (a *accountList) Set(value string) error {
+	for _, account := range strings.Split(value, ",") {
+		if account = strings.TrimSpace(account); account != "" {
+			*a = append(*a, account)
+		}
+	}
+	return nil
+}
+
+// operationKey identifies an operation record the same way the unique index does
+type operationKey struct {
+	blockNum int64
+	trxID    string
+	opInTrx  int
+	account  string
+}
+
+func keyOf(op *models.Operation) operationKey {
+	return operationKey{blockNum: op.BlockNum, trxID: op.TrxID, opInTrx: op.OpInTrx, account: op.Account}
+}
+
+func (k operationKey) String() string {
+	return fmt.Sprintf("block %d trx %s op %d account %s", k.blockNum, k.trxID, k.opInTrx, k.account)
+}
+
+// verifier compares stored operations with operations re-extracted from the chain
+type verifier struct {
+	steemAPI  chain.Client
+	storage   *storage.MongoDB
+	processor *sync.BlockProcessor
+	accounts  []string
+
+	blocks     int
+	checked    int
+	missing    int // On chain but not stored
+	extra      int // Stored but not on chain
+	divergent  int // Stored with different type or data
+	maxReports int
+	reported   int
+}
+
+func main() {
+	// Parse command line flags
+	var accountFlags accountList
+	flag.Var(&accountFlags, "account", "Account to verify (comma-separated or repeatable; defaults to steem.accounts)")
+	startBlock := flag.Int64("start", 0, "Start block number")
+	endBlock := flag.Int64("end", 0, "End block number (defaults to the last irreversible block)")
+	sample := flag.Int("sample", 0, "Verify this many randomly chosen blocks from the range instead of every block")
+	maxReports := flag.Int("max-reports", 100, "Maximum number of individual discrepancies to print")
+	benchRounds := flag.Int("bench", 0, "Benchmark operation extraction over the range this many times instead of verifying (MongoDB is not used)")
+	showVersion := flag.Bool("version", false, "Print version information and exit")
+	var configFlags config.Flags
+	configFlags.Register(flag.CommandLine)
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(version.String("verify"))
+		return
+	}
+
+	// Get config file path from remaining arguments
+	args := flag.Args()
+	if len(args) == 0 {
+		log.Fatal("Config file path is required")
+	}
+	configPath := args[0]
+
+	if *startBlock <= 0 {
+		log.Fatal("Start block must be greater than 0 (use -start flag)")
+	}
+
+	// Load configuration
+	config, err := configFlags.Load(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	logging.Setup(config.Logging)
+	version.LogBanner("verify", config.Summary())
+
+	accounts := []string(accountFlags)
+	if len(accounts) == 0 {
+		accounts = config.Steem.Accounts
+	}
+	if len(accounts) == 0 {
+		log.Fatal("No accounts to verify: steem.accounts is empty and -account is not set")
+	}
+
+	// Initialize Steem API client
+	steemAPI, err := chain.NewClient(config.Steem)
+	if err != nil {
+		log.Fatalf("Failed to initialize chain client: %v", err)
+	}
+	log.Printf("Steem API initialized: %s", config.Steem.APIURL)
+
+	// Only irreversible blocks can be compared reliably
+	dgp, err := steemAPI.GetDynamicGlobalProperties()
+	if err != nil {
+		log.Fatalf("Failed to get dynamic global properties: %v", err)
+	}
+	lastIrreversible := int64(dgp.LastIrreversibleBlockNum)
+	if *endBlock <= 0 || *endBlock > lastIrreversible {
+		*endBlock = lastIrreversible
+	}
+	if *startBlock > *endBlock {
+		log.Fatalf("Start block (%d) must be less than or equal to end block (%d)", *startBlock, *endBlock)
+	}
+
+	if *benchRounds > 0 {
+		runBenchmark(steemAPI, config, accounts, *startBlock, *endBlock, *benchRounds)
+		return
+	}
+
+	// Initialize MongoDB storage
+	mongoStorage, err := storage.NewMongoDB(config.MongoDB.URI, config.MongoDB.Database)
+	if err != nil {
+		log.Fatalf("Failed to initialize MongoDB: %v", err)
+	}
+	defer mongoStorage.Close()
+	mongoStorage.SetSlowQueryThreshold(time.Duration(config.MongoDB.SlowQueryMS) * time.Millisecond)
+	log.Printf("MongoDB initialized: %s/%s", config.MongoDB.URI, config.MongoDB.Database)
+
+	// Extract operations exactly like the sync service; no notifications are sent
+	processor := sync.NewBlockProcessor(mongoStorage, nil, []models.TelegramUserConfig{}, accounts, "")
+	processor.SetSamplingRules(config.Steem.Sampling)
+	processor.SetAccountPaths(config.Steem.AccountPaths)
+	processor.SetFallbackExtraction(config.Steem.FallbackExtraction)
+	processor.SetMentionDetection(config.Steem.DetectMentions)
+	processor.SetStoragePolicies(config.Steem.StoragePolicies)
+
+	batchSize := config.Steem.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100 // Default batch size
+	}
+
+	v := &verifier{
+		steemAPI:   steemAPI,
+		storage:    mongoStorage,
+		processor:  processor,
+		accounts:   accounts,
+		maxReports: *maxReports,
+	}
+	ctx := context.Background()
+
+	log.Printf("Verifying accounts=%s, blocks %d to %d", strings.Join(accounts, ","), *startBlock, *endBlock)
+	if *sample > 0 {
+		for _, blockNum := range sampleBlocks(*startBlock, *endBlock, *sample) {
+			v.verifyRange(ctx, blockNum, blockNum)
+		}
+	} else {
+		for current := *startBlock; current <= *endBlock; current += batchSize {
+			v.verifyRange(ctx, current, min(current+batchSize-1, *endBlock))
+			log.Printf("Progress: %d/%d blocks verified", v.blocks, *endBlock-*startBlock+1)
+		}
+	}
+
+	log.Printf("Verification completed: %d blocks, %d operations checked, %d missing, %d extra, %d divergent",
+		v.blocks, v.checked, v.missing, v.extra, v.divergent)
+	if v.missing+v.extra+v.divergent > 0 {
+		if v.reported < v.missing+v.extra+v.divergent {
+			log.Printf("Only the first %d discrepancies were printed (use -max-reports)", v.reported)
+		}
+		os.Exit(1)
+	}
+}
+
+// sampleBlocks picks up to n distinct blocks from [start, end] in ascending order
+func sampleBlocks(start, end int64, n int) []int64 {
+	total := end - start + 1
+	if int64(n) >= total {
+		n = int(total)
+	}
+	picked := make(map[int64]bool, n)
+	for len(picked) < n {
+		picked[start+rand.Int63n(total)] = true
+	}
+	blocks := make([]int64, 0, n)
+	for blockNum := range picked {
+		blocks = append(blocks, blockNum)
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i] < blocks[j] })
+	return blocks
+}
+
+// verifyRange compares one block range
+func (v *verifier) verifyRange(ctx context.Context, startBlock, endBlock int64) {
+	opsMap, err := v.steemAPI.GetOpsInBlocks(uint(startBlock), uint(endBlock+1), false)
+	if err != nil {
+		log.Fatalf("Failed to get operations for blocks %d to %d: %v", startBlock, endBlock, err)
+	}
+
+	expected := make(map[operationKey]*models.Operation)
+	for blockNum := startBlock; blockNum <= endBlock; blockNum++ {
+		ops, ok := opsMap[uint(blockNum)]
+		if !ok || len(ops) == 0 {
+			continue
+		}
+		operations, err := v.processor.ProcessOperations(ctx, ops)
+		if err != nil {
+			log.Fatalf("Failed to process operations for block %d: %v", blockNum, err)
+		}
+		// Compare with op_data as it is stored, after storage policies dropped or hashed content
+		for _, op := range v.processor.ApplyStoragePolicy(operations) {
+			// Operations dropped by sampling rules are intentionally not stored
+			if v.processor.SamplingKeeps(op) {
+				expected[keyOf(op)] = op
+			}
+		}
+	}
+
+	stored, err := v.storage.GetOperationsInBlockRange(ctx, v.accounts, startBlock, endBlock)
+	if err != nil {
+		log.Fatalf("Failed to load stored operations for blocks %d to %d: %v", startBlock, endBlock, err)
+	}
+
+	for i := range stored {
+		op := &stored[i]
+		key := keyOf(op)
+		chainOp, ok := expected[key]
+		if !ok {
+			v.extra++
+			v.report("EXTRA     %s (%s): stored but not found on chain", key, op.OpType)
+			continue
+		}
+		delete(expected, key)
+		v.checked++
+
+		if diff := compareOperations(chainOp, op); diff != "" {
+			v.divergent++
+			v.report("DIVERGENT %s: %s", key, diff)
+		}
+	}
+
+	// Whatever is left was not stored
+	missing := make([]operationKey, 0, len(expected))
+	for key := range expected {
+		missing = append(missing, key)
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		if missing[i].blockNum != missing[j].blockNum {
+			return missing[i].blockNum < missing[j].blockNum
+		}
+		return missing[i].opInTrx < missing[j].opInTrx
+	})
+	for _, key := range missing {
+		v.missing++
+		v.checked++
+		v.report("MISSING   %s (%s): on chain but not stored", key, expected[key].OpType)
+	}
+
+	v.blocks += int(endBlock - startBlock + 1)
+
+	// Small delay to avoid overwhelming the API
+	time.Sleep(100 * time.Millisecond)
+}
+
+// report prints a discrepancy unless the report limit is reached
+func (v *verifier) report(format string, args ...interface{}) {
+	if v.reported >= v.maxReports {
+		return
+	}
+	v.reported++
+	log.Printf(format, args...)
+}
+
+// compareOperations describes how a stored operation differs from the chain, or returns ""
+func compareOperations(chainOp, storedOp *models.Operation) string {
+	if chainOp.OpType != storedOp.OpType {
+		return fmt.Sprintf("op_type %q on chain, %q stored", chainOp.OpType, storedOp.OpType)
+	}
+	if !chainOp.Timestamp.Equal(storedOp.Timestamp) {
+		return fmt.Sprintf("timestamp %s on chain, %s stored", chainOp.Timestamp.UTC(), storedOp.Timestamp.UTC())
+	}
+
+	chainData, err := normalize(chainOp.OpData)
+	if err != nil {
+		return fmt.Sprintf("cannot compare op_data: %v", err)
+	}
+	storedData, err := normalize(storedOp.OpData)
+	if err != nil {
+		return fmt.Sprintf("cannot compare op_data: %v", err)
+	}
+	if !reflect.DeepEqual(chainData, storedData) {
+		return "op_data differs"
+	}
+	return ""
+}
+
+// normalize converts decoded BSON or JSON data to plain JSON values so both sides compare equal
+func normalize(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(plain(value))
+	if err != nil {
+		return nil, err
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// plain replaces ordered BSON documents with maps, which JSON would otherwise encode as key/value lists
+func plain(value interface{}) interface{} {
+	switch v := value.(type) {
+	case primitive.D:
+		m := make(map[string]interface{}, len(v))
+		for _, elem := range v {
+			m[elem.Key] = plain(elem.Value)
+		}
+		return m
+	case primitive.M:
+		return plain(map[string]interface{}(v))
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, elem := range v {
+			m[key] = plain(elem)
+		}
+		return m
+	case primitive.A:
+		return plain([]interface{}(v))
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, elem := range v {
+			s[i] = plain(elem)
+		}
+		return s
+	default:
+		return v
+	}
+}
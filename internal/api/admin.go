@@ -0,0 +1,117 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// pauseRequest selects which subsystems a pause/resume request applies to
+// When neither sync nor notifications is set, both are affected
+type pauseRequest struct {
+	Sync          bool   `json:"sync"`
+	Notifications bool   `json:"notifications"`
+	Reason        string `json:"reason"`
+}
+
+// requireAdmin rejects requests that don't carry the configured admin bearer token
+func requireAdmin(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin API is disabled (api.admin_token is not configured)"})
+			return
+		}
+
+		provided := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid admin token"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// GetControlState handles GET /api/v1/admin/state
+func (h *Handler) GetControlState(c *gin.Context) {
+	state, err := h.storage.GetControlState(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
+
+// Pause handles POST /api/v1/admin/pause
+func (h *Handler) Pause(c *gin.Context) {
+	h.setPaused(c, true)
+}
+
+// Resume handles POST /api/v1/admin/resume
+func (h *Handler) Resume(c *gin.Context) {
+	h.setPaused(c, false)
+}
+
+// setPaused applies a pause or resume request to the persisted control state
+func (h *Handler) setPaused(c *gin.Context, paused bool) {
+	var req pauseRequest
+	// An empty body is valid and means "both"
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if !req.Sync && !req.Notifications {
+		req.Sync = true
+		req.Notifications = true
+	}
+
+	var syncPaused, notificationsPaused *bool
+	if req.Sync {
+		syncPaused = &paused
+	}
+	if req.Notifications {
+		notificationsPaused = &paused
+	}
+
+	state, err := h.storage.SetPauseState(c.Request.Context(), syncPaused, notificationsPaused, req.Reason)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
+
+// GetStorageReport handles GET /api/v1/admin/storage
+func (h *Handler) GetStorageReport(c *gin.Context) {
+	report, err := h.storage.StorageReport(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetIndexReport handles GET /api/v1/admin/indexes
+// Explains the common query shapes for a sample account (default: first tracked account)
+func (h *Handler) GetIndexReport(c *gin.Context) {
+	account := c.Query("account")
+	if account == "" && len(h.config.Steem.Accounts) > 0 {
+		account = h.config.Steem.Accounts[0]
+	}
+
+	report, err := h.storage.IndexReport(c.Request.Context(), account)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
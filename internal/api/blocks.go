@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// BlockOperations lists what the watcher recorded from one block
+type BlockOperations struct {
+	BlockNum   int64               `json:"block_num"`
+	Synced     bool                `json:"synced"` // The sync has passed the block; otherwise the list may still grow
+	Count      int                 `json:"count"`
+	Operations []*models.Operation `json:"operations"` // In block order; an operation between tracked accounts appears once per account
+}
+
+// GetBlockOperations handles GET /api/v1/blocks/:block_num/operations
+// Optional account narrows the result to the records of one tracked account
+func (h *Handler) GetBlockOperations(c *gin.Context) {
+	blockNum, err := strconv.ParseInt(c.Param("block_num"), 10, 64)
+	if err != nil || blockNum <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid block number"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	result := BlockOperations{BlockNum: blockNum, Operations: []*models.Operation{}}
+	query := storage.OperationQuery{Account: c.Query("account"), StartBlock: blockNum, EndBlock: blockNum}
+	err = h.storage.StreamOperations(ctx, query, func(op *models.Operation) error {
+		result.Operations = append(result.Operations, op)
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	result.Count = len(result.Operations)
+
+	syncState, err := h.storage.GetSyncState(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	result.Synced = blockNum <= syncState.LastBlock
+
+	c.JSON(http.StatusOK, result)
+}
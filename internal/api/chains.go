@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// ChainInfo describes a watched chain and where its routes are mounted
+type ChainInfo struct {
+	Name     string   `json:"name"`
+	APIURL   string   `json:"api_url"`
+	Accounts []string `json:"accounts"`
+	Path     string   `json:"path"` // Prefix of the chain's routes, e.g. /api/v1/chains/hive
+}
+
+// listChains lists the chains served by handlers
+func listChains(prefix string, handlers []*Handler) gin.HandlerFunc {
+	chains := make([]ChainInfo, 0, len(handlers))
+	for _, h := range handlers {
+		chains = append(chains, ChainInfo{
+			Name:     h.config.Steem.Chain,
+			APIURL:   models.MaskURI(h.config.Steem.APIURL),
+			Accounts: h.config.Steem.Accounts,
+			Path:     prefix + "/chains/" + h.config.Steem.Chain,
+		})
+	}
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"chains": chains})
+	}
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// Donor leaderboard defaults
+const (
+	defaultDonorPeriod = "30d"
+	defaultDonorSymbol = "SBD"
+	defaultDonorLimit  = 50
+	maxDonorLimit      = 500
+)
+
+// GetDonors handles GET /api/v1/sps/donors
+// period is a rolling window ending now ("7d", "30d", "365d", ...) or "all"; from and to
+// (inclusive UTC dates) take precedence. Accounts that opted out are left out and aliases replace names
+func (h *Handler) GetDonors(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	board := models.DonorLeaderboard{
+		Treasury: h.config.Reconciliation.Treasury()[0],
+		Period:   c.DefaultQuery("period", defaultDonorPeriod),
+		To:       time.Now().UTC(),
+		Symbol:   strings.ToUpper(c.DefaultQuery("symbol", defaultDonorSymbol)),
+	}
+	if c.Query("from") != "" || c.Query("to") != "" {
+		from, to, ok := dateRange(c, 30)
+		if !ok {
+			return
+		}
+		board.Period = "custom"
+		board.From, board.To = &from, to
+	} else if board.Period != "all" {
+		days, err := strconv.Atoi(strings.TrimSuffix(board.Period, "d"))
+		if err != nil || !strings.HasSuffix(board.Period, "d") || days <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid period: use <days>d (e.g. 30d) or all"})
+			return
+		}
+		from := board.To.AddDate(0, 0, -days)
+		board.From = &from
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultDonorLimit)))
+	if err != nil || limit < 1 {
+		limit = defaultDonorLimit
+	}
+	if limit > maxDonorLimit {
+		limit = maxDonorLimit
+	}
+
+	var from time.Time
+	if board.From != nil {
+		from = *board.From
+	}
+	donors, err := h.storage.Donors(ctx, board.Treasury, board.Symbol, h.config.Reconciliation.Inflow(), from, board.To)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	metadata, err := h.storage.ListAccountMetadata(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	byAccount := make(map[string]models.AccountMetadata, len(metadata))
+	for _, m := range metadata {
+		byAccount[m.Account] = m
+	}
+
+	board.Donors = []models.Donor{}
+	for _, donor := range donors {
+		if len(board.Donors) == limit {
+			break
+		}
+		meta := byAccount[donor.Account]
+		if meta.OptOut {
+			continue
+		}
+		donor.Rank = len(board.Donors) + 1
+		donor.DisplayName = donor.Account
+		if meta.Alias != "" {
+			donor.DisplayName = meta.Alias
+		}
+		board.Donors = append(board.Donors, donor)
+	}
+
+	c.JSON(http.StatusOK, board)
+}
+
+// ListAccountMetadata handles GET /api/v1/admin/accounts/metadata
+func (h *Handler) ListAccountMetadata(c *gin.Context) {
+	metadata, err := h.storage.ListAccountMetadata(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if metadata == nil {
+		metadata = []models.AccountMetadata{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"accounts": metadata})
+}
+
+// SaveAccountMetadata handles PUT /api/v1/admin/accounts/:account/metadata
+// Replaces the alias, opt-out flag and note of the account
+func (h *Handler) SaveAccountMetadata(c *gin.Context) {
+	var metadata models.AccountMetadata
+	if err := c.ShouldBindJSON(&metadata); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	metadata.Account = c.Param("account")
+
+	if err := h.storage.SaveAccountMetadata(c.Request.Context(), &metadata); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, metadata)
+}
+
+// DeleteAccountMetadata handles DELETE /api/v1/admin/accounts/:account/metadata
+func (h *Handler) DeleteAccountMetadata(c *gin.Context) {
+	err := h.storage.DeleteAccountMetadata(c.Request.Context(), c.Param("account"))
+	if errors.Is(err, storage.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "account metadata not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	querydsl "github.com/ety001/sps-fund-watcher/internal/query"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Server-Sent Events stream timing
+const (
+	eventPollInterval = 2 * time.Second  // How often MongoDB is checked for new operations
+	eventHeartbeat    = 15 * time.Second // Comment sent on idle streams so proxies keep them open
+	eventBatchSize    = 100              // Operations read per query while catching up
+	eventRetry        = 5000             // Reconnect delay suggested to clients, in milliseconds
+)
+
+// StreamAccountEvents handles GET /api/v1/accounts/:account/events
+// Streams the account's operations as Server-Sent Events as the sync service stores them.
+// Each event's id is its position; a client reconnecting with Last-Event-ID (or last_event_id,
+// since browsers can't set headers on the first connection) resumes right after it.
+// Without one the stream starts after the latest stored operation. type and q filter like on
+// the operations endpoint
+func (h *Handler) StreamAccountEvents(c *gin.Context) {
+	ctx := c.Request.Context()
+	query := storage.OperationQuery{Account: c.Param("account"), OpTypes: queryList(c, "type")}
+	if q := c.Query("q"); q != "" {
+		filter, err := querydsl.Parse(q)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid q: " + err.Error()})
+			return
+		}
+		query.Filter = filter
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	var cursor storage.OperationCursor
+	if lastEventID != "" {
+		var err error
+		if cursor, err = parseEventID(lastEventID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid Last-Event-ID: " + err.Error()})
+			return
+		}
+	} else {
+		var err error
+		if cursor, err = h.storage.LatestOperationCursor(ctx, query); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no") // Keep nginx from buffering the stream
+	c.Status(http.StatusOK)
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", eventRetry)
+	c.Writer.Flush()
+
+	poll := time.NewTicker(eventPollInterval)
+	defer poll.Stop()
+	lastWrite := time.Now()
+
+	for {
+		ops, err := h.storage.OperationsAfter(ctx, query, cursor, eventBatchSize)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			// End the stream; the client reconnects with the last event ID it received
+			data, _ := json.Marshal(gin.H{"error": err.Error()})
+			fmt.Fprintf(c.Writer, "event: error\ndata: %s\n\n", data)
+			c.Writer.Flush()
+			return
+		}
+
+		for i := range ops {
+			next, err := storage.CursorOf(&ops[i])
+			if err != nil {
+				continue
+			}
+			cursor = next
+			data, err := json.Marshal(&ops[i])
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(c.Writer, "id: %s\nevent: operation\ndata: %s\n\n", eventID(cursor), data)
+		}
+		if len(ops) > 0 {
+			c.Writer.Flush()
+			lastWrite = time.Now()
+		}
+		if len(ops) == eventBatchSize {
+			// Still catching up
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-poll.C:
+		}
+		if time.Since(lastWrite) >= eventHeartbeat {
+			fmt.Fprint(c.Writer, ": keep-alive\n\n")
+			c.Writer.Flush()
+			lastWrite = time.Now()
+		}
+	}
+}
+
+// eventID formats a stream position as "<block_num>-<operation id>"
+func eventID(cursor storage.OperationCursor) string {
+	return strconv.FormatInt(cursor.BlockNum, 10) + "-" + cursor.ID.Hex()
+}
+
+// parseEventID parses an event ID produced by eventID
+func parseEventID(value string) (storage.OperationCursor, error) {
+	block, id, ok := strings.Cut(value, "-")
+	if !ok {
+		return storage.OperationCursor{}, fmt.Errorf("%q is not <block_num>-<operation id>", value)
+	}
+	blockNum, err := strconv.ParseInt(block, 10, 64)
+	if err != nil || blockNum < 0 {
+		return storage.OperationCursor{}, fmt.Errorf("invalid block number %q", block)
+	}
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return storage.OperationCursor{}, fmt.Errorf("invalid operation id %q", id)
+	}
+	return storage.OperationCursor{BlockNum: blockNum, ID: objectID}, nil
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ety001/sps-fund-watcher/internal/export"
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// streamExport writes every operation matching a query as an attachment in the format of the
+// format parameter (csv or jsonl), in block order and without pagination
+// Operations are streamed from a cursor, so exports of any size use constant memory
+func (h *Handler) streamExport(c *gin.Context, query storage.OperationQuery, name string) {
+	format := c.Query("format")
+	writer, err := export.NewWriter(c.Writer, format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+"."+format))
+	c.Status(http.StatusOK)
+
+	err = h.storage.StreamOperations(c.Request.Context(), query, func(op *models.Operation) error {
+		return writer.Write(op)
+	})
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err != nil {
+		// The status line is already sent; record the error and cut the response short
+		_ = c.Error(fmt.Errorf("export %s failed: %w", name, err))
+	}
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// feedSize is the number of most recent operations included in a feed
+const feedSize = 50
+
+// rssFeed is an RSS 2.0 document
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Description string  `xml:"description"`
+	PubDate     string  `xml:"pubDate"`
+	GUID        rssGUID `xml:"guid"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+// GetViewFeed handles GET /api/v1/views/:name/rss
+// Publishes the latest operations matching a saved view with feed enabled
+func (h *Handler) GetViewFeed(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	view, err := h.storage.GetView(ctx, c.Param("name"))
+	if errors.Is(err, storage.ErrNotFound) || (err == nil && !view.Feed) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "feed not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	query, err := storage.ViewQuery(view)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid view query: " + err.Error()})
+		return
+	}
+
+	result, err := h.storage.QueryOperations(ctx, query, 1, feedSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	description := view.Description
+	if description == "" {
+		description = "Operations matching the saved view " + view.Name
+	}
+
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         view.Name,
+			Link:          h.externalURL(c, "/views/"+url.PathEscape(view.Name)),
+			Description:   description,
+			LastBuildDate: time.Now().UTC().Format(time.RFC1123Z),
+		},
+	}
+	for i := range result.Operations {
+		feed.Channel.Items = append(feed.Channel.Items, rssItemFor(&result.Operations[i]))
+	}
+
+	output, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/rss+xml; charset=utf-8", append([]byte(xml.Header), output...))
+}
+
+// rssItemFor renders an operation as a feed item
+func rssItemFor(op *models.Operation) rssItem {
+	title := fmt.Sprintf("%s: %s (block %d)", op.Account, op.OpType, op.BlockNum)
+	if amount, ok := models.OperationAmount(op.OpData); ok {
+		title = fmt.Sprintf("%s: %s %s (block %d)", op.Account, op.OpType, amount, op.BlockNum)
+	}
+
+	details, _ := json.Marshal(op.OpData)
+
+	return rssItem{
+		Title:       title,
+		Description: string(details),
+		PubDate:     op.Timestamp.UTC().Format(time.RFC1123Z),
+		GUID:        rssGUID{Value: fmt.Sprintf("%s/%d/%s", op.TrxID, op.OpInTrx, op.Account)},
+	}
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// GetFundEvents handles GET /api/v1/fund-events
+// Lists derived fund events newest first; account, kind and from/to narrow the list
+func (h *Handler) GetFundEvents(c *gin.Context) {
+	h.listFundEvents(c, c.Query("account"))
+}
+
+// GetAccountFundEvents handles GET /api/v1/accounts/:account/fund-events
+func (h *Handler) GetAccountFundEvents(c *gin.Context) {
+	h.listFundEvents(c, c.Param("account"))
+}
+
+func (h *Handler) listFundEvents(c *gin.Context, account string) {
+	query := storage.FundEventQuery{Account: account, Kinds: queryList(c, "kind")}
+	for _, kind := range query.Kinds {
+		if !slices.Contains(models.FundEventKinds, kind) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown kind %q (supported: %s)", kind, strings.Join(models.FundEventKinds, ", "))})
+			return
+		}
+	}
+	var timeRange storage.OperationQuery
+	if !parseTimeRange(c, &timeRange) {
+		return
+	}
+	query.From, query.To = timeRange.From, timeRange.To
+
+	page, pageSize := h.parsePagination(c)
+	result, err := h.storage.QueryFundEvents(c.Request.Context(), query, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
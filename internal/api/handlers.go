@@ -1,11 +1,18 @@
 package api
 
 import (
+	"fmt"
+	"net"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/ety001/sps-fund-watcher/internal/chain"
 	"github.com/ety001/sps-fund-watcher/internal/models"
+	querydsl "github.com/ety001/sps-fund-watcher/internal/query"
 	"github.com/ety001/sps-fund-watcher/internal/storage"
+	"github.com/ety001/sps-fund-watcher/internal/version"
 	"github.com/gin-gonic/gin"
 )
 
@@ -13,33 +20,92 @@ import (
 type Handler struct {
 	storage *storage.MongoDB
 	config  *models.Config
+	chain   chain.Client // Used to fetch chain data for proofs
+	started time.Time    // When the API process started, for the status page uptime
+
+	trustedProxies []*net.IPNet // api.trusted_proxies, whose X-Forwarded-* headers are honoured
+
+	statusPage statusPageCache
 }
 
 // NewHandler creates a new API handler
-func NewHandler(storage *storage.MongoDB, config *models.Config) *Handler {
+func NewHandler(storage *storage.MongoDB, config *models.Config, client chain.Client) *Handler {
+	// Validated with the config; on an error no proxy is trusted
+	proxies, _ := config.API.TrustedProxyNets()
 	return &Handler{
-		storage: storage,
-		config:  config,
+		storage:        storage,
+		config:         config,
+		chain:          client,
+		started:        time.Now(),
+		trustedProxies: proxies,
 	}
 }
 
-// GetOperations handles GET /api/v1/accounts/:account/operations
-func (h *Handler) GetOperations(c *gin.Context) {
-	account := c.Param("account")
-	opType := c.Query("type") // Optional filter by operation type
-
+// parsePagination reads page and page_size, falling back to defaults for invalid values
+// page_size is at most api.max_page_size
+func (h *Handler) parsePagination(c *gin.Context) (int, int) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
 
+	limit := h.config.API.PageSizeLimit()
 	if page < 1 {
 		page = 1
 	}
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 20
+	if pageSize < 1 || pageSize > limit {
+		pageSize = min(20, limit)
+	}
+	return page, pageSize
+}
+
+// queryList collects the values of a query parameter given as a comma-separated list, repeated,
+// or both, skipping empty entries
+func queryList(c *gin.Context, name string) []string {
+	var values []string
+	for _, param := range c.QueryArray(name) {
+		for _, value := range strings.Split(param, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// GetOperations handles GET /api/v1/accounts/:account/operations
+func (h *Handler) GetOperations(c *gin.Context) {
+	account := c.Param("account")
+
+	page, pageSize := h.parsePagination(c)
+
+	// Optional filter by operation types, e.g. type=transfer,transfer_to_savings or repeated type params
+	query := storage.OperationQuery{Account: account, OpTypes: queryList(c, "type")}
+
+	// Optional filter expression, e.g. q=op_data.amount>1000 AND op_data.to="steem.dao"
+	if q := c.Query("q"); q != "" {
+		filter, err := querydsl.Parse(q)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid q: " + err.Error()})
+			return
+		}
+		query.Filter = filter
+	}
+
+	// Optional block time range, e.g. from=2024-01-01&to=2024-02-01
+	if !parseTimeRange(c, &query) {
+		return
+	}
+
+	if c.Query("format") != "" {
+		h.streamExport(c, query, account+"-operations")
+		return
+	}
+	if acceptsNDJSON(c) {
+		h.streamOperations(c, query)
+		return
 	}
 
 	ctx := c.Request.Context()
-	result, err := h.storage.GetOperations(ctx, account, opType, page, pageSize)
+	result, err := h.storage.QueryOperations(ctx, query, page, pageSize)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -48,22 +114,184 @@ func (h *Handler) GetOperations(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
+// parseTimeRange reads the optional from/to block time range into query (RFC3339 or YYYY-MM-DD;
+// to is exclusive), answering 400 and returning false for invalid values
+func parseTimeRange(c *gin.Context, query *storage.OperationQuery) bool {
+	for param, target := range map[string]*time.Time{"from": &query.From, "to": &query.To} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := parseTime(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param + ": " + err.Error()})
+			return false
+		}
+		*target = parsed
+	}
+	return true
+}
+
+// maxMergedAccounts bounds the accounts of one merged operations query
+const maxMergedAccounts = 20
+
+// GetMergedOperations handles GET /api/v1/operations
+// accounts (comma-separated or repeated) selects the accounts; an operation stored for several of
+// them is listed once. type and q filter like on the per-account endpoint
+func (h *Handler) GetMergedOperations(c *gin.Context) {
+	accounts := queryList(c, "accounts")
+	if len(accounts) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "accounts is required"})
+		return
+	}
+	if len(accounts) > maxMergedAccounts {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d accounts can be queried together", maxMergedAccounts)})
+		return
+	}
+	for _, account := range accounts {
+		if err := models.ValidateAccountName(account); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account: " + err.Error()})
+			return
+		}
+	}
+
+	page, pageSize := h.parsePagination(c)
+
+	query := storage.OperationQuery{Accounts: accounts, OpTypes: queryList(c, "type")}
+	if q := c.Query("q"); q != "" {
+		filter, err := querydsl.Parse(q)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid q: " + err.Error()})
+			return
+		}
+		query.Filter = filter
+	}
+
+	result, err := h.storage.QueryMergedOperations(c.Request.Context(), query, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetEvents handles GET /api/v1/events
+// Lists each chain operation once with both parties, even when it was stored for the sending and the
+// receiving account, for public feeds. accounts optionally limits it to operations stored for any of
+// those accounts; type, q and from/to filter like on the per-account endpoint
+func (h *Handler) GetEvents(c *gin.Context) {
+	accounts := queryList(c, "accounts")
+	if len(accounts) > maxMergedAccounts {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d accounts can be queried together", maxMergedAccounts)})
+		return
+	}
+	for _, account := range accounts {
+		if err := models.ValidateAccountName(account); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account: " + err.Error()})
+			return
+		}
+	}
+
+	page, pageSize := h.parsePagination(c)
+
+	query := storage.OperationQuery{Accounts: accounts, OpTypes: queryList(c, "type")}
+	if q := c.Query("q"); q != "" {
+		filter, err := querydsl.Parse(q)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid q: " + err.Error()})
+			return
+		}
+		query.Filter = filter
+	}
+	if !parseTimeRange(c, &query) {
+		return
+	}
+
+	merged, err := h.storage.QueryMergedOperations(c.Request.Context(), query, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := models.EventResponse{
+		Events:   make([]models.Event, 0, len(merged.Operations)),
+		Total:    merged.Total,
+		Page:     merged.Page,
+		PageSize: merged.PageSize,
+		HasMore:  merged.HasMore,
+	}
+	for _, op := range merged.Operations {
+		result.Events = append(result.Events, models.NewEvent(op))
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 // GetTransfers handles GET /api/v1/accounts/:account/transfers
 func (h *Handler) GetTransfers(c *gin.Context) {
 	account := c.Param("account")
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if c.Query("format") != "" {
+		h.streamExport(c, storage.OperationQuery{Account: account, OpType: "transfer"}, account+"-transfers")
+		return
+	}
 
-	if page < 1 {
-		page = 1
+	page, pageSize := h.parsePagination(c)
+
+	ctx := c.Request.Context()
+	result, err := h.storage.GetOperations(ctx, account, "transfer", page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetTransferSummary handles GET /api/v1/accounts/:account/transfers/summary
+// Query params: group_by ("counterparty" (default), "day" or "month"), from/to (RFC3339 or YYYY-MM-DD)
+func (h *Handler) GetTransferSummary(c *gin.Context) {
+	groupBy := c.DefaultQuery("group_by", models.GroupByCounterparty)
+	switch groupBy {
+	case models.GroupByCounterparty, models.GroupByDay, models.GroupByMonth:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group_by: use counterparty, day or month"})
+		return
 	}
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 20
+
+	var from, to time.Time
+	for param, target := range map[string]*time.Time{"from": &from, "to": &to} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := parseTime(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param + ": " + err.Error()})
+			return
+		}
+		*target = parsed
+	}
+
+	summary, err := h.storage.TransferSummary(c.Request.Context(), c.Param("account"), groupBy, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
+	c.JSON(http.StatusOK, summary)
+}
+
+// GetMentions handles GET /api/v1/accounts/:account/mentions
+// Mention events are recorded when steem.detect_mentions is enabled
+func (h *Handler) GetMentions(c *gin.Context) {
+	account := c.Param("account")
+
+	page, pageSize := h.parsePagination(c)
+
 	ctx := c.Request.Context()
-	result, err := h.storage.GetOperations(ctx, account, "transfer", page, pageSize)
+	result, err := h.storage.GetOperations(ctx, account, models.OpTypeMention, page, pageSize)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -76,15 +304,7 @@ func (h *Handler) GetTransfers(c *gin.Context) {
 func (h *Handler) GetUpdates(c *gin.Context) {
 	account := c.Param("account")
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
-
-	if page < 1 {
-		page = 1
-	}
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 20
-	}
+	page, pageSize := h.parsePagination(c)
 
 	ctx := c.Request.Context()
 
@@ -113,19 +333,109 @@ func (h *Handler) GetUpdates(c *gin.Context) {
 	c.JSON(http.StatusOK, combined)
 }
 
+// GetAggregates handles GET /api/v1/accounts/:account/aggregates
+// Returns hourly counts for operations stored by aggregate sampling rules
+func (h *Handler) GetAggregates(c *gin.Context) {
+	var from, to time.Time
+	for param, target := range map[string]*time.Time{"from": &from, "to": &to} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := parseTime(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param + ": " + err.Error()})
+			return
+		}
+		*target = parsed
+	}
+
+	aggregates, err := h.storage.GetAggregates(c.Request.Context(), c.Param("account"), c.Query("type"), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if aggregates == nil {
+		aggregates = []models.OperationAggregate{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"aggregates": aggregates})
+}
+
+// parseTime accepts RFC3339 timestamps or plain dates (UTC)
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
 // GetAccounts handles GET /api/v1/accounts
-// Returns the list of tracked accounts from configuration
+// Returns the list of tracked accounts from configuration, and the proposal receivers tracked
+// automatically when reconciliation.auto_track_receivers is set
 func (h *Handler) GetAccounts(c *gin.Context) {
 	// Get accounts from configuration instead of database
 	accounts := h.config.Steem.Accounts
 	if accounts == nil {
 		accounts = []string{}
 	}
+	if !h.config.Reconciliation.AutoTrackReceivers {
+		c.JSON(http.StatusOK, gin.H{"accounts": accounts})
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{"accounts": accounts})
+	autoTracked, err := h.storage.ListAutoTracked(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if autoTracked == nil {
+		autoTracked = []models.AutoTrackedAccount{}
+	}
+	c.JSON(http.StatusOK, gin.H{"accounts": accounts, "auto_tracked": autoTracked})
 }
 
 // Health handles GET /api/v1/health
 func (h *Handler) Health(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
+
+// GetStatus handles GET /api/v1/status
+// Returns build information, the current sync state and the sync lag
+func (h *Handler) GetStatus(c *gin.Context) {
+	syncState, err := h.storage.GetSyncState(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	leader, err := h.storage.GetLease(c.Request.Context(), models.SyncLeaseName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	status := StatusResponse{
+		Version: version.Get(),
+		Sync:    syncState,
+		Leader:  leader,
+
+		Instance:  h.config.Instance.Labels(),
+		ChainName: h.config.Steem.Chain,
+	}
+
+	// The node is asked for the current chain position so monitors can alert on sync lag
+	dgp, err := h.chain.GetDynamicGlobalProperties()
+	if err != nil {
+		status.ChainError = err.Error()
+	} else {
+		head, lib := int64(dgp.HeadBlockNumber), int64(dgp.LastIrreversibleBlockNum)
+		status.Chain = &ChainStatus{
+			HeadBlock:             head,
+			LastIrreversibleBlock: lib,
+			Lag:                   syncState.Lag(h.config.Steem.SyncMode, head, lib),
+		}
+	}
+
+	c.JSON(http.StatusOK, status)
+}
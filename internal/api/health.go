@@ -0,0 +1,147 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	protocolapi "github.com/steemit/steemutil/protocol/api"
+)
+
+// readinessTimeout bounds each check of the readiness endpoint
+const readinessTimeout = 5 * time.Second
+
+// Readiness check statuses
+const (
+	healthOK     = "ok"
+	healthFail   = "fail"
+	healthPaused = "paused" // Sync paused by an operator; not a failure
+)
+
+// HealthCheck is the result of one readiness check
+type HealthCheck struct {
+	Status    string `json:"status"` // ok or fail; the sync check can also be paused
+	Error     string `json:"error,omitempty"`
+	LatencyMS int64  `json:"latency_ms"`
+}
+
+// SyncHealth is the readiness of the sync service, judged by its stored sync state
+type SyncHealth struct {
+	HealthCheck
+	LastBlock          int64  `json:"last_block"`
+	Lag                *int64 `json:"lag,omitempty"` // Blocks behind the node; absent when the node is unreachable
+	SinceUpdateSeconds int64  `json:"since_update_seconds"`
+	MaxLag             int64  `json:"max_lag"`
+	MaxSyncAgeSeconds  int64  `json:"max_sync_age_seconds"`
+}
+
+// ReadinessResponse reports the components the watcher depends on
+type ReadinessResponse struct {
+	Status  string      `json:"status"` // ok when no check failed
+	MongoDB HealthCheck `json:"mongodb"`
+	Steem   HealthCheck `json:"steem"`
+	Sync    SyncHealth  `json:"sync"`
+}
+
+// Ready handles GET /api/v1/health/ready
+// Checks MongoDB connectivity, Steem node reachability and sync freshness, answering 503 when any
+// of them fails so load balancers and monitors act on real conditions. /health only tells the
+// API process is up
+func (h *Handler) Ready(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+
+	// MongoDB and the node are checked concurrently
+	type dgpResult struct {
+		dgp *protocolapi.DynamicGlobalProperties
+		err error
+	}
+	node := make(chan dgpResult, 1)
+	steemStart := time.Now()
+	go func() {
+		dgp, err := h.chain.GetDynamicGlobalProperties()
+		node <- dgpResult{dgp, err}
+	}()
+
+	var response ReadinessResponse
+	mongoStart := time.Now()
+	mongoErr := h.storage.Ping(ctx)
+	response.MongoDB = healthCheck(mongoErr, time.Since(mongoStart))
+
+	var chainState dgpResult
+	select {
+	case chainState = <-node:
+	case <-ctx.Done():
+		chainState.err = fmt.Errorf("no answer within %s", readinessTimeout)
+	}
+	response.Steem = healthCheck(chainState.err, time.Since(steemStart))
+
+	response.Sync = h.syncHealth(ctx, mongoErr, chainState.dgp)
+
+	response.Status = healthOK
+	status := http.StatusOK
+	for _, check := range []string{response.MongoDB.Status, response.Steem.Status, response.Sync.Status} {
+		if check == healthFail {
+			response.Status = healthFail
+			status = http.StatusServiceUnavailable
+		}
+	}
+	c.JSON(status, response)
+}
+
+// syncHealth judges the sync by how recently it updated its state and how far it is behind the node
+func (h *Handler) syncHealth(ctx context.Context, mongoErr error, dgp *protocolapi.DynamicGlobalProperties) SyncHealth {
+	thresholds := h.config.API.Health
+	health := SyncHealth{
+		MaxLag:            thresholds.MaxLagBlocks(),
+		MaxSyncAgeSeconds: int64(thresholds.MaxSyncAge() / time.Second),
+	}
+	start := time.Now()
+	result := func(err error) SyncHealth {
+		health.HealthCheck = healthCheck(err, time.Since(start))
+		return health
+	}
+
+	if mongoErr != nil {
+		return result(errors.New("sync state unavailable: MongoDB is unreachable"))
+	}
+	syncState, err := h.storage.GetSyncState(ctx)
+	if err != nil {
+		return result(err)
+	}
+	control, err := h.storage.GetControlState(ctx)
+	if err != nil {
+		return result(err)
+	}
+
+	health.LastBlock = syncState.LastBlock
+	health.SinceUpdateSeconds = int64(time.Since(syncState.UpdatedAt) / time.Second)
+	if dgp != nil {
+		lag := syncState.Lag(h.config.Steem.SyncMode, int64(dgp.HeadBlockNumber), int64(dgp.LastIrreversibleBlockNum))
+		health.Lag = &lag
+	}
+
+	switch {
+	case control.SyncPaused:
+		health.HealthCheck = HealthCheck{Status: healthPaused, LatencyMS: time.Since(start).Milliseconds()}
+		return health
+	case time.Since(syncState.UpdatedAt) > thresholds.MaxSyncAge():
+		return result(fmt.Errorf("sync state not updated for %ds (max %ds)", health.SinceUpdateSeconds, health.MaxSyncAgeSeconds))
+	case health.Lag != nil && *health.Lag > health.MaxLag:
+		return result(fmt.Errorf("sync is %d blocks behind (max %d)", *health.Lag, health.MaxLag))
+	}
+	return result(nil)
+}
+
+// healthCheck returns the check result of a call that returned err after latency
+func healthCheck(err error, latency time.Duration) HealthCheck {
+	check := HealthCheck{Status: healthOK, LatencyMS: latency.Milliseconds()}
+	if err != nil {
+		check.Status = healthFail
+		check.Error = err.Error()
+	}
+	return check
+}
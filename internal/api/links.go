@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fromTrustedProxy reports whether the request comes from one of api.trusted_proxies
+// Only those may set the X-Forwarded-* headers links are built from; from anyone else they
+// would let a client put links to a host of its choice into responses others may get from a cache
+func (h *Handler) fromTrustedProxy(c *gin.Context) bool {
+	ip := net.ParseIP(c.RemoteIP())
+	if ip == nil {
+		return false
+	}
+	for _, network := range h.trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedPrefix returns the path prefix a trusted reverse proxy strips before forwarding (X-Forwarded-Prefix)
+func (h *Handler) forwardedPrefix(c *gin.Context) string {
+	if !h.fromTrustedProxy(c) {
+		return ""
+	}
+	prefix := strings.TrimRight(c.GetHeader("X-Forwarded-Prefix"), "/")
+	if prefix != "" && !strings.HasPrefix(prefix, "/") {
+		return ""
+	}
+	return prefix
+}
+
+// linkPath returns the path clients use for an API path such as "/views/x",
+// including the proxy prefix and api.base_path
+func (h *Handler) linkPath(c *gin.Context, path string) string {
+	return h.forwardedPrefix(c) + h.config.API.RoutePrefix() + path
+}
+
+// externalURL returns the absolute URL clients use for an API path
+// Behind a trusted reverse proxy, X-Forwarded-Proto and X-Forwarded-Host name the public scheme and host
+func (h *Handler) externalURL(c *gin.Context, path string) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	host := c.Request.Host
+
+	if h.fromTrustedProxy(c) {
+		if proto := c.GetHeader("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+			scheme = proto
+		}
+		if forwarded := c.GetHeader("X-Forwarded-Host"); forwarded != "" {
+			// Proxy chains append hosts; the first one is what the client asked for
+			host = strings.TrimSpace(strings.Split(forwarded, ",")[0])
+		}
+	}
+
+	return scheme + "://" + host + h.linkPath(c, path)
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+func TestExternalURL(t *testing.T) {
+	forwarded := map[string]string{
+		"X-Forwarded-Prefix": "/evil",
+		"X-Forwarded-Proto":  "https",
+		"X-Forwarded-Host":   "attacker.example, proxy.local",
+	}
+	tests := []struct {
+		name    string
+		remote  string
+		headers map[string]string
+		want    string
+	}{
+		{name: "direct request", remote: "192.0.2.1:1000", want: "http://api.local/watcher/api/v1/views/x"},
+		{name: "forwarded headers from a client", remote: "192.0.2.1:1000", headers: forwarded, want: "http://api.local/watcher/api/v1/views/x"},
+		{name: "forwarded headers from a trusted proxy", remote: "10.0.0.5:1000", headers: forwarded, want: "https://attacker.example/evil/watcher/api/v1/views/x"},
+	}
+	h := NewHandler(nil, &models.Config{API: models.APIConfig{BasePath: "/watcher", TrustedProxies: []string{"10.0.0.0/8"}}}, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "http://api.local/watcher/api/v1/openapi.json", nil)
+			c.Request.RemoteAddr = tt.remote
+			for name, value := range tt.headers {
+				c.Request.Header.Set(name, value)
+			}
+			if got := h.externalURL(c, "/views/x"); got != tt.want {
+				t.Errorf("externalURL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// ResendRequest selects the pending notifications to resend; no IDs means all of them
+type ResendRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// ListPendingNotifications handles GET /api/v1/admin/notifications/pending
+func (h *Handler) ListPendingNotifications(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	notifications, err := h.storage.ListPendingNotifications(c.Request.Context(), int64(limit))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"notifications": notifications})
+}
+
+// GetNotificationDeadLetters handles GET /api/v1/admin/notifications/dead-letters
+func (h *Handler) GetNotificationDeadLetters(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	deadLetters, err := h.storage.GetNotificationDeadLetters(c.Request.Context(), int64(limit))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"dead_letters": deadLetters})
+}
+
+// ResendPendingNotifications handles POST /api/v1/admin/notifications/resend
+// The notifications become due at once; the active sync instance sends them on its next round
+func (h *Handler) ResendPendingNotifications(c *gin.Context) {
+	var req ResendRequest
+	// An empty body is valid and means "all"
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	count, err := h.storage.ResendPendingNotifications(c.Request.Context(), req.IDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"scheduled": count})
+}
+
+// DeletePendingNotification handles DELETE /api/v1/admin/notifications/pending/:id
+func (h *Handler) DeletePendingNotification(c *gin.Context) {
+	err := h.storage.DeletePendingNotification(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, storage.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "pending notification not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
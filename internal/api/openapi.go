@@ -0,0 +1,264 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/version"
+	"github.com/gin-gonic/gin"
+)
+
+// openAPIVersion is the OpenAPI version of the generated document
+const openAPIVersion = "3.0.3"
+
+// paramDoc documents a query parameter
+type paramDoc struct {
+	name        string
+	description string
+	required    bool
+}
+
+// routeDoc documents a route in the OpenAPI document
+type routeDoc struct {
+	summary  string
+	query    []paramDoc
+	request  reflect.Type // JSON request body, if any
+	response string       // Name of the published schema of the response, if any
+	content  string       // Content type of non-JSON responses, or an alternative to response
+}
+
+// Query parameters shared by several routes
+var (
+	pageParams = []paramDoc{
+		{name: "page", description: "Page number (default 1)"},
+		{name: "page_size", description: "Operations per page, 1 to api.max_page_size (default 20; max_page_size defaults to 100)"},
+	}
+	typeParam   = paramDoc{name: "type", description: "Operation types, comma-separated or repeated"}
+	qParam      = paramDoc{name: "q", description: `Filter expression, e.g. op_data.amount>1000 AND op_data.to="steem.dao"`}
+	formatParam = paramDoc{name: "format", description: "csv or jsonl downloads all matching operations instead of a page"}
+	kindParam   = paramDoc{name: "kind", description: "Fund event kinds, comma-separated or repeated"}
+	timeParams  = []paramDoc{{name: "from", description: "RFC3339 or YYYY-MM-DD (inclusive)"}, {name: "to", description: "RFC3339 or YYYY-MM-DD (exclusive)"}}
+	dayParams   = []paramDoc{{name: "from", description: "YYYY-MM-DD (inclusive)"}, {name: "to", description: "YYYY-MM-DD (inclusive)"}}
+	// Accepted by every route with a JSON response
+	tzParam = paramDoc{name: "tz", description: "IANA time zone, e.g. Europe/Berlin; adds <field>_local in that zone next to each timestamp (default api.timezone)"}
+)
+
+// paged prepends the pagination parameters
+func paged(params ...paramDoc) []paramDoc {
+	return append(append([]paramDoc{}, pageParams...), params...)
+}
+
+// withTimeRange appends the from/to block time parameters
+func withTimeRange(params ...paramDoc) []paramDoc {
+	return append(append([]paramDoc{}, params...), timeParams...)
+}
+
+// routeDocs documents the routes by method and gin path
+// Routes missing here still appear in the document, with only their path parameters
+var routeDocs = map[string]routeDoc{
+	"GET /api/v1/health":                              {summary: "Liveness check: the API process is up"},
+	"GET /api/v1/health/ready":                        {summary: "Readiness of MongoDB, the Steem node and the sync; 503 when a check fails", response: "readiness"},
+	"GET /api/v1/status":                              {summary: "Build version and current sync state", response: "status"},
+	"GET /api/v1/schemas":                             {summary: "List published JSON Schemas"},
+	"GET /api/v1/schemas/:name":                       {summary: "JSON Schema of an API payload"},
+	"GET /api/v1/openapi.json":                        {summary: "This OpenAPI document"},
+	"GET /api/v1/docs":                                {summary: "Swagger UI for this API", content: "text/html"},
+	"GET /api/v1/search":                              {summary: "Search stored operations across accounts", response: "operation_response", query: paged(withTimeRange(paramDoc{name: "memo", description: "Words in the transfer memo"}, paramDoc{name: "counterparty", description: "Account in from, to or receiver"}, paramDoc{name: "min_amount", description: "Inclusive minimum amount"}, paramDoc{name: "max_amount", description: "Inclusive maximum amount"}, paramDoc{name: "symbol", description: "Asset symbol, e.g. SBD"}, typeParam, paramDoc{name: "account", description: "Tracked account the record belongs to"})...)},
+	"GET /api/v1/stats":                               {summary: "Operation counts per type, account and day", response: "operation_stats", query: withTimeRange(paramDoc{name: "account", description: "Only this account"}, paramDoc{name: "type", description: "Only this operation type"})},
+	"GET /api/v1/accounts":                            {summary: "Tracked accounts"},
+	"GET /api/v1/accounts/:account/operations":        {summary: "Operations of an account, newest first; Accept: application/x-ndjson streams all matches in block order", response: "operation_response", content: ndjsonContentType, query: paged(withTimeRange(typeParam, qParam, formatParam)...)},
+	"GET /api/v1/accounts/:account/transfers":         {summary: "Transfers of an account, newest first", response: "operation_response", query: paged(formatParam)},
+	"GET /api/v1/accounts/:account/transfers/summary": {summary: "Transfer totals per counterparty, day or month", response: "transfer_summary", query: withTimeRange(paramDoc{name: "group_by", description: "counterparty (default), day or month"})},
+	"GET /api/v1/accounts/:account/updates":           {summary: "Account update operations", response: "operation_response", query: paged()},
+	"GET /api/v1/accounts/:account/mentions":          {summary: "Mention events of an account", response: "operation_response", query: paged()},
+	"GET /api/v1/accounts/:account/aggregates":        {summary: "Hourly counts stored by aggregate sampling rules", query: withTimeRange(paramDoc{name: "type", description: "Only this operation type"})},
+	"GET /api/v1/accounts/:account/events":            {summary: "Server-Sent Events stream of new operations; resumes after Last-Event-ID", content: "text/event-stream", query: []paramDoc{typeParam, qParam, {name: "last_event_id", description: "Resume after this event ID (for clients that can't send Last-Event-ID)"}}},
+	"GET /api/v1/operations":                          {summary: "Operations of several accounts merged into one list", response: "merged_operations", query: paged(paramDoc{name: "accounts", description: "Accounts, comma-separated or repeated (at most 20)", required: true}, typeParam, qParam)},
+	"GET /api/v1/events":                              {summary: "Each chain operation once with both parties, for public feeds", response: "events", query: withTimeRange(paged(paramDoc{name: "accounts", description: "Only operations stored for these accounts, comma-separated or repeated (at most 20; default all)"}, typeParam, qParam)...)},
+	"GET /api/v1/fund-events":                         {summary: "Derived fund events (payouts, funding changes, key changes, large withdrawals), newest first", response: "fund_events", query: withTimeRange(paged(paramDoc{name: "account", description: "Only events about this account"}, kindParam)...)},
+	"GET /api/v1/accounts/:account/fund-events":       {summary: "Derived fund events about an account, newest first", response: "fund_events", query: withTimeRange(paged(kindParam)...)},
+	"GET /api/v1/operations/:id/proof":                {summary: "Chain data backing a stored operation", response: "operation_proof"},
+	"GET /api/v1/blocks/:block_num/operations":        {summary: "Everything recorded from a block", response: "block_operations", query: []paramDoc{{name: "account", description: "Only records of this account"}}},
+	"GET /api/v1/reconciliation/proposals":            {summary: "Proposal payouts compared with daily_pay", response: "proposal_reconciliation", query: dayParams},
+	"GET /api/v1/sps/runway":                          {summary: "Treasury runway projection", response: "runway_projection"},
+	"GET /api/v1/sps/flows":                           {summary: "Daily treasury inflows and outflows", response: "treasury_flows", query: dayParams},
+	"GET /api/v1/sps/donors":                          {summary: "Leaderboard of donations to the treasury", response: "donor_leaderboard", query: append([]paramDoc{{name: "period", description: "<days>d (default 30d) or all"}, {name: "symbol", description: "SBD (default) or STEEM"}, {name: "limit", description: "Donors listed (default 50, at most 500)"}}, timeParams...)},
+	"GET /api/v1/views":                               {summary: "Saved views"},
+	"GET /api/v1/views/:name":                         {summary: "Run a saved view", query: paged()},
+	"GET /api/v1/views/:name/rss":                     {summary: "RSS feed of a saved view", content: "application/rss+xml"},
+	"GET /api/v1/chains":                              {summary: "Watched chains; each chain's routes repeat under its path"},
+
+	"GET /api/v1/admin/state":                         {summary: "Pause switches", response: "control_state"},
+	"POST /api/v1/admin/pause":                        {summary: "Pause sync and/or notifications", request: reflect.TypeOf(pauseRequest{}), response: "control_state"},
+	"POST /api/v1/admin/resume":                       {summary: "Resume sync and/or notifications", request: reflect.TypeOf(pauseRequest{}), response: "control_state"},
+	"GET /api/v1/admin/storage":                       {summary: "Database size per collection", response: "storage_report"},
+	"GET /api/v1/admin/indexes":                       {summary: "Index usage", response: "index_report"},
+	"GET /api/v1/admin/webhooks":                      {summary: "Registered webhooks"},
+	"POST /api/v1/admin/webhooks":                     {summary: "Create or replace a webhook", request: reflect.TypeOf(models.Webhook{})},
+	"GET /api/v1/admin/webhooks/dead-letters":         {summary: "Webhook deliveries that failed for good"},
+	"DELETE /api/v1/admin/webhooks/:name":             {summary: "Delete a webhook"},
+	"GET /api/v1/admin/views":                         {summary: "Saved views"},
+	"POST /api/v1/admin/views":                        {summary: "Create or replace a saved view", request: reflect.TypeOf(models.SavedView{}), response: "saved_view"},
+	"DELETE /api/v1/admin/views/:name":                {summary: "Delete a saved view"},
+	"GET /api/v1/admin/accounts/metadata":             {summary: "Account aliases and opt-outs"},
+	"PUT /api/v1/admin/accounts/:account/metadata":    {summary: "Set an account's alias, opt-out and note", request: reflect.TypeOf(models.AccountMetadata{}), response: "account_metadata"},
+	"DELETE /api/v1/admin/accounts/:account/metadata": {summary: "Remove an account's metadata"},
+	"GET /api/v1/admin/review":                        {summary: "Operations matched by the fallback extraction and waiting for review", response: "operation_response", query: paged()},
+	"POST /api/v1/admin/review/:id/confirm":           {summary: "Confirm a flagged operation"},
+	"DELETE /api/v1/admin/review/:id":                 {summary: "Reject and delete a flagged operation"},
+	"GET /api/v1/admin/notifications/pending":         {summary: "Telegram notifications that failed to send and wait for a resend", query: []paramDoc{{name: "limit", description: "Notifications listed (default 50, at most 500)"}}},
+	"POST /api/v1/admin/notifications/resend":         {summary: "Resend pending notifications now", request: reflect.TypeOf(ResendRequest{})},
+	"DELETE /api/v1/admin/notifications/pending/:id":  {summary: "Drop a pending notification"},
+	"GET /api/v1/admin/notifications/dead-letters":    {summary: "Telegram notifications that gave up retrying", query: []paramDoc{{name: "limit", description: "Dead letters listed (default 50, at most 500)"}}},
+	"POST /api/v1/admin/templates/render":             {summary: "Render a message template without sending it", request: reflect.TypeOf(TemplateRenderRequest{}), response: "template_render"},
+}
+
+// openAPIHandler returns a handler serving the OpenAPI document of the router's routes
+// The routes are read when the document is requested, so every registered route is listed
+func (h *Handler) openAPIHandler(router *gin.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Paths are documented relative to the server, which carries the proxy prefix and base path
+		server := strings.TrimSuffix(h.externalURL(c, ""), "/api/v1")
+		c.JSON(http.StatusOK, openAPIDocument(router.Routes(), strings.TrimRight(h.config.API.BasePath, "/"), server))
+	}
+}
+
+// openAPIDocument builds the OpenAPI document for routes mounted under basePath
+func openAPIDocument(routes gin.RoutesInfo, basePath, server string) map[string]interface{} {
+	schemas := make(map[string]interface{}, len(publishedSchemas))
+	for name, t := range publishedSchemas {
+		schemas[name] = jsonSchema(t)
+	}
+
+	paths := map[string]map[string]interface{}{}
+	for _, route := range routes {
+		routePath := strings.TrimPrefix(route.Path, basePath)
+		// The routes of each chain mirror the ones directly under /api/v1
+		if strings.HasPrefix(routePath, "/api/v1/chains/") {
+			continue
+		}
+		path, pathParams := openAPIPath(routePath)
+		doc := routeDocs[route.Method+" "+routePath]
+
+		var parameters []interface{}
+		for _, name := range pathParams {
+			parameters = append(parameters, map[string]interface{}{
+				"name": name, "in": "path", "required": true, "schema": map[string]interface{}{"type": "string"},
+			})
+		}
+		query := doc.query
+		if doc.content == "" || doc.response != "" {
+			query = append(append([]paramDoc{}, query...), tzParam)
+		}
+		for _, param := range query {
+			parameters = append(parameters, map[string]interface{}{
+				"name": param.name, "in": "query", "required": param.required, "description": param.description,
+				"schema": map[string]interface{}{"type": "string"},
+			})
+		}
+
+		success := map[string]interface{}{"description": "OK"}
+		switch {
+		case doc.response != "":
+			success["content"] = map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": map[string]interface{}{"$ref": "#/components/schemas/" + doc.response}},
+			}
+			if doc.content != "" {
+				success["content"].(map[string]interface{})[doc.content] = map[string]interface{}{}
+			}
+		case doc.content != "":
+			success["content"] = map[string]interface{}{doc.content: map[string]interface{}{}}
+		default:
+			success["content"] = map[string]interface{}{"application/json": map[string]interface{}{"schema": map[string]interface{}{"type": "object"}}}
+		}
+		responses := map[string]interface{}{"200": success}
+		if route.Method == http.MethodDelete {
+			responses = map[string]interface{}{"204": map[string]interface{}{"description": "Deleted"}}
+		}
+
+		operation := map[string]interface{}{
+			"summary":   doc.summary,
+			"responses": responses,
+		}
+		if doc.summary == "" {
+			operation["summary"] = route.Method + " " + routePath
+		}
+		if len(parameters) > 0 {
+			operation["parameters"] = parameters
+		}
+		if doc.request != nil {
+			operation["requestBody"] = map[string]interface{}{
+				"content": map[string]interface{}{"application/json": map[string]interface{}{"schema": jsonSchema(doc.request)}},
+			}
+		}
+		if strings.HasPrefix(routePath, "/api/v1/admin/") {
+			operation["tags"] = []string{"admin"}
+			operation["security"] = []interface{}{map[string]interface{}{"adminToken": []string{}}}
+		} else {
+			operation["tags"] = []string{"public"}
+		}
+
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+		paths[path][strings.ToLower(route.Method)] = operation
+	}
+
+	return map[string]interface{}{
+		"openapi": openAPIVersion,
+		"info": map[string]interface{}{
+			"title":       "SPS Fund Watcher API",
+			"version":     version.Get().Version,
+			"description": "Operations of tracked Steem accounts and treasury (SPS) reports",
+		},
+		"servers": []interface{}{map[string]interface{}{"url": server}},
+		"paths":   paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"adminToken": map[string]interface{}{"type": "http", "scheme": "bearer", "description": "api.admin_token"},
+				"apiKey":     map[string]interface{}{"type": "apiKey", "in": "header", "name": "X-API-Key", "description": "Optional key from api.rate_limit.keys"},
+			},
+		},
+	}
+}
+
+// openAPIPath converts a gin path ("/accounts/:account") to OpenAPI form ("/accounts/{account}")
+// and returns the names of its path parameters
+func openAPIPath(path string) (string, []string) {
+	segments := strings.Split(path, "/")
+	var params []string
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			name := segment[1:]
+			params = append(params, name)
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// swaggerUIPage loads Swagger UI from a CDN and points it at the OpenAPI document
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>SPS Fund Watcher API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "openapi.json", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`
+
+// SwaggerUI handles GET /api/v1/docs
+func (h *Handler) SwaggerUI(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// OperationProof is the chain data needed to verify a stored operation independently
+type OperationProof struct {
+	Operation models.Operation `json:"operation"`
+	Block     ProofBlockHeader `json:"block"`
+	// Transaction containing the operation, exactly as returned by condenser_api.get_block
+	Transaction      map[string]interface{} `json:"transaction,omitempty"`
+	TransactionIndex *int                   `json:"transaction_index,omitempty"`
+	// Virtual operation entry from condenser_api.get_ops_in_block (virtual operations have no transaction)
+	VirtualOperation map[string]interface{} `json:"virtual_operation,omitempty"`
+	// Transaction IDs of the block in order; the node API does not expose merkle paths
+	TransactionIDs []string `json:"transaction_ids"`
+	Source         string   `json:"source"` // Node the data was fetched from
+	Verification   []string `json:"verification"`
+}
+
+// ProofBlockHeader is the signed header of the block containing the operation
+type ProofBlockHeader struct {
+	BlockNum              int64       `json:"block_num"`
+	BlockID               string      `json:"block_id"`
+	Previous              string      `json:"previous"`
+	Timestamp             string      `json:"timestamp"`
+	Witness               string      `json:"witness"`
+	TransactionMerkleRoot string      `json:"transaction_merkle_root"`
+	WitnessSignature      string      `json:"witness_signature"`
+	SigningKey            string      `json:"signing_key"`
+	Extensions            interface{} `json:"extensions"`
+}
+
+// rawBlock is the subset of condenser_api.get_block used for proofs
+type rawBlock struct {
+	Previous              string                   `json:"previous"`
+	Timestamp             string                   `json:"timestamp"`
+	Witness               string                   `json:"witness"`
+	TransactionMerkleRoot string                   `json:"transaction_merkle_root"`
+	Extensions            interface{}              `json:"extensions"`
+	WitnessSignature      string                   `json:"witness_signature"`
+	Transactions          []map[string]interface{} `json:"transactions"`
+	BlockID               string                   `json:"block_id"`
+	SigningKey            string                   `json:"signing_key"`
+	TransactionIDs        []string                 `json:"transaction_ids"`
+}
+
+// GetOperationProof handles GET /api/v1/operations/:id/proof
+// Returns the block header and transaction (or virtual operation) backing a stored operation
+func (h *Handler) GetOperationProof(c *gin.Context) {
+	op, err := h.storage.GetOperation(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, storage.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "operation not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var block rawBlock
+	if err := h.chain.CallWithResult("condenser_api", "get_block", []interface{}{op.BlockNum}, &block); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("failed to fetch block %d: %v", op.BlockNum, err)})
+		return
+	}
+	if block.BlockID == "" {
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("block %d not available from node", op.BlockNum)})
+		return
+	}
+
+	proof := OperationProof{
+		Operation: *op,
+		Block: ProofBlockHeader{
+			BlockNum:              op.BlockNum,
+			BlockID:               block.BlockID,
+			Previous:              block.Previous,
+			Timestamp:             block.Timestamp,
+			Witness:               block.Witness,
+			TransactionMerkleRoot: block.TransactionMerkleRoot,
+			WitnessSignature:      block.WitnessSignature,
+			SigningKey:            block.SigningKey,
+			Extensions:            block.Extensions,
+		},
+		TransactionIDs: block.TransactionIDs,
+		Source:         h.config.Steem.APIURL,
+	}
+
+	if virtualOp, ok := parseVirtualTrxID(op.TrxID); ok {
+		entry, err := h.findVirtualOperation(op.BlockNum, virtualOp)
+		if err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			return
+		}
+		proof.VirtualOperation = entry
+		proof.Verification = []string{
+			fmt.Sprintf("Call condenser_api.get_block(%d) on any public node and compare block_id, previous and transaction_merkle_root", op.BlockNum),
+			fmt.Sprintf("Call condenser_api.get_ops_in_block(%d, true) and find the entry with virtual_op %d", op.BlockNum, virtualOp),
+			"Compare that entry's op with operation.op_type and operation.op_data",
+		}
+	} else {
+		for i, id := range block.TransactionIDs {
+			if id == op.TrxID && i < len(block.Transactions) {
+				index := i
+				proof.Transaction = block.Transactions[i]
+				proof.TransactionIndex = &index
+				break
+			}
+		}
+		if proof.Transaction == nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("transaction %s not found in block %d", op.TrxID, op.BlockNum)})
+			return
+		}
+		proof.Verification = []string{
+			fmt.Sprintf("Call condenser_api.get_block(%d) on any public node and compare block_id, previous and transaction_merkle_root", op.BlockNum),
+			fmt.Sprintf("Check that transaction_ids[%d] is %s and the transaction matches", *proof.TransactionIndex, op.TrxID),
+			"Find the operation of type operation.op_type in transaction.operations and compare it with operation.op_data",
+		}
+	}
+
+	c.JSON(http.StatusOK, proof)
+}
+
+// parseVirtualTrxID extracts the virtual_op number from synthetic IDs of the form virtual_<block>_<virtual_op>
+func parseVirtualTrxID(trxID string) (int64, bool) {
+	if !strings.HasPrefix(trxID, "virtual_") {
+		return 0, false
+	}
+	parts := strings.Split(trxID, "_")
+	if len(parts) != 3 {
+		return 0, false
+	}
+	virtualOp, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return virtualOp, true
+}
+
+// findVirtualOperation fetches the raw get_ops_in_block entry for a virtual operation
+func (h *Handler) findVirtualOperation(blockNum, virtualOp int64) (map[string]interface{}, error) {
+	var entries []map[string]interface{}
+	if err := h.chain.CallWithResult("condenser_api", "get_ops_in_block", []interface{}{blockNum, true}, &entries); err != nil {
+		return nil, fmt.Errorf("failed to fetch virtual operations of block %d: %w", blockNum, err)
+	}
+
+	for _, entry := range entries {
+		// JSON numbers decode as float64
+		if number, ok := entry["virtual_op"].(float64); ok && int64(number) == virtualOp {
+			return entry, nil
+		}
+	}
+	return nil, fmt.Errorf("virtual operation %d not found in block %d", virtualOp, blockNum)
+}
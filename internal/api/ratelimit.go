@@ -0,0 +1,132 @@
+package api
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"math"
+	"net/http"
+	stdsync "sync"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimitSweepInterval is how often buckets that have refilled completely are dropped
+const rateLimitSweepInterval = time.Minute
+
+// tokenBucket holds the tokens left for one client
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// bucketLimit is the refill rate and size of a bucket
+type bucketLimit struct {
+	rps   float64
+	burst float64
+}
+
+func newBucketLimit(rps float64, burst int) bucketLimit {
+	if burst <= 0 {
+		burst = int(math.Max(1, math.Ceil(2*rps)))
+	}
+	return bucketLimit{rps: rps, burst: float64(burst)}
+}
+
+// rateLimiter keeps a token bucket per client IP and API key
+type rateLimiter struct {
+	ipLimit bucketLimit
+	keys    []models.APIKeyConfig
+
+	mu        stdsync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// allow takes a token from the client's bucket, or reports how long to wait for the next one
+func (l *rateLimiter) allow(client string, limit bucketLimit, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) > rateLimitSweepInterval {
+		l.sweep(now)
+	}
+
+	bucket, ok := l.buckets[client]
+	if !ok {
+		bucket = &tokenBucket{tokens: limit.burst, last: now}
+		l.buckets[client] = bucket
+	}
+	bucket.tokens = math.Min(limit.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*limit.rps)
+	bucket.last = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - bucket.tokens) / limit.rps * float64(time.Second))
+	return false, wait
+}
+
+// sweep drops buckets of clients idle for ten minutes, which have normally refilled by then
+func (l *rateLimiter) sweep(now time.Time) {
+	for client, bucket := range l.buckets {
+		if now.Sub(bucket.last) > 10*rateLimitSweepInterval {
+			delete(l.buckets, client)
+		}
+	}
+	l.lastSweep = now
+}
+
+// key returns the configured API key matching provided
+func (l *rateLimiter) key(provided string) (models.APIKeyConfig, bool) {
+	for _, key := range l.keys {
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(key.Key)) == 1 {
+			return key, true
+		}
+	}
+	return models.APIKeyConfig{}, false
+}
+
+// rateLimit limits requests per client IP, or per API key for clients sending X-API-Key
+// Clients over their limit get 429 with a Retry-After header. The client IP is taken from
+// X-Forwarded-For only with trustProxies, and then only as far as the router trusts the proxies.
+func rateLimit(config models.RateLimitConfig, trustProxies bool) gin.HandlerFunc {
+	limiter := &rateLimiter{
+		ipLimit: newBucketLimit(config.RPS, config.Burst),
+		keys:    config.Keys,
+		buckets: make(map[string]*tokenBucket),
+	}
+
+	return func(c *gin.Context) {
+		client := "ip:" + c.RemoteIP()
+		if trustProxies {
+			client = "ip:" + c.ClientIP()
+		}
+		limit := limiter.ipLimit
+
+		if provided := c.GetHeader("X-API-Key"); provided != "" {
+			key, ok := limiter.key(provided)
+			if !ok {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid API key"})
+				return
+			}
+			client = "key:" + key.Key
+			limit = newBucketLimit(key.RPS, key.Burst)
+		}
+
+		if limit.rps <= 0 {
+			c.Next()
+			return
+		}
+
+		ok, wait := limiter.allow(client, limit, time.Now())
+		if !ok {
+			c.Header("Retry-After", fmt.Sprintf("%d", int(math.Ceil(wait.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+			return
+		}
+		c.Next()
+	}
+}
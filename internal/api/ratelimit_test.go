@@ -0,0 +1,123 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// newTestRouter builds the API router without storage; /schemas doesn't need any
+func newTestRouter(api models.APIConfig) *gin.Engine {
+	return SetupRoutes(NewHandler(nil, &models.Config{API: api}, nil))
+}
+
+// get sends a request to /api/v1/schemas from remoteAddr with the given X-Forwarded-For
+func get(router *gin.Engine, remoteAddr, forwardedFor string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/schemas", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// rateLimitRequest is a request from remote with an X-Forwarded-For header and its expected status
+type rateLimitRequest struct {
+	remote, forwarded string
+	status            int
+}
+
+func TestRateLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		proxies  []string
+		requests []rateLimitRequest
+	}{
+		{
+			name: "burst then 429",
+			requests: []rateLimitRequest{
+				{"192.0.2.1:1000", "", http.StatusOK},
+				{"192.0.2.1:1001", "", http.StatusOK},
+				{"192.0.2.1:1002", "", http.StatusTooManyRequests},
+				{"192.0.2.2:1000", "", http.StatusOK},
+			},
+		},
+		{
+			name: "spoofed X-Forwarded-For gets no new bucket",
+			requests: []rateLimitRequest{
+				{"192.0.2.1:1000", "198.51.100.1", http.StatusOK},
+				{"192.0.2.1:1000", "198.51.100.2", http.StatusOK},
+				{"192.0.2.1:1000", "198.51.100.3", http.StatusTooManyRequests},
+			},
+		},
+		{
+			name:    "X-Forwarded-For from a trusted proxy",
+			proxies: []string{"10.0.0.0/8"},
+			requests: []rateLimitRequest{
+				{"10.0.0.5:1000", "198.51.100.1", http.StatusOK},
+				{"10.0.0.5:1000", "198.51.100.1", http.StatusOK},
+				{"10.0.0.5:1000", "198.51.100.1", http.StatusTooManyRequests},
+				{"10.0.0.5:1000", "198.51.100.2", http.StatusOK},
+				// Not a trusted proxy: its header is ignored
+				{"192.0.2.1:1000", "198.51.100.3", http.StatusOK},
+				{"192.0.2.1:1000", "198.51.100.4", http.StatusOK},
+				{"192.0.2.1:1000", "198.51.100.5", http.StatusTooManyRequests},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(models.APIConfig{
+				RateLimit:      models.RateLimitConfig{RPS: 0.001, Burst: 2},
+				TrustedProxies: tt.proxies,
+			})
+			for i, r := range tt.requests {
+				w := get(router, r.remote, r.forwarded)
+				if w.Code != r.status {
+					t.Fatalf("request %d from %s (X-Forwarded-For %q): status %d, want %d", i, r.remote, r.forwarded, w.Code, r.status)
+				}
+				if w.Code == http.StatusTooManyRequests && w.Header().Get("Retry-After") == "" {
+					t.Errorf("request %d: 429 without Retry-After", i)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiterRefill(t *testing.T) {
+	limiter := &rateLimiter{buckets: make(map[string]*tokenBucket)}
+	limit := newBucketLimit(2, 2)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		after time.Duration
+		want  bool
+		wait  time.Duration // Expected wait when refused
+	}{
+		{name: "first of the burst", want: true},
+		{name: "second of the burst", want: true},
+		{name: "burst used up", want: false, wait: 500 * time.Millisecond},
+		{name: "half a token later", after: 250 * time.Millisecond, want: false, wait: 250 * time.Millisecond},
+		{name: "one token later", after: 500 * time.Millisecond, want: true},
+		{name: "refilled to the burst only", after: time.Hour, want: true},
+		{name: "second after refill", after: time.Hour, want: true},
+		{name: "third after refill", after: time.Hour, want: false, wait: 500 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		ok, wait := limiter.allow("ip:192.0.2.1", limit, start.Add(tt.after))
+		if ok != tt.want || (!ok && wait != tt.wait) {
+			t.Errorf("%s: allow = %v (wait %v), want %v (wait %v)", tt.name, ok, wait, tt.want, tt.wait)
+		}
+	}
+}
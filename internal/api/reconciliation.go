@@ -0,0 +1,91 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/reconcile"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultReconciliationDays is the range checked when no dates are given
+const defaultReconciliationDays = 7
+
+// GetProposalReconciliation handles GET /api/v1/reconciliation/proposals
+// Optional from and to are inclusive UTC dates (YYYY-MM-DD); the default is the last 7 complete days
+func (h *Handler) GetProposalReconciliation(c *gin.Context) {
+	from, to, ok := dateRange(c, defaultReconciliationDays)
+	if !ok {
+		return
+	}
+
+	report, err := reconcile.Proposals(c.Request.Context(), h.storage, h.config.Reconciliation, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetRunway handles GET /api/v1/sps/runway
+func (h *Handler) GetRunway(c *gin.Context) {
+	runway, err := reconcile.Runway(c.Request.Context(), h.storage, h.config.Reconciliation, time.Now().UTC())
+	if errors.Is(err, reconcile.ErrNoBalanceSnapshot) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, runway)
+}
+
+// defaultFlowDays is the range of the flows endpoint when no dates are given
+const defaultFlowDays = 30
+
+// GetFlows handles GET /api/v1/sps/flows
+// Optional from and to are inclusive UTC dates (YYYY-MM-DD); the default is the last 30 complete days
+func (h *Handler) GetFlows(c *gin.Context) {
+	from, to, ok := dateRange(c, defaultFlowDays)
+	if !ok {
+		return
+	}
+
+	flows, err := reconcile.Flows(c.Request.Context(), h.storage, h.config.Reconciliation, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, flows)
+}
+
+// dateRange parses the inclusive from and to dates of a request into [from, to)
+// It ends the range at today (exclusive) and spans defaultDays when the dates are missing,
+// and writes a 400 response and returns false when they are invalid
+func dateRange(c *gin.Context, defaultDays int) (time.Time, time.Time, bool) {
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to: " + err.Error()})
+			return time.Time{}, time.Time{}, false
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+	from := to.AddDate(0, 0, -defaultDays)
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from: " + err.Error()})
+			return time.Time{}, time.Time{}, false
+		}
+		from = parsed
+	}
+	if !to.After(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
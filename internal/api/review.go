@@ -0,0 +1,50 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// ListReview handles GET /api/v1/admin/review
+// Lists operations of unknown types matched by steem.fallback_extraction in review mode
+func (h *Handler) ListReview(c *gin.Context) {
+	page, pageSize := h.parsePagination(c)
+
+	result, err := h.storage.GetOperationsForReview(c.Request.Context(), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ConfirmReview handles POST /api/v1/admin/review/:id/confirm
+// The operation is kept as a regular match; it is not notified after the fact
+func (h *Handler) ConfirmReview(c *gin.Context) {
+	h.resolveReview(c, h.storage.ConfirmOperation)
+}
+
+// RejectReview handles DELETE /api/v1/admin/review/:id
+func (h *Handler) RejectReview(c *gin.Context) {
+	h.resolveReview(c, h.storage.RejectOperation)
+}
+
+// resolveReview applies a review decision to the operation in the :id parameter
+func (h *Handler) resolveReview(c *gin.Context, resolve func(ctx context.Context, id string) error) {
+	err := resolve(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, storage.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no operation waiting for review has this ID"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -1,18 +1,32 @@
 package api
 
 import (
+	"github.com/ety001/sps-fund-watcher/internal/reporting"
 	"github.com/gin-gonic/gin"
 )
 
 // SetupRoutes sets up all API routes
-func SetupRoutes(handler *Handler) *gin.Engine {
+// chains are the handlers of the chains in the chains section, served under /api/v1/chains/<name>
+// next to the chain of handler, which is also served directly under /api/v1
+func SetupRoutes(handler *Handler, chains ...*Handler) *gin.Engine {
 	router := gin.Default()
 
+	// X-Forwarded-For only names the client when it comes from one of api.trusted_proxies
+	// The entries were validated with the config; should that fail, gin trusts no proxy
+	_ = router.SetTrustedProxies(handler.config.API.TrustedProxies)
+
+	// Report panics before gin's recovery middleware turns them into 500 responses
+	router.Use(func(c *gin.Context) {
+		defer reporting.Recover()
+		c.Next()
+	})
+
 	// CORS middleware
 	router.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "X-Timezone")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-API-Key, Last-Event-ID")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -23,16 +37,96 @@ func SetupRoutes(handler *Handler) *gin.Engine {
 		c.Next()
 	})
 
+	// Local times next to the UTC timestamps of JSON responses
+	router.Use(localTimes(handler.config.API.Timezone))
+
 	// API v1 routes
-	v1 := router.Group("/api/v1")
+	// Mounted under api.base_path, e.g. /watcher/api/v1
+	prefix := handler.config.API.RoutePrefix()
+	v1 := router.Group(prefix)
+	if limits := handler.config.API.RateLimit; limits.RPS > 0 || len(limits.Keys) > 0 {
+		v1.Use(rateLimit(limits, len(handler.config.API.TrustedProxies) > 0))
+	}
+	{
+		v1.GET("/schemas", handler.ListSchemas)
+		v1.GET("/schemas/:name", handler.GetSchema)
+		v1.GET("/openapi.json", handler.openAPIHandler(router))
+		v1.GET("/docs", handler.SwaggerUI)
+		registerPublicRoutes(v1, handler)
+	}
+
+	// Every chain under its name when several are watched, the one in steem included
+	if len(chains) > 0 {
+		handlers := append([]*Handler{handler}, chains...)
+		v1.GET("/chains", listChains(prefix, handlers))
+		for _, h := range handlers {
+			registerPublicRoutes(v1.Group("/chains/"+h.config.Steem.Chain), h)
+		}
+	}
+
+	// Public status page, outside /api/v1 so a proxy can expose it alone
+	// Rendered pages are cached, so it needs no rate limit
+	if handler.config.API.StatusPage.Enabled {
+		router.GET(handler.config.API.StatusPagePath(), handler.StatusPage)
+	}
+
+	// Admin routes (require api.admin_token)
+	admin := router.Group(prefix+"/admin", requireAdmin(handler.config.API.AdminToken))
 	{
-		v1.GET("/health", handler.Health)
-		v1.GET("/accounts", handler.GetAccounts)
-		v1.GET("/accounts/:account/operations", handler.GetOperations)
-		v1.GET("/accounts/:account/transfers", handler.GetTransfers)
-		v1.GET("/accounts/:account/updates", handler.GetUpdates)
+		admin.GET("/state", handler.GetControlState)
+		admin.POST("/pause", handler.Pause)
+		admin.POST("/resume", handler.Resume)
+		admin.GET("/storage", handler.GetStorageReport)
+		admin.GET("/indexes", handler.GetIndexReport)
+		admin.GET("/webhooks", handler.ListWebhooks)
+		admin.POST("/webhooks", handler.SaveWebhook)
+		admin.GET("/webhooks/dead-letters", handler.GetWebhookDeadLetters)
+		admin.DELETE("/webhooks/:name", handler.DeleteWebhook)
+		admin.GET("/views", handler.ListViews)
+		admin.POST("/views", handler.SaveView)
+		admin.DELETE("/views/:name", handler.DeleteView)
+		admin.GET("/accounts/metadata", handler.ListAccountMetadata)
+		admin.PUT("/accounts/:account/metadata", handler.SaveAccountMetadata)
+		admin.DELETE("/accounts/:account/metadata", handler.DeleteAccountMetadata)
+		admin.GET("/review", handler.ListReview)
+		admin.POST("/review/:id/confirm", handler.ConfirmReview)
+		admin.DELETE("/review/:id", handler.RejectReview)
+		admin.GET("/notifications/pending", handler.ListPendingNotifications)
+		admin.POST("/notifications/resend", handler.ResendPendingNotifications)
+		admin.DELETE("/notifications/pending/:id", handler.DeletePendingNotification)
+		admin.GET("/notifications/dead-letters", handler.GetNotificationDeadLetters)
+		admin.POST("/templates/render", handler.RenderTemplate)
 	}
 
 	return router
 }
 
+// registerPublicRoutes registers the routes serving a chain's data on group
+func registerPublicRoutes(group *gin.RouterGroup, h *Handler) {
+	group.GET("/health", h.Health)
+	group.GET("/health/ready", h.Ready)
+	group.GET("/status", h.GetStatus)
+	group.GET("/search", h.Search)
+	group.GET("/accounts", h.GetAccounts)
+	group.GET("/accounts/:account/operations", h.GetOperations)
+	group.GET("/accounts/:account/transfers", h.GetTransfers)
+	group.GET("/accounts/:account/transfers/summary", h.GetTransferSummary)
+	group.GET("/accounts/:account/updates", h.GetUpdates)
+	group.GET("/accounts/:account/mentions", h.GetMentions)
+	group.GET("/accounts/:account/aggregates", h.GetAggregates)
+	group.GET("/accounts/:account/events", h.StreamAccountEvents)
+	group.GET("/accounts/:account/fund-events", h.GetAccountFundEvents)
+	group.GET("/operations", h.GetMergedOperations)
+	group.GET("/events", h.GetEvents)
+	group.GET("/fund-events", h.GetFundEvents)
+	group.GET("/operations/:id/proof", h.GetOperationProof)
+	group.GET("/blocks/:block_num/operations", h.GetBlockOperations)
+	group.GET("/reconciliation/proposals", h.GetProposalReconciliation)
+	group.GET("/sps/runway", h.GetRunway)
+	group.GET("/sps/flows", h.GetFlows)
+	group.GET("/sps/donors", h.GetDonors)
+	group.GET("/stats", h.GetStats)
+	group.GET("/views", h.ListViews)
+	group.GET("/views/:name", h.GetView)
+	group.GET("/views/:name/rss", h.GetViewFeed)
+}
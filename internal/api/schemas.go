@@ -0,0 +1,195 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/version"
+	"github.com/gin-gonic/gin"
+)
+
+// jsonSchemaDialect is the JSON Schema version of the published schemas
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// StatusResponse is the payload of GET /api/v1/status
+type StatusResponse struct {
+	Version version.Info      `json:"version"`
+	Sync    *models.SyncState `json:"sync"`
+	Leader  *models.Lease     `json:"leader,omitempty"` // Sync lease, when leader election is used
+
+	Instance  models.InstanceLabels `json:"instance"`             // Labels of the API instance answering
+	ChainName string                `json:"chain_name,omitempty"` // Label of the chain when several are watched
+
+	// Chain is the node's view of the chain; ChainError is set instead when the node could not be reached
+	Chain      *ChainStatus `json:"chain,omitempty"`
+	ChainError string       `json:"chain_error,omitempty"`
+}
+
+// ChainStatus is the chain position reported by the node and how far the sync is behind it
+type ChainStatus struct {
+	HeadBlock             int64 `json:"head_block"`
+	LastIrreversibleBlock int64 `json:"last_irreversible_block"`
+	Lag                   int64 `json:"lag"` // Blocks behind the head block in head sync mode, else behind the last irreversible block
+}
+
+// publishedSchemas maps schema names to the Go types of API payloads
+var publishedSchemas = map[string]reflect.Type{
+	"operation":               reflect.TypeOf(models.Operation{}),
+	"operation_response":      reflect.TypeOf(models.OperationResponse{}),
+	"merged_operations":       reflect.TypeOf(models.MergedOperationResponse{}),
+	"events":                  reflect.TypeOf(models.EventResponse{}),
+	"fund_events":             reflect.TypeOf(models.FundEventResponse{}),
+	"status":                  reflect.TypeOf(StatusResponse{}),
+	"readiness":               reflect.TypeOf(ReadinessResponse{}),
+	"sync_state":              reflect.TypeOf(models.SyncState{}),
+	"control_state":           reflect.TypeOf(models.ControlState{}),
+	"lease":                   reflect.TypeOf(models.Lease{}),
+	"proposal_reconciliation": reflect.TypeOf(models.ProposalReconciliation{}),
+	"runway_projection":       reflect.TypeOf(models.RunwayProjection{}),
+	"treasury_flows":          reflect.TypeOf(models.TreasuryFlows{}),
+	"transfer_summary":        reflect.TypeOf(models.TransferSummary{}),
+	"donor_leaderboard":       reflect.TypeOf(models.DonorLeaderboard{}),
+	"operation_stats":         reflect.TypeOf(models.OperationStats{}),
+	"account_metadata":        reflect.TypeOf(models.AccountMetadata{}),
+	"saved_view":              reflect.TypeOf(models.SavedView{}),
+	"operation_proof":         reflect.TypeOf(OperationProof{}),
+	"block_operations":        reflect.TypeOf(BlockOperations{}),
+	"storage_report":          reflect.TypeOf(models.StorageReport{}),
+	"index_report":            reflect.TypeOf(models.IndexReport{}),
+	"template_render":         reflect.TypeOf(TemplateRenderResponse{}),
+}
+
+// ListSchemas handles GET /api/v1/schemas
+func (h *Handler) ListSchemas(c *gin.Context) {
+	names := make([]string, 0, len(publishedSchemas))
+	for name := range publishedSchemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	schemas := make([]gin.H, 0, len(names))
+	for _, name := range names {
+		schemas = append(schemas, gin.H{"name": name, "url": h.linkPath(c, "/schemas/"+name)})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"schemas": schemas})
+}
+
+// GetSchema handles GET /api/v1/schemas/:name
+func (h *Handler) GetSchema(c *gin.Context) {
+	name := c.Param("name")
+	t, ok := publishedSchemas[name]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown schema: " + name})
+		return
+	}
+
+	schema := jsonSchema(t)
+	schema["$schema"] = jsonSchemaDialect
+	schema["$id"] = h.linkPath(c, "/schemas/"+name)
+	schema["title"] = t.Name()
+
+	c.JSON(http.StatusOK, schema)
+}
+
+// jsonSchema builds a JSON Schema for a Go type based on its encoding/json representation
+func jsonSchema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": jsonSchema(t.Elem())}
+	case reflect.Map:
+		schema := map[string]interface{}{"type": "object"}
+		if t.Elem().Kind() != reflect.Interface {
+			schema["additionalProperties"] = jsonSchema(t.Elem())
+		}
+		return schema
+	case reflect.Struct:
+		return structSchema(t)
+	default:
+		// interface{} and anything else: any JSON value
+		return map[string]interface{}{}
+	}
+}
+
+// structSchema builds an object schema from exported struct fields and their json tags
+func structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		// Fields of embedded structs without a json name are promoted, as encoding/json does
+		tag, tagged := field.Tag.Lookup("json")
+		if embedded := field.Type; field.Anonymous && strings.Split(tag, ",")[0] == "" {
+			for embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				promoted := structSchema(embedded)
+				for name, property := range promoted["properties"].(map[string]interface{}) {
+					properties[name] = property
+				}
+				if names, ok := promoted["required"].([]string); ok {
+					required = append(required, names...)
+				}
+				continue
+			}
+		}
+
+		name := field.Name
+		omitEmpty := false
+		if tagged {
+			parts := strings.Split(tag, ",")
+			if parts[0] == "-" {
+				continue
+			}
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			for _, opt := range parts[1:] {
+				if opt == "omitempty" {
+					omitEmpty = true
+				}
+			}
+		}
+
+		properties[name] = jsonSchema(field.Type)
+		if !omitEmpty {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	querydsl "github.com/ety001/sps-fund-watcher/internal/query"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// Search handles GET /api/v1/search
+// Query params: memo, counterparty, min_amount, max_amount, symbol, type (comma-separated),
+// account, from/to (RFC3339 or YYYY-MM-DD), page, page_size; at least one criterion is required
+func (h *Handler) Search(c *gin.Context) {
+	search := querydsl.Search{
+		Memo:         strings.TrimSpace(c.Query("memo")),
+		Counterparty: c.Query("counterparty"),
+		Symbol:       strings.ToUpper(c.Query("symbol")),
+	}
+	if search.Counterparty != "" {
+		if err := models.ValidateAccountName(search.Counterparty); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid counterparty: " + err.Error()})
+			return
+		}
+	}
+	search.OpTypes = queryList(c, "type")
+	for param, target := range map[string]**float64{"min_amount": &search.MinAmount, "max_amount": &search.MaxAmount} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		amount, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param + ": " + err.Error()})
+			return
+		}
+		*target = &amount
+	}
+	for param, target := range map[string]*time.Time{"from": &search.From, "to": &search.To} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := parseTime(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param + ": " + err.Error()})
+			return
+		}
+		*target = parsed
+	}
+
+	account := c.Query("account")
+	if search.Empty() && account == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "at least one search parameter is required"})
+		return
+	}
+	filter, err := search.Filter()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, pageSize := h.parsePagination(c)
+	result, err := h.storage.QueryOperations(c.Request.Context(), storage.OperationQuery{Account: account, Filter: filter}, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Stats window limits
+const (
+	defaultStatsDays = 30
+	maxStatsDays     = 366
+)
+
+// GetStats handles GET /api/v1/stats
+// Counts stored operations per type, account and UTC day. from and to (RFC3339 or YYYY-MM-DD,
+// to exclusive) default to the last 30 days; account and type narrow the selection
+func (h *Handler) GetStats(c *gin.Context) {
+	to := time.Now().UTC()
+	if value := c.Query("to"); value != "" {
+		parsed, err := parseTime(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to: " + err.Error()})
+			return
+		}
+		to = parsed
+	}
+	from := to.AddDate(0, 0, -defaultStatsDays)
+	if value := c.Query("from"); value != "" {
+		parsed, err := parseTime(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from: " + err.Error()})
+			return
+		}
+		from = parsed
+	}
+	if !to.After(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+		return
+	}
+	if to.Sub(from) > maxStatsDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "window must not exceed 366 days"})
+		return
+	}
+
+	stats, err := h.storage.OperationStats(c.Request.Context(), c.Query("account"), c.Query("type"), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
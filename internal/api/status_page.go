@@ -0,0 +1,218 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	stdsync "sync"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+	"github.com/ety001/sps-fund-watcher/internal/version"
+	"github.com/gin-gonic/gin"
+)
+
+// statusPageTTL is how long a rendered status page is served before it is rebuilt,
+// so public traffic doesn't translate into MongoDB and node load
+const statusPageTTL = 30 * time.Second
+
+// fundMovementTypes are the operations listed as fund movements on the status page
+var fundMovementTypes = []string{"transfer", "transfer_to_savings", "transfer_from_savings", "transfer_to_vesting", "proposal_pay"}
+
+// statusPageCache holds the last rendered pages, full and embedded
+type statusPageCache struct {
+	mu    stdsync.Mutex
+	pages map[bool]cachedPage
+}
+
+type cachedPage struct {
+	body     []byte
+	rendered time.Time
+}
+
+// statusPageData is what the status page template shows
+type statusPageData struct {
+	Title     string
+	Embed     bool
+	State     string // Operational, Paused, Degraded or Down
+	StateNote string
+	Sync      SyncHealth
+	LastSync  string
+	Uptime    string
+	Started   string
+	Accounts  []string
+	Movements []statusMovement
+	Updated   string
+	Version   string
+}
+
+type statusMovement struct {
+	Time   string
+	Type   string
+	From   string
+	To     string
+	Amount string
+}
+
+// StatusPage handles GET /status
+// A self-contained HTML summary of sync health, tracked accounts and the latest fund movements,
+// meant for embedding (embed=1 drops the page chrome) without exposing the API itself
+func (h *Handler) StatusPage(c *gin.Context) {
+	embed := c.Query("embed") == "1" || c.Query("embed") == "true"
+
+	h.statusPage.mu.Lock()
+	defer h.statusPage.mu.Unlock()
+	page, ok := h.statusPage.pages[embed]
+	if !ok || time.Since(page.rendered) > statusPageTTL {
+		body, err := h.renderStatusPage(c.Request.Context(), embed)
+		if err != nil {
+			c.Data(http.StatusInternalServerError, "text/plain; charset=utf-8", []byte("status page unavailable"))
+			return
+		}
+		page = cachedPage{body: body, rendered: time.Now()}
+		if h.statusPage.pages == nil {
+			h.statusPage.pages = make(map[bool]cachedPage)
+		}
+		h.statusPage.pages[embed] = page
+	}
+
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(statusPageTTL/time.Second)))
+	c.Data(http.StatusOK, "text/html; charset=utf-8", page.body)
+}
+
+// renderStatusPage collects the status and renders the page
+// Failing components are shown on the page rather than failing the request
+func (h *Handler) renderStatusPage(ctx context.Context, embed bool) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	config := h.config.API.StatusPage
+	data := statusPageData{
+		Title:   config.Title,
+		Embed:   embed,
+		Uptime:  formatAge(time.Since(h.started)),
+		Started: h.started.UTC().Format("2006-01-02 15:04 UTC"),
+		Updated: time.Now().UTC().Format("2006-01-02 15:04:05 UTC"),
+		Version: version.Get().Version,
+	}
+	if data.Title == "" {
+		data.Title = models.DefaultStatusPageTitle
+	}
+
+	data.Accounts = append([]string{}, h.config.Steem.Accounts...)
+	sort.Strings(data.Accounts)
+
+	mongoErr := h.storage.Ping(ctx)
+	dgp, chainErr := h.chain.GetDynamicGlobalProperties()
+	if chainErr != nil {
+		dgp = nil
+	}
+	data.Sync = h.syncHealth(ctx, mongoErr, dgp)
+	data.LastSync = formatAge(time.Duration(data.Sync.SinceUpdateSeconds) * time.Second)
+
+	switch {
+	case mongoErr != nil:
+		data.State, data.StateNote = "Down", "The database is unreachable."
+	case data.Sync.Status == healthPaused:
+		data.State, data.StateNote = "Paused", "Syncing is paused by the operator."
+	case data.Sync.Status == healthFail:
+		data.State, data.StateNote = "Degraded", data.Sync.Error
+	case chainErr != nil:
+		data.State, data.StateNote = "Degraded", "The Steem node is unreachable."
+	default:
+		data.State = "Operational"
+	}
+
+	if mongoErr == nil {
+		query := storage.OperationQuery{Accounts: h.config.Steem.Accounts, OpTypes: fundMovementTypes}
+		if result, err := h.storage.QueryMergedOperations(ctx, query, 1, config.MovementCount()); err == nil {
+			for _, op := range result.Operations {
+				from, to := models.OperationParties(op.OpData)
+				movement := statusMovement{
+					Time: op.Timestamp.UTC().Format("2006-01-02 15:04"),
+					Type: op.OpType,
+					From: from,
+					To:   to,
+				}
+				if asset, ok := models.OperationAmount(op.OpData); ok {
+					movement.Amount = asset.String()
+				}
+				data.Movements = append(data.Movements, movement)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := statusPageTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// formatAge renders a duration coarsely, e.g. "3d 4h", "12m" or "40s"
+func formatAge(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd %dh", int(d/(24*time.Hour)), int(d%(24*time.Hour)/time.Hour))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh %dm", int(d/time.Hour), int(d%time.Hour/time.Minute))
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	default:
+		return fmt.Sprintf("%ds", int(d/time.Second))
+	}
+}
+
+// statusPageTemplate is self-contained (inline styles, no scripts) so it can be embedded anywhere
+var statusPageTemplate = template.Must(template.New("status").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <meta http-equiv="refresh" content="60">
+  <title>{{.Title}} status</title>
+  <style>
+    body { font-family: -apple-system, "Segoe UI", Roboto, sans-serif; color: #222; margin: 0; padding: {{if .Embed}}8px{{else}}24px{{end}}; background: {{if .Embed}}transparent{{else}}#f6f7f9{{end}}; }
+    main { max-width: 860px; margin: 0 auto; }
+    h1 { font-size: 1.4em; margin: 0 0 12px; }
+    h2 { font-size: 1.05em; margin: 20px 0 8px; }
+    .state { display: inline-block; padding: 4px 10px; border-radius: 4px; color: #fff; font-weight: 600; }
+    .Operational { background: #2e7d32; } .Paused { background: #757575; } .Degraded { background: #ef6c00; } .Down { background: #c62828; }
+    table { border-collapse: collapse; width: 100%; font-size: 0.9em; background: #fff; }
+    th, td { text-align: left; padding: 4px 8px; border-bottom: 1px solid #e3e3e3; }
+    .muted { color: #777; font-size: 0.85em; }
+    .accounts span { display: inline-block; margin: 0 6px 4px 0; padding: 2px 6px; background: #e8eaf0; border-radius: 3px; font-size: 0.85em; }
+  </style>
+</head>
+<body>
+<main>
+  {{if not .Embed}}<h1>{{.Title}}</h1>{{end}}
+  <p><span class="state {{.State}}">{{.State}}</span> {{.StateNote}}</p>
+  <table>
+    <tr><th>Last synced block</th><td>{{.Sync.LastBlock}}</td></tr>
+    <tr><th>Blocks behind</th><td>{{if .Sync.Lag}}{{.Sync.Lag}}{{else}}unknown{{end}}</td></tr>
+    <tr><th>Last sync update</th><td>{{.LastSync}} ago</td></tr>
+    <tr><th>Uptime</th><td>{{.Uptime}} (since {{.Started}})</td></tr>
+  </table>
+
+  <h2>Tracked accounts</h2>
+  <div class="accounts">{{range .Accounts}}<span>{{.}}</span>{{else}}<span>none</span>{{end}}</div>
+
+  <h2>Latest fund movements</h2>
+  {{if .Movements}}
+  <table>
+    <tr><th>Time (UTC)</th><th>Type</th><th>From</th><th>To</th><th>Amount</th></tr>
+    {{range .Movements}}<tr><td>{{.Time}}</td><td>{{.Type}}</td><td>{{.From}}</td><td>{{.To}}</td><td>{{.Amount}}</td></tr>
+    {{end}}
+  </table>
+  {{else}}<p class="muted">No fund movements recorded yet.</p>{{end}}
+
+  <p class="muted">Updated {{.Updated}}{{if not .Embed}} · sps-fund-watcher {{.Version}}{{end}}</p>
+</main>
+</body>
+</html>
+`))
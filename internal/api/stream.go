@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// ndjsonContentType is requested through the Accept header to stream operations instead of paging them
+const ndjsonContentType = "application/x-ndjson"
+
+// streamFlushEvery is how many operations are written between flushes of the response
+const streamFlushEvery = 100
+
+// streamStatusTrailer is the HTTP trailer telling how an operation stream ended
+const streamStatusTrailer = "X-Stream-Status"
+
+// Values of the X-Stream-Status trailer
+const (
+	streamComplete  = "complete"
+	streamTruncated = "truncated" // api.stream.max_operations was reached
+	streamTimeout   = "timeout"   // api.stream.timeout_seconds was reached
+	streamFailed    = "error"
+)
+
+var errStreamLimit = errors.New("stream limit reached")
+
+// acceptsNDJSON reports whether the client asked for an NDJSON stream
+func acceptsNDJSON(c *gin.Context) bool {
+	return strings.Contains(c.GetHeader("Accept"), ndjsonContentType)
+}
+
+// streamOperations writes the operations matching a query as one JSON document per line, in
+// block order, from a single cursor instead of pages
+// api.stream caps the operations and the duration of a stream; the X-Stream-Status trailer tells
+// whether it is complete, so a client can continue a truncated stream with a later from
+func (h *Handler) streamOperations(c *gin.Context, query storage.OperationQuery) {
+	limit := h.config.API.Stream.Limit()
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.config.API.Stream.Timeout())
+	defer cancel()
+
+	// One operation past the cap is read to tell a truncated stream from a complete one
+	query.Limit = int64(limit) + 1
+
+	c.Header("Content-Type", ndjsonContentType)
+	c.Header("Trailer", streamStatusTrailer)
+	c.Header("X-Stream-Limit", strconv.Itoa(limit))
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	count := 0
+	err := h.storage.StreamOperations(ctx, query, func(op *models.Operation) error {
+		if count == limit {
+			return errStreamLimit
+		}
+		count++
+		if err := encoder.Encode(op); err != nil {
+			return fmt.Errorf("failed to encode operation: %w", err)
+		}
+		if count%streamFlushEvery == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	})
+
+	status := streamComplete
+	switch {
+	case err == nil:
+	case errors.Is(err, errStreamLimit):
+		status = streamTruncated
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		status = streamTimeout
+	default:
+		// The status line is already sent; record the error and end the stream
+		status = streamFailed
+		_ = c.Error(fmt.Errorf("operation stream failed: %w", err))
+	}
+	c.Writer.Header().Set(streamStatusTrailer, status)
+}
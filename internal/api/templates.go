@@ -0,0 +1,65 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/telegram"
+	"github.com/gin-gonic/gin"
+)
+
+// TemplateRenderRequest is a message template and the operation to render it with
+type TemplateRenderRequest struct {
+	// Template uses the variables of telegram.message_template; empty renders the default message
+	Template string `json:"template"`
+	// ParseMode is "HTML" or "MarkdownV2" (default: telegram.parse_mode)
+	ParseMode string           `json:"parse_mode,omitempty"`
+	Operation models.Operation `json:"operation"`
+}
+
+// TemplateRenderResponse is a rendered template
+// Errors lists problems that would make the configuration invalid; the output is rendered regardless
+type TemplateRenderResponse struct {
+	Rendered  string   `json:"rendered"`
+	ParseMode string   `json:"parse_mode"`
+	Errors    []string `json:"errors"`
+}
+
+// RenderTemplate handles POST /api/v1/admin/templates/render
+// It renders a template exactly as the sync service would, without sending anything
+func (h *Handler) RenderTemplate(c *gin.Context) {
+	var req TemplateRenderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	parseMode := req.ParseMode
+	if parseMode == "" {
+		parseMode = h.config.Telegram.ParseMode
+	}
+	if parseMode != "" && !strings.EqualFold(parseMode, telegram.ParseModeHTML) && !strings.EqualFold(parseMode, telegram.ParseModeMarkdownV2) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "parse_mode must be HTML or MarkdownV2"})
+		return
+	}
+	formatter := telegram.NewFormatter(parseMode)
+
+	op := req.Operation
+	if op.Timestamp.IsZero() {
+		op.Timestamp = time.Now().UTC()
+	}
+
+	response := TemplateRenderResponse{ParseMode: formatter.ParseMode(), Errors: models.TemplateProblems(req.Template)}
+	if response.Errors == nil {
+		response.Errors = []string{}
+	}
+	if req.Template == "" {
+		response.Rendered = formatter.OperationMessage(op.Account, op.OpType, op.OpData, op.BlockNum, op.Timestamp)
+	} else {
+		response.Rendered = formatter.OperationMessageWithTemplate(req.Template, op.Account, op.OpType, op.OpData, op.BlockNum, op.Timestamp)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// localTimeSuffix names the field added next to each timestamp, e.g. timestamp_local
+const localTimeSuffix = "_local"
+
+// timestampFields are the JSON names of the time fields of API payloads; only these get a local
+// time, so strings that merely look like times are left alone
+var timestampFields = collectTimestampFields()
+
+// adminPayloads are the admin API payloads not in publishedSchemas
+var adminPayloads = []reflect.Type{
+	reflect.TypeOf(models.Webhook{}),
+	reflect.TypeOf(models.WebhookDeadLetter{}),
+	reflect.TypeOf(models.PendingNotification{}),
+	reflect.TypeOf(models.NotificationDeadLetter{}),
+	reflect.TypeOf(models.CompensatorJob{}),
+	reflect.TypeOf(models.AutoTrackedAccount{}),
+	reflect.TypeOf(models.BalanceSnapshot{}),
+}
+
+// chainDataFields hold data written by accounts on chain or by plugins, which is never rewritten
+var chainDataFields = map[string]bool{"op_data": true, "enrichment": true}
+
+// collectTimestampFields lists the time.Time fields of publishedSchemas, adminPayloads and the
+// types they contain
+func collectTimestampFields() map[string]bool {
+	fields := make(map[string]bool)
+	seen := make(map[reflect.Type]bool)
+	timeType := reflect.TypeOf(time.Time{})
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct || t == timeType || seen[t] {
+			return
+		}
+		seen[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			fieldType := field.Type
+			for fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType == timeType {
+				if name == "" {
+					name = field.Name
+				}
+				fields[name] = true
+				continue
+			}
+			walk(field.Type)
+		}
+	}
+	for _, t := range publishedSchemas {
+		walk(t)
+	}
+	for _, t := range adminPayloads {
+		walk(t)
+	}
+	return fields
+}
+
+// localTimes adds the time in the zone of the tz parameter (default api.timezone) next to every
+// timestamp field of a JSON response, e.g. "timestamp_local": "2024-05-01T14:00:00+02:00"
+// Timestamps stay in UTC; streamed responses (NDJSON, server-sent events) pass through unchanged
+func localTimes(defaultZone string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name, ok := c.GetQuery("tz")
+		if !ok {
+			name = defaultZone
+		}
+		if name == "" {
+			c.Next()
+			return
+		}
+		location, err := time.LoadLocation(name)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid tz: unknown time zone " + name})
+			return
+		}
+
+		writer := &localTimeWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Header("X-Timezone", location.String())
+		c.Next()
+
+		c.Writer = writer.ResponseWriter
+		if writer.buffered {
+			c.Writer.Write(addLocalTimes(writer.body.Bytes(), location))
+		}
+	}
+}
+
+// localTimeWriter holds back JSON bodies so their timestamps can be converted once complete
+type localTimeWriter struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	decided  bool
+	buffered bool
+}
+
+func (w *localTimeWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.decided = true
+		w.buffered = strings.HasPrefix(w.Header().Get("Content-Type"), "application/json")
+	}
+	if w.buffered {
+		return w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *localTimeWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// addLocalTimes returns body with the local times added, or body itself if it isn't valid JSON
+func addLocalTimes(body []byte, location *time.Location) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return body
+	}
+	out, err := json.Marshal(withLocalTimes(value, location))
+	if err != nil {
+		return body
+	}
+	return out
+}
+
+// withLocalTimes walks a decoded JSON value and adds <key>_local next to each timestamp field
+// Chain data such as op_data is not entered
+func withLocalTimes(value interface{}, location *time.Location) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		local := make(map[string]interface{})
+		for key, item := range v {
+			if chainDataFields[key] {
+				continue
+			}
+			if s, ok := item.(string); ok {
+				if t, ok := parseTimestamp(s); ok && timestampFields[key] {
+					local[key+localTimeSuffix] = t.In(location).Format(time.RFC3339)
+				}
+				continue
+			}
+			v[key] = withLocalTimes(item, location)
+		}
+		for key, item := range local {
+			if _, taken := v[key]; !taken {
+				v[key] = item
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = withLocalTimes(item, location)
+		}
+	}
+	return value
+}
+
+// parseTimestamp recognizes the RFC 3339 times encoding/json writes for time.Time
+// Zero times (unset fields) are skipped
+func parseTimestamp(s string) (time.Time, bool) {
+	if len(s) < len("2006-01-02T15:04:05Z") || s[4] != '-' || s[10] != 'T' {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil || t.IsZero() || t.Year() <= 1 {
+		return time.Time{}, false
+	}
+	return t, true
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// ViewResponse is a saved view together with one page of its matching operations
+type ViewResponse struct {
+	View models.SavedView `json:"view"`
+	*models.OperationResponse
+}
+
+// ListViews handles GET /api/v1/views
+func (h *Handler) ListViews(c *gin.Context) {
+	views, err := h.storage.ListViews(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if views == nil {
+		views = []models.SavedView{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"views": views})
+}
+
+// GetView handles GET /api/v1/views/:name
+// Runs the saved filter and returns a page of matching operations
+func (h *Handler) GetView(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	view, err := h.storage.GetView(ctx, c.Param("name"))
+	if errors.Is(err, storage.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "view not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	query, err := storage.ViewQuery(view)
+	if err != nil {
+		// Queries are validated on save, so this only happens if the language changed underneath
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid view query: " + err.Error()})
+		return
+	}
+
+	page, pageSize := h.parsePagination(c)
+	result, err := h.storage.QueryOperations(ctx, query, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ViewResponse{View: *view, OperationResponse: result})
+}
+
+// SaveView handles POST /api/v1/admin/views
+// Creates a view or replaces an existing one with the same name
+func (h *Handler) SaveView(c *gin.Context) {
+	var view models.SavedView
+	if err := c.ShouldBindJSON(&view); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if view.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+	if _, err := storage.ViewQuery(&view); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query: " + err.Error()})
+		return
+	}
+
+	if err := h.storage.SaveView(c.Request.Context(), &view); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, view)
+}
+
+// DeleteView handles DELETE /api/v1/admin/views/:name
+func (h *Handler) DeleteView(c *gin.Context) {
+	err := h.storage.DeleteView(c.Request.Context(), c.Param("name"))
+	if errors.Is(err, storage.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "view not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// ListWebhooks handles GET /api/v1/admin/webhooks
+// Returns webhooks from the configuration and from the admin API, with secrets hidden
+func (h *Handler) ListWebhooks(c *gin.Context) {
+	registered, err := h.storage.ListWebhooks(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	hide := func(hooks []models.Webhook) []models.Webhook {
+		result := make([]models.Webhook, len(hooks))
+		for i, hook := range hooks {
+			hook.Secret = ""
+			result[i] = hook
+		}
+		return result
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"configured": hide(h.config.Webhooks),
+		"registered": hide(registered),
+	})
+}
+
+// SaveWebhook handles POST /api/v1/admin/webhooks
+// Creates a webhook or replaces an existing one with the same name
+func (h *Handler) SaveWebhook(c *gin.Context) {
+	var hook models.Webhook
+	if err := c.ShouldBindJSON(&hook); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if hook.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+	if u, err := url.Parse(hook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url must be an absolute http(s) URL"})
+		return
+	}
+
+	if err := h.storage.SaveWebhook(c.Request.Context(), &hook); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	hook.Secret = ""
+	c.JSON(http.StatusOK, hook)
+}
+
+// DeleteWebhook handles DELETE /api/v1/admin/webhooks/:name
+func (h *Handler) DeleteWebhook(c *gin.Context) {
+	err := h.storage.DeleteWebhook(c.Request.Context(), c.Param("name"))
+	if errors.Is(err, storage.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetWebhookDeadLetters handles GET /api/v1/admin/webhooks/dead-letters
+func (h *Handler) GetWebhookDeadLetters(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	deadLetters, err := h.storage.GetWebhookDeadLetters(c.Request.Context(), int64(limit))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"dead_letters": deadLetters})
+}
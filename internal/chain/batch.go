@@ -0,0 +1,147 @@
+package chain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	stdsync "sync"
+	"time"
+)
+
+// Batch requests
+const (
+	// Attempts per batch, or per call of several sent separately, before the error is returned;
+	// the caller's own retry takes over once the node keeps failing
+	batchAttempts   = 3
+	batchRetryDelay = time.Second
+)
+
+// rpcCall is one call of a batch; its result is decoded into result
+type rpcCall struct {
+	method string // Full method name, e.g. condenser_api.get_block
+	params []interface{}
+	result interface{}
+}
+
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int           `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcResponse struct {
+	ID     int             `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("RPC error %d: %s", e.Code, e.Message)
+}
+
+// batchCaller sends calls as JSON-RPC batches, i.e. one POST with an array of requests
+// Requests go through the node's HTTP client, so the node identification and budget apply to
+// each batch as one request
+type batchCaller struct {
+	url    string
+	size   int // Calls per batch
+	client *http.Client
+}
+
+func newBatchCaller(url string, size int, client *http.Client) *batchCaller {
+	return &batchCaller{url: url, size: size, client: client}
+}
+
+// call sends calls in batches of at most size calls, all batches at once, and decodes the
+// results; it returns the first error of any batch
+func (b *batchCaller) call(calls []rpcCall) error {
+	var wg stdsync.WaitGroup
+	errs := make(chan error, (len(calls)+b.size-1)/b.size)
+	for start := 0; start < len(calls); start += b.size {
+		end := min(start+b.size, len(calls))
+		wg.Add(1)
+		go func(batch []rpcCall) {
+			defer wg.Done()
+			var err error
+			for attempt := 1; attempt <= batchAttempts; attempt++ {
+				if err = b.send(batch); err == nil {
+					return
+				}
+				if attempt < batchAttempts {
+					time.Sleep(batchRetryDelay)
+				}
+			}
+			errs <- err
+		}(calls[start:end])
+	}
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
+
+// send sends one batch and decodes its results
+func (b *batchCaller) send(calls []rpcCall) error {
+	requests := make([]rpcRequest, len(calls))
+	for i, call := range calls {
+		requests[i] = rpcRequest{JSONRPC: "2.0", ID: i + 1, Method: call.method, Params: call.params}
+	}
+	body, err := json.Marshal(requests)
+	if err != nil {
+		return fmt.Errorf("failed to encode batch: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, b.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create batch request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send batch of %d calls: %w", len(calls), err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read batch response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("batch of %d calls failed with HTTP %d", len(calls), resp.StatusCode)
+	}
+
+	// Nodes without batch support answer with a single error object
+	var responses []rpcResponse
+	if err := json.Unmarshal(data, &responses); err != nil {
+		var single rpcResponse
+		if json.Unmarshal(data, &single) == nil && single.Error != nil {
+			return fmt.Errorf("node rejected the batch (set steem.rpc_batch_size to 0 if it doesn't support batches): %w", single.Error)
+		}
+		return fmt.Errorf("failed to decode batch response: %w", err)
+	}
+
+	// Responses may come in any order
+	byID := make(map[int]rpcResponse, len(responses))
+	for _, response := range responses {
+		byID[response.ID] = response
+	}
+	for i, call := range calls {
+		response, ok := byID[i+1]
+		switch {
+		case !ok:
+			return fmt.Errorf("batch response misses %s%v", call.method, call.params)
+		case response.Error != nil:
+			return fmt.Errorf("%s%v: %w", call.method, call.params, response.Error)
+		}
+		if err := json.Unmarshal(response.Result, call.result); err != nil {
+			return fmt.Errorf("failed to decode %s%v: %w", call.method, call.params, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package chain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	stdsync "sync"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/steemit/steemutil/protocol"
+	protocolapi "github.com/steemit/steemutil/protocol/api"
+)
+
+// Client is the node API used by the sync service, the API and the command line tools
+// Code depends on this interface rather than on the SDK so node backends can be swapped and
+// replaced by fakes in tests
+type Client interface {
+	GetDynamicGlobalProperties() (*protocolapi.DynamicGlobalProperties, error)
+	GetBlock(blockNum uint) (*protocolapi.Block, error)
+	// GetBlocks returns the given blocks by block number
+	GetBlocks(blockNums []uint) (map[uint]*protocolapi.Block, error)
+	// GetOpsInBlock returns the operations of one block, only the virtual ones if onlyVirtual is set
+	GetOpsInBlock(blockNum uint, onlyVirtual bool) ([]*protocol.OperationObject, error)
+	// GetOpsInBlocks returns the operations of the blocks in [from, to) by block number
+	GetOpsInBlocks(from, to uint, onlyVirtual bool) (map[uint][]*protocol.OperationObject, error)
+	// GetRawOpsInBlocks is GetOpsInBlocks returning each block's operations undecoded, as JSON arrays
+	GetRawOpsInBlocks(from, to uint, onlyVirtual bool) (map[uint]json.RawMessage, error)
+	// GetAccounts returns the existing accounts among names; unknown names are left out
+	GetAccounts(names []string) ([]Account, error)
+	// CallWithResult makes a raw JSON-RPC call and decodes the result
+	CallWithResult(apiName, method string, params []interface{}, result interface{}) error
+}
+
+// Account is the part of a get_accounts entry the watcher reads
+type Account struct {
+	Name       string `json:"name"`
+	Balance    string `json:"balance"`     // Liquid STEEM, e.g. "1.000 STEEM"
+	SBDBalance string `json:"sbd_balance"` // Liquid SBD
+}
+
+// NewClient returns the client selected by steem.client for steem.api_url
+func NewClient(config models.SteemConfig) (Client, error) {
+	switch config.Client {
+	case "", models.ChainClientSDK:
+		client := &sdkClient{url: config.APIURL, http: newNodeHTTPClient(config)}
+		if config.RPCBatchSize > 0 {
+			client.batch = newBatchCaller(config.APIURL, config.RPCBatchSize, client.http)
+		}
+		return client, nil
+	default:
+		return nil, fmt.Errorf("unknown steem.client %q", config.Client)
+	}
+}
+
+// sdkClient makes the condenser_api calls of the steemgosdk API and decodes them into the same
+// steemutil types, but sends them through its own HTTP client so the steem.requests settings
+// only apply to the node; it sends one request per block, and with steem.rpc_batch_size set,
+// calls for several blocks are batched instead
+type sdkClient struct {
+	url   string
+	http  *http.Client
+	batch *batchCaller // nil when batching is off
+}
+
+func (c *sdkClient) GetDynamicGlobalProperties() (*protocolapi.DynamicGlobalProperties, error) {
+	dgp := &protocolapi.DynamicGlobalProperties{}
+	if err := c.call(rpcCall{method: "condenser_api.get_dynamic_global_properties", params: []interface{}{}, result: dgp}); err != nil {
+		return nil, fmt.Errorf("failed to get dynamic global properties: %w", err)
+	}
+	return dgp, nil
+}
+
+func (c *sdkClient) GetBlock(blockNum uint) (*protocolapi.Block, error) {
+	block := &protocolapi.Block{}
+	if err := c.call(rpcCall{method: "condenser_api.get_block", params: []interface{}{blockNum}, result: block}); err != nil {
+		return nil, fmt.Errorf("failed to get block: %w", err)
+	}
+	return block, nil
+}
+
+func (c *sdkClient) GetOpsInBlock(blockNum uint, onlyVirtual bool) ([]*protocol.OperationObject, error) {
+	var ops []*protocol.OperationObject
+	if err := c.call(rpcCall{method: "condenser_api.get_ops_in_block", params: []interface{}{blockNum, onlyVirtual}, result: &ops}); err != nil {
+		return nil, fmt.Errorf("failed to get operations: %w", err)
+	}
+	return ops, nil
+}
+
+func (c *sdkClient) CallWithResult(apiName, method string, params []interface{}, result interface{}) error {
+	return c.call(rpcCall{method: apiName + "." + method, params: params, result: result})
+}
+
+// call sends a single JSON-RPC request and decodes its result
+func (c *sdkClient) call(call rpcCall) error {
+	if err := c.send(call); err != nil {
+		return fmt.Errorf("%s%v: %w", call.method, call.params, err)
+	}
+	return nil
+}
+
+func (c *sdkClient) send(call rpcCall) error {
+	body, err := json.Marshal(rpcRequest{JSONRPC: "2.0", ID: 1, Method: call.method, Params: call.params})
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("request failed with HTTP %d", resp.StatusCode)
+	}
+
+	var response rpcResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if response.Error != nil {
+		return response.Error
+	}
+	if len(response.Result) == 0 {
+		// A missing result (e.g. a block that doesn't exist yet) leaves result as it is
+		return nil
+	}
+	if err := json.Unmarshal(response.Result, call.result); err != nil {
+		return fmt.Errorf("failed to decode result: %w", err)
+	}
+	return nil
+}
+
+func (c *sdkClient) GetBlocks(blockNums []uint) (map[uint]*protocolapi.Block, error) {
+	blocks := make(map[uint]*protocolapi.Block, len(blockNums))
+	if c.batch == nil {
+		for _, blockNum := range blockNums {
+			block, err := c.GetBlock(blockNum)
+			if err != nil {
+				return nil, err
+			}
+			blocks[blockNum] = block
+		}
+		return blocks, nil
+	}
+
+	calls := make([]rpcCall, len(blockNums))
+	for i, blockNum := range blockNums {
+		block := &protocolapi.Block{}
+		blocks[blockNum] = block
+		calls[i] = rpcCall{method: "condenser_api.get_block", params: []interface{}{blockNum}, result: block}
+	}
+	if err := c.batch.call(calls); err != nil {
+		return nil, fmt.Errorf("failed to get blocks: %w", err)
+	}
+	return blocks, nil
+}
+
+func (c *sdkClient) GetOpsInBlocks(from, to uint, onlyVirtual bool) (map[uint][]*protocol.OperationObject, error) {
+	if from >= to {
+		return nil, fmt.Errorf("invalid block range [%d, %d)", from, to)
+	}
+
+	ops := make([][]*protocol.OperationObject, to-from)
+	calls := make([]rpcCall, to-from)
+	for i := range calls {
+		calls[i] = rpcCall{method: "condenser_api.get_ops_in_block", params: []interface{}{from + uint(i), onlyVirtual}, result: &ops[i]}
+	}
+	if c.batch != nil {
+		if err := c.batch.call(calls); err != nil {
+			return nil, fmt.Errorf("failed to get operations: %w", err)
+		}
+	} else if err := c.callEach(calls); err != nil {
+		return nil, fmt.Errorf("failed to get operations: %w", err)
+	}
+	opsMap := make(map[uint][]*protocol.OperationObject, len(ops))
+	for i, blockOps := range ops {
+		opsMap[from+uint(i)] = blockOps
+	}
+	return opsMap, nil
+}
+
+func (c *sdkClient) GetRawOpsInBlocks(from, to uint, onlyVirtual bool) (map[uint]json.RawMessage, error) {
+	ops := make([]json.RawMessage, to-from)
+	calls := make([]rpcCall, to-from)
+	for i := range calls {
+		calls[i] = rpcCall{method: "condenser_api.get_ops_in_block", params: []interface{}{from + uint(i), onlyVirtual}, result: &ops[i]}
+	}
+	if c.batch != nil {
+		if err := c.batch.call(calls); err != nil {
+			return nil, fmt.Errorf("failed to get operations: %w", err)
+		}
+	} else if err := c.callEach(calls); err != nil {
+		return nil, fmt.Errorf("failed to get operations: %w", err)
+	}
+	opsMap := make(map[uint]json.RawMessage, len(ops))
+	for i, blockOps := range ops {
+		opsMap[from+uint(i)] = blockOps
+	}
+	return opsMap, nil
+}
+
+// callEach sends calls as separate requests, all at once, with the retries of a batch; it
+// returns the first error of any call
+func (c *sdkClient) callEach(calls []rpcCall) error {
+	var wg stdsync.WaitGroup
+	errs := make(chan error, len(calls))
+	for _, call := range calls {
+		wg.Add(1)
+		go func(call rpcCall) {
+			defer wg.Done()
+			var err error
+			for attempt := 1; attempt <= batchAttempts; attempt++ {
+				if err = c.call(call); err == nil {
+					return
+				}
+				if attempt < batchAttempts {
+					time.Sleep(batchRetryDelay)
+				}
+			}
+			errs <- err
+		}(call)
+	}
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
+
+func (c *sdkClient) GetAccounts(names []string) ([]Account, error) {
+	var accounts []Account
+	if err := c.CallWithResult("condenser_api", "get_accounts", []interface{}{names}, &accounts); err != nil {
+		return nil, fmt.Errorf("failed to get accounts: %w", err)
+	}
+	return accounts, nil
+}
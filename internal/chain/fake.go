@@ -0,0 +1,182 @@
+package chain
+
+import (
+	"encoding/json"
+	"fmt"
+	stdsync "sync"
+
+	"github.com/steemit/steemutil/protocol"
+	protocolapi "github.com/steemit/steemutil/protocol/api"
+)
+
+// Fake is an in-memory node for tests
+// Blocks and operations are set per block number and can be replaced at any time, e.g. to
+// simulate a fork; blocks that were never set are returned empty, like a node answers for
+// blocks it doesn't have yet
+type Fake struct {
+	mu         stdsync.Mutex
+	properties protocolapi.DynamicGlobalProperties
+	blocks     map[uint]*protocolapi.Block
+	ops        map[uint]json.RawMessage
+	accounts   map[string]Account
+	results    map[string]json.RawMessage
+}
+
+// NewFake creates an empty fake node
+func NewFake() *Fake {
+	return &Fake{
+		blocks:   make(map[uint]*protocolapi.Block),
+		ops:      make(map[uint]json.RawMessage),
+		accounts: make(map[string]Account),
+		results:  make(map[string]json.RawMessage),
+	}
+}
+
+// SetHead sets the head and last irreversible block numbers
+func (f *Fake) SetHead(head, lastIrreversible uint) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.properties.HeadBlockNumber = protocol.UInt32(head)
+	f.properties.LastIrreversibleBlockNum = protocol.UInt(lastIrreversible)
+}
+
+// SetBlock sets a block and its operations, given as the JSON array get_ops_in_block returns
+func (f *Fake) SetBlock(blockNum uint, block *protocolapi.Block, ops string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.blocks[blockNum] = block
+	f.ops[blockNum] = json.RawMessage(ops)
+}
+
+// SetAccounts sets the accounts that exist on the fake node
+func (f *Fake) SetAccounts(accounts ...Account) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, account := range accounts {
+		f.accounts[account.Name] = account
+	}
+}
+
+// SetResult sets the result CallWithResult returns for a method, e.g. "condenser_api.list_proposals"
+func (f *Fake) SetResult(method string, result interface{}) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.results[method] = data
+	return nil
+}
+
+func (f *Fake) GetDynamicGlobalProperties() (*protocolapi.DynamicGlobalProperties, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	dgp := f.properties
+	return &dgp, nil
+}
+
+func (f *Fake) GetBlock(blockNum uint) (*protocolapi.Block, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if block, ok := f.blocks[blockNum]; ok && block != nil {
+		copied := *block
+		return &copied, nil
+	}
+	return &protocolapi.Block{}, nil
+}
+
+func (f *Fake) GetBlocks(blockNums []uint) (map[uint]*protocolapi.Block, error) {
+	blocks := make(map[uint]*protocolapi.Block, len(blockNums))
+	for _, blockNum := range blockNums {
+		block, err := f.GetBlock(blockNum)
+		if err != nil {
+			return nil, err
+		}
+		blocks[blockNum] = block
+	}
+	return blocks, nil
+}
+
+func (f *Fake) GetOpsInBlock(blockNum uint, onlyVirtual bool) ([]*protocol.OperationObject, error) {
+	f.mu.Lock()
+	raw := f.ops[blockNum]
+	f.mu.Unlock()
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	var ops []*protocol.OperationObject
+	if err := json.Unmarshal(raw, &ops); err != nil {
+		return nil, fmt.Errorf("failed to decode operations of block %d: %w", blockNum, err)
+	}
+	if !onlyVirtual {
+		return ops, nil
+	}
+	var virtual []*protocol.OperationObject
+	for _, op := range ops {
+		if op.VirtualOperation != 0 {
+			virtual = append(virtual, op)
+		}
+	}
+	return virtual, nil
+}
+
+func (f *Fake) GetOpsInBlocks(from, to uint, onlyVirtual bool) (map[uint][]*protocol.OperationObject, error) {
+	if from >= to {
+		return nil, fmt.Errorf("invalid block range [%d, %d)", from, to)
+	}
+	opsMap := make(map[uint][]*protocol.OperationObject, to-from)
+	for blockNum := from; blockNum < to; blockNum++ {
+		ops, err := f.GetOpsInBlock(blockNum, onlyVirtual)
+		if err != nil {
+			return nil, err
+		}
+		opsMap[blockNum] = ops
+	}
+	return opsMap, nil
+}
+
+func (f *Fake) GetRawOpsInBlocks(from, to uint, onlyVirtual bool) (map[uint]json.RawMessage, error) {
+	opsMap, err := f.GetOpsInBlocks(from, to, onlyVirtual)
+	if err != nil {
+		return nil, err
+	}
+	rawMap := make(map[uint]json.RawMessage, len(opsMap))
+	for blockNum, ops := range opsMap {
+		if ops == nil {
+			ops = []*protocol.OperationObject{}
+		}
+		raw, err := json.Marshal(ops)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode operations of block %d: %w", blockNum, err)
+		}
+		rawMap[blockNum] = raw
+	}
+	return rawMap, nil
+}
+
+func (f *Fake) GetAccounts(names []string) ([]Account, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var accounts []Account
+	for _, name := range names {
+		if account, ok := f.accounts[name]; ok {
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts, nil
+}
+
+func (f *Fake) CallWithResult(apiName, method string, params []interface{}, result interface{}) error {
+	f.mu.Lock()
+	data, ok := f.results[apiName+"."+method]
+	f.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%s.%s%v: no result set on the fake node", apiName, method, params)
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("failed to decode result: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package chain
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/steemit/steemutil/protocol"
+)
+
+// MaxHistoryPage is the most account history entries a node returns per call
+const MaxHistoryPage = 1000
+
+// HistoryEntry is one entry of an account's history
+type HistoryEntry struct {
+	Seq int64 // Position in the account's history, starting at 0
+	Op  *protocol.OperationObject
+}
+
+// AccountHistory returns up to limit history entries of account ending at sequence number from,
+// oldest first; from -1 starts at the newest entry
+func AccountHistory(client Client, account string, from int64, limit int) ([]HistoryEntry, error) {
+	var raw [][2]json.RawMessage
+	if err := client.CallWithResult("condenser_api", "get_account_history", []interface{}{account, from, limit}, &raw); err != nil {
+		return nil, fmt.Errorf("failed to get account history of %s: %w", account, err)
+	}
+
+	entries := make([]HistoryEntry, 0, len(raw))
+	for _, item := range raw {
+		var entry HistoryEntry
+		if err := json.Unmarshal(item[0], &entry.Seq); err != nil {
+			return nil, fmt.Errorf("failed to decode account history of %s: %w", account, err)
+		}
+		entry.Op = &protocol.OperationObject{}
+		if err := json.Unmarshal(item[1], entry.Op); err != nil {
+			return nil, fmt.Errorf("failed to decode history entry %d of %s: %w", entry.Seq, account, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,39 @@
+package chain
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxProposalPage is the most proposals a node lists per call
+const maxProposalPage = 1000
+
+// proposalDateLayout is the format of a proposal's start_date and end_date (UTC)
+const proposalDateLayout = "2006-01-02T15:04:05"
+
+// Proposal is an entry of list_proposals
+type Proposal struct {
+	ID        int64  `json:"id"`
+	Creator   string `json:"creator"`
+	Receiver  string `json:"receiver"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	DailyPay  string `json:"daily_pay"`
+	Subject   string `json:"subject"`
+	Permlink  string `json:"permlink"`
+}
+
+// End returns when the proposal's funding period ends
+func (p Proposal) End() (time.Time, error) {
+	return time.Parse(proposalDateLayout, p.EndDate)
+}
+
+// ActiveProposals returns the proposals whose funding period has started and not ended, funded or not
+func ActiveProposals(client Client) ([]Proposal, error) {
+	var proposals []Proposal
+	params := []interface{}{[]interface{}{""}, maxProposalPage, "by_creator", "ascending", "active"}
+	if err := client.CallWithResult("condenser_api", "list_proposals", params, &proposals); err != nil {
+		return nil, fmt.Errorf("failed to list proposals: %w", err)
+	}
+	return proposals, nil
+}
@@ -0,0 +1,123 @@
+package chain
+
+import (
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	stdsync "sync"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+)
+
+// rpcTimeout bounds each request to the node, as the SDK's client did
+const rpcTimeout = 30 * time.Second
+
+// Request budgets by nodeKey, shared by the clients of one node within the process
+var (
+	budgetsMu stdsync.Mutex
+	budgets   = make(map[string]*requestBudget)
+)
+
+// nodeTransport adds the identification headers to the requests of one node's client and holds
+// them back while the node's request budget is used up
+// It is only used by the node client, so requests to other hosts (Telegram, webhooks, price
+// services) never carry the node's headers
+type nodeTransport struct {
+	base    http.RoundTripper
+	headers http.Header
+	budget  *requestBudget // nil when unlimited
+}
+
+// newNodeHTTPClient returns the HTTP client for requests to steem.api_url, with the
+// steem.requests settings applied
+func newNodeHTTPClient(config models.SteemConfig) *http.Client {
+	requests := config.Requests
+	transport := &nodeTransport{base: http.DefaultTransport, headers: make(http.Header)}
+	for name, value := range requests.Headers {
+		transport.headers.Set(name, value)
+	}
+	transport.headers.Set("User-Agent", requests.UserAgentOrDefault())
+	if rps, burst := requests.Budget(config.APIURL); rps > 0 {
+		transport.budget = nodeBudget(config.APIURL, rps, burst)
+	}
+	return &http.Client{Timeout: rpcTimeout, Transport: transport}
+}
+
+// nodeBudget returns the request budget of the node at apiURL, created on first use
+func nodeBudget(apiURL string, rps float64, burst int) *requestBudget {
+	key := apiURL
+	if u, err := url.Parse(apiURL); err == nil {
+		key = nodeKey(u)
+	}
+
+	budgetsMu.Lock()
+	defer budgetsMu.Unlock()
+	if budget, ok := budgets[key]; ok {
+		return budget
+	}
+	budget := newRequestBudget(rps, burst)
+	budgets[key] = budget
+	return budget
+}
+
+// nodeKey identifies a node by scheme, host and path
+func nodeKey(u *url.URL) string {
+	return strings.ToLower(u.Scheme+"://"+u.Host) + strings.TrimRight(u.Path, "/")
+}
+
+func (t *nodeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.budget != nil {
+		if wait := t.budget.reserve(time.Now()); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-req.Context().Done():
+				timer.Stop()
+				return nil, req.Context().Err()
+			}
+		}
+	}
+
+	// A RoundTripper must not modify the caller's request
+	req = req.Clone(req.Context())
+	for name, values := range t.headers {
+		req.Header[name] = values
+	}
+	return t.base.RoundTrip(req)
+}
+
+// requestBudget is a token bucket whose tokens can go negative: a request that finds it empty
+// reserves the next token and waits for it, so waiting requests are spaced at the budget's rate
+type requestBudget struct {
+	rps   float64
+	burst float64
+
+	mu     stdsync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newRequestBudget(rps float64, burst int) *requestBudget {
+	if burst <= 0 {
+		burst = int(math.Max(1, math.Ceil(2*rps)))
+	}
+	return &requestBudget{rps: rps, burst: float64(burst), tokens: float64(burst)}
+}
+
+// reserve takes a token and returns how long to wait until it is available
+func (b *requestBudget) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.last.IsZero() {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rps)
+	}
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rps * float64(time.Second))
+}
@@ -0,0 +1,78 @@
+// Package chart renders the images attached to Telegram reports
+package chart
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	gochart "github.com/wcharczuk/go-chart/v2"
+	"github.com/wcharczuk/go-chart/v2/drawing"
+)
+
+// Image size of rendered charts; charts with many bars get wider so every bar keeps its label
+const (
+	minWidth    = 1000
+	height      = 500
+	minBarWidth = 32
+	barSpacing  = 8
+	axisWidth   = 120
+)
+
+// barColor is the color of bars (Steem blue)
+var barColor = drawing.ColorFromHex("1a5099")
+
+// Bar is one labeled value of a bar chart
+type Bar struct {
+	Label string
+	Value float64
+}
+
+// Bars renders a bar chart as PNG
+// Bars must not be empty; negative values are drawn as zero
+func Bars(title string, bars []Bar) ([]byte, error) {
+	if len(bars) == 0 {
+		return nil, errors.New("no bars to draw")
+	}
+
+	values := make([]gochart.Value, len(bars))
+	peak := 0.0
+	for i, bar := range bars {
+		value := max(bar.Value, 0)
+		peak = max(peak, value)
+		values[i] = gochart.Value{Label: bar.Label, Value: value, Style: gochart.Style{FillColor: barColor, StrokeColor: barColor}}
+	}
+	// The value axis needs a range; an all-zero chart gets a nominal one
+	yRange := &gochart.ContinuousRange{Min: 0, Max: peak * 1.1}
+	if peak == 0 {
+		yRange.Max = 1
+	}
+	valueFormat := "%.0f"
+	if yRange.Max < 10 {
+		valueFormat = "%.2f"
+	}
+	width := max(minWidth, axisWidth+len(bars)*(minBarWidth+barSpacing))
+
+	graph := gochart.BarChart{
+		Title:      title,
+		TitleStyle: gochart.Style{FontSize: 14},
+		Width:      width,
+		Height:     height,
+		Background: gochart.Style{Padding: gochart.Box{Top: 50, Left: 20, Right: 20, Bottom: 20}},
+		BarWidth:   (width-axisWidth)/len(bars) - barSpacing,
+		BarSpacing: barSpacing,
+		XAxis:      gochart.Style{FontSize: 8},
+		YAxis: gochart.YAxis{
+			Style:          gochart.Style{FontSize: 9},
+			Range:          yRange,
+			ValueFormatter: func(v interface{}) string { return fmt.Sprintf(valueFormat, v) },
+		},
+		Bars: values,
+	}
+
+	var buf bytes.Buffer
+	if err := graph.Render(gochart.PNG, &buf); err != nil {
+		return nil, fmt.Errorf("failed to render chart: %w", err)
+	}
+	return buf.Bytes(), nil
+}
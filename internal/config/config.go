@@ -0,0 +1,115 @@
+// Package config loads the configuration shared by all commands
+// Values are layered: built-in defaults, then the YAML file, then SPS_* environment variables,
+// then -set flags; the result is validated before it is returned
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	// Time zones in the configuration and API requests resolve without the host's zoneinfo files
+	_ "time/tzdata"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Defaults returns the configuration used for settings the file leaves out
+func Defaults() *models.Config {
+	return &models.Config{
+		Steem: models.SteemConfig{
+			APIURL:   "https://api.steem.fans",
+			SyncMode: models.SyncModeIrreversible,
+		},
+		MongoDB: models.MongoDBConfig{
+			URI:      "mongodb://localhost:27017",
+			Database: "sps_fund_watcher",
+		},
+		API: models.APIConfig{
+			Host: "0.0.0.0",
+			Port: "8080",
+		},
+		Retry: models.RetryConfig{
+			Telegram: models.DefaultTelegramRetry,
+			Webhooks: models.DefaultWebhookRetry,
+		},
+		Logging: models.LoggingConfig{
+			Level:  "info",
+			Format: models.LogFormatText,
+		},
+	}
+}
+
+// Load reads the configuration file at path and applies the environment and overrides
+// (dotted YAML paths with a value, e.g. "mongodb.database=test")
+func Load(path string, overrides []string) (*models.Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	config := Defaults()
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := applyEnv(config, os.LookupEnv); err != nil {
+		return nil, err
+	}
+	for _, override := range overrides {
+		key, value, ok := strings.Cut(override, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid override %q: expected key=value", override)
+		}
+		if err := set(config, key, value); err != nil {
+			return nil, fmt.Errorf("invalid override %q: %w", override, err)
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Flags are the configuration flags every command accepts
+type Flags struct {
+	overrides []string
+	print     bool
+	chain     string
+}
+
+// Register adds -set, -chain and -print-config to fs
+func (f *Flags) Register(fs *flag.FlagSet) {
+	fs.Func("set", "Override a config value by its YAML path, e.g. -set mongodb.database=test (repeatable)", func(value string) error {
+		f.overrides = append(f.overrides, value)
+		return nil
+	})
+	fs.StringVar(&f.chain, "chain", "", "Use only this chain of the chains section (or steem.chain)")
+	fs.BoolVar(&f.print, "print-config", false, "Print the effective configuration with secrets masked and exit")
+}
+
+// Load loads the configuration at path with the -set overrides, narrowed to the -chain chain
+// With -print-config it prints the configuration and exits, like -version
+func (f *Flags) Load(path string) (*models.Config, error) {
+	config, err := Load(path, f.overrides)
+	if err != nil {
+		return nil, err
+	}
+	if f.chain != "" {
+		chainConfig, ok := config.FindChain(f.chain)
+		if !ok {
+			return nil, fmt.Errorf("unknown chain %q", f.chain)
+		}
+		config = chainConfig
+	}
+	if f.print {
+		out, err := Masked(config)
+		if err != nil {
+			return nil, err
+		}
+		os.Stdout.Write(out)
+		os.Exit(0)
+	}
+	return config, nil
+}
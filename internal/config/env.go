@@ -0,0 +1,124 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix starts the environment variables that override settings: the YAML path in upper case
+// with underscores, e.g. SPS_MONGODB_URI for mongodb.uri or SPS_TELEGRAM_BOT_TOKEN for telegram.bot_token
+const EnvPrefix = "SPS_"
+
+// applyEnv sets every setting whose environment variable is present
+// Scalar lists may be comma-separated; other lists and maps take YAML flow syntax
+func applyEnv(config *models.Config, lookup func(string) (string, bool)) error {
+	return walk(reflect.ValueOf(config).Elem(), nil, func(path []string, field reflect.Value) error {
+		name := EnvPrefix + strings.ToUpper(strings.Join(path, "_"))
+		raw, ok := lookup(name)
+		if !ok {
+			return nil
+		}
+		if err := setValue(field, raw); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// walk calls fn for each setting of a struct that isn't itself a struct, with its YAML path
+func walk(v reflect.Value, path []string, fn func([]string, reflect.Value) error) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := yamlName(t.Field(i))
+		if name == "" {
+			continue
+		}
+		field := v.Field(i)
+		fieldPath := append(append([]string{}, path...), name)
+		if field.Kind() == reflect.Struct {
+			if err := walk(field, fieldPath, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := fn(fieldPath, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// set assigns the setting at a dotted YAML path, e.g. "telegram.enabled"
+func set(config *models.Config, key string, raw string) error {
+	v := reflect.ValueOf(config).Elem()
+	for _, name := range strings.Split(key, ".") {
+		if v.Kind() != reflect.Struct {
+			return fmt.Errorf("%s is not a setting", key)
+		}
+		field, ok := fieldByYAML(v, name)
+		if !ok {
+			return fmt.Errorf("unknown setting %s", key)
+		}
+		v = field
+	}
+	if v.Kind() == reflect.Struct {
+		return fmt.Errorf("%s is a section, not a setting", key)
+	}
+	return setValue(v, raw)
+}
+
+// setValue parses raw as YAML into field; strings are taken as they are and scalar lists may
+// also be given comma-separated
+func setValue(field reflect.Value, raw string) error {
+	switch {
+	case field.Kind() == reflect.String:
+		field.SetString(raw)
+		return nil
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String && !strings.HasPrefix(strings.TrimSpace(raw), "["):
+		items := []string{}
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items).Convert(field.Type()))
+		return nil
+	}
+
+	value := reflect.New(field.Type())
+	if err := yaml.Unmarshal([]byte(raw), value.Interface()); err != nil {
+		return fmt.Errorf("cannot parse %q as %s", raw, field.Type())
+	}
+	field.Set(value.Elem())
+	return nil
+}
+
+// fieldByYAML returns the field of a struct with the given YAML name
+func fieldByYAML(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if yamlName(t.Field(i)) == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// yamlName returns the YAML key of a field, or "" if the field isn't read from YAML
+func yamlName(f reflect.StructField) string {
+	if !f.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return strings.ToLower(f.Name)
+	}
+	return name
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Settings masked by Masked, by YAML key
+var (
+	secretKeys = map[string]bool{"bot_token": true, "admin_token": true, "sentry_dsn": true, "secret": true, "key": true, "api_key": true, "password": true}
+	uriKeys    = map[string]bool{"uri": true, "url": true, "api_url": true, "proxy": true}
+	// Request headers often carry credentials, so all their values are masked
+	headerKeys = map[string]bool{"headers": true}
+)
+
+// Masked returns the configuration as YAML with tokens, keys and header values masked and
+// passwords removed from URIs
+func Masked(config *models.Config) ([]byte, error) {
+	var node yaml.Node
+	if err := node.Encode(config); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	mask(&node, false)
+	out, err := yaml.Marshal(&node)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	return out, nil
+}
+
+// mask masks the secrets below node; all masks every scalar value
+func mask(node *yaml.Node, all bool) {
+	if node.Kind != yaml.MappingNode {
+		for _, child := range node.Content {
+			mask(child, all)
+		}
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i].Value, node.Content[i+1]
+		if value.Kind != yaml.ScalarNode {
+			mask(value, all || headerKeys[key])
+			continue
+		}
+		switch {
+		case all || secretKeys[key]:
+			if value.Value != "" {
+				value.Value = models.MaskSecret(value.Value)
+			}
+		case uriKeys[key]:
+			value.Value = models.MaskURI(value.Value)
+		}
+	}
+}
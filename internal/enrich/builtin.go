@@ -0,0 +1,149 @@
+package enrich
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ety001/sps-fund-watcher/internal/chain"
+	"github.com/ety001/sps-fund-watcher/internal/models"
+)
+
+func init() {
+	Register(models.EnricherAmount, func(models.EnrichmentConfig, chain.Client) (Enricher, error) {
+		return amountEnricher{}, nil
+	})
+	Register(models.EnricherCategory, func(models.EnrichmentConfig, chain.Client) (Enricher, error) {
+		return categoryEnricher{}, nil
+	})
+	Register(models.EnricherProposalLink, func(config models.EnrichmentConfig, _ chain.Client) (Enricher, error) {
+		template := config.ProposalURL
+		if template == "" {
+			template = models.DefaultProposalURL
+		}
+		return proposalLinkEnricher{template: template}, nil
+	})
+	Register(models.EnricherCounterpartyTags, func(config models.EnrichmentConfig, _ chain.Client) (Enricher, error) {
+		return counterpartyTagsEnricher{tags: config.Tags}, nil
+	})
+	Register(models.EnricherUSDValue, newUSDValueEnricher)
+}
+
+// amountEnricher adds the parsed amount and symbol of fund movements
+type amountEnricher struct{}
+
+func (amountEnricher) Name() string { return models.EnricherAmount }
+
+func (amountEnricher) Enrich(_ context.Context, op *models.Operation, fields map[string]interface{}) error {
+	if asset, ok := models.OperationAmount(op.OpData); ok {
+		fields["amount"] = asset.Amount
+		fields["symbol"] = asset.Symbol
+	}
+	return nil
+}
+
+// opCategories groups operation types into coarse categories
+var opCategories = map[string]string{
+	"transfer":                   "transfer",
+	"transfer_to_savings":        "savings",
+	"transfer_from_savings":      "savings",
+	"fill_transfer_from_savings": "savings",
+	"transfer_to_vesting":        "power",
+	"withdraw_vesting":           "power",
+	"fill_vesting_withdraw":      "power",
+	"delegate_vesting_shares":    "power",
+	"proposal_pay":               "proposal_payout",
+	"sps_fund":                   "treasury",
+	"create_proposal":            "proposal",
+	"update_proposal":            "proposal",
+	"remove_proposal":            "proposal",
+	"update_proposal_votes":      "governance",
+	"account_witness_vote":       "governance",
+	"account_witness_proxy":      "governance",
+	"author_reward":              "reward",
+	"curation_reward":            "reward",
+	"producer_reward":            "reward",
+	"comment_benefactor_reward":  "reward",
+	"claim_reward_balance":       "reward",
+	"convert":                    "market",
+	"fill_convert_request":       "market",
+	"limit_order_create":         "market",
+	"limit_order_cancel":         "market",
+	"fill_order":                 "market",
+	"comment":                    "social",
+	"vote":                       "social",
+	"custom_json":                "social",
+	models.OpTypeMention:         "social",
+}
+
+// categoryEnricher adds a category and, for fund movements, the direction seen from the tracked account
+type categoryEnricher struct{}
+
+func (categoryEnricher) Name() string { return models.EnricherCategory }
+
+func (categoryEnricher) Enrich(_ context.Context, op *models.Operation, fields map[string]interface{}) error {
+	category, ok := opCategories[op.OpType]
+	if !ok {
+		category = "other"
+	}
+	fields["category"] = category
+
+	from, to := models.OperationParties(op.OpData)
+	switch {
+	case to == op.Account && from != op.Account:
+		fields["direction"] = "in"
+	case from == op.Account && to != "" && to != op.Account:
+		fields["direction"] = "out"
+	}
+	return nil
+}
+
+// proposalLinkEnricher links proposal operations to the proposal post and lists the proposal ids they touch
+type proposalLinkEnricher struct {
+	template string
+}
+
+func (proposalLinkEnricher) Name() string { return models.EnricherProposalLink }
+
+func (e proposalLinkEnricher) Enrich(_ context.Context, op *models.Operation, fields map[string]interface{}) error {
+	creator, _ := op.OpData["creator"].(string)
+	permlink, _ := op.OpData["permlink"].(string)
+	if creator != "" && permlink != "" {
+		fields["proposal_url"] = strings.NewReplacer("{creator}", creator, "{permlink}", permlink).Replace(e.template)
+	}
+
+	var ids []interface{}
+	if id, ok := op.OpData["proposal_id"]; ok {
+		ids = append(ids, id)
+	}
+	if list, ok := op.OpData["proposal_ids"].([]interface{}); ok {
+		ids = append(ids, list...)
+	}
+	if len(ids) > 0 {
+		fields["proposal_ids"] = ids
+	}
+	return nil
+}
+
+// counterpartyTagsEnricher names the other side of an operation and adds its configured tags
+type counterpartyTagsEnricher struct {
+	tags map[string][]string
+}
+
+func (counterpartyTagsEnricher) Name() string { return models.EnricherCounterpartyTags }
+
+func (e counterpartyTagsEnricher) Enrich(_ context.Context, op *models.Operation, fields map[string]interface{}) error {
+	from, to := models.OperationParties(op.OpData)
+	counterparty := to
+	if to == op.Account {
+		counterparty = from
+	}
+	if counterparty == "" || counterparty == op.Account {
+		return nil
+	}
+
+	fields["counterparty"] = counterparty
+	if tags := e.tags[counterparty]; len(tags) > 0 {
+		fields["counterparty_tags"] = tags
+	}
+	return nil
+}
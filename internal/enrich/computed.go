@@ -0,0 +1,103 @@
+package enrich
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ety001/sps-fund-watcher/internal/chain"
+	"github.com/ety001/sps-fund-watcher/internal/expr"
+	"github.com/ety001/sps-fund-watcher/internal/models"
+)
+
+// computedField is a compiled enrichment.computed entry
+type computedField struct {
+	models.ComputedField
+	expr *expr.Expr
+}
+
+// computedEnricher sets the computed fields after the other enrichers, so expressions can read
+// their fields through the enrichment variable; later fields also see earlier ones
+type computedEnricher struct {
+	fields []computedField
+	tables map[string]map[string]interface{}
+	prices *priceFeed // nil when no expression reads price
+}
+
+func newComputedEnricher(config models.EnrichmentConfig, client chain.Client) (*computedEnricher, error) {
+	e := &computedEnricher{tables: config.Tables}
+	for _, field := range config.Computed {
+		compiled, err := expr.Parse(field.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expression of computed field %q: %w", field.Name, err)
+		}
+		if slices.Contains(compiled.Variables(), "price") && e.prices == nil {
+			if client == nil {
+				return nil, fmt.Errorf("computed field %q reads price, which needs a node client", field.Name)
+			}
+			e.prices = newPriceFeed(config, client)
+		}
+		e.fields = append(e.fields, computedField{field, compiled})
+	}
+	return e, nil
+}
+
+func (e *computedEnricher) Name() string { return "computed" }
+
+func (e *computedEnricher) Enrich(ctx context.Context, op *models.Operation, fields map[string]interface{}) error {
+	var errs []error
+	for _, field := range e.fields {
+		if len(field.Operations) > 0 && !slices.Contains(field.Operations, op.OpType) {
+			continue
+		}
+		value, err := field.expr.Eval(func(name string) (interface{}, error) {
+			return e.variable(ctx, op, fields, name)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", field.Name, err))
+			continue
+		}
+		if value != nil {
+			fields[field.Name] = value
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// variable resolves a variable of a computed field expression for op
+func (e *computedEnricher) variable(ctx context.Context, op *models.Operation, fields map[string]interface{}, name string) (interface{}, error) {
+	switch name {
+	case "account":
+		return op.Account, nil
+	case "op_type":
+		return op.OpType, nil
+	case "block_num":
+		return op.BlockNum, nil
+	case "trx_id":
+		return op.TrxID, nil
+	case "op_data":
+		return op.OpData, nil
+	case "enrichment":
+		return fields, nil
+	case "amount", "asset":
+		asset, ok := models.OperationAmount(op.OpData)
+		if !ok {
+			return nil, nil
+		}
+		if name == "amount" {
+			return asset.Amount, nil
+		}
+		return asset.Symbol, nil
+	case "price":
+		prices, err := e.prices.prices(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"SBD": prices["SBD"], "STEEM": prices["STEEM"]}, nil
+	}
+	if table, ok := e.tables[name]; ok {
+		return table, nil
+	}
+	return nil, fmt.Errorf("unknown variable %s", name)
+}
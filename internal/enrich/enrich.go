@@ -0,0 +1,108 @@
+package enrich
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	stdsync "sync"
+
+	"github.com/ety001/sps-fund-watcher/internal/chain"
+	"github.com/ety001/sps-fund-watcher/internal/logging"
+	"github.com/ety001/sps-fund-watcher/internal/models"
+)
+
+var logger = logging.Component("enrich")
+
+// Enricher adds computed fields to an operation before it is stored
+// Enrich writes into fields, which becomes Operation.Enrichment; it must not modify op
+type Enricher interface {
+	Name() string
+	Enrich(ctx context.Context, op *models.Operation, fields map[string]interface{}) error
+}
+
+// Factory builds an enricher from the deployment's enrichment config
+// client is nil in tools that run without a node connection
+type Factory func(config models.EnrichmentConfig, client chain.Client) (Enricher, error)
+
+var (
+	registryMu stdsync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+// Register makes an enricher available under name; registering a name twice panics
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("enrich: enricher %q registered twice", name))
+	}
+	registry[name] = factory
+}
+
+// Names returns the registered enricher names in sorted order
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Pipeline runs the configured enrichers in order
+type Pipeline struct {
+	enrichers []Enricher
+}
+
+// NewPipeline builds the enrichers listed in config.Enrichers, followed by the computed fields
+// It returns nil when neither is configured; a nil pipeline is a no-op
+func NewPipeline(config models.EnrichmentConfig, client chain.Client) (*Pipeline, error) {
+	if len(config.Enrichers) == 0 && len(config.Computed) == 0 {
+		return nil, nil
+	}
+
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	pipeline := &Pipeline{}
+	for _, name := range config.Enrichers {
+		factory, ok := registry[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown enricher %q", name)
+		}
+		enricher, err := factory(config, client)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize enricher %q: %w", name, err)
+		}
+		pipeline.enrichers = append(pipeline.enrichers, enricher)
+	}
+	if len(config.Computed) > 0 {
+		computed, err := newComputedEnricher(config, client)
+		if err != nil {
+			return nil, err
+		}
+		pipeline.enrichers = append(pipeline.enrichers, computed)
+	}
+	return pipeline, nil
+}
+
+// Apply enriches operations in place
+// A failing enricher is logged and skipped so enrichment never holds back sync
+func (p *Pipeline) Apply(ctx context.Context, operations []*models.Operation) {
+	if p == nil {
+		return
+	}
+	for _, op := range operations {
+		fields := make(map[string]interface{})
+		for _, enricher := range p.enrichers {
+			if err := enricher.Enrich(ctx, op, fields); err != nil {
+				logger.Warn("Enricher failed", "enricher", enricher.Name(), "block", op.BlockNum, "trx_id", op.TrxID, "error", err)
+			}
+		}
+		if len(fields) > 0 {
+			op.Enrichment = fields
+		}
+	}
+}
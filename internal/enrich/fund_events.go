@@ -0,0 +1,261 @@
+package enrich
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	stdsync "sync"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+)
+
+// keyAuthorities are the op_data fields of account updates that replace an authority or key
+var keyAuthorities = []string{"owner", "active", "posting", "memo_key"}
+
+// withdrawalTypes are the operations that move funds out of the sending account
+var withdrawalTypes = map[string]bool{
+	"transfer":              true,
+	"transfer_from_savings": true,
+}
+
+// payoutRound is the set of receivers paid in one hourly SPS payout block
+type payoutRound struct {
+	block     int64
+	receivers map[string]bool
+}
+
+// FundEventDeriver derives high-level fund events from enriched operations
+// Payouts, key changes and large withdrawals come from single operations. Funding changes compare
+// consecutive payout rounds: Steem's proposal_pay names the receiver but not the proposal, so
+// funding is followed per receiver
+type FundEventDeriver struct {
+	kinds       map[string]bool
+	largeAmount float64
+	largeSymbol string
+
+	mu       stdsync.Mutex
+	current  payoutRound // Latest payout round seen
+	previous payoutRound // The round before it
+}
+
+// NewFundEventDeriver returns the deriver for config, or nil when fund events are disabled
+// A nil deriver derives nothing
+func NewFundEventDeriver(config models.FundEventsConfig) *FundEventDeriver {
+	if !config.Enabled {
+		return nil
+	}
+	kinds := config.Kinds
+	if len(kinds) == 0 {
+		kinds = models.FundEventKinds
+	}
+	d := &FundEventDeriver{
+		kinds:       make(map[string]bool, len(kinds)),
+		largeAmount: config.LargeWithdrawalAmount,
+		largeSymbol: config.LargeWithdrawalSymbol,
+	}
+	for _, kind := range kinds {
+		d.kinds[kind] = true
+	}
+	return d
+}
+
+// Seed sets the latest payout round known from storage, so funding changes are detected across restarts
+func (d *FundEventDeriver) Seed(block int64, receivers []string) {
+	if d == nil || block == 0 {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if block > d.current.block {
+		d.current = payoutRound{block: block, receivers: toSet(receivers)}
+	}
+}
+
+// Derive returns the fund events of operations given in block order
+// An operation stored for several accounts yields its events once
+func (d *FundEventDeriver) Derive(operations []*models.Operation) []models.FundEvent {
+	if d == nil {
+		return nil
+	}
+
+	var events []models.FundEvent
+	seen := make(map[string]bool)
+	add := func(event models.FundEvent) {
+		if d.kinds[event.Kind] && !seen[event.ID] {
+			seen[event.ID] = true
+			events = append(events, event)
+		}
+	}
+
+	for start := 0; start < len(operations); {
+		end := start + 1
+		for end < len(operations) && operations[end].BlockNum == operations[start].BlockNum {
+			end++
+		}
+
+		paid := make(map[string]bool)
+		for _, op := range operations[start:end] {
+			if op.OpType == "proposal_pay" {
+				if receiver, _ := op.OpData["receiver"].(string); receiver != "" {
+					paid[receiver] = true
+				}
+			}
+			for _, event := range d.operationEvents(op) {
+				add(event)
+			}
+		}
+		if len(paid) > 0 {
+			for _, event := range d.payoutRound(operations[start], paid) {
+				add(event)
+			}
+		}
+		start = end
+	}
+	return events
+}
+
+// operationEvents returns the events a single operation stands for
+func (d *FundEventDeriver) operationEvents(op *models.Operation) []models.FundEvent {
+	switch {
+	case op.OpType == "proposal_pay":
+		receiver, _ := op.OpData["receiver"].(string)
+		asset, ok := models.OperationAmount(op.OpData)
+		if receiver == "" || !ok {
+			return nil
+		}
+		summary := fmt.Sprintf("%s received %s from the SPS fund", receiver, asset)
+		data := map[string]interface{}{"receiver": receiver, "amount": asset.Amount, "symbol": asset.Symbol}
+		return []models.FundEvent{models.FundEventFromOperation(models.FundEventPayoutMade, receiver, op, summary, data)}
+
+	case withdrawalTypes[op.OpType]:
+		if d.largeAmount <= 0 {
+			return nil
+		}
+		from, to := models.OperationParties(op.OpData)
+		asset, ok := models.OperationAmount(op.OpData)
+		// Only the sender's copy counts as a withdrawal
+		if !ok || from != op.Account || asset.Amount < d.largeAmount {
+			return nil
+		}
+		if d.largeSymbol != "" && !strings.EqualFold(asset.Symbol, d.largeSymbol) {
+			return nil
+		}
+		summary := fmt.Sprintf("%s sent %s to %s", from, asset, to)
+		if op.OpType == "transfer_from_savings" {
+			summary = fmt.Sprintf("%s withdrew %s from savings to %s", from, asset, to)
+		}
+		data := map[string]interface{}{"from": from, "to": to, "amount": asset.Amount, "symbol": asset.Symbol}
+		return []models.FundEvent{models.FundEventFromOperation(models.FundEventLargeWithdrawal, from, op, summary, data)}
+
+	default:
+		account, keys := changedKeys(op)
+		if len(keys) == 0 {
+			return nil
+		}
+		summary := fmt.Sprintf("%s changed keys: %s", account, strings.Join(keys, ", "))
+		data := map[string]interface{}{"keys": keys}
+		return []models.FundEvent{models.FundEventFromOperation(models.FundEventKeyChanged, account, op, summary, data)}
+	}
+}
+
+// changedKeys returns the account whose keys an operation replaces and which ones
+func changedKeys(op *models.Operation) (string, []string) {
+	var keys []string
+	switch op.OpType {
+	case "account_update", "account_update2":
+		for _, field := range keyAuthorities {
+			// account_update always carries memo_key, so only account_update2 changes it on purpose
+			if field == "memo_key" && op.OpType == "account_update" {
+				continue
+			}
+			if present(op.OpData[field]) {
+				keys = append(keys, field)
+			}
+		}
+		account, _ := op.OpData["account"].(string)
+		return account, keys
+	case "recover_account":
+		account, _ := op.OpData["account_to_recover"].(string)
+		return account, []string{"owner"}
+	}
+	return "", nil
+}
+
+// present reports whether an optional op_data value is set
+func present(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case string:
+		return v != ""
+	case map[string]interface{}:
+		return len(v) > 0
+	}
+	return true
+}
+
+// payoutRound compares the receivers paid in a payout block with the previous round
+// The first round seen without a seed has nothing to compare with
+func (d *FundEventDeriver) payoutRound(op *models.Operation, paid map[string]bool) []models.FundEvent {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	switch {
+	case op.BlockNum > d.current.block:
+		d.previous, d.current = d.current, payoutRound{block: op.BlockNum, receivers: paid}
+	case op.BlockNum == d.current.block:
+		// The same round again, e.g. after a fork or restart
+		for receiver := range paid {
+			d.current.receivers[receiver] = true
+		}
+	default:
+		// Older blocks don't change the funding state
+		return nil
+	}
+	if d.previous.block == 0 {
+		return nil
+	}
+
+	var events []models.FundEvent
+	event := func(kind, receiver, summary string) models.FundEvent {
+		return models.FundEvent{
+			ID:        fmt.Sprintf("%s:%d:%s", kind, op.BlockNum, receiver),
+			Kind:      kind,
+			Account:   receiver,
+			BlockNum:  op.BlockNum,
+			Timestamp: op.Timestamp,
+			Summary:   summary,
+			Data:      map[string]interface{}{"receiver": receiver, "previous_round_block": d.previous.block},
+		}
+	}
+	for _, receiver := range sortedSet(d.current.receivers) {
+		if !d.previous.receivers[receiver] {
+			events = append(events, event(models.FundEventProposalFunded, receiver,
+				fmt.Sprintf("%s started receiving SPS fund payouts (not paid in the previous round)", receiver)))
+		}
+	}
+	for _, receiver := range sortedSet(d.previous.receivers) {
+		if !d.current.receivers[receiver] {
+			events = append(events, event(models.FundEventProposalDefunded, receiver,
+				fmt.Sprintf("%s stopped receiving SPS fund payouts (paid in the previous round)", receiver)))
+		}
+	}
+	return events
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
+
+func sortedSet(set map[string]bool) []string {
+	values := make([]string, 0, len(set))
+	for value := range set {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return values
+}
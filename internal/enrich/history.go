@@ -0,0 +1,211 @@
+package enrich
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+)
+
+// historyTimeout bounds a CoinGecko request; long ranges return one point per day, so the
+// response stays small
+const historyTimeout = 60 * time.Second
+
+// historyWindowDays is the longest range requested from CoinGecko at once; the free API
+// rejects longer ones
+const historyWindowDays = 365
+
+// marketChart is the result of CoinGecko's /coins/{id}/market_chart/range
+type marketChart struct {
+	Prices [][2]float64 `json:"prices"` // [unix milliseconds, price]
+}
+
+// HistoryAssets are the assets daily prices are kept for
+var HistoryAssets = []string{"STEEM", "SBD"}
+
+// coinID returns the CoinGecko ID of a price history asset
+func coinID(config models.PriceHistoryConfig, asset string) (string, error) {
+	switch asset {
+	case "STEEM":
+		if config.CoinID != "" {
+			return config.CoinID, nil
+		}
+		return models.DefaultPriceCoinID, nil
+	case "SBD":
+		if config.SBDCoinID != "" {
+			return config.SBDCoinID, nil
+		}
+		return models.DefaultSBDPriceCoinID, nil
+	}
+	return "", fmt.Errorf("no price history for asset %q", asset)
+}
+
+// historyBase returns the CoinGecko API base URL
+func historyBase(config models.PriceHistoryConfig) string {
+	if config.URL != "" {
+		return config.URL
+	}
+	return models.DefaultPriceHistoryURL
+}
+
+// FetchDailyPrices returns the USD price of an asset (STEEM or SBD) on each UTC day from from
+// to to from CoinGecko
+// The range is requested in windows of historyWindowDays, which CoinGecko answers with daily
+// points (hourly ones below 90 days); each day gets its first point, which is the price at
+// or just after midnight
+func FetchDailyPrices(ctx context.Context, config models.PriceHistoryConfig, asset string, from, to time.Time) ([]models.DailyPrice, error) {
+	coin, err := coinID(config, asset)
+	if err != nil {
+		return nil, err
+	}
+	base := historyBase(config)
+
+	now := time.Now().UTC()
+	var prices []models.DailyPrice
+	seen := make(map[string]bool)
+	for start := from; !start.After(to); start = start.AddDate(0, 0, historyWindowDays) {
+		end := start.AddDate(0, 0, historyWindowDays-1)
+		if end.After(to) {
+			end = to
+		}
+		points, err := fetchMarketChart(ctx, config, base, coin, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("%s to %s: %w", start.Format(time.DateOnly), end.Format(time.DateOnly), err)
+		}
+		for _, point := range points {
+			day := time.UnixMilli(int64(point[0])).UTC().Format(time.DateOnly)
+			if seen[day] || point[1] <= 0 {
+				continue
+			}
+			seen[day] = true
+			prices = append(prices, models.DailyPrice{Asset: asset, Date: day, USD: point[1], Source: models.PriceSourceCoinGecko, UpdatedAt: now})
+		}
+	}
+	return prices, nil
+}
+
+// fetchMarketChart requests the price points of a coin from the first to the end of the last day
+func fetchMarketChart(ctx context.Context, config models.PriceHistoryConfig, base, coin string, from, to time.Time) ([][2]float64, error) {
+	query := url.Values{
+		"vs_currency": {"usd"},
+		"from":        {strconv.FormatInt(from.Unix(), 10)},
+		"to":          {strconv.FormatInt(to.AddDate(0, 0, 1).Unix(), 10)},
+	}
+	endpoint := fmt.Sprintf("%s/coins/%s/market_chart/range?%s", strings.TrimSuffix(base, "/"), url.PathEscape(coin), query.Encode())
+
+	var chart marketChart
+	if err := getHistory(ctx, config, base, endpoint, &chart); err != nil {
+		return nil, err
+	}
+	return chart.Prices, nil
+}
+
+// FetchCurrentPrice returns the current USD market price of an asset (STEEM or SBD) from CoinGecko
+func FetchCurrentPrice(ctx context.Context, config models.PriceHistoryConfig, asset string) (float64, error) {
+	coin, err := coinID(config, asset)
+	if err != nil {
+		return 0, err
+	}
+	base := historyBase(config)
+	query := url.Values{"ids": {coin}, "vs_currencies": {"usd"}}
+	endpoint := fmt.Sprintf("%s/simple/price?%s", strings.TrimSuffix(base, "/"), query.Encode())
+
+	var prices map[string]struct {
+		USD float64 `json:"usd"`
+	}
+	if err := getHistory(ctx, config, base, endpoint, &prices); err != nil {
+		return 0, err
+	}
+	if prices[coin].USD <= 0 {
+		return 0, fmt.Errorf("no %s price for %s", asset, coin)
+	}
+	return prices[coin].USD, nil
+}
+
+// getHistory requests a CoinGecko endpoint and decodes its JSON result into out
+func getHistory(ctx context.Context, config models.PriceHistoryConfig, base, endpoint string, out interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, historyTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create price request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if config.APIKey != "" {
+		// Paid plans use their own host and header
+		if strings.Contains(base, "pro-api.") {
+			req.Header.Set("x-cg-pro-api-key", config.APIKey)
+		} else {
+			req.Header.Set("x-cg-demo-api-key", config.APIKey)
+		}
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch prices: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 300))
+		return fmt.Errorf("failed to fetch prices: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode prices: %w", err)
+	}
+	return nil
+}
+
+// ReadDailyPrices reads prices from CSV lines of a YYYY-MM-DD date, a USD price and optionally
+// the asset (STEEM when omitted), e.g. exported from another price service; a header line is skipped
+func ReadDailyPrices(r io.Reader) ([]models.DailyPrice, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	now := time.Now().UTC()
+	var prices []models.DailyPrice
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read prices: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		if len(record) < 2 {
+			return nil, fmt.Errorf("line %d: expected a date and a price", line)
+		}
+		date, err := time.Parse(time.DateOnly, strings.TrimSpace(record[0]))
+		if err != nil {
+			if first {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: invalid date %q (expected YYYY-MM-DD)", line, record[0])
+		}
+		usd, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil || usd <= 0 {
+			return nil, fmt.Errorf("line %d: invalid price %q", line, record[1])
+		}
+		asset := "STEEM"
+		if len(record) > 2 && strings.TrimSpace(record[2]) != "" {
+			asset = strings.ToUpper(strings.TrimSpace(record[2]))
+			if _, err := coinID(models.PriceHistoryConfig{}, asset); err != nil {
+				return nil, fmt.Errorf("line %d: invalid asset %q (expected STEEM or SBD)", line, record[2])
+			}
+		}
+		prices = append(prices, models.DailyPrice{Asset: asset, Date: date.Format(time.DateOnly), USD: usd, Source: models.PriceSourceCSV, UpdatedAt: now})
+	}
+	return prices, nil
+}
@@ -0,0 +1,117 @@
+package enrich
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	stdsync "sync"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/chain"
+	"github.com/ety001/sps-fund-watcher/internal/models"
+)
+
+// defaultPriceRefresh is how often the median price is fetched when price_refresh_minutes is unset
+const defaultPriceRefresh = 60 * time.Minute
+
+// medianPrice is the result of condenser_api.get_current_median_history_price
+type medianPrice struct {
+	Base  string `json:"base"`  // e.g. "0.250 SBD"
+	Quote string `json:"quote"` // e.g. "1.000 STEEM"
+}
+
+// priceFeed caches the USD prices of STEEM, the witnesses' median feed price, and of SBD,
+// its market price from price_history like the price backfill uses
+type priceFeed struct {
+	client  chain.Client
+	history models.PriceHistoryConfig
+	refresh time.Duration
+
+	mu        stdsync.Mutex
+	steemUSD  float64
+	sbdUSD    float64
+	fetchedAt time.Time
+}
+
+func newPriceFeed(config models.EnrichmentConfig, client chain.Client) *priceFeed {
+	refresh := defaultPriceRefresh
+	if config.PriceRefreshMinutes > 0 {
+		refresh = time.Duration(config.PriceRefreshMinutes) * time.Minute
+	}
+	return &priceFeed{client: client, history: config.PriceHistory, refresh: refresh}
+}
+
+// usdValueEnricher values STEEM and SBD amounts in USD
+// STEEM uses the witnesses' median feed price and SBD its market price, fetched when the
+// operation is stored, so backfilled operations are valued at today's prices
+type usdValueEnricher struct {
+	*priceFeed
+}
+
+func newUSDValueEnricher(config models.EnrichmentConfig, client chain.Client) (Enricher, error) {
+	if client == nil {
+		return nil, errors.New("usd_value needs a node client")
+	}
+	return &usdValueEnricher{newPriceFeed(config, client)}, nil
+}
+
+func (e *usdValueEnricher) Name() string { return models.EnricherUSDValue }
+
+func (e *usdValueEnricher) Enrich(ctx context.Context, op *models.Operation, fields map[string]interface{}) error {
+	asset, ok := models.OperationAmount(op.OpData)
+	if !ok || (asset.Symbol != "STEEM" && asset.Symbol != "SBD") {
+		return nil
+	}
+
+	prices, err := e.prices(ctx)
+	if err != nil {
+		return err
+	}
+	price := prices[asset.Symbol]
+	fields["usd_value"] = asset.Amount * price
+	fields["usd_price"] = price
+	return nil
+}
+
+// prices returns the cached STEEM and SBD prices in USD by asset, refreshing them when they
+// are older than the refresh interval
+func (f *priceFeed) prices(ctx context.Context) (map[string]float64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.steemUSD == 0 || time.Since(f.fetchedAt) >= f.refresh {
+		steemUSD, err := f.medianPrice()
+		if err != nil {
+			return nil, err
+		}
+		sbdUSD, err := FetchCurrentPrice(ctx, f.history, "SBD")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get SBD price: %w", err)
+		}
+		f.steemUSD, f.sbdUSD = steemUSD, sbdUSD
+		f.fetchedAt = time.Now()
+	}
+	return map[string]float64{"STEEM": f.steemUSD, "SBD": f.sbdUSD}, nil
+}
+
+// medianPrice returns the witnesses' median feed price of STEEM in USD
+func (f *priceFeed) medianPrice() (float64, error) {
+
+	var median medianPrice
+	if err := f.client.CallWithResult("condenser_api", "get_current_median_history_price", []interface{}{}, &median); err != nil {
+		return 0, fmt.Errorf("failed to get median price: %w", err)
+	}
+	base, err := models.ParseAsset(median.Base)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse median price: %w", err)
+	}
+	quote, err := models.ParseAsset(median.Quote)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse median price: %w", err)
+	}
+	if base.Symbol != "SBD" || quote.Symbol != "STEEM" || quote.Amount == 0 {
+		return 0, fmt.Errorf("unexpected median price %s / %s", median.Base, median.Quote)
+	}
+
+	return base.Amount / quote.Amount, nil
+}
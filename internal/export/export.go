@@ -0,0 +1,155 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+)
+
+// Export formats
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+// Writer encodes operations one at a time, so exports of any size can be streamed
+type Writer interface {
+	Write(op *models.Operation) error
+	// Flush writes buffered data; call it once after the last operation
+	Flush() error
+}
+
+// NewWriter returns a writer for the given format
+func NewWriter(w io.Writer, format string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatJSONL:
+		return &jsonlWriter{encoder: json.NewEncoder(w)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format %q (use csv or jsonl)", format)
+	}
+}
+
+// ContentType returns the MIME type of an export format
+func ContentType(format string) string {
+	if format == FormatCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/x-ndjson"
+}
+
+// jsonlWriter writes one JSON document per line
+type jsonlWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *jsonlWriter) Write(op *models.Operation) error {
+	if err := w.encoder.Encode(op); err != nil {
+		return fmt.Errorf("failed to encode operation: %w", err)
+	}
+	return nil
+}
+
+func (w *jsonlWriter) Flush() error {
+	return nil
+}
+
+// csvColumns are the CSV header; op_data is embedded as a JSON string
+var csvColumns = []string{
+	"id", "block_num", "timestamp", "trx_id", "op_in_trx", "account", "op_type",
+	"amount", "asset", "from", "to", "memo", "op_data", "source", "first_seen_at",
+}
+
+// csvCounterpartyFields are the op_data fields flattened into the from and to columns, in lookup order
+var csvCounterpartyFields = map[string][]string{
+	"from": {"from", "from_account", "owner", "account"},
+	"to":   {"to", "to_account", "receiver"},
+}
+
+// csvWriter writes a header followed by one row per operation
+type csvWriter struct {
+	writer      *csv.Writer
+	wroteHeader bool
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{writer: csv.NewWriter(w)}
+}
+
+func (w *csvWriter) Write(op *models.Operation) error {
+	if !w.wroteHeader {
+		if err := w.writer.Write(csvColumns); err != nil {
+			return fmt.Errorf("failed to write CSV header: %w", err)
+		}
+		w.wroteHeader = true
+	}
+
+	opData, err := json.Marshal(op.OpData)
+	if err != nil {
+		return fmt.Errorf("failed to encode op_data: %w", err)
+	}
+
+	// Amount columns make transfers and payments usable in spreadsheets without parsing op_data
+	var amount, asset string
+	if parsed, ok := models.OperationAmount(op.OpData); ok {
+		amount = strconv.FormatFloat(parsed.Amount, 'f', 3, 64)
+		asset = parsed.Symbol
+	}
+
+	// Counterparties and memo let fund movements be filtered without parsing op_data
+	flattened := make(map[string]string, len(csvCounterpartyFields))
+	for column, fields := range csvCounterpartyFields {
+		for _, field := range fields {
+			if value, ok := op.OpData[field].(string); ok && value != "" {
+				flattened[column] = value
+				break
+			}
+		}
+	}
+	memo, _ := op.OpData["memo"].(string)
+
+	var firstSeen string
+	if !op.FirstSeenAt.IsZero() {
+		firstSeen = op.FirstSeenAt.UTC().Format(time.RFC3339)
+	}
+
+	row := []string{
+		op.ID,
+		strconv.FormatInt(op.BlockNum, 10),
+		op.Timestamp.UTC().Format(time.RFC3339),
+		op.TrxID,
+		strconv.Itoa(op.OpInTrx),
+		op.Account,
+		op.OpType,
+		amount,
+		asset,
+		flattened["from"],
+		flattened["to"],
+		memo,
+		string(opData),
+		op.Source,
+		firstSeen,
+	}
+	if err := w.writer.Write(row); err != nil {
+		return fmt.Errorf("failed to write CSV row: %w", err)
+	}
+	return nil
+}
+
+func (w *csvWriter) Flush() error {
+	// An empty export still gets a header
+	if !w.wroteHeader {
+		if err := w.writer.Write(csvColumns); err != nil {
+			return fmt.Errorf("failed to write CSV header: %w", err)
+		}
+		w.wroteHeader = true
+	}
+	w.writer.Flush()
+	return w.writer.Error()
+}
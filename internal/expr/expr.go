@@ -0,0 +1,720 @@
+// Package expr evaluates the small expression language of computed enrichment fields.
+//
+// Grammar:
+//
+//	expr    = and { "or" and }
+//	and     = not { "and" not }
+//	not     = "not" not | compare
+//	compare = sum [ ("==" | "!=" | "<" | "<=" | ">" | ">=") sum ]
+//	sum     = product { ("+" | "-") product }
+//	product = unary { ("*" | "/" | "%") unary }
+//	unary   = "-" unary | postfix
+//	postfix = primary { "." name | "[" expr "]" }
+//	primary = number | string | true | false | null | name | name "(" [ expr { "," expr } ] ")" | "(" expr ")"
+//
+// Example: amount * price[asset]
+//
+// Values are numbers (float64), strings, booleans, null, maps and lists. A missing field, key
+// or variable value is null, and arithmetic on null is null, so an expression over fields an
+// operation doesn't have yields null instead of failing. Division by zero is null as well.
+package expr
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MaxLength is the maximum accepted length of an expression
+const MaxLength = 1000
+
+// Env resolves the variables of an expression; it is only asked for names the expression uses
+type Env func(name string) (interface{}, error)
+
+// Expr is a compiled expression
+type Expr struct {
+	root      node
+	variables []string
+}
+
+// Parse compiles an expression
+func Parse(src string) (*Expr, error) {
+	if len(src) > MaxLength {
+		return nil, fmt.Errorf("expression too long (max %d characters)", MaxLength)
+	}
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, seen: make(map[string]bool)}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.peek().text, p.peek().pos)
+	}
+	return &Expr{root: root, variables: p.variables}, nil
+}
+
+// Variables returns the variables the expression reads, in order of first use
+func (e *Expr) Variables() []string {
+	return e.variables
+}
+
+// Eval evaluates the expression with the variables of env
+func (e *Expr) Eval(env Env) (interface{}, error) {
+	return e.root.eval(env)
+}
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokString
+	tokNumber
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// tokenize splits an expression into tokens. It is separate from the lexer in
+// internal/query on purpose: there "-" starts a negative number, "." is part of a
+// field path and "=" and "~" are operators, while here "-" is arithmetic, "."
+// accesses a field of any value and comparisons need "==" so they can't be
+// mistaken for assignment
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(src) {
+		ch := src[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n':
+			i++
+		case ch == '"':
+			var sb strings.Builder
+			start := i
+			i++
+			for {
+				if i >= len(src) {
+					return nil, fmt.Errorf("unterminated string at position %d", start)
+				}
+				if src[i] == '\\' && i+1 < len(src) {
+					sb.WriteByte(src[i+1])
+					i += 2
+					continue
+				}
+				if src[i] == '"' {
+					i++
+					break
+				}
+				sb.WriteByte(src[i])
+				i++
+			}
+			tokens = append(tokens, token{kind: tokString, text: sb.String(), pos: start})
+		case ch >= '0' && ch <= '9':
+			start := i
+			for i < len(src) && (src[i] == '.' || (src[i] >= '0' && src[i] <= '9')) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: src[start:i], pos: start})
+		case ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z'):
+			start := i
+			for i < len(src) && (src[i] == '_' || (src[i] >= 'a' && src[i] <= 'z') ||
+				(src[i] >= 'A' && src[i] <= 'Z') || (src[i] >= '0' && src[i] <= '9')) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: src[start:i], pos: start})
+		case strings.ContainsRune("=!<>", rune(ch)):
+			start := i
+			i++
+			if i < len(src) && src[i] == '=' {
+				i++
+			}
+			text := src[start:i]
+			if text == "=" || text == "!" {
+				return nil, fmt.Errorf("unexpected %q at position %d (use == or !=)", text, start)
+			}
+			tokens = append(tokens, token{kind: tokOp, text: text, pos: start})
+		case strings.ContainsRune("+-*/%()[].,", rune(ch)):
+			tokens = append(tokens, token{kind: tokOp, text: string(ch), pos: i})
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", ch, i)
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens    []token
+	pos       int
+	variables []string
+	seen      map[string]bool
+}
+
+func (p *parser) done() bool  { return p.pos >= len(p.tokens) }
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() (token, error) {
+	if p.done() {
+		return token{}, fmt.Errorf("unexpected end of expression")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	return t, nil
+}
+
+// isOp reports whether the next token is one of the given operators
+func (p *parser) isOp(ops ...string) bool {
+	if p.done() || p.peek().kind != tokOp {
+		return false
+	}
+	for _, op := range ops {
+		if p.peek().text == op {
+			return true
+		}
+	}
+	return false
+}
+
+// isKeyword reports whether the next token is the given keyword (case-insensitive)
+func (p *parser) isKeyword(keyword string) bool {
+	return !p.done() && p.peek().kind == tokIdent && strings.EqualFold(p.peek().text, keyword)
+}
+
+// expect consumes the operator op
+func (p *parser) expect(op string) error {
+	t, err := p.next()
+	if err != nil {
+		return fmt.Errorf("expected %q at end of expression", op)
+	}
+	if t.kind != tokOp || t.text != op {
+		return fmt.Errorf("expected %q at position %d", op, t.pos)
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalNode{op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		p.pos++
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalNode{op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.isKeyword("not") {
+		p.pos++
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{inner: inner}, nil
+	}
+	return p.parseCompare()
+}
+
+func (p *parser) parseCompare() (node, error) {
+	left, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOp("==", "!=", "<", "<=", ">", ">=") {
+		op := p.peek().text
+		p.pos++
+		right, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		return compareNode{op: op, left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parseSum() (node, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("+", "-") {
+		op := p.peek().text
+		p.pos++
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = arithNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseProduct() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("*", "/", "%") {
+		op := p.peek().text
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = arithNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.isOp("-") {
+		p.pos++
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return arithNode{op: "-", left: literalNode{value: 0.0}, right: inner}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	n, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.isOp("."):
+			p.pos++
+			t, err := p.next()
+			if err != nil || t.kind != tokIdent {
+				return nil, fmt.Errorf("expected field name after '.'")
+			}
+			n = indexNode{target: n, key: literalNode{value: t.text}}
+		case p.isOp("["):
+			p.pos++
+			key, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			n = indexNode{target: n, key: key}
+		default:
+			return n, nil
+		}
+	}
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	switch t.kind {
+	case tokNumber:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.text, t.pos)
+		}
+		return literalNode{value: n}, nil
+	case tokString:
+		return literalNode{value: t.text}, nil
+	case tokIdent:
+		switch strings.ToLower(t.text) {
+		case "true":
+			return literalNode{value: true}, nil
+		case "false":
+			return literalNode{value: false}, nil
+		case "null":
+			return literalNode{value: nil}, nil
+		case "and", "or", "not":
+			return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+		}
+		if p.isOp("(") {
+			return p.parseCall(t)
+		}
+		if !p.seen[t.text] {
+			p.seen[t.text] = true
+			p.variables = append(p.variables, t.text)
+		}
+		return variableNode{name: t.text}, nil
+	case tokOp:
+		if t.text == "(" {
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q at position %d", name.text, name.pos)
+	}
+	p.pos++ // (
+
+	var args []node
+	if !p.isOp(")") {
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if !p.isOp(",") {
+				break
+			}
+			p.pos++
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	if len(args) < fn.minArgs || (fn.maxArgs >= 0 && len(args) > fn.maxArgs) {
+		return nil, fmt.Errorf("%s takes %s at position %d", name.text, fn.arity(), name.pos)
+	}
+	return callNode{name: name.text, fn: fn, args: args}, nil
+}
+
+// node is a compiled part of an expression
+type node interface {
+	eval(env Env) (interface{}, error)
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n literalNode) eval(Env) (interface{}, error) {
+	return n.value, nil
+}
+
+type variableNode struct {
+	name string
+}
+
+func (n variableNode) eval(env Env) (interface{}, error) {
+	value, err := env(n.name)
+	if err != nil {
+		return nil, err
+	}
+	return normalize(value), nil
+}
+
+type indexNode struct {
+	target node
+	key    node
+}
+
+func (n indexNode) eval(env Env) (interface{}, error) {
+	target, err := n.target.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	key, err := n.key.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch t := target.(type) {
+	case map[string]interface{}:
+		if k, ok := key.(string); ok {
+			return normalize(t[k]), nil
+		}
+	case []interface{}:
+		if i, ok := key.(float64); ok && i >= 0 && int(i) < len(t) && i == math.Trunc(i) {
+			return normalize(t[int(i)]), nil
+		}
+	}
+	return nil, nil
+}
+
+type notNode struct {
+	inner node
+}
+
+func (n notNode) eval(env Env) (interface{}, error) {
+	value, err := n.inner.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	return !truthy(value), nil
+}
+
+type logicalNode struct {
+	op          string
+	left, right node
+}
+
+func (n logicalNode) eval(env Env) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if truthy(left) == (n.op == "or") {
+		return n.op == "or", nil
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	return truthy(right), nil
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+func (n compareNode) eval(env Env) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	}
+	if left == nil || right == nil {
+		return false, nil
+	}
+
+	var cmp int
+	switch l := left.(type) {
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			return nil, fmt.Errorf("cannot compare number with %s", typeName(right))
+		}
+		cmp = compareFloats(l, r)
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			return nil, fmt.Errorf("cannot compare string with %s", typeName(right))
+		}
+		cmp = strings.Compare(l, r)
+	default:
+		return nil, fmt.Errorf("cannot order %s values", typeName(left))
+	}
+	switch n.op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default:
+		return cmp >= 0, nil
+	}
+}
+
+type arithNode struct {
+	op          string
+	left, right node
+}
+
+func (n arithNode) eval(env Env) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if left == nil || right == nil {
+		return nil, nil
+	}
+
+	// + joins strings
+	if ls, ok := left.(string); ok && n.op == "+" {
+		if rs, ok := right.(string); ok {
+			return ls + rs, nil
+		}
+	}
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+	if !lok || !rok {
+		return nil, fmt.Errorf("cannot apply %s to %s and %s (use number() for amounts like \"1.000 STEEM\")", n.op, typeName(left), typeName(right))
+	}
+	switch n.op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return nil, nil
+		}
+		return l / r, nil
+	default:
+		if r == 0 {
+			return nil, nil
+		}
+		return math.Mod(l, r), nil
+	}
+}
+
+type callNode struct {
+	name string
+	fn   function
+	args []node
+}
+
+func (n callNode) eval(env Env) (interface{}, error) {
+	args := make([]interface{}, len(n.args))
+	for i, arg := range n.args {
+		value, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+	value, err := n.fn.call(args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", n.name, err)
+	}
+	return value, nil
+}
+
+// normalize converts the numbers of decoded documents (ints of YAML and BSON, json.Number) to float64
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case interface{ Float64() (float64, error) }:
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+	case map[string]string:
+		converted := make(map[string]interface{}, len(v))
+		for key, s := range v {
+			converted[key] = s
+		}
+		return converted
+	case map[string]float64:
+		converted := make(map[string]interface{}, len(v))
+		for key, f := range v {
+			converted[key] = f
+		}
+		return converted
+	case []string:
+		converted := make([]interface{}, len(v))
+		for i, s := range v {
+			converted[i] = s
+		}
+		return converted
+	}
+	return value
+}
+
+// truthy reports whether a value counts as true: false, null, 0 and "" don't
+func truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	}
+	return true
+}
+
+// equal compares scalar values; maps and lists are never equal
+func equal(left, right interface{}) bool {
+	switch left.(type) {
+	case nil, float64, string, bool:
+		return left == right
+	}
+	return false
+}
+
+func compareFloats(l, r float64) int {
+	switch {
+	case l < r:
+		return -1
+	case l > r:
+		return 1
+	}
+	return 0
+}
+
+// typeName names the type of a value in error messages
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "list"
+	}
+	return fmt.Sprintf("%T", value)
+}
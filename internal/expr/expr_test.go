@@ -0,0 +1,157 @@
+package expr
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// mapEnv resolves variables from a map; unknown names are null
+func mapEnv(vars map[string]interface{}) Env {
+	return func(name string) (interface{}, error) {
+		return vars[name], nil
+	}
+}
+
+func TestEval(t *testing.T) {
+	vars := map[string]interface{}{
+		"amount":  2.5,
+		"count":   3,
+		"asset":   "STEEM",
+		"price":   map[string]float64{"STEEM": 0.2, "SBD": 1},
+		"op_data": map[string]interface{}{"amount": "10.000 SBD", "to": "steem.dao", "tags": []interface{}{"a", "b"}},
+		"list":    []string{"x", "y"},
+	}
+	tests := []struct {
+		src     string
+		want    interface{}
+		wantErr string
+	}{
+		// Precedence and associativity
+		{src: "1 + 2 * 3", want: 7.0},
+		{src: "(1 + 2) * 3", want: 9.0},
+		{src: "10 - 4 - 3", want: 3.0},
+		{src: "12 / 3 / 2", want: 2.0},
+		{src: "7 % 4 + 1", want: 4.0},
+		{src: "1 + 2 > 2 and 3 < 2 or true", want: true},
+		{src: "not 1 == 2", want: true},
+		{src: "not true or true", want: true},
+
+		// Unary minus
+		{src: "-2 * 3", want: -6.0},
+		{src: "--2", want: 2.0},
+		{src: "2 - -3", want: 5.0},
+		{src: "-amount", want: -2.5},
+		{src: "-(1 + 2) * 2", want: -6.0},
+
+		// Variables, fields and indexing
+		{src: "amount * price[asset]", want: 0.5},
+		{src: "count + 1", want: 4.0},
+		{src: "op_data.to", want: "steem.dao"},
+		{src: `op_data["to"] == "steem.dao"`, want: true},
+		{src: "op_data.tags[1]", want: "b"},
+		{src: "list[0] + list[1]", want: "xy"},
+
+		// Missing values and nulls
+		{src: "missing", want: nil},
+		{src: "op_data.missing", want: nil},
+		{src: "price.HIVE * amount", want: nil},
+		{src: "op_data.tags[2]", want: nil},
+		{src: "op_data.tags[-1]", want: nil},
+		{src: "op_data.tags[0.5]", want: nil},
+		{src: "missing.deeper[0]", want: nil},
+		{src: "null + 1", want: nil},
+		{src: "-missing", want: nil},
+		{src: "missing == null", want: true},
+		{src: "missing > 1", want: false},
+		{src: "coalesce(missing, op_data.missing, 5)", want: 5.0},
+		{src: "round(missing)", want: nil},
+
+		// Division by zero
+		{src: "1 / 0", want: nil},
+		{src: "5 % 0", want: nil},
+		{src: "coalesce(amount / 0, 0)", want: 0.0},
+
+		// Functions
+		{src: "number(op_data.amount) * 2", want: 20.0},
+		{src: `number("abc")`, want: nil},
+		{src: "round(2.345, 2)", want: 2.35},
+		{src: "min(3, missing, 1, 2)", want: 1.0},
+		{src: "max(3, 1, 2)", want: 3.0},
+		{src: `upper("steem") + lower("SBD")`, want: "STEEMsbd"},
+		{src: `contains(op_data.tags, "a")`, want: true},
+		{src: `contains(op_data.to, "dao")`, want: true},
+		{src: "len(op_data.tags)", want: 2.0},
+		{src: `if(amount > 1, "large", "small")`, want: "large"},
+
+		// Type errors
+		{src: `"a" * 2`, wantErr: "cannot apply *"},
+		{src: `op_data.amount + 1`, wantErr: "use number()"},
+		{src: `"a" < 1`, wantErr: "cannot compare string with number"},
+		{src: `abs("x")`, wantErr: "abs: expected a number"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			e, err := Parse(tt.src)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			got, err := e.Eval(mapEnv(vars))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Eval error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Eval: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Eval = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src     string
+		wantErr string
+	}{
+		{src: "round()", wantErr: "round takes 1 to 2 arguments"},
+		{src: "round(1, 2, 3)", wantErr: "round takes 1 to 2 arguments"},
+		{src: "abs(1, 2)", wantErr: "abs takes 1 argument"},
+		{src: "if(true, 1)", wantErr: "if takes 3 arguments"},
+		{src: "min()", wantErr: "min takes at least 1 arguments"},
+		{src: "sqrt(4)", wantErr: `unknown function "sqrt"`},
+		{src: "amount = 1", wantErr: "use == or !="},
+		{src: "1 +", wantErr: "unexpected end of expression"},
+		{src: "(1 + 2", wantErr: `expected ")"`},
+		{src: "list[0", wantErr: `expected "]"`},
+		{src: "op_data.", wantErr: "expected field name"},
+		{src: "1 2", wantErr: `unexpected "2"`},
+		{src: "and", wantErr: `unexpected "and"`},
+		{src: `"open`, wantErr: "unterminated string"},
+		{src: "amount $ 2", wantErr: "unexpected character"},
+		{src: strings.Repeat("1+", MaxLength) + "1", wantErr: "expression too long"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := Parse(tt.src)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVariables(t *testing.T) {
+	e, err := Parse("amount * price[asset] + amount + number(op_data.amount)")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got, want := e.Variables(), []string{"amount", "price", "asset", "op_data"}; !slices.Equal(got, want) {
+		t.Errorf("Variables = %v, want %v", got, want)
+	}
+}
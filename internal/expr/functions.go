@@ -0,0 +1,193 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// function is a built-in function; maxArgs -1 means any number of arguments
+type function struct {
+	minArgs, maxArgs int
+	call             func(args []interface{}) (interface{}, error)
+}
+
+func (f function) arity() string {
+	switch {
+	case f.maxArgs < 0:
+		return fmt.Sprintf("at least %d arguments", f.minArgs)
+	case f.minArgs == f.maxArgs && f.minArgs == 1:
+		return "1 argument"
+	case f.minArgs == f.maxArgs:
+		return fmt.Sprintf("%d arguments", f.minArgs)
+	}
+	return fmt.Sprintf("%d to %d arguments", f.minArgs, f.maxArgs)
+}
+
+// functions are the built-in functions by name
+var functions = map[string]function{
+	"number":   {1, 1, fnNumber},
+	"abs":      {1, 1, numeric(math.Abs)},
+	"floor":    {1, 1, numeric(math.Floor)},
+	"ceil":     {1, 1, numeric(math.Ceil)},
+	"round":    {1, 2, fnRound},
+	"min":      {1, -1, extreme(-1)},
+	"max":      {1, -1, extreme(1)},
+	"lower":    {1, 1, text(strings.ToLower)},
+	"upper":    {1, 1, text(strings.ToUpper)},
+	"contains": {2, 2, fnContains},
+	"len":      {1, 1, fnLen},
+	"if":       {3, 3, fnIf},
+	"coalesce": {1, -1, fnCoalesce},
+}
+
+// Functions returns the names of the built-in functions
+func Functions() []string {
+	names := make([]string, 0, len(functions))
+	for name := range functions {
+		names = append(names, name)
+	}
+	return names
+}
+
+// fnNumber converts a value to a number; strings use their leading token, so "1.000 STEEM" is 1
+func fnNumber(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case float64:
+		return v, nil
+	case string:
+		field, _, _ := strings.Cut(strings.TrimSpace(v), " ")
+		if n, err := strconv.ParseFloat(field, 64); err == nil {
+			return n, nil
+		}
+	case bool:
+		if v {
+			return 1.0, nil
+		}
+		return 0.0, nil
+	}
+	return nil, nil
+}
+
+// numeric wraps a function of one number; null stays null
+func numeric(fn func(float64) float64) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		if args[0] == nil {
+			return nil, nil
+		}
+		n, ok := args[0].(float64)
+		if !ok {
+			return nil, fmt.Errorf("expected a number, got %s", typeName(args[0]))
+		}
+		return fn(n), nil
+	}
+}
+
+// fnRound rounds to the given number of decimals (default 0)
+func fnRound(args []interface{}) (interface{}, error) {
+	if args[0] == nil {
+		return nil, nil
+	}
+	n, ok := args[0].(float64)
+	if !ok {
+		return nil, fmt.Errorf("expected a number, got %s", typeName(args[0]))
+	}
+	decimals := 0.0
+	if len(args) == 2 {
+		if decimals, ok = args[1].(float64); !ok {
+			return nil, fmt.Errorf("expected a number of decimals, got %s", typeName(args[1]))
+		}
+	}
+	scale := math.Pow(10, decimals)
+	return math.Round(n*scale) / scale, nil
+}
+
+// extreme returns min (sign -1) or max (sign 1) of its numeric arguments, ignoring nulls
+func extreme(sign float64) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		var result interface{}
+		for _, arg := range args {
+			if arg == nil {
+				continue
+			}
+			n, ok := arg.(float64)
+			if !ok {
+				return nil, fmt.Errorf("expected numbers, got %s", typeName(arg))
+			}
+			if result == nil || (n-result.(float64))*sign > 0 {
+				result = n
+			}
+		}
+		return result, nil
+	}
+}
+
+// text wraps a function of one string; null stays null
+func text(fn func(string) string) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		if args[0] == nil {
+			return nil, nil
+		}
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string, got %s", typeName(args[0]))
+		}
+		return fn(s), nil
+	}
+}
+
+// fnContains reports whether a string contains a substring or a list contains a value
+func fnContains(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case nil:
+		return false, nil
+	case string:
+		sub, ok := args[1].(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string to look for, got %s", typeName(args[1]))
+		}
+		return strings.Contains(v, sub), nil
+	case []interface{}:
+		for _, item := range v {
+			if equal(normalize(item), args[1]) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return nil, fmt.Errorf("expected a string or list, got %s", typeName(args[0]))
+}
+
+// fnLen returns the length of a string, list or object
+func fnLen(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return float64(len(v)), nil
+	case []interface{}:
+		return float64(len(v)), nil
+	case map[string]interface{}:
+		return float64(len(v)), nil
+	}
+	return nil, fmt.Errorf("expected a string, list or object, got %s", typeName(args[0]))
+}
+
+// fnIf returns its second argument when the first is true, else its third
+func fnIf(args []interface{}) (interface{}, error) {
+	if truthy(args[0]) {
+		return args[1], nil
+	}
+	return args[2], nil
+}
+
+// fnCoalesce returns its first non-null argument
+func fnCoalesce(args []interface{}) (interface{}, error) {
+	for _, arg := range args {
+		if arg != nil {
+			return arg, nil
+		}
+	}
+	return nil, nil
+}
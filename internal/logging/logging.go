@@ -0,0 +1,78 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+)
+
+// Setup installs the default logger for the configured level and format
+// Output of the standard log package goes through it as well, at info level
+func Setup(config models.LoggingConfig) {
+	level := slog.LevelInfo
+	if config.Level != "" {
+		// Invalid levels are rejected by config validation
+		_ = level.UnmarshalText([]byte(config.Level))
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	if config.Format == models.LogFormatJSON {
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	} else {
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	}
+	slog.SetDefault(slog.New(handler))
+}
+
+// SetLabels adds the instance labels to every record of the default logger
+func SetLabels(labels models.InstanceLabels) {
+	logger := slog.Default().With("instance", labels.Instance)
+	if labels.Profile != "" {
+		logger = logger.With("profile", labels.Profile)
+	}
+	slog.SetDefault(logger)
+}
+
+// Component returns a logger that tags records with a component field
+// It follows the current default logger, so package-level loggers created before Setup pick up its settings
+func Component(name string) *slog.Logger {
+	return slog.New(&defaultHandler{}).With("component", name)
+}
+
+// defaultHandler forwards records to the handler of the current default logger
+type defaultHandler struct {
+	steps []func(slog.Handler) slog.Handler // WithAttrs/WithGroup calls, replayed in order
+}
+
+func (h *defaultHandler) target() slog.Handler {
+	handler := slog.Default().Handler()
+	for _, step := range h.steps {
+		handler = step(handler)
+	}
+	return handler
+}
+
+func (h *defaultHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return slog.Default().Handler().Enabled(ctx, level)
+}
+
+func (h *defaultHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.target().Handle(ctx, record)
+}
+
+func (h *defaultHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithAttrs(attrs) })
+}
+
+func (h *defaultHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithGroup(name) })
+}
+
+func (h *defaultHandler) with(step func(slog.Handler) slog.Handler) slog.Handler {
+	steps := make([]func(slog.Handler) slog.Handler, len(h.steps), len(h.steps)+1)
+	copy(steps, h.steps)
+	return &defaultHandler{steps: append(steps, step)}
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// AccountMetadata holds operator-maintained information about an account
+type AccountMetadata struct {
+	Account string `bson:"_id" json:"account"`
+	Alias   string `bson:"alias" json:"alias,omitempty"` // Display name on public pages such as the donor leaderboard
+	OptOut  bool   `bson:"opt_out" json:"opt_out"`       // Leave the account off public leaderboards
+	Note    string `bson:"note" json:"note,omitempty"`   // Free-form, not shown publicly
+
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// Donor is one sender's total direct transfers into the treasury in one asset
+type Donor struct {
+	Rank         int       `bson:"-" json:"rank"`
+	Account      string    `bson:"account" json:"account"`
+	DisplayName  string    `bson:"-" json:"display_name"` // Alias from the account metadata, or the account
+	Symbol       string    `bson:"symbol" json:"symbol"`
+	Amount       float64   `bson:"amount" json:"amount"`
+	Count        int64     `bson:"count" json:"count"`
+	LastDonation time.Time `bson:"last_donation" json:"last_donation"`
+}
+
+// DonorLeaderboard ranks the treasury's donors over a period
+type DonorLeaderboard struct {
+	Treasury string     `json:"treasury"`
+	Period   string     `json:"period"`
+	From     *time.Time `json:"from,omitempty"`
+	To       time.Time  `json:"to"`
+	Symbol   string     `json:"symbol"`
+	Donors   []Donor    `json:"donors"`
+}
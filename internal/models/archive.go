@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// ArchiveConfig controls whether the sync service keeps the raw operations of fetched blocks
+// Archived blocks can be replayed by the reprocess command, e.g. after adding enrichers,
+// account paths or accounts, without fetching the blocks from a node again
+type ArchiveConfig struct {
+	// "off" (default), "blocks" to keep every block with operations, or "matched" to keep only
+	// blocks with operations of tracked accounts
+	Mode string `yaml:"mode"`
+}
+
+// Archive modes
+const (
+	ArchiveModeOff     = "off"
+	ArchiveModeBlocks  = "blocks"
+	ArchiveModeMatched = "matched"
+)
+
+// Enabled reports whether blocks are archived
+func (a ArchiveConfig) Enabled() bool {
+	return a.Mode != "" && a.Mode != ArchiveModeOff
+}
+
+// ArchivedBlock is the raw get_ops_in_block result of a block
+// Whole blocks are kept even in "matched" mode, because stored operations are identified by
+// their position in the block
+type ArchivedBlock struct {
+	BlockNum int64 `bson:"block_num" json:"block_num"`
+	// Header of blocks with matches when archived; empty for the others
+	BlockID   string    `bson:"block_id,omitempty" json:"block_id,omitempty"`
+	Witness   string    `bson:"witness,omitempty" json:"witness,omitempty"`
+	Timestamp time.Time `bson:"timestamp" json:"timestamp"`
+	// Operations as returned by the node, a JSON array of operation objects
+	Operations string    `bson:"operations" json:"operations"`
+	ArchivedAt time.Time `bson:"archived_at" json:"archived_at"`
+}
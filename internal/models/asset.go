@@ -0,0 +1,52 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Asset is a parsed chain amount such as "1000.000 STEEM"
+type Asset struct {
+	Amount float64
+	Symbol string
+}
+
+// ParseAsset parses an amount string in "<number> <SYMBOL>" form
+func ParseAsset(s string) (Asset, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return Asset{}, fmt.Errorf("invalid asset %q", s)
+	}
+
+	amount, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return Asset{}, fmt.Errorf("invalid asset amount %q: %w", s, err)
+	}
+
+	return Asset{Amount: amount, Symbol: strings.ToUpper(fields[1])}, nil
+}
+
+// String formats the asset with the chain's 3-decimal precision
+func (a Asset) String() string {
+	return fmt.Sprintf("%.3f %s", a.Amount, a.Symbol)
+}
+
+// amountFields lists op_data fields that carry the moved amount, in lookup order
+var amountFields = []string{"amount", "payment", "additional_funds"}
+
+// OperationAmount returns the amount moved by an operation (transfer amount, proposal payment, ...)
+func OperationAmount(opData map[string]interface{}) (Asset, bool) {
+	for _, field := range amountFields {
+		raw, ok := opData[field].(string)
+		if !ok {
+			continue
+		}
+		asset, err := ParseAsset(raw)
+		if err != nil {
+			continue
+		}
+		return asset, true
+	}
+	return Asset{}, false
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// AutoTrackedAccount is the receiver of a funded proposal that is tracked automatically while the
+// proposal runs (reconciliation.auto_track_receivers)
+type AutoTrackedAccount struct {
+	Account   string    `bson:"_id" json:"account"`
+	Proposals []int64   `bson:"proposals" json:"proposals"` // IDs of the active proposals paying it
+	Until     time.Time `bson:"until" json:"until"`         // End of the last of them
+	AddedAt   time.Time `bson:"added_at" json:"added_at"`
+}
@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// balanceEffect is an op_data amount that moves the liquid balance of the account in another field
+type balanceEffect struct {
+	party  string // Field naming the account; empty for the treasury, which virtual operations leave implicit
+	amount string
+	sign   float64
+}
+
+// balanceEffects lists the liquid balance changes of each operation type
+// Market order cancellations and escrow approval fees carry no amount and are not covered
+var balanceEffects = map[string][]balanceEffect{
+	"transfer":                       {{"from", "amount", -1}, {"to", "amount", 1}},
+	"transfer_to_vesting":            {{"from", "amount", -1}},
+	"fill_vesting_withdraw":          {{"to_account", "deposited", 1}},
+	"transfer_to_savings":            {{"from", "amount", -1}},
+	"fill_transfer_from_savings":     {{"to", "amount", 1}},
+	"interest":                       {{"owner", "interest", 1}},
+	"convert":                        {{"owner", "amount", -1}},
+	"fill_convert_request":           {{"owner", "amount_out", 1}},
+	"claim_reward_balance":           {{"account", "reward_steem", 1}, {"account", "reward_sbd", 1}},
+	"limit_order_create":             {{"owner", "amount_to_sell", -1}},
+	"fill_order":                     {{"current_owner", "open_pays", 1}, {"open_owner", "current_pays", 1}},
+	"escrow_transfer":                {{"from", "steem_amount", -1}, {"from", "sbd_amount", -1}, {"from", "fee", -1}},
+	"escrow_release":                 {{"receiver", "steem_amount", 1}, {"receiver", "sbd_amount", 1}},
+	"account_create":                 {{"creator", "fee", -1}},
+	"account_create_with_delegation": {{"creator", "fee", -1}},
+	"proposal_pay":                   {{"receiver", "payment", 1}, {"", "payment", -1}},
+	"sps_fund":                       {{"", "additional_funds", 1}},
+	"sps_convert":                    {{"fund_account", "amount_in", -1}, {"fund_account", "amount_out", 1}},
+}
+
+// BalanceChanges returns how an operation changed the liquid STEEM and SBD balances of the
+// account it is stored for, by asset symbol; nil when it moved none
+func BalanceChanges(op *Operation) map[string]float64 {
+	var changes map[string]float64
+	for _, effect := range balanceEffects[op.OpType] {
+		party := TreasuryAccount
+		if effect.party != "" {
+			party, _ = op.OpData[effect.party].(string)
+		}
+		if party != op.Account {
+			continue
+		}
+		raw, _ := op.OpData[effect.amount].(string)
+		asset, err := ParseAsset(raw)
+		if err != nil || (asset.Symbol != "STEEM" && asset.Symbol != "SBD") {
+			continue
+		}
+		if changes == nil {
+			changes = make(map[string]float64)
+		}
+		changes[asset.Symbol] += effect.sign * asset.Amount
+	}
+	return changes
+}
+
+// BalanceMismatch is a difference between the chain balance change of an account between two
+// balance snapshots and the change explained by its stored operations
+type BalanceMismatch struct {
+	Account    string    `json:"account"`
+	Symbol     string    `json:"symbol"`
+	StartBlock int64     `json:"start_block"` // First block of the checked range
+	EndBlock   int64     `json:"end_block"`   // Block the later snapshot was taken at
+	Chain      float64   `json:"chain"`       // Balance change on chain
+	Stored     float64   `json:"stored"`      // Sum of the stored operations
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"`
+}
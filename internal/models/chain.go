@@ -0,0 +1,81 @@
+package models
+
+import "path/filepath"
+
+// DefaultChainName labels the chain in steem when further chains are configured
+const DefaultChainName = "steem"
+
+// ChainConfig is a chain watched next to the one in steem, e.g. Hive
+// Settings not listed here (batch size, sync mode, sampling, Telegram rules, ...) are shared with steem
+type ChainConfig struct {
+	Name       string   `yaml:"name"` // Label in storage, the API and notifications
+	APIURL     string   `yaml:"api_url"`
+	StartBlock int64    `yaml:"start_block"`
+	Accounts   []string `yaml:"accounts"`
+	Database   string   `yaml:"database"` // Default <mongodb.database>_<name>
+	// Block explorer of notification buttons; the Steem explorers of telegram.explorer don't apply,
+	// so without it notifications have no buttons
+	Explorer ExplorerConfig `yaml:"explorer"`
+}
+
+// DatabaseName returns the MongoDB database of the chain
+func (c ChainConfig) DatabaseName(base string) string {
+	if c.Database != "" {
+		return c.Database
+	}
+	return base + "_" + c.Name
+}
+
+// ChainName returns the label of the chain in steem, or "" when only one chain is watched and
+// no label was set
+func (c *Config) ChainName() string {
+	if c.Steem.Chain == "" && len(c.Chains) > 0 {
+		return DefaultChainName
+	}
+	return c.Steem.Chain
+}
+
+// ChainConfigs returns the configuration of each watched chain: the one in steem first, then one
+// per entry of chains
+func (c *Config) ChainConfigs() []*Config {
+	if len(c.Chains) == 0 {
+		return []*Config{c}
+	}
+	primary := *c
+	primary.Steem.Chain = c.ChainName()
+	configs := []*Config{&primary}
+	for _, chain := range c.Chains {
+		configs = append(configs, c.ForChain(chain))
+	}
+	return configs
+}
+
+// FindChain returns the configuration of the chain with the given label
+func (c *Config) FindChain(name string) (*Config, bool) {
+	for _, config := range c.ChainConfigs() {
+		if config.Steem.Chain == name {
+			return config, true
+		}
+	}
+	return nil, false
+}
+
+// ForChain derives the configuration of a chain in chains from the shared settings
+// Treasury reports, activity alerts and bot commands concern the chain in steem, so they are off
+func (c *Config) ForChain(chain ChainConfig) *Config {
+	derived := *c
+	derived.Chains = nil
+	derived.Steem.Chain = chain.Name
+	derived.Steem.APIURL = chain.APIURL
+	derived.Steem.StartBlock = chain.StartBlock
+	derived.Steem.Accounts = chain.Accounts
+	derived.MongoDB.Database = chain.DatabaseName(c.MongoDB.Database)
+	if c.MongoDB.SpoolDir != "" {
+		derived.MongoDB.SpoolDir = filepath.Join(c.MongoDB.SpoolDir, chain.Name)
+	}
+	derived.Telegram.Explorer = chain.Explorer
+	derived.Reconciliation = ReconciliationConfig{}
+	derived.ActivityAlerts = nil
+	derived.Telegram.Commands.Enabled = false
+	return &derived
+}
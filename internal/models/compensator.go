@@ -0,0 +1,27 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CompensatorJob records the progress of a compensator run so it can be resumed
+type CompensatorJob struct {
+	ID                 string    `bson:"_id" json:"id"` // Derived from accounts and block range
+	Accounts           []string  `bson:"accounts" json:"accounts"`
+	StartBlock         int64     `bson:"start_block" json:"start_block"`
+	EndBlock           int64     `bson:"end_block" json:"end_block"`
+	LastProcessedBlock int64     `bson:"last_processed_block" json:"last_processed_block"`
+	Operations         int64     `bson:"operations" json:"operations"` // Operations saved so far
+	Completed          bool      `bson:"completed" json:"completed"`
+	Scheduled          bool      `bson:"scheduled,omitempty" json:"scheduled,omitempty"` // Queued by the sync service for compensator -scheduled
+	Reason             string    `bson:"reason,omitempty" json:"reason,omitempty"`       // Why a scheduled job was queued
+	StartedAt          time.Time `bson:"started_at" json:"started_at"`
+	UpdatedAt          time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// CompensatorJobID identifies the job compensating accounts over [startBlock, endBlock]
+func CompensatorJobID(accounts []string, startBlock, endBlock int64) string {
+	return fmt.Sprintf("%s:%d-%d", strings.Join(accounts, ","), startBlock, endBlock)
+}
@@ -0,0 +1,18 @@
+package models
+
+// ComputedField is an enrichment field whose value is computed by an expression, e.g.
+// usd_value: amount * price[asset]
+// A null result (e.g. from fields the operation doesn't have) leaves the field out
+type ComputedField struct {
+	Name       string   `yaml:"name"`
+	Expression string   `yaml:"expression"`
+	Operations []string `yaml:"operations"` // Operation types the field is computed for; empty means all
+}
+
+// ComputedFieldVariables are the variables available to computed field expressions besides the
+// enrichment tables:
+//   - account, op_type, block_num and trx_id of the operation
+//   - op_data and enrichment, the enrichment fields set so far
+//   - amount and asset parsed from the operation's amount, payment or additional funds
+//   - price, the USD price by asset: 1 for SBD, the median feed price for STEEM
+var ComputedFieldVariables = []string{"account", "op_type", "block_num", "trx_id", "op_data", "enrichment", "amount", "asset", "price"}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// MaskSecret hides all but the last 4 characters of a secret
+func MaskSecret(secret string) string {
+	if secret == "" {
+		return "(not set)"
+	}
+	if len(secret) <= 4 {
+		return "****"
+	}
+	return "****" + secret[len(secret)-4:]
+}
+
+// MaskURI hides the password part of a connection URI
+func MaskURI(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.User == nil {
+		return uri
+	}
+	if _, hasPassword := u.User.Password(); hasPassword {
+		u.User = url.UserPassword(u.User.Username(), "****")
+	}
+	return u.String()
+}
+
+// Summary returns the effective configuration as human-readable lines with secrets masked
+func (c *Config) Summary() []string {
+	syncMode := c.Steem.SyncMode
+	if syncMode == "" {
+		syncMode = SyncModeIrreversible
+	}
+
+	users, _ := NormalizeTelegramConfig(&c.Telegram)
+
+	return []string{
+		fmt.Sprintf("instance.name=%s profile=%s", c.Instance.Labels().Instance, c.Instance.Profile),
+		fmt.Sprintf("steem.api_url=%s", c.Steem.APIURL),
+		fmt.Sprintf("steem.start_block=%d batch_size=%d rpc_batch_size=%d fetch_workers=%d process_workers=%d sync_mode=%s",
+			c.Steem.StartBlock, c.Steem.BatchSize, c.Steem.RPCBatchSize, c.Steem.FetchWorkers, c.Steem.ProcessWorkers, syncMode),
+		fmt.Sprintf("steem.accounts=%v", c.Steem.Accounts),
+		fmt.Sprintf("steem.fallback_extraction.mode=%s fields=%v", c.Steem.FallbackExtraction.ModeOrDefault(), c.Steem.FallbackExtraction.FieldsOrDefault()),
+		fmt.Sprintf("steem.requests.user_agent=%q headers=%v rps=%g budgets=%d",
+			c.Steem.Requests.UserAgentOrDefault(), sortedKeys(c.Steem.Requests.Headers), c.Steem.Requests.RPS, len(c.Steem.Requests.Budgets)),
+		fmt.Sprintf("mongodb.uri=%s database=%s", MaskURI(c.MongoDB.URI), c.MongoDB.Database),
+		fmt.Sprintf("telegram.enabled=%t bot_token=%s channel_id=%s rules=%d proxy=%s",
+			c.Telegram.Enabled, MaskSecret(c.Telegram.BotToken), c.Telegram.ChannelID, len(users), MaskURI(c.Telegram.Proxy)),
+		fmt.Sprintf("api.listen=%s:%s prefix=%s admin_token=%s", c.API.Host, c.API.Port, c.API.RoutePrefix(), MaskSecret(c.API.AdminToken)),
+		fmt.Sprintf("error_reporting.enabled=%t environment=%s", c.ErrorReporting.SentryDSN != "", c.ErrorReporting.Environment),
+	}
+}
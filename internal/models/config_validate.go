@@ -0,0 +1,656 @@
+package models
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/expr"
+)
+
+// MessageTemplateVariables are the placeholders supported in Telegram message templates
+var MessageTemplateVariables = []string{"{{.Account}}", "{{.OpType}}", "{{.BlockNum}}", "{{.Timestamp}}", "{{.Details}}"}
+
+// Limits for sanity checks
+const (
+	maxBatchSize    = 1000 // Larger get_ops_in_block ranges time out on public nodes
+	maxFetchWorkers = 32
+	minLeaseSeconds = 6 // The lease is renewed every third of its duration
+)
+
+// accountSegment is one dot-separated part of a Steem account name
+var accountSegment = regexp.MustCompile(`^[a-z][a-z0-9-]*[a-z0-9]$`)
+
+// chainName is a chain label, used in API paths and database names
+var chainName = regexp.MustCompile(`^[a-z][a-z0-9-]{0,31}$`)
+
+// computedFieldName is the name of a computed enrichment field
+var computedFieldName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// templatePlaceholder finds {{...}} placeholders in message templates
+var templatePlaceholder = regexp.MustCompile(`{{[^}]*}}`)
+
+// ValidationError lists every problem found in a configuration
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid configuration (%d problems):\n  - %s", len(e.Problems), strings.Join(e.Problems, "\n  - "))
+}
+
+// validator collects problems instead of stopping at the first one
+type validator struct {
+	problems []string
+}
+
+func (v *validator) addf(format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+// ValidateAccountName checks a name against the Steem account naming rules
+func ValidateAccountName(name string) error {
+	if len(name) < 3 || len(name) > 16 {
+		return fmt.Errorf("account name %q must be 3 to 16 characters long", name)
+	}
+	for _, segment := range strings.Split(name, ".") {
+		if len(segment) < 3 || !accountSegment.MatchString(segment) {
+			return fmt.Errorf("account name %q is invalid: each dot-separated part needs 3+ lowercase letters, digits or dashes, starting with a letter and not ending with a dash", name)
+		}
+	}
+	return nil
+}
+
+func (v *validator) accounts(field string, accounts []string) {
+	for _, account := range accounts {
+		if err := ValidateAccountName(account); err != nil {
+			v.addf("%s: %v", field, err)
+		}
+	}
+}
+
+func (v *validator) url(field, raw string, schemes ...string) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		v.addf("%s: %q is not a valid URL", field, raw)
+		return
+	}
+	for _, scheme := range schemes {
+		if u.Scheme == scheme {
+			return
+		}
+	}
+	v.addf("%s: %q must use one of the schemes %s", field, raw, strings.Join(schemes, ", "))
+}
+
+func (v *validator) listenAddr(field, addr string) {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		v.addf("%s: %q is not a valid host:port address", field, addr)
+	}
+}
+
+func (v *validator) explorer(field string, explorer ExplorerConfig) {
+	if _, ok := explorerPresets[explorer.Preset]; explorer.Preset != "" && !ok {
+		v.addf("%s.preset must be %q or %q (got %q)", field, ExplorerSteemWorld, ExplorerSteemDB, explorer.Preset)
+	}
+	urls := map[string]string{"block_url": explorer.BlockURL, "tx_url": explorer.TxURL, "account_url": explorer.AccountURL}
+	for _, name := range sortedKeys(urls) {
+		if raw := urls[name]; raw != "" {
+			v.url(field+"."+name, raw, "http", "https")
+		}
+	}
+}
+
+func (v *validator) retryPolicy(field string, policy RetryPolicy) {
+	v.nonNegative(field+".max_attempts", int64(policy.MaxAttempts))
+	if policy.BackoffSeconds < 0 {
+		v.addf("%s.backoff_seconds must not be negative (got %g)", field, policy.BackoffSeconds)
+	}
+	if policy.MaxBackoffSeconds < 0 {
+		v.addf("%s.max_backoff_seconds must not be negative (got %g)", field, policy.MaxBackoffSeconds)
+	}
+	if policy.Multiplier < 1 {
+		v.addf("%s.multiplier must be at least 1 (got %g)", field, policy.Multiplier)
+	}
+	if policy.Jitter < 0 || policy.Jitter > 1 {
+		v.addf("%s.jitter must be between 0 and 1 (got %g)", field, policy.Jitter)
+	}
+	if policy.GiveUp != "" && policy.GiveUp != GiveUpDeadLetter && policy.GiveUp != GiveUpDrop {
+		v.addf("%s.give_up must be %q or %q (got %q)", field, GiveUpDeadLetter, GiveUpDrop, policy.GiveUp)
+	}
+}
+
+func (v *validator) nonNegative(field string, value int64) {
+	if value < 0 {
+		v.addf("%s must not be negative (got %d)", field, value)
+	}
+}
+
+func (v *validator) fundEventKinds(field string, kinds []string) {
+	for _, kind := range kinds {
+		if !slices.Contains(FundEventKinds, kind) {
+			v.addf("%s: unknown fund event kind %q (supported: %s)", field, kind, strings.Join(FundEventKinds, ", "))
+		}
+	}
+}
+
+func (v *validator) template(field, template string) {
+	for _, problem := range TemplateProblems(template) {
+		v.addf("%s: %s", field, problem)
+	}
+}
+
+// computedFields checks the computed enrichment fields and the tables they read
+func (v *validator) computedFields(config EnrichmentConfig) {
+	for _, table := range sortedKeys(config.Tables) {
+		if slices.Contains(ComputedFieldVariables, table) {
+			v.addf("enrichment.tables.%s shadows the built-in variable %s", table, table)
+		}
+	}
+	names := make(map[string]bool)
+	for i, computed := range config.Computed {
+		field := fmt.Sprintf("enrichment.computed[%d]", i)
+		if !computedFieldName.MatchString(computed.Name) {
+			v.addf("%s.name %q must be letters, digits and underscores, not starting with a digit", field, computed.Name)
+		} else if names[computed.Name] {
+			v.addf("%s.name %q is used by more than one computed field", field, computed.Name)
+		}
+		names[computed.Name] = true
+		e, err := expr.Parse(computed.Expression)
+		if err != nil {
+			v.addf("%s.expression: %v", field, err)
+			continue
+		}
+		for _, variable := range e.Variables() {
+			if _, ok := config.Tables[variable]; !ok && !slices.Contains(ComputedFieldVariables, variable) {
+				v.addf("%s.expression: unknown variable %s (supported: %s and the enrichment tables)", field, variable, strings.Join(ComputedFieldVariables, ", "))
+			}
+		}
+	}
+}
+
+// TemplateProblems lists the unknown placeholders of a message template
+func TemplateProblems(template string) []string {
+	var problems []string
+	for _, placeholder := range templatePlaceholder.FindAllString(template, -1) {
+		known := false
+		for _, variable := range MessageTemplateVariables {
+			if placeholder == variable {
+				known = true
+				break
+			}
+		}
+		if !known {
+			problems = append(problems, fmt.Sprintf("unknown template variable %s (supported: %s)", placeholder, strings.Join(MessageTemplateVariables, ", ")))
+		}
+	}
+	return problems
+}
+
+// Validate checks the configuration and reports all problems at once as a *ValidationError
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	// Steem
+	if c.Steem.APIURL == "" {
+		v.addf("steem.api_url is required")
+	} else {
+		v.url("steem.api_url", c.Steem.APIURL, "http", "https")
+	}
+	v.nonNegative("steem.start_block", c.Steem.StartBlock)
+	v.nonNegative("steem.batch_size", c.Steem.BatchSize)
+	if c.Steem.BatchSize > maxBatchSize {
+		v.addf("steem.batch_size must not exceed %d (got %d)", maxBatchSize, c.Steem.BatchSize)
+	}
+	v.nonNegative("steem.fetch_workers", int64(c.Steem.FetchWorkers))
+	if c.Steem.FetchWorkers > maxFetchWorkers {
+		v.addf("steem.fetch_workers must not exceed %d (got %d)", maxFetchWorkers, c.Steem.FetchWorkers)
+	}
+	if c.Steem.SyncMode != "" && c.Steem.SyncMode != SyncModeIrreversible && c.Steem.SyncMode != SyncModeHead {
+		v.addf("steem.sync_mode must be %q or %q (got %q)", SyncModeIrreversible, SyncModeHead, c.Steem.SyncMode)
+	}
+	if c.Steem.Client != "" && c.Steem.Client != ChainClientSDK {
+		v.addf("steem.client must be %q (got %q)", ChainClientSDK, c.Steem.Client)
+	}
+	v.nonNegative("steem.rpc_batch_size", int64(c.Steem.RPCBatchSize))
+	if c.Steem.RPCBatchSize > MaxRPCBatchSize {
+		v.addf("steem.rpc_batch_size must be at most %d (got %d)", MaxRPCBatchSize, c.Steem.RPCBatchSize)
+	}
+	v.nonNegative("steem.process_workers", int64(c.Steem.ProcessWorkers))
+	v.nonNegative("steem.account_check_interval_minutes", int64(c.Steem.AccountCheckIntervalMinutes))
+	v.accounts("steem.accounts", c.Steem.Accounts)
+	for i, rule := range c.Steem.Sampling {
+		field := fmt.Sprintf("steem.sampling[%d]", i)
+		if err := ValidateAccountName(rule.Account); err != nil {
+			v.addf("%s.account: %v", field, err)
+		}
+		switch rule.Mode {
+		case SamplingModeSample:
+			if rule.EveryN < 2 {
+				v.addf("%s.every_n must be at least 2 for mode %q", field, SamplingModeSample)
+			}
+		case SamplingModeAggregate:
+		default:
+			v.addf("%s.mode must be %q or %q (got %q)", field, SamplingModeSample, SamplingModeAggregate, rule.Mode)
+		}
+	}
+
+	for i, policy := range c.Steem.StoragePolicies {
+		field := fmt.Sprintf("steem.storage_policies[%d]", i)
+		if len(policy.OpTypes) == 0 {
+			v.addf("%s.op_types is required", field)
+		}
+		v.accounts(field+".accounts", policy.Accounts)
+		switch policy.Policy {
+		case StoragePolicyFull, StoragePolicyMetadata, StoragePolicyHash:
+		default:
+			v.addf("%s.policy must be %q, %q or %q (got %q)", field,
+				StoragePolicyFull, StoragePolicyMetadata, StoragePolicyHash, policy.Policy)
+		}
+	}
+
+	for _, opType := range sortedKeys(c.Steem.AccountPaths) {
+		for i, path := range c.Steem.AccountPaths[opType] {
+			if path == "" || strings.Contains(path, "..") || strings.HasPrefix(path, ".") || strings.HasSuffix(path, ".") {
+				v.addf("steem.account_paths.%s[%d]: %q is not a valid dotted path", opType, i, path)
+			}
+		}
+	}
+
+	switch c.Steem.FallbackExtraction.Mode {
+	case "", FallbackModeMatch, FallbackModeReview, FallbackModeOff:
+	default:
+		v.addf("steem.fallback_extraction.mode must be %q, %q or %q (got %q)",
+			FallbackModeMatch, FallbackModeReview, FallbackModeOff, c.Steem.FallbackExtraction.Mode)
+	}
+	for i, field := range c.Steem.FallbackExtraction.Fields {
+		if field == "" || strings.Contains(field, ".") {
+			v.addf("steem.fallback_extraction.fields[%d]: %q must be a top-level op_data field", i, field)
+		}
+	}
+
+	requests := c.Steem.Requests
+	if strings.ContainsAny(requests.UserAgent, "\r\n") {
+		v.addf("steem.requests.user_agent must be a single line")
+	}
+	for _, name := range sortedKeys(requests.Headers) {
+		if !validHeaderName(name) {
+			v.addf("steem.requests.headers: %q is not a valid header name", name)
+		} else if strings.ContainsAny(requests.Headers[name], "\r\n") {
+			v.addf("steem.requests.headers.%s must be a single line", name)
+		}
+	}
+	if requests.RPS < 0 {
+		v.addf("steem.requests.rps must not be negative (got %g)", requests.RPS)
+	}
+	v.nonNegative("steem.requests.burst", int64(requests.Burst))
+	for i, budget := range requests.Budgets {
+		field := fmt.Sprintf("steem.requests.budgets[%d]", i)
+		v.url(field+".url", budget.URL, "http", "https")
+		if budget.RPS < 0 {
+			v.addf("%s.rps must not be negative (got %g)", field, budget.RPS)
+		}
+		v.nonNegative(field+".burst", int64(budget.Burst))
+	}
+
+	for i, envelope := range c.ActivityAlerts {
+		field := fmt.Sprintf("activity_alerts[%d]", i)
+		if err := ValidateAccountName(envelope.Account); err != nil {
+			v.addf("%s.account: %v", field, err)
+		} else if !slices.Contains(c.Steem.Accounts, envelope.Account) {
+			v.addf("%s.account %s is not in steem.accounts, so none of its operations are stored", field, envelope.Account)
+		}
+		v.nonNegative(field+".min_per_day", envelope.MinPerDay)
+		v.nonNegative(field+".max_per_day", envelope.MaxPerDay)
+		switch {
+		case envelope.MinPerDay == 0 && envelope.MaxPerDay == 0:
+			v.addf("%s needs min_per_day or max_per_day", field)
+		case envelope.MaxPerDay > 0 && envelope.MaxPerDay < envelope.MinPerDay:
+			v.addf("%s.max_per_day must not be below min_per_day", field)
+		}
+	}
+
+	// MongoDB
+	if c.MongoDB.URI == "" {
+		v.addf("mongodb.uri is required")
+	} else if !strings.HasPrefix(c.MongoDB.URI, "mongodb://") && !strings.HasPrefix(c.MongoDB.URI, "mongodb+srv://") {
+		v.addf("mongodb.uri must start with mongodb:// or mongodb+srv://")
+	}
+	if c.MongoDB.Database == "" {
+		v.addf("mongodb.database is required")
+	}
+	v.nonNegative("mongodb.storage_warn_mb", c.MongoDB.StorageWarnMB)
+	v.nonNegative("mongodb.slow_query_ms", int64(c.MongoDB.SlowQueryMS))
+	v.nonNegative("mongodb.max_outage_seconds", int64(c.MongoDB.MaxOutageSeconds))
+	v.nonNegative("mongodb.spool_max_mb", c.MongoDB.SpoolMaxMB)
+
+	// Telegram
+	c.validateTelegram(v)
+
+	// Reconciliation
+	v.accounts("reconciliation.treasury_accounts", c.Reconciliation.TreasuryAccounts)
+	v.accounts("reconciliation.inflow_accounts", c.Reconciliation.InflowAccounts)
+	v.nonNegative("reconciliation.runway_lookback_days", int64(c.Reconciliation.RunwayLookbackDays))
+	v.nonNegative("reconciliation.balance_check_minutes", int64(c.Reconciliation.BalanceCheckMinutes))
+	v.nonNegative("reconciliation.daily_chart_days", int64(c.Reconciliation.DailyChartDays))
+	if c.Reconciliation.DailyChartDays > MaxDailyChartDays {
+		v.addf("reconciliation.daily_chart_days must be at most %d (got %d)", MaxDailyChartDays, c.Reconciliation.DailyChartDays)
+	}
+	if c.Reconciliation.TolerancePercent < 0 {
+		v.addf("reconciliation.tolerance_percent must not be negative (got %g)", c.Reconciliation.TolerancePercent)
+	}
+	// Funded receivers are found through the proposal_pay operations stored for the treasury
+	if c.Reconciliation.AutoTrackReceivers && !slices.Contains(c.Steem.Accounts, TreasuryAccount) {
+		v.addf("reconciliation.auto_track_receivers needs %s in steem.accounts", TreasuryAccount)
+	}
+
+	// Chains
+	if c.Steem.Chain != "" && !chainName.MatchString(c.Steem.Chain) {
+		v.addf("steem.chain must be lowercase letters, digits and dashes, starting with a letter (got %q)", c.Steem.Chain)
+	}
+	seenChains := map[string]bool{c.ChainName(): true}
+	seenDatabases := map[string]bool{c.MongoDB.Database: true}
+	for i, chain := range c.Chains {
+		field := fmt.Sprintf("chains[%d]", i)
+		switch {
+		case !chainName.MatchString(chain.Name):
+			v.addf("%s.name must be lowercase letters, digits and dashes, starting with a letter (got %q)", field, chain.Name)
+		case seenChains[chain.Name]:
+			v.addf("%s.name: chain %q is configured twice", field, chain.Name)
+		}
+		seenChains[chain.Name] = true
+		if chain.APIURL == "" {
+			v.addf("%s.api_url is required", field)
+		} else {
+			v.url(field+".api_url", chain.APIURL, "http", "https")
+		}
+		v.nonNegative(field+".start_block", chain.StartBlock)
+		v.accounts(field+".accounts", chain.Accounts)
+		v.explorer(field+".explorer", chain.Explorer)
+		// Each chain keeps its own sync state, so databases can't be shared
+		if database := chain.DatabaseName(c.MongoDB.Database); seenDatabases[database] {
+			v.addf("%s.database: database %q is used by another chain", field, database)
+		} else {
+			seenDatabases[database] = true
+		}
+	}
+
+	// Compensator
+	v.nonNegative("compensator.confirm_blocks", c.Compensator.ConfirmBlocks)
+
+	// Enrichment; enricher names are resolved when the pipeline is built, so custom enrichers can be registered
+	seenEnrichers := make(map[string]bool)
+	for i, name := range c.Enrichment.Enrichers {
+		if name == "" {
+			v.addf("enrichment.enrichers[%d] must not be empty", i)
+		} else if seenEnrichers[name] {
+			v.addf("enrichment.enrichers[%d]: %q is listed twice", i, name)
+		}
+		seenEnrichers[name] = true
+	}
+	v.nonNegative("enrichment.price_refresh_minutes", int64(c.Enrichment.PriceRefreshMinutes))
+	for _, account := range sortedKeys(c.Enrichment.Tags) {
+		if err := ValidateAccountName(account); err != nil {
+			v.addf("enrichment.tags.%s: %v", account, err)
+		}
+	}
+
+	v.computedFields(c.Enrichment)
+	if c.Enrichment.PriceHistory.URL != "" {
+		v.url("enrichment.price_history.url", c.Enrichment.PriceHistory.URL, "http", "https")
+	}
+
+	events := c.Enrichment.FundEvents
+	v.fundEventKinds("enrichment.fund_events.kinds", events.Kinds)
+	if events.LargeWithdrawalAmount < 0 {
+		v.addf("enrichment.fund_events.large_withdrawal_amount must not be negative (got %g)", events.LargeWithdrawalAmount)
+	}
+
+	// Leader election
+	if c.LeaderElection.LeaseSeconds != 0 && c.LeaderElection.LeaseSeconds < minLeaseSeconds {
+		v.addf("leader_election.lease_seconds must be at least %d (got %d)", minLeaseSeconds, c.LeaderElection.LeaseSeconds)
+	}
+
+	// API
+	if c.API.Port != "" {
+		if port, err := strconv.Atoi(c.API.Port); err != nil || port < 1 || port > 65535 {
+			v.addf("api.port must be a number between 1 and 65535 (got %q)", c.API.Port)
+		}
+	}
+	if base := c.API.BasePath; base != "" && (!strings.HasPrefix(base, "/") || strings.ContainsAny(base, "?#:*") || strings.Contains(base, "//")) {
+		v.addf("api.base_path must be a path starting with / such as \"/watcher\" (got %q)", base)
+	}
+	if movements := c.API.StatusPage.Movements; movements < 0 || movements > 50 {
+		v.addf("api.status_page.movements must be between 0 and 50 (got %d)", movements)
+	}
+	v.nonNegative("api.health.max_lag", c.API.Health.MaxLag)
+	v.nonNegative("api.health.max_sync_age_seconds", int64(c.API.Health.MaxSyncAgeSeconds))
+	v.nonNegative("api.max_page_size", int64(c.API.MaxPageSize))
+	v.nonNegative("api.stream.max_operations", int64(c.API.Stream.MaxOperations))
+	v.nonNegative("api.stream.timeout_seconds", int64(c.API.Stream.TimeoutSeconds))
+	if c.API.Timezone != "" {
+		if _, err := time.LoadLocation(c.API.Timezone); err != nil {
+			v.addf("api.timezone: unknown time zone %q", c.API.Timezone)
+		}
+	}
+	if _, err := c.API.TrustedProxyNets(); err != nil {
+		v.addf("api.trusted_proxies: %v", err)
+	}
+	if c.API.RateLimit.RPS < 0 {
+		v.addf("api.rate_limit.rps must not be negative (got %g)", c.API.RateLimit.RPS)
+	}
+	v.nonNegative("api.rate_limit.burst", int64(c.API.RateLimit.Burst))
+	keys := make(map[string]bool)
+	for i, key := range c.API.RateLimit.Keys {
+		field := fmt.Sprintf("api.rate_limit.keys[%d]", i)
+		if key.Name == "" {
+			v.addf("%s.name is required", field)
+		}
+		if key.Key == "" {
+			v.addf("%s.key is required", field)
+		} else if keys[key.Key] {
+			v.addf("%s.key is used by another key", field)
+		}
+		keys[key.Key] = true
+		if key.RPS < 0 {
+			v.addf("%s.rps must not be negative (got %g)", field, key.RPS)
+		}
+		v.nonNegative(field+".burst", int64(key.Burst))
+	}
+
+	// Webhooks
+	names := make(map[string]bool)
+	for i, hook := range c.Webhooks {
+		field := fmt.Sprintf("webhooks[%d]", i)
+		if hook.Name == "" {
+			v.addf("%s.name is required", field)
+		} else if names[hook.Name] {
+			v.addf("%s.name %q is used by more than one webhook", field, hook.Name)
+		}
+		names[hook.Name] = true
+		v.url(field+".url", hook.URL, "http", "https")
+		v.accounts(field+".accounts", hook.Accounts)
+		v.nonNegative(field+".max_attempts", int64(hook.MaxAttempts))
+	}
+
+	// Plugins
+	pluginNames := make(map[string]bool)
+	for i, plugin := range c.Plugins {
+		field := fmt.Sprintf("plugins[%d]", i)
+		if plugin.Name == "" {
+			v.addf("%s.name is required", field)
+		} else if pluginNames[plugin.Name] {
+			v.addf("%s.name %q is used by more than one plugin", field, plugin.Name)
+		}
+		pluginNames[plugin.Name] = true
+		if len(plugin.Command) == 0 || plugin.Command[0] == "" {
+			v.addf("%s.command is required", field)
+		}
+		v.accounts(field+".accounts", plugin.Accounts)
+		v.nonNegative(field+".timeout_ms", int64(plugin.TimeoutMS))
+		if plugin.OnError != "" && plugin.OnError != PluginOnErrorNotify && plugin.OnError != PluginOnErrorVeto {
+			v.addf("%s.on_error must be %q or %q (got %q)", field, PluginOnErrorNotify, PluginOnErrorVeto, plugin.OnError)
+		}
+	}
+
+	// Archive
+	switch c.Archive.Mode {
+	case "", ArchiveModeOff, ArchiveModeBlocks, ArchiveModeMatched:
+	default:
+		v.addf("archive.mode must be %q, %q or %q (got %q)", ArchiveModeOff, ArchiveModeBlocks, ArchiveModeMatched, c.Archive.Mode)
+	}
+
+	// Retry policies
+	v.retryPolicy("retry.telegram", c.Retry.Telegram)
+	v.retryPolicy("retry.webhooks", c.Retry.Webhooks)
+
+	// Retention
+	v.nonNegative("retention.days", int64(c.Retention.Days))
+	v.nonNegative("retention.prune_interval_minutes", int64(c.Retention.PruneIntervalMinutes))
+	for i, rule := range c.Retention.Rules {
+		field := fmt.Sprintf("retention.rules[%d]", i)
+		v.nonNegative(field+".days", int64(rule.Days))
+		v.accounts(field+".accounts", rule.Accounts)
+	}
+
+	// Logging
+	if c.Logging.Level != "" {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(c.Logging.Level)); err != nil {
+			v.addf("logging.level must be \"debug\", \"info\", \"warn\" or \"error\" (got %q)", c.Logging.Level)
+		}
+	}
+	if c.Logging.Format != "" && c.Logging.Format != LogFormatText && c.Logging.Format != LogFormatJSON {
+		v.addf("logging.format must be %q or %q (got %q)", LogFormatText, LogFormatJSON, c.Logging.Format)
+	}
+
+	// Error reporting
+	if c.ErrorReporting.SentryDSN != "" {
+		v.url("error_reporting.sentry_dsn", c.ErrorReporting.SentryDSN, "http", "https")
+	}
+	v.nonNegative("error_reporting.repeat_threshold", int64(c.ErrorReporting.RepeatThreshold))
+
+	// Profiling
+	if c.Pprof.Enabled {
+		v.listenAddr("pprof.sync_addr", c.Pprof.SyncListenAddr())
+		v.listenAddr("pprof.api_addr", c.Pprof.APIListenAddr())
+		if c.Pprof.SyncListenAddr() == c.Pprof.APIListenAddr() {
+			v.addf("pprof.sync_addr and pprof.api_addr must differ (both are %q)", c.Pprof.SyncListenAddr())
+		}
+	}
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+// validateTelegram checks the Telegram section, including rule templates and filters
+func (c *Config) validateTelegram(v *validator) {
+	// Missing credentials are not an error: notifications stay off until they are filled in
+	t := c.Telegram
+	if t.ParseMode != "" && !strings.EqualFold(t.ParseMode, "HTML") && !strings.EqualFold(t.ParseMode, "MarkdownV2") {
+		v.addf("telegram.parse_mode must be \"HTML\" or \"MarkdownV2\" (got %q)", t.ParseMode)
+	}
+	if t.StaleNotifyMode != "" && t.StaleNotifyMode != "suppress" && t.StaleNotifyMode != "digest" {
+		v.addf("telegram.stale_notify_mode must be \"suppress\" or \"digest\" (got %q)", t.StaleNotifyMode)
+	}
+	v.nonNegative("telegram.max_notify_age_minutes", int64(t.MaxNotifyAgeMinutes))
+	v.nonNegative("telegram.message_thread_id", t.MessageThreadID)
+	v.nonNegative("telegram.resend_max_age_hours", int64(t.ResendMaxAgeHours))
+	if t.Proxy != "" {
+		v.url("telegram.proxy", t.Proxy, "http", "https", "socks5")
+	}
+	if t.RuleEvaluation != "" && t.RuleEvaluation != RuleEvaluationAll && t.RuleEvaluation != RuleEvaluationFirst {
+		v.addf("telegram.rule_evaluation must be %q or %q (got %q)", RuleEvaluationAll, RuleEvaluationFirst, t.RuleEvaluation)
+	}
+	v.template("telegram.message_template", t.MessageTemplate)
+	v.accounts("telegram.accounts", t.Accounts)
+	if t.Commands.Enabled && !t.Enabled {
+		v.addf("telegram.commands.enabled needs telegram.enabled")
+	}
+	if t.Commands.Enabled && len(t.Commands.AllowedChats) == 0 {
+		v.addf("telegram.commands.allowed_chats must list at least one chat when commands are enabled")
+	}
+	v.explorer("telegram.explorer", t.Explorer)
+	for i, chat := range t.Commands.AllowedChats {
+		if _, err := strconv.ParseInt(chat, 10, 64); err != nil {
+			v.addf("telegram.commands.allowed_chats[%d]: %q is not a numeric chat ID", i, chat)
+		}
+	}
+
+	for i, user := range t.Users {
+		field := fmt.Sprintf("telegram.users[%d]", i)
+		if user.Name != "" {
+			field = fmt.Sprintf("telegram.users[%d] (%s)", i, user.Name)
+		}
+		v.accounts(field+".accounts", user.Accounts)
+		for _, account := range sortedKeys(user.AccountOperations) {
+			if err := ValidateAccountName(account); err != nil {
+				v.addf("%s.account_operations: %v", field, err)
+			}
+		}
+		v.template(field+".message_template", user.MessageTemplate)
+		v.nonNegative(field+".digest_interval_minutes", int64(user.DigestIntervalMinutes))
+		v.nonNegative(field+".bulk_threshold", int64(user.BulkThreshold))
+		v.nonNegative(field+".message_thread_id", user.MessageThreadID)
+		if user.MinAmount < 0 {
+			v.addf("%s.min_amount must not be negative", field)
+		}
+		if user.PinMinAmount < 0 {
+			v.addf("%s.pin_min_amount must not be negative", field)
+		}
+		v.fundEventKinds(field+".fund_events", user.FundEvents)
+		if len(user.FundEvents) > 0 && !c.Enrichment.FundEvents.Enabled {
+			v.addf("%s.fund_events needs enrichment.fund_events.enabled", field)
+		}
+
+		for _, opType := range sortedKeys(user.OperationFilters) {
+			for j, condition := range user.OperationFilters[opType].Conditions {
+				conditionField := fmt.Sprintf("%s.operation_filters.%s.conditions[%d]", field, opType, j)
+				if condition.Field == "" {
+					v.addf("%s.field is required", conditionField)
+				}
+				switch condition.NormalizedOp() {
+				case FilterOpEquals, FilterOpNotEquals, FilterOpContains, FilterOpNotContains:
+				case FilterOpGreater, FilterOpGreaterOrEq, FilterOpLess, FilterOpLessOrEq:
+					if _, err := strconv.ParseFloat(condition.Value, 64); err != nil {
+						v.addf("%s.value must be a number for operator %q (got %q)", conditionField, condition.Op, condition.Value)
+					}
+				default:
+					v.addf("%s.op %q is not supported", conditionField, condition.Op)
+				}
+			}
+		}
+	}
+}
+
+// sortedKeys returns map keys in a stable order for reproducible messages
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// validHeaderName reports whether name is an HTTP header field name (an RFC 7230 token)
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r > 0x7e || r <= ' ' || strings.ContainsRune("\"(),/:;<=>?@[\\]{}", r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// ControlState holds operator switches shared between the API and sync services
+// It is persisted in MongoDB so that it survives restarts of either process
+type ControlState struct {
+	ID                  string    `bson:"_id,omitempty" json:"-"`
+	SyncPaused          bool      `bson:"sync_paused" json:"sync_paused"`                   // Stop processing new blocks
+	NotificationsPaused bool      `bson:"notifications_paused" json:"notifications_paused"` // Keep syncing but don't send notifications
+	Reason              string    `bson:"reason" json:"reason"`                             // Free-form note left by the operator
+	UpdatedAt           time.Time `bson:"updated_at" json:"updated_at"`
+}
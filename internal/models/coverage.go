@@ -0,0 +1,65 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// BlockRange is an inclusive range of block numbers
+type BlockRange struct {
+	Start int64 `bson:"start_block" json:"start_block"`
+	End   int64 `bson:"end_block" json:"end_block"`
+}
+
+// AccountCoverage records which block ranges have been scanned for an account
+// by the sync service or the compensator
+type AccountCoverage struct {
+	Account   string       `bson:"_id" json:"account"`
+	Ranges    []BlockRange `bson:"ranges" json:"ranges"`
+	UpdatedAt time.Time    `bson:"updated_at" json:"updated_at"`
+}
+
+// MergeRanges sorts ranges and joins overlapping or adjacent ones
+func MergeRanges(ranges []BlockRange) []BlockRange {
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	sorted := append([]BlockRange{}, ranges...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+
+	merged := []BlockRange{sorted[0]}
+	for _, r := range sorted[1:] {
+		last := &merged[len(merged)-1]
+		if r.Start <= last.End+1 {
+			if r.End > last.End {
+				last.End = r.End
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// MissingRanges returns the parts of [start, end] not covered by the given ranges
+func MissingRanges(start, end int64, covered []BlockRange) []BlockRange {
+	var gaps []BlockRange
+	next := start
+	for _, r := range MergeRanges(covered) {
+		if r.End < next {
+			continue
+		}
+		if r.Start > end {
+			break
+		}
+		if r.Start > next {
+			gaps = append(gaps, BlockRange{Start: next, End: r.Start - 1})
+		}
+		next = r.End + 1
+	}
+	if next <= end {
+		gaps = append(gaps, BlockRange{Start: next, End: end})
+	}
+	return gaps
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// IndexReport shows how MongoDB executes the common query shapes and recent slow commands
+type IndexReport struct {
+	GeneratedAt time.Time         `json:"generated_at"`
+	Queries     []QueryPlanReport `json:"queries"`
+	SlowQueries SlowQueryStats    `json:"slow_queries"`
+}
+
+// QueryPlanReport is the explain() summary of one query shape
+type QueryPlanReport struct {
+	Name          string   `json:"name"`
+	Collection    string   `json:"collection"`
+	Filter        string   `json:"filter"`
+	ExpectedIndex string   `json:"expected_index"`
+	UsedIndexes   []string `json:"used_indexes"`
+	Stages        []string `json:"stages"`     // Stages of the winning plan, outermost first
+	UsesIndex     bool     `json:"uses_index"` // Expected index is used and there is no collection scan
+	KeysExamined  int64    `json:"keys_examined"`
+	DocsExamined  int64    `json:"docs_examined"`
+	Returned      int64    `json:"returned"`
+	ExecutionMS   int64    `json:"execution_ms"`
+	Error         string   `json:"error,omitempty"`
+}
+
+// SlowQueryStats summarizes MongoDB commands slower than the configured threshold
+type SlowQueryStats struct {
+	ThresholdMS int64       `json:"threshold_ms"` // 0 when slow-query capture is disabled
+	Total       int64       `json:"total"`        // Slow commands since the process started
+	Recent      []SlowQuery `json:"recent"`       // Most recent first
+}
+
+// SlowQuery is a single slow MongoDB command
+type SlowQuery struct {
+	Command    string    `json:"command"`
+	Collection string    `json:"collection"`
+	DurationMS int64     `json:"duration_ms"`
+	Failed     bool      `json:"failed"`
+	Detail     string    `json:"detail"` // Truncated command document
+	At         time.Time `json:"at"`
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Fund event kinds
+const (
+	FundEventPayoutMade       = "payout_made"       // A proposal payout to a receiver
+	FundEventProposalFunded   = "proposal_funded"   // A receiver is paid in a payout round after not being paid in the previous one
+	FundEventProposalDefunded = "proposal_defunded" // A receiver paid in the previous payout round is not paid anymore
+	FundEventKeyChanged       = "key_changed"       // An account's owner, active, posting or memo key changed
+	FundEventLargeWithdrawal  = "large_withdrawal"  // An outgoing transfer at or above the configured amount
+)
+
+// FundEventKinds lists the fund event kinds in documentation order
+var FundEventKinds = []string{
+	FundEventPayoutMade,
+	FundEventProposalFunded,
+	FundEventProposalDefunded,
+	FundEventKeyChanged,
+	FundEventLargeWithdrawal,
+}
+
+// FundEvent is a high-level event derived from stored operations
+// Raw operations stay the audit record; fund events are what dashboards and alerts show
+type FundEvent struct {
+	// ID is derived from the kind and the source, so deriving the same event again overwrites it
+	ID        string    `bson:"_id" json:"id"`
+	Kind      string    `bson:"kind" json:"kind"`
+	Account   string    `bson:"account" json:"account"` // Account the event is about, e.g. the payout receiver
+	BlockNum  int64     `bson:"block_num" json:"block_num"`
+	Timestamp time.Time `bson:"timestamp" json:"timestamp"` // Block time
+	Summary   string    `bson:"summary" json:"summary"`     // One-line description, e.g. "alice received 41.667 SBD from the SPS fund"
+
+	// Source operation; empty for events derived from a whole payout round (funded/defunded)
+	TrxID   string `bson:"trx_id,omitempty" json:"trx_id,omitempty"`
+	OpInTrx int    `bson:"op_in_trx,omitempty" json:"op_in_trx,omitempty"`
+	OpType  string `bson:"op_type,omitempty" json:"op_type,omitempty"`
+
+	// Kind-specific fields, e.g. amount and symbol, or the changed keys
+	Data map[string]interface{} `bson:"data,omitempty" json:"data,omitempty"`
+
+	CreatedAt time.Time `bson:"created_at" json:"created_at"` // When the watcher derived it
+}
+
+// FundEventFromOperation returns an event of kind sourced from op and about account
+func FundEventFromOperation(kind, account string, op *Operation, summary string, data map[string]interface{}) FundEvent {
+	return FundEvent{
+		ID:        fmt.Sprintf("%s:%d:%s:%d", kind, op.BlockNum, op.TrxID, op.OpInTrx),
+		Kind:      kind,
+		Account:   account,
+		BlockNum:  op.BlockNum,
+		Timestamp: op.Timestamp,
+		Summary:   summary,
+		TrxID:     op.TrxID,
+		OpInTrx:   op.OpInTrx,
+		OpType:    op.OpType,
+		Data:      data,
+	}
+}
+
+// FundEventResponse is a page of fund events, newest first
+type FundEventResponse struct {
+	Events   []FundEvent `json:"events"`
+	Total    int64       `json:"total"`
+	Page     int         `json:"page"`
+	PageSize int         `json:"page_size"`
+	HasMore  bool        `json:"has_more"`
+}
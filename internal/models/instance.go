@@ -0,0 +1,25 @@
+package models
+
+import "os"
+
+// InstanceConfig names a watcher instance when several run side by side, e.g. one per treasury
+// or environment
+type InstanceConfig struct {
+	Name    string `yaml:"name"`    // Default hostname
+	Profile string `yaml:"profile"` // Optional, e.g. "mainnet" or "dao-audit"
+}
+
+// InstanceLabels identify an instance in logs, error reports and the status endpoint
+type InstanceLabels struct {
+	Instance string `json:"instance"`
+	Profile  string `json:"profile,omitempty"`
+}
+
+// Labels returns the labels of the instance, filling in the hostname as default name
+func (i InstanceConfig) Labels() InstanceLabels {
+	name := i.Name
+	if name == "" {
+		name, _ = os.Hostname()
+	}
+	return InstanceLabels{Instance: name, Profile: i.Profile}
+}
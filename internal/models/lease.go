@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Lease is a named lock held by one instance until it expires or is released
+// Sync instances use it to elect the one that actually syncs
+type Lease struct {
+	Name       string    `bson:"_id" json:"name"`
+	Holder     string    `bson:"holder" json:"holder"`
+	AcquiredAt time.Time `bson:"acquired_at" json:"acquired_at"` // When the current holder took over
+	RenewedAt  time.Time `bson:"renewed_at" json:"renewed_at"`
+	ExpiresAt  time.Time `bson:"expires_at" json:"expires_at"`
+}
+
+// SyncLeaseName is the lease sync instances compete for when leader election is enabled
+const SyncLeaseName = "sync"
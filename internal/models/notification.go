@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// PendingNotification is a Telegram channel message that could not be sent and waits to be resent
+type PendingNotification struct {
+	ID        string               `bson:"_id,omitempty" json:"id"`
+	Text      string               `bson:"text" json:"text"` // Formatted in the parse mode it was built for
+	ParseMode string               `bson:"parse_mode" json:"parse_mode"`
+	Buttons   []NotificationButton `bson:"buttons,omitempty" json:"buttons,omitempty"`
+	ThreadID  int64                `bson:"thread_id,omitempty" json:"thread_id,omitempty"`
+	Silent    bool                 `bson:"silent,omitempty" json:"silent,omitempty"`
+	Pin       bool                 `bson:"pin,omitempty" json:"pin,omitempty"`
+
+	Attempts      int       `bson:"attempts" json:"attempts"` // Including the original send
+	LastError     string    `bson:"last_error" json:"last_error"`
+	CreatedAt     time.Time `bson:"created_at" json:"created_at"`
+	NextAttemptAt time.Time `bson:"next_attempt_at" json:"next_attempt_at"`
+}
+
+// NotificationDeadLetter is a channel message that gave up under retry.telegram
+type NotificationDeadLetter struct {
+	PendingNotification `bson:",inline"`
+	Reason              string    `bson:"reason" json:"reason"` // "max_attempts" or "expired"
+	FailedAt            time.Time `bson:"failed_at" json:"failed_at"`
+}
+
+// Reasons a notification gives up
+const (
+	GiveUpMaxAttempts = "max_attempts" // retry.telegram.max_attempts used up
+	GiveUpExpired     = "expired"      // Older than telegram.resend_max_age_hours
+)
+
+// NotificationButton is a link button under a notification
+type NotificationButton struct {
+	Text string `bson:"text" json:"text"`
+	URL  string `bson:"url" json:"url"`
+}
@@ -11,8 +11,69 @@ type Operation struct {
 	Account   string                 `bson:"account" json:"account"`
 	OpType    string                 `bson:"op_type" json:"op_type"`
 	OpData    map[string]interface{} `bson:"op_data" json:"op_data"`
-	Timestamp time.Time              `bson:"timestamp" json:"timestamp"`
-	CreatedAt time.Time              `bson:"created_at" json:"created_at"`
+	Timestamp time.Time              `bson:"timestamp" json:"timestamp"` // Block time
+
+	// BlockID and Witness pin the exact block and its producer; empty for records stored before they were tracked
+	BlockID string `bson:"block_id,omitempty" json:"block_id,omitempty"`
+	Witness string `bson:"witness,omitempty" json:"witness,omitempty"`
+
+	// FirstSeenAt is when the operation was first stored and never changes on re-processing
+	// UpdatedAt is when it was last written; both are UTC wall-clock times of this service
+	FirstSeenAt time.Time `bson:"first_seen_at,omitempty" json:"first_seen_at"`
+	UpdatedAt   time.Time `bson:"updated_at" json:"updated_at"`
+
+	// Unconfirmed is set for operations from reversible blocks (head sync mode)
+	// until irreversibility catches up and the block is reconciled
+	Unconfirmed bool `bson:"unconfirmed" json:"unconfirmed,omitempty"`
+
+	// SampleRate is N when this operation was kept as 1 in N by a sampling rule
+	SampleRate int `bson:"sample_rate,omitempty" json:"sample_rate,omitempty"`
+
+	// StoragePolicy is "metadata" or "hash" when content fields of op_data were dropped or hashed
+	StoragePolicy string `bson:"storage_policy,omitempty" json:"storage_policy,omitempty"`
+
+	// Enrichment holds computed fields added by the configured enrichers, keyed by field name
+	Enrichment map[string]interface{} `bson:"enrichment,omitempty" json:"enrichment,omitempty"`
+
+	// Source records which pipeline first stored the operation; like FirstSeenAt it is never overwritten
+	// Empty for operations stored before provenance was tracked
+	Source string `bson:"source,omitempty" json:"source,omitempty"`
+
+	// NeedsReview is set when the account was only found by the fallback extraction of an unknown
+	// operation type in review mode; like Source it is only written on the first insert, so an
+	// operation confirmed through the admin API stays confirmed when its block is re-processed
+	NeedsReview bool `bson:"needs_review,omitempty" json:"needs_review,omitempty"`
+
+	// VetoedBy names the plugin that kept the operation from being announced (see plugins)
+	VetoedBy string `bson:"vetoed_by,omitempty" json:"vetoed_by,omitempty"`
+
+	// Chain labels the chain the operation is from when several chains are watched (see chains)
+	Chain string `bson:"chain,omitempty" json:"chain,omitempty"`
+}
+
+// Operation sources
+const (
+	SourceSync      = "sync"      // Captured live by the sync service
+	SourceReplay    = "replay"    // Written by the sync service from its outage spool
+	SourceImport    = "import"    // Written by the bootstrap command from account history
+	SourceReprocess = "reprocess" // Written by the reprocess command from archived blocks
+)
+
+// OpTypeMention is the synthetic operation type of mention events
+// A mention event records that a comment names a tracked account without being addressed to it;
+// its op_data only references the comment (author, permlink, title), never the body
+const OpTypeMention = "mention"
+
+// CompensatorSource is the source of operations backfilled by a compensator job
+func CompensatorSource(jobID string) string {
+	return "compensator:" + jobID
+}
+
+// SetSource marks operations as ingested by source
+func SetSource(operations []*Operation, source string) {
+	for _, op := range operations {
+		op.Source = source
+	}
 }
 
 // SyncState represents the current sync state
@@ -23,6 +84,30 @@ type SyncState struct {
 	UpdatedAt             time.Time `bson:"updated_at" json:"updated_at"`
 }
 
+// Lag returns how many blocks the sync is behind its target: the head block in head sync mode,
+// otherwise the last irreversible block. A nil state (nothing synced yet) is behind by the whole target
+func (s *SyncState) Lag(syncMode string, headBlock, lastIrreversibleBlock int64) int64 {
+	target := lastIrreversibleBlock
+	if syncMode == SyncModeHead {
+		target = headBlock
+	}
+	if s == nil {
+		return target
+	}
+	if lag := target - s.LastBlock; lag > 0 {
+		return lag
+	}
+	return 0
+}
+
+// ReversibleBlock records a reversible block processed in head sync mode
+// It is used to detect forks once the block becomes irreversible
+type ReversibleBlock struct {
+	BlockNum    int64     `bson:"block_num" json:"block_num"`
+	BlockID     string    `bson:"block_id" json:"block_id"`
+	ProcessedAt time.Time `bson:"processed_at" json:"processed_at"`
+}
+
 // OperationResponse represents a paginated operation response
 type OperationResponse struct {
 	Operations []Operation `json:"operations"`
@@ -31,3 +116,70 @@ type OperationResponse struct {
 	PageSize   int         `json:"page_size"`
 	HasMore    bool        `json:"has_more"`
 }
+
+// OperationAggregate counts operations of one type for an account within an hour
+// It replaces individual records for operations matched by an aggregate sampling rule
+type OperationAggregate struct {
+	Account string    `bson:"account" json:"account"`
+	OpType  string    `bson:"op_type" json:"op_type"`
+	Hour    time.Time `bson:"hour" json:"hour"`
+	Count   int64     `bson:"count" json:"count"`
+}
+
+// MergedOperation is an operation listed once for several accounts it was stored for
+type MergedOperation struct {
+	Operation `bson:",inline"`
+	Accounts  []string `bson:"accounts" json:"accounts"` // Requested accounts the operation was stored for
+}
+
+// partyFields are the op_data fields holding the sending and receiving account, in lookup order
+var partyFields = [2][]string{
+	{"from", "from_account", "creator", "owner", "account"},
+	{"to", "to_account", "receiver"},
+}
+
+// OperationParties returns the sending and receiving account of an operation, empty when unknown
+func OperationParties(opData map[string]interface{}) (from, to string) {
+	lookup := func(fields []string) string {
+		for _, field := range fields {
+			if value, ok := opData[field].(string); ok && value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+	return lookup(partyFields[0]), lookup(partyFields[1])
+}
+
+// MergedOperationResponse is a page of operations merged across accounts
+type MergedOperationResponse struct {
+	Operations []MergedOperation `json:"operations"`
+	Total      int64             `json:"total"`
+	Page       int               `json:"page"`
+	PageSize   int               `json:"page_size"`
+	HasMore    bool              `json:"has_more"`
+}
+
+// Event is a chain operation listed once, however many tracked accounts stored it
+// A transfer between two tracked accounts is stored once per account; as an event it names both parties
+type Event struct {
+	MergedOperation
+	From string `json:"from,omitempty"` // Sending account, when the operation has one
+	To   string `json:"to,omitempty"`   // Receiving account, when the operation has one
+}
+
+// NewEvent returns the event of a merged operation
+func NewEvent(op MergedOperation) Event {
+	event := Event{MergedOperation: op}
+	event.From, event.To = OperationParties(op.OpData)
+	return event
+}
+
+// EventResponse is a page of events
+type EventResponse struct {
+	Events   []Event `json:"events"`
+	Total    int64   `json:"total"`
+	Page     int     `json:"page"`
+	PageSize int     `json:"page_size"`
+	HasMore  bool    `json:"has_more"`
+}
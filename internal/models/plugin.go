@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// PluginConfig is an external program that sees matched operations before they are stored
+// The program runs for as long as the sync service and exchanges one JSON line per operation:
+// it reads {"plugin": ..., "operation": {...}} on stdin and answers on stdout with
+// {"fields": {...}} to add enrichment fields and/or {"veto": true, "reason": "..."} to keep the
+// operation from being announced
+type PluginConfig struct {
+	Name    string   `yaml:"name"`
+	Command []string `yaml:"command"` // Program and arguments, e.g. ["python3", "/opt/plugins/tag.py"]
+	// Operation types and accounts sent to the plugin; empty means all
+	Operations []string `yaml:"operations"`
+	Accounts   []string `yaml:"accounts"`
+	// How long the plugin may take per operation (default 2000); a plugin that takes longer is restarted
+	TimeoutMS int `yaml:"timeout_ms"`
+	// What a failed or timed-out call means: "notify" (default) announces the operation without the
+	// plugin's fields, "veto" holds back its notifications
+	OnError string `yaml:"on_error"`
+}
+
+// DefaultPluginTimeout is the per-operation timeout of plugins without timeout_ms
+const DefaultPluginTimeout = 2 * time.Second
+
+// Plugin error handling
+const (
+	PluginOnErrorNotify = "notify"
+	PluginOnErrorVeto   = "veto"
+)
+
+// Timeout returns how long the plugin may take per operation
+func (p PluginConfig) Timeout() time.Duration {
+	if p.TimeoutMS <= 0 {
+		return DefaultPluginTimeout
+	}
+	return time.Duration(p.TimeoutMS) * time.Millisecond
+}
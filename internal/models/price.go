@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// PriceHistoryConfig is where STEEM and SBD market prices come from: daily ones for the price-backfill
+// tool and the current SBD price for the usd_value enricher
+type PriceHistoryConfig struct {
+	URL       string `yaml:"url"`         // CoinGecko API base URL (default https://api.coingecko.com/api/v3)
+	CoinID    string `yaml:"coin_id"`     // CoinGecko ID of the chain's token (default "steem")
+	SBDCoinID string `yaml:"sbd_coin_id"` // CoinGecko ID of the chain's dollar token (default "steem-dollars")
+	APIKey    string `yaml:"api_key"`     // Optional CoinGecko API key; the free public API only reaches back a year
+}
+
+// Price history defaults
+const (
+	DefaultPriceHistoryURL = "https://api.coingecko.com/api/v3"
+	DefaultPriceCoinID     = "steem"
+	DefaultSBDPriceCoinID  = "steem-dollars"
+)
+
+// Price sources
+const (
+	PriceSourceCoinGecko = "coingecko"
+	PriceSourceCSV       = "csv"
+)
+
+// DailyPrice is the USD price of an asset on a UTC day
+type DailyPrice struct {
+	Asset     string    `bson:"asset" json:"asset"` // e.g. "STEEM"
+	Date      string    `bson:"date" json:"date"`   // YYYY-MM-DD
+	USD       float64   `bson:"usd" json:"usd"`
+	Source    string    `bson:"source" json:"source"` // "coingecko" or "csv"
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// TreasuryAccount is the chain account holding the SPS fund; sps_fund credits go to it
+const TreasuryAccount = "steem.dao"
+
+// Proposal payout statuses
+const (
+	PayoutStatusOK         = "ok"
+	PayoutStatusUnpaid     = "unpaid"     // Expected a payout but none was recorded (e.g. not funded that day)
+	PayoutStatusUnderpaid  = "underpaid"  // Paid less than daily_pay for the active hours
+	PayoutStatusOverpaid   = "overpaid"   // Paid more than daily_pay for the active hours
+	PayoutStatusUnexpected = "unexpected" // Paid without a known active proposal
+)
+
+// ProposalPayoutDay compares the expected and recorded payouts of one receiver on one UTC day
+type ProposalPayoutDay struct {
+	Date       string   `json:"date"` // YYYY-MM-DD
+	Receiver   string   `json:"receiver"`
+	Proposals  []string `json:"proposals"` // creator/permlink of the proposals active that day
+	Symbol     string   `json:"symbol"`
+	Expected   float64  `json:"expected"`
+	Paid       float64  `json:"paid"`
+	Difference float64  `json:"difference"` // Paid - Expected
+	Status     string   `json:"status"`
+}
+
+// ProposalReconciliation is the result of checking proposal payouts over a range of days
+type ProposalReconciliation struct {
+	From          time.Time           `json:"from"` // Inclusive, midnight UTC
+	To            time.Time           `json:"to"`   // Exclusive, midnight UTC
+	Days          []ProposalPayoutDay `json:"days"`
+	Discrepancies int                 `json:"discrepancies"` // Days whose status is not "ok"
+}
+
+// BalanceSnapshot records the liquid balances of an account at a point in time
+type BalanceSnapshot struct {
+	Account  string             `bson:"account" json:"account"`
+	Balances map[string]float64 `bson:"balances" json:"balances"` // By asset symbol
+	TakenAt  time.Time          `bson:"taken_at" json:"taken_at"`
+	BlockNum int64              `bson:"block_num,omitempty" json:"block_num,omitempty"` // Head block the balances were read at, when known
+	Checked  bool               `bson:"checked,omitempty" json:"-"`                     // Compared with the stored operations by the balance check
+}
+
+// RunwayProjection estimates how long the treasury balance lasts at the current net burn
+type RunwayProjection struct {
+	Treasury         string    `json:"treasury"`
+	Symbol           string    `json:"symbol"`
+	Balance          float64   `json:"balance"`
+	BalanceAt        time.Time `json:"balance_at"` // When the balance snapshot was taken
+	DailyObligations float64   `json:"daily_obligations"`
+	DailyInflow      float64   `json:"daily_inflow"`     // Averaged over lookback_days
+	NetDailyBurn     float64   `json:"net_daily_burn"`   // Obligations - inflow
+	RunwayDays       *float64  `json:"runway_days"`      // Null when inflow covers the obligations
+	RunwayMonths     *float64  `json:"runway_months"`    // Runway in 30.44-day months
+	FundedProposals  []string  `json:"funded_proposals"` // creator/permlink of the proposals counted as obligations
+	LookbackDays     int       `json:"lookback_days"`
+	ComputedAt       time.Time `json:"computed_at"`
+}
+
+// TreasuryFlow sums the treasury's inflows and outflows of one asset on one UTC day
+type TreasuryFlow struct {
+	Date            string  `json:"date,omitempty"` // YYYY-MM-DD; empty for totals
+	Symbol          string  `json:"symbol"`
+	Inflation       float64 `json:"inflation"`        // sps_fund credits and transfers from the inflow accounts
+	Donations       float64 `json:"donations"`        // Other transfers into the treasury
+	ProposalPayouts float64 `json:"proposal_payouts"` // proposal_pay to tracked receivers
+	OtherOutflows   float64 `json:"other_outflows"`   // Transfers sent by the treasury
+}
+
+// TreasuryFlows breaks the treasury's flows down by source over a range of days
+type TreasuryFlows struct {
+	Treasury string         `json:"treasury"`
+	From     time.Time      `json:"from"` // Inclusive, midnight UTC
+	To       time.Time      `json:"to"`   // Exclusive, midnight UTC
+	Days     []TreasuryFlow `json:"days"`
+	Totals   []TreasuryFlow `json:"totals"` // By symbol
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// RetryConfig holds the retry policy of each notification channel
+type RetryConfig struct {
+	Telegram RetryPolicy `yaml:"telegram"` // Channel messages, resent from the pending_notifications collection
+	Webhooks RetryPolicy `yaml:"webhooks"` // Webhook deliveries; max_attempts of a webhook overrides the policy's
+}
+
+// RetryPolicy controls how a failed notification is retried and what happens when it gives up
+type RetryPolicy struct {
+	// Attempts including the first send (0 = unlimited for Telegram, within telegram.resend_max_age_hours)
+	MaxAttempts int `yaml:"max_attempts"`
+	// Wait after the first failure; each further failure multiplies it by multiplier
+	BackoffSeconds    float64 `yaml:"backoff_seconds"`
+	MaxBackoffSeconds float64 `yaml:"max_backoff_seconds"` // 0 = no cap
+	Multiplier        float64 `yaml:"multiplier"`
+	// Fraction by which each wait is randomly lengthened or shortened, e.g. 0.2 for ±20%, so
+	// notifications that failed together don't retry together
+	Jitter float64 `yaml:"jitter"`
+	// "dead_letter" (default) keeps notifications that give up for inspection, "drop" discards them
+	GiveUp string `yaml:"give_up"`
+}
+
+// Give-up actions
+const (
+	GiveUpDeadLetter = "dead_letter"
+	GiveUpDrop       = "drop"
+)
+
+// Default retry policies; the Telegram one matches the earlier fixed 1, 2, 4... minute schedule
+var (
+	DefaultTelegramRetry = RetryPolicy{BackoffSeconds: 60, MaxBackoffSeconds: 3600, Multiplier: 2, Jitter: 0.2, GiveUp: GiveUpDeadLetter}
+	DefaultWebhookRetry  = RetryPolicy{MaxAttempts: 3, BackoffSeconds: 1, MaxBackoffSeconds: 300, Multiplier: 2, Jitter: 0.2, GiveUp: GiveUpDeadLetter}
+)
+
+// Backoff returns the wait after the given number of failed attempts; random is a number in
+// [0, 1) that places the wait within the jitter
+func (p RetryPolicy) Backoff(attempts int, random float64) time.Duration {
+	seconds := p.BackoffSeconds * math.Pow(math.Max(p.Multiplier, 1), float64(max(attempts-1, 0)))
+	if p.MaxBackoffSeconds > 0 {
+		seconds = math.Min(seconds, p.MaxBackoffSeconds)
+	}
+	seconds *= 1 + p.Jitter*(2*random-1)
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// Exhausted reports whether a notification that failed the given number of attempts gives up
+func (p RetryPolicy) Exhausted(attempts int) bool {
+	return p.MaxAttempts > 0 && attempts >= p.MaxAttempts
+}
+
+// DeadLetters reports whether notifications that give up are kept
+func (p RetryPolicy) DeadLetters() bool {
+	return p.GiveUp != GiveUpDrop
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// StatsCount is the number of stored operations in one group
+type StatsCount struct {
+	Key   string `bson:"_id" json:"key"` // Operation type, account or YYYY-MM-DD
+	Count int64  `bson:"count" json:"count"`
+}
+
+// OperationStats counts the stored operations of a time window for dashboards
+type OperationStats struct {
+	From      time.Time    `json:"from"`
+	To        time.Time    `json:"to"` // Exclusive
+	Account   string       `json:"account,omitempty"`
+	OpType    string       `json:"op_type,omitempty"`
+	Total     int64        `json:"total"`
+	ByOpType  []StatsCount `json:"by_op_type"` // Largest first
+	ByAccount []StatsCount `json:"by_account"` // Largest first
+	ByDay     []StatsCount `json:"by_day"`     // Every UTC day of the window, oldest first
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// StorageReport summarizes MongoDB storage usage and growth
+type StorageReport struct {
+	GeneratedAt    time.Time         `json:"generated_at"`
+	DataSize       int64             `json:"data_size"`    // Uncompressed size of all documents, bytes
+	StorageSize    int64             `json:"storage_size"` // Space allocated for documents on disk, bytes
+	IndexSize      int64             `json:"index_size"`   // Space allocated for indexes on disk, bytes
+	Collections    []CollectionUsage `json:"collections"`
+	OperationTypes []OperationCount  `json:"operation_types"` // Stored operations per account and type
+	Growth         StorageGrowth     `json:"growth"`
+}
+
+// CollectionUsage reports the size of a single collection
+type CollectionUsage struct {
+	Name        string `json:"name"`
+	Count       int64  `json:"count"`
+	DataSize    int64  `json:"data_size"`
+	StorageSize int64  `json:"storage_size"`
+	IndexSize   int64  `json:"index_size"`
+	AvgObjSize  int64  `json:"avg_obj_size"`
+}
+
+// OperationCount is the number of stored operations for an account and type
+type OperationCount struct {
+	Account string `bson:"account" json:"account"`
+	OpType  string `bson:"op_type" json:"op_type"`
+	Count   int64  `bson:"count" json:"count"`
+}
+
+// StorageGrowth projects operation storage growth from the last week of inserts
+type StorageGrowth struct {
+	OperationsLast7Days int64 `json:"operations_last_7_days"`
+	OperationsPerDay    int64 `json:"operations_per_day"`
+	BytesPerDay         int64 `json:"bytes_per_day"`
+	Projected30Days     int64 `json:"projected_30_days"` // Additional bytes expected over the next 30 days
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Transfer summary groupings
+const (
+	GroupByCounterparty = "counterparty"
+	GroupByDay          = "day"
+	GroupByMonth        = "month"
+)
+
+// TransferTotals sums the transfers of one group in one asset
+type TransferTotals struct {
+	Key      string  `bson:"key" json:"key,omitempty"` // Counterparty, YYYY-MM-DD or YYYY-MM; empty for totals
+	Symbol   string  `bson:"symbol" json:"symbol"`
+	Received float64 `bson:"received" json:"received"`
+	Sent     float64 `bson:"sent" json:"sent"`
+	Net      float64 `bson:"net" json:"net"` // Received - sent
+	Count    int64   `bson:"count" json:"count"`
+}
+
+// TransferSummary aggregates an account's transfers by counterparty or period
+type TransferSummary struct {
+	Account string           `json:"account"`
+	GroupBy string           `json:"group_by"`
+	From    *time.Time       `json:"from,omitempty"`
+	To      *time.Time       `json:"to,omitempty"`
+	Groups  []TransferTotals `json:"groups"`
+	Totals  []TransferTotals `json:"totals"` // By symbol
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// SavedView is a named, shareable filter over stored operations
+// Views are managed through the admin API and served at /api/v1/views/:name
+type SavedView struct {
+	ID          string    `bson:"_id,omitempty" json:"-"`
+	Name        string    `bson:"name" json:"name"`
+	Description string    `bson:"description" json:"description"`
+	Account     string    `bson:"account" json:"account"` // Optional account restriction
+	OpType      string    `bson:"op_type" json:"op_type"` // Optional operation type restriction
+	Query       string    `bson:"query" json:"query"`     // Optional filter expression (same language as the q parameter)
+	Feed        bool      `bson:"feed" json:"feed"`       // Publish an RSS feed at /api/v1/views/:name/rss
+	Notify      bool      `bson:"notify" json:"notify"`   // Send Telegram alerts for new matching operations
+	CreatedAt   time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `bson:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Webhook is an outgoing HTTP endpoint that receives matched operations
+// Webhooks come either from the configuration file or from the admin API (stored in MongoDB)
+type Webhook struct {
+	ID               string    `bson:"_id,omitempty" json:"id,omitempty" yaml:"-"`
+	Name             string    `bson:"name" json:"name" yaml:"name"`
+	URL              string    `bson:"url" json:"url" yaml:"url"`
+	Secret           string    `bson:"secret" json:"secret,omitempty" yaml:"secret"`                        // HMAC-SHA256 signing key
+	Accounts         []string  `bson:"accounts" json:"accounts" yaml:"accounts"`                            // Empty means all tracked accounts
+	NotifyOperations []string  `bson:"notify_operations" json:"notify_operations" yaml:"notify_operations"` // Empty means all operations
+	MaxAttempts      int       `bson:"max_attempts" json:"max_attempts" yaml:"max_attempts"`                // Delivery attempts before giving up (default retry.webhooks.max_attempts)
+	CreatedAt        time.Time `bson:"created_at" json:"created_at" yaml:"-"`
+}
+
+// WebhookPayload is the JSON body POSTed to webhook endpoints
+type WebhookPayload struct {
+	Event     string     `json:"event"` // Always "operation" for now
+	Webhook   string     `json:"webhook"`
+	Operation *Operation `json:"operation"`
+	SentAt    time.Time  `json:"sent_at"`
+}
+
+// WebhookDeadLetter records a webhook delivery that failed permanently
+type WebhookDeadLetter struct {
+	ID        string    `bson:"_id,omitempty" json:"id"`
+	Webhook   string    `bson:"webhook" json:"webhook"`
+	URL       string    `bson:"url" json:"url"`
+	Payload   string    `bson:"payload" json:"payload"` // JSON body that could not be delivered
+	Attempts  int       `bson:"attempts" json:"attempts"`
+	LastError string    `bson:"last_error" json:"last_error"`
+	FailedAt  time.Time `bson:"failed_at" json:"failed_at"`
+}
@@ -0,0 +1,181 @@
+// Package plugin runs external programs that see matched operations before they are stored
+// Plugins add site-specific enrichment fields or veto notifications without rebuilding the watcher
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	stdsync "sync"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/logging"
+	"github.com/ety001/sps-fund-watcher/internal/models"
+)
+
+var logger = logging.Component("plugin")
+
+// Request is the line written to a plugin's stdin for each operation
+type Request struct {
+	Plugin    string            `json:"plugin"`
+	Operation *models.Operation `json:"operation"` // With the enrichment fields added so far
+}
+
+// Response is the line a plugin answers with; {} lets the operation pass unchanged
+type Response struct {
+	Fields map[string]interface{} `json:"fields,omitempty"` // Merged into the operation's enrichment
+	Veto   bool                   `json:"veto,omitempty"`   // Store the operation but don't announce it
+	Reason string                 `json:"reason,omitempty"` // Logged with a veto
+}
+
+// errTimeout is returned when a plugin doesn't answer within its timeout
+var errTimeout = errors.New("plugin timed out")
+
+// Plugin is a running plugin program; it is started on the first call and restarted on the call
+// after it exited, failed or timed out
+type Plugin struct {
+	config models.PluginConfig
+
+	mu     stdsync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+// New returns the plugin of config without starting it
+func New(config models.PluginConfig) *Plugin {
+	return &Plugin{config: config}
+}
+
+// Name returns the configured plugin name
+func (p *Plugin) Name() string {
+	return p.config.Name
+}
+
+// Matches reports whether the plugin wants to see op
+func (p *Plugin) Matches(op *models.Operation) bool {
+	return (len(p.config.Operations) == 0 || slices.Contains(p.config.Operations, op.OpType)) &&
+		(len(p.config.Accounts) == 0 || slices.Contains(p.config.Accounts, op.Account))
+}
+
+// Call sends op to the plugin and returns its answer
+func (p *Plugin) Call(ctx context.Context, op *models.Operation) (*Response, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cmd == nil {
+		if err := p.start(); err != nil {
+			return nil, err
+		}
+	}
+
+	line, err := json.Marshal(Request{Plugin: p.config.Name, Operation: op})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode operation: %w", err)
+	}
+
+	// The timeout covers the write too: a plugin that stops reading fills the pipe and blocks
+	// it, and stop unblocks it by closing the pipe and killing the program
+	type answer struct {
+		line []byte
+		err  error
+	}
+	answers := make(chan answer, 1)
+	stdin, stdout := p.stdin, p.stdout
+	go func() {
+		if _, err := stdin.Write(append(line, '\n')); err != nil {
+			answers <- answer{err: fmt.Errorf("failed to write to plugin: %w", err)}
+			return
+		}
+		line, err := stdout.ReadBytes('\n')
+		if err != nil {
+			err = fmt.Errorf("failed to read from plugin: %w", err)
+		}
+		answers <- answer{line, err}
+	}()
+
+	timer := time.NewTimer(p.config.Timeout())
+	defer timer.Stop()
+	select {
+	case a := <-answers:
+		if a.err != nil {
+			p.stop()
+			return nil, a.err
+		}
+		var response Response
+		if err := json.Unmarshal(a.line, &response); err != nil {
+			// The plugin may be out of step with its requests, so it starts over
+			p.stop()
+			return nil, fmt.Errorf("invalid plugin response %q: %w", truncate(a.line), err)
+		}
+		return &response, nil
+	case <-timer.C:
+		p.stop()
+		return nil, errTimeout
+	case <-ctx.Done():
+		p.stop()
+		return nil, ctx.Err()
+	}
+}
+
+// Close stops the plugin program
+func (p *Plugin) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stop()
+}
+
+// start launches the program; its stderr is logged line by line
+func (p *Plugin) start() error {
+	cmd := exec.Command(p.config.Command[0], p.config.Command[1:]...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to start plugin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to start plugin: %w", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("failed to start plugin: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start plugin: %w", err)
+	}
+
+	go func() {
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			logger.Info("Plugin output", "plugin", p.config.Name, "line", scanner.Text())
+		}
+	}()
+	logger.Info("Plugin started", "plugin", p.config.Name, "pid", cmd.Process.Pid)
+
+	p.cmd, p.stdin, p.stdout = cmd, stdin, bufio.NewReader(stdout)
+	return nil
+}
+
+// stop kills the program, if running, so the next call starts it again
+func (p *Plugin) stop() {
+	if p.cmd == nil {
+		return
+	}
+	p.stdin.Close()
+	p.cmd.Process.Kill()
+	p.cmd.Wait()
+	p.cmd, p.stdin, p.stdout = nil, nil, nil
+}
+
+// truncate shortens a response for error messages
+func truncate(line []byte) string {
+	if len(line) > 200 {
+		return string(line[:200]) + "..."
+	}
+	return string(line)
+}
@@ -0,0 +1,57 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+)
+
+// stallOnce is a plugin that stalls without reading its first time and vetoes every operation
+// once restarted; the marker file records the first run
+const stallOnce = `if [ -e "$0" ]; then while read -r line; do echo '{"veto":true}'; done; else touch "$0"; exec sleep 60; fi`
+
+func TestCallTimesOutAndRestarts(t *testing.T) {
+	tests := []struct {
+		name string
+		memo string
+	}{
+		// The request fits in the pipe, so the plugin stalls reading the answer
+		{name: "stalled read", memo: "small"},
+		// The request fills the pipe, so the plugin stalls the write itself
+		{name: "stalled write", memo: strings.Repeat("x", 1<<20)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(models.PluginConfig{
+				Name:      "stall",
+				Command:   []string{"sh", "-c", stallOnce, filepath.Join(t.TempDir(), "started")},
+				TimeoutMS: 200,
+			})
+			defer p.Close()
+
+			op := &models.Operation{Account: "alice", OpType: "transfer", OpData: map[string]interface{}{"memo": tt.memo}}
+			start := time.Now()
+			if _, err := p.Call(context.Background(), op); !errors.Is(err, errTimeout) {
+				t.Fatalf("first call error = %v, want %v", err, errTimeout)
+			}
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Fatalf("first call took %v, want about the 200ms timeout", elapsed)
+			}
+
+			// The next call starts a new program, which answers
+			op.OpData["memo"] = "small"
+			response, err := p.Call(context.Background(), op)
+			if err != nil {
+				t.Fatalf("second call: %v", err)
+			}
+			if !response.Veto {
+				t.Errorf("second call response = %+v, want a veto", response)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package plugin
+
+import (
+	"context"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+)
+
+// Runner passes operations through the configured plugins in order
+type Runner struct {
+	plugins []*Plugin
+	onError map[string]string // By plugin name
+}
+
+// NewRunner returns a runner for the plugins in configs, or nil when there are none
+// A nil runner is a no-op
+func NewRunner(configs []models.PluginConfig) *Runner {
+	if len(configs) == 0 {
+		return nil
+	}
+	r := &Runner{onError: make(map[string]string, len(configs))}
+	for _, config := range configs {
+		r.plugins = append(r.plugins, New(config))
+		r.onError[config.Name] = config.OnError
+	}
+	return r
+}
+
+// Apply runs the plugins on operations in place: fields they return are merged into the
+// enrichment (later plugins win) and a veto sets VetoedBy
+// A failing plugin is logged and, unless its on_error is "veto", skipped
+func (r *Runner) Apply(ctx context.Context, operations []*models.Operation) {
+	if r == nil {
+		return
+	}
+	for _, op := range operations {
+		for _, p := range r.plugins {
+			if !p.Matches(op) {
+				continue
+			}
+			response, err := p.Call(ctx, op)
+			if err != nil {
+				logger.Warn("Plugin failed", "plugin", p.Name(), "block", op.BlockNum, "trx_id", op.TrxID, "error", err)
+				if r.onError[p.Name()] == models.PluginOnErrorVeto && op.VetoedBy == "" {
+					op.VetoedBy = p.Name()
+				}
+				continue
+			}
+			if len(response.Fields) > 0 {
+				if op.Enrichment == nil {
+					op.Enrichment = make(map[string]interface{}, len(response.Fields))
+				}
+				for key, value := range response.Fields {
+					op.Enrichment[key] = value
+				}
+			}
+			if response.Veto && op.VetoedBy == "" {
+				op.VetoedBy = p.Name()
+				logger.Info("Plugin vetoed notification", "plugin", p.Name(), "account", op.Account, "op_type", op.OpType,
+					"block", op.BlockNum, "trx_id", op.TrxID, "reason", response.Reason)
+			}
+		}
+	}
+}
+
+// Close stops all plugin programs
+func (r *Runner) Close() {
+	if r == nil {
+		return
+	}
+	for _, p := range r.plugins {
+		p.Close()
+	}
+}
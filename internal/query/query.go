@@ -0,0 +1,386 @@
+// Package query compiles the small filter expression language accepted by the
+// operations API (the q parameter) into MongoDB filters.
+//
+// Grammar:
+//
+//	expr       = and { "OR" and }
+//	and        = unary { "AND" unary }
+//	unary      = "NOT" unary | "(" expr ")" | comparison
+//	comparison = field op value
+//	field      = name { "." name }            (root must be an operation field)
+//	op         = "=" | "!=" | ">" | ">=" | "<" | "<=" | "~"   ("~" is case-insensitive contains)
+//	value      = "string" | number | true | false | null
+//
+// Example: op_data.amount>1000 AND op_data.to="steem.dao"
+//
+// Numeric comparisons convert the stored value to a number, so asset strings such as
+// "1000.000 STEEM" compare by their amount. They compile to $expr, which can't use an
+// index, so MongoDB evaluates them on every operation the other filters select.
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	// MaxLength is the maximum accepted length of an expression
+	MaxLength = 1000
+	// MaxConditions is the maximum number of comparisons in an expression
+	MaxConditions = 20
+)
+
+// allowedRoots lists the operation fields that may be queried
+var allowedRoots = map[string]bool{
+	"account":       true,
+	"op_type":       true,
+	"block_num":     true,
+	"trx_id":        true,
+	"op_in_trx":     true,
+	"timestamp":     true,
+	"first_seen_at": true,
+	"updated_at":    true,
+	"source":        true,
+	"op_data":       true,
+}
+
+// timeFields are compared as dates rather than strings
+var timeFields = map[string]bool{
+	"timestamp":     true,
+	"first_seen_at": true,
+	"updated_at":    true,
+}
+
+// fieldSegment restricts field path segments so user input can never address operators
+var fieldSegment = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// Parse compiles an expression into a MongoDB filter
+func Parse(expr string) (bson.M, error) {
+	if len(expr) > MaxLength {
+		return nil, fmt.Errorf("query too long (max %d characters)", MaxLength)
+	}
+
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	filter, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.peek().text, p.peek().pos)
+	}
+	return filter, nil
+}
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokString
+	tokNumber
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// tokenize splits an expression into tokens
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(expr) {
+		ch := expr[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n':
+			i++
+		case ch == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "(", pos: i})
+			i++
+		case ch == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")", pos: i})
+			i++
+		case ch == '"':
+			var sb strings.Builder
+			start := i
+			i++
+			for {
+				if i >= len(expr) {
+					return nil, fmt.Errorf("unterminated string at position %d", start)
+				}
+				if expr[i] == '\\' && i+1 < len(expr) {
+					sb.WriteByte(expr[i+1])
+					i += 2
+					continue
+				}
+				if expr[i] == '"' {
+					i++
+					break
+				}
+				sb.WriteByte(expr[i])
+				i++
+			}
+			tokens = append(tokens, token{kind: tokString, text: sb.String(), pos: start})
+		case strings.ContainsRune("=!<>~", rune(ch)):
+			start := i
+			i++
+			if i < len(expr) && expr[i] == '=' && ch != '=' && ch != '~' {
+				i++
+			}
+			text := expr[start:i]
+			if text == "!" {
+				return nil, fmt.Errorf("unexpected '!' at position %d", start)
+			}
+			tokens = append(tokens, token{kind: tokOp, text: text, pos: start})
+		case ch == '-' || (ch >= '0' && ch <= '9'):
+			start := i
+			i++
+			for i < len(expr) && (expr[i] == '.' || (expr[i] >= '0' && expr[i] <= '9')) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: expr[start:i], pos: start})
+		case ch == '_' || ch == '.' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z'):
+			start := i
+			for i < len(expr) && (expr[i] == '_' || expr[i] == '.' ||
+				(expr[i] >= 'a' && expr[i] <= 'z') || (expr[i] >= 'A' && expr[i] <= 'Z') || (expr[i] >= '0' && expr[i] <= '9')) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: expr[start:i], pos: start})
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", ch, i)
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens     []token
+	pos        int
+	conditions int
+}
+
+func (p *parser) done() bool  { return p.pos >= len(p.tokens) }
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() (token, error) {
+	if p.done() {
+		return token{}, fmt.Errorf("unexpected end of query")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	return t, nil
+}
+
+// isKeyword reports whether the next token is the given keyword (case-insensitive)
+func (p *parser) isKeyword(keyword string) bool {
+	return !p.done() && p.peek().kind == tokIdent && strings.EqualFold(p.peek().text, keyword)
+}
+
+func (p *parser) parseOr() (bson.M, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	clauses := []bson.M{left}
+	for p.isKeyword("OR") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, right)
+	}
+	if len(clauses) == 1 {
+		return left, nil
+	}
+	return bson.M{"$or": clauses}, nil
+}
+
+func (p *parser) parseAnd() (bson.M, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	clauses := []bson.M{left}
+	for p.isKeyword("AND") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, right)
+	}
+	if len(clauses) == 1 {
+		return left, nil
+	}
+	return bson.M{"$and": clauses}, nil
+}
+
+func (p *parser) parseUnary() (bson.M, error) {
+	if p.isKeyword("NOT") {
+		p.pos++
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return bson.M{"$nor": []bson.M{inner}}, nil
+	}
+
+	if !p.done() && p.peek().kind == tokLParen {
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		t, err := p.next()
+		if err != nil || t.kind != tokRParen {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		return inner, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (bson.M, error) {
+	p.conditions++
+	if p.conditions > MaxConditions {
+		return nil, fmt.Errorf("too many conditions (max %d)", MaxConditions)
+	}
+
+	fieldTok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if fieldTok.kind != tokIdent {
+		return nil, fmt.Errorf("expected field name at position %d", fieldTok.pos)
+	}
+	field, err := validateField(fieldTok.text)
+	if err != nil {
+		return nil, err
+	}
+
+	opTok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if opTok.kind != tokOp {
+		return nil, fmt.Errorf("expected comparison operator after %s at position %d", field, opTok.pos)
+	}
+
+	valueTok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	value, err := parseValue(field, valueTok)
+	if err != nil {
+		return nil, err
+	}
+
+	return compile(field, opTok.text, value)
+}
+
+// validateField checks that a field path only addresses operation fields
+func validateField(field string) (string, error) {
+	segments := strings.Split(field, ".")
+	if !allowedRoots[segments[0]] {
+		return "", fmt.Errorf("unknown field %q", field)
+	}
+	for _, segment := range segments {
+		if !fieldSegment.MatchString(segment) {
+			return "", fmt.Errorf("invalid field %q", field)
+		}
+	}
+	return field, nil
+}
+
+// parseValue converts a value token to a Go value
+func parseValue(field string, t token) (interface{}, error) {
+	switch t.kind {
+	case tokString:
+		if timeFields[field] {
+			for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+				if ts, err := time.Parse(layout, t.text); err == nil {
+					return ts, nil
+				}
+			}
+			return nil, fmt.Errorf("invalid timestamp %q (use RFC3339 or YYYY-MM-DD)", t.text)
+		}
+		return t.text, nil
+	case tokNumber:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", t.text)
+		}
+		return n, nil
+	case tokIdent:
+		switch strings.ToLower(t.text) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+	}
+	return nil, fmt.Errorf("expected value at position %d", t.pos)
+}
+
+// compile builds the MongoDB condition for a single comparison
+func compile(field, op string, value interface{}) (bson.M, error) {
+	if op == "~" {
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("~ requires a string value")
+		}
+		return bson.M{field: bson.M{"$regex": regexp.QuoteMeta(s), "$options": "i"}}, nil
+	}
+
+	mongoOps := map[string]string{"=": "$eq", "!=": "$ne", ">": "$gt", ">=": "$gte", "<": "$lt", "<=": "$lte"}
+	mongoOp, ok := mongoOps[op]
+	if !ok {
+		return nil, fmt.Errorf("unknown operator %q", op)
+	}
+
+	// Numbers inside op_data are frequently stored as strings ("1000.000 STEEM"),
+	// so numeric comparisons convert the stored value first
+	if n, isNumber := value.(float64); isNumber && strings.HasPrefix(field, "op_data.") {
+		// Missing or unconvertible values (null) never match, even though null sorts below numbers
+		converted := numericValue(field)
+		return bson.M{"$expr": bson.M{"$and": bson.A{
+			bson.M{"$ne": bson.A{converted, nil}},
+			bson.M{mongoOp: bson.A{converted, n}},
+		}}}, nil
+	}
+
+	return bson.M{field: bson.M{mongoOp: value}}, nil
+}
+
+// numericValue returns an aggregation expression converting a field to a number
+// Strings use their leading token (so "1000.000 STEEM" becomes 1000); unconvertible values become null
+func numericValue(field string) bson.M {
+	ref := "$" + field
+	return bson.M{"$cond": bson.M{
+		"if": bson.M{"$eq": bson.A{bson.M{"$type": ref}, "string"}},
+		"then": bson.M{"$convert": bson.M{
+			"input":   bson.M{"$arrayElemAt": bson.A{bson.M{"$split": bson.A{ref, " "}}, 0}},
+			"to":      "double",
+			"onError": nil,
+			"onNull":  nil,
+		}},
+		"else": bson.M{"$convert": bson.M{"input": ref, "to": "double", "onError": nil, "onNull": nil}},
+	}}
+}
@@ -0,0 +1,135 @@
+package query
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		want bson.M
+	}{
+		{name: "equality", expr: `op_type="transfer"`, want: bson.M{"op_type": bson.M{"$eq": "transfer"}}},
+		{name: "nested field", expr: `op_data.to!="steem.dao"`, want: bson.M{"op_data.to": bson.M{"$ne": "steem.dao"}}},
+		{name: "number outside op_data", expr: "block_num>=100", want: bson.M{"block_num": bson.M{"$gte": 100.0}}},
+		{name: "literals", expr: "op_data.extensions=null", want: bson.M{"op_data.extensions": bson.M{"$eq": nil}}},
+		{name: "timestamp", expr: `timestamp<"2024-01-02"`, want: bson.M{"timestamp": bson.M{"$lt": time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}}},
+		{
+			name: "contains quotes regex input",
+			expr: `op_data.memo~"a.b*(c"`,
+			want: bson.M{"op_data.memo": bson.M{"$regex": `a\.b\*\(c`, "$options": "i"}},
+		},
+		{name: "NOT", expr: `NOT account="alice"`, want: bson.M{"$nor": []bson.M{{"account": bson.M{"$eq": "alice"}}}}},
+		{
+			name: "AND binds tighter than OR",
+			expr: `account="a" OR account="b" and op_type="vote"`,
+			want: bson.M{"$or": []bson.M{
+				{"account": bson.M{"$eq": "a"}},
+				{"$and": []bson.M{{"account": bson.M{"$eq": "b"}}, {"op_type": bson.M{"$eq": "vote"}}}},
+			}},
+		},
+		{
+			name: "parentheses",
+			expr: `not (account="a" OR account="b")`,
+			want: bson.M{"$nor": []bson.M{{"$or": []bson.M{{"account": bson.M{"$eq": "a"}}, {"account": bson.M{"$eq": "b"}}}}}},
+		},
+		{
+			name: "numeric op_data comparison",
+			expr: "op_data.amount>1000",
+			want: bson.M{"$expr": bson.M{"$and": bson.A{
+				bson.M{"$ne": bson.A{numericValue("op_data.amount"), nil}},
+				bson.M{"$gt": bson.A{numericValue("op_data.amount"), 1000.0}},
+			}}},
+		},
+		{
+			name: "negative numeric op_data comparison",
+			expr: "op_data.weight<=-1",
+			want: bson.M{"$expr": bson.M{"$and": bson.A{
+				bson.M{"$ne": bson.A{numericValue("op_data.weight"), nil}},
+				bson.M{"$lte": bson.A{numericValue("op_data.weight"), -1.0}},
+			}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.expr, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		wantErr string
+	}{
+		{name: "operator field", expr: `$where="1"`, wantErr: "unexpected character"},
+		{name: "operator segment", expr: `op_data.$gt=1`, wantErr: "unexpected character"},
+		{name: "field outside the allowlist", expr: `password="x"`, wantErr: `unknown field "password"`},
+		{name: "empty segment", expr: `op_data..to="x"`, wantErr: "invalid field"},
+		{name: "contains a number", expr: "op_data.memo~1", wantErr: "~ requires a string value"},
+		{name: "invalid timestamp", expr: `timestamp>"yesterday"`, wantErr: "invalid timestamp"},
+		{name: "missing value", expr: "account=", wantErr: "unexpected end of query"},
+		{name: "missing operator", expr: `account "alice"`, wantErr: "expected comparison operator"},
+		{name: "unclosed parenthesis", expr: `(account="a"`, wantErr: "missing closing parenthesis"},
+		{name: "trailing token", expr: `account="a" "b"`, wantErr: "unexpected"},
+		{name: "too long", expr: `op_data.memo~"` + strings.Repeat("a", MaxLength) + `"`, wantErr: "query too long"},
+		{
+			name:    "too many conditions",
+			expr:    strings.Repeat(`account="a" OR `, MaxConditions) + `account="a"`,
+			wantErr: "too many conditions",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse(%q) error = %v, want one containing %q", tt.expr, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseLimitsAccepted(t *testing.T) {
+	expr := strings.Repeat(`account="a" OR `, MaxConditions-1) + `account="a"`
+	if _, err := Parse(expr); err != nil {
+		t.Errorf("%d conditions: %v", MaxConditions, err)
+	}
+}
+
+func TestValidateField(t *testing.T) {
+	tests := []struct {
+		field string
+		ok    bool
+	}{
+		{field: "account", ok: true},
+		{field: "op_data.amount", ok: true},
+		{field: "op_data.json_metadata.app", ok: true},
+		{field: "$where"},
+		{field: "op_data.$gt"},
+		{field: "op_data.a$b"},
+		{field: "enrichment.usd_value"},
+		{field: "_id"},
+		{field: "op_data."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			_, err := validateField(tt.field)
+			if (err == nil) != tt.ok {
+				t.Errorf("validateField(%q) error = %v, want ok %v", tt.field, err, tt.ok)
+			}
+		})
+	}
+}
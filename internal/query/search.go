@@ -0,0 +1,115 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// CounterpartyFields are the op_data fields searched for a counterparty account
+var CounterpartyFields = []string{"from", "to", "receiver"}
+
+// amountFields are the op_data fields holding the moved amount (see models.OperationAmount)
+var amountFields = []string{"amount", "payment"}
+
+// symbolPattern restricts asset symbols so they can be used in a regular expression
+var symbolPattern = regexp.MustCompile(`^[A-Z]+$`)
+
+// Search holds the structured criteria of the search endpoint; zero values are ignored
+type Search struct {
+	Memo         string   // Words of op_data.memo, matched through the text index
+	Counterparty string   // Account in one of CounterpartyFields
+	MinAmount    *float64 // Inclusive
+	MaxAmount    *float64 // Inclusive
+	Symbol       string   // Asset symbol of the amount, e.g. "SBD"
+	OpTypes      []string
+	From         time.Time // Inclusive block time
+	To           time.Time // Exclusive block time
+}
+
+// Empty reports whether no criterion is set
+func (s Search) Empty() bool {
+	return s.Memo == "" && s.Counterparty == "" && s.MinAmount == nil && s.MaxAmount == nil &&
+		s.Symbol == "" && len(s.OpTypes) == 0 && s.From.IsZero() && s.To.IsZero()
+}
+
+// Filter compiles the criteria into a MongoDB filter
+func (s Search) Filter() (bson.M, error) {
+	var conditions bson.A
+
+	if s.Memo != "" {
+		conditions = append(conditions, bson.M{"$text": bson.M{"$search": s.Memo}})
+	}
+	if s.Counterparty != "" {
+		var fields bson.A
+		for _, field := range CounterpartyFields {
+			fields = append(fields, bson.M{"op_data." + field: s.Counterparty})
+		}
+		conditions = append(conditions, bson.M{"$or": fields})
+	}
+	if len(s.OpTypes) > 0 {
+		conditions = append(conditions, bson.M{"op_type": bson.M{"$in": s.OpTypes}})
+	}
+	if !s.From.IsZero() || !s.To.IsZero() {
+		timestamp := bson.M{}
+		if !s.From.IsZero() {
+			timestamp["$gte"] = s.From
+		}
+		if !s.To.IsZero() {
+			timestamp["$lt"] = s.To
+		}
+		conditions = append(conditions, bson.M{"timestamp": timestamp})
+	}
+
+	if s.MinAmount != nil || s.MaxAmount != nil || s.Symbol != "" {
+		amount, err := s.amountFilter()
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, amount)
+	}
+
+	if len(conditions) == 0 {
+		return bson.M{}, nil
+	}
+	return bson.M{"$and": conditions}, nil
+}
+
+// amountFilter matches operations whose amount field is within the range and has the symbol
+func (s Search) amountFilter() (bson.M, error) {
+	if s.MinAmount != nil && s.MaxAmount != nil && *s.MinAmount > *s.MaxAmount {
+		return nil, fmt.Errorf("min_amount must not be greater than max_amount")
+	}
+	if s.Symbol != "" && !symbolPattern.MatchString(s.Symbol) {
+		return nil, fmt.Errorf("invalid symbol %q", s.Symbol)
+	}
+
+	var fields bson.A
+	for _, name := range amountFields {
+		field := "op_data." + name
+		var conditions bson.A
+		if s.Symbol != "" {
+			conditions = append(conditions, bson.M{field: bson.M{"$regex": " " + s.Symbol + "$"}})
+		} else {
+			conditions = append(conditions, bson.M{field: bson.M{"$exists": true}})
+		}
+		if s.MinAmount != nil {
+			condition, err := compile(field, ">=", *s.MinAmount)
+			if err != nil {
+				return nil, err
+			}
+			conditions = append(conditions, condition)
+		}
+		if s.MaxAmount != nil {
+			condition, err := compile(field, "<=", *s.MaxAmount)
+			if err != nil {
+				return nil, err
+			}
+			conditions = append(conditions, condition)
+		}
+		fields = append(fields, bson.M{"$and": conditions})
+	}
+	return bson.M{"$or": fields}, nil
+}
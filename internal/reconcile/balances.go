@@ -0,0 +1,52 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+)
+
+// balanceEpsilon is the smallest difference treated as a mismatch, half the chain's precision
+const balanceEpsilon = 0.0005
+
+// BalanceMismatches compares the chain balance change of an account between two snapshots with
+// the change explained by the operations stored for it in the blocks between them
+func BalanceMismatches(ctx context.Context, store *storage.MongoDB, previous, current *models.BalanceSnapshot) ([]models.BalanceMismatch, error) {
+	query := storage.OperationQuery{
+		Account:    current.Account,
+		StartBlock: previous.BlockNum + 1,
+		EndBlock:   current.BlockNum,
+	}
+	stored := make(map[string]float64)
+	err := store.StreamOperations(ctx, query, func(op *models.Operation) error {
+		for symbol, change := range models.BalanceChanges(op) {
+			stored[symbol] += change
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load balance changes: %w", err)
+	}
+
+	var mismatches []models.BalanceMismatch
+	for _, symbol := range []string{"SBD", "STEEM"} {
+		chain := current.Balances[symbol] - previous.Balances[symbol]
+		diff := chain - stored[symbol]
+		if diff < balanceEpsilon && diff > -balanceEpsilon {
+			continue
+		}
+		mismatches = append(mismatches, models.BalanceMismatch{
+			Account:    current.Account,
+			Symbol:     symbol,
+			StartBlock: previous.BlockNum + 1,
+			EndBlock:   current.BlockNum,
+			Chain:      round(chain),
+			Stored:     round(stored[symbol]),
+			From:       previous.TakenAt,
+			To:         current.TakenAt,
+		})
+	}
+	return mismatches, nil
+}
@@ -0,0 +1,121 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Flows breaks the treasury's inflows and outflows down by source for the UTC days in [from, to)
+// Inflation (sps_fund virtual operations and transfers from the inflow accounts) is kept apart
+// from donations, i.e. all other transfers into the treasury
+func Flows(ctx context.Context, store *storage.MongoDB, config models.ReconciliationConfig, from, to time.Time) (*models.TreasuryFlows, error) {
+	from = from.UTC().Truncate(day)
+	to = to.UTC().Truncate(day)
+	if !to.After(from) {
+		return nil, fmt.Errorf("invalid range: %s is not after %s", to.Format(time.DateOnly), from.Format(time.DateOnly))
+	}
+
+	treasury := config.Treasury()[0]
+	isInflow := make(map[string]bool)
+	for _, account := range config.Inflow() {
+		isInflow[account] = true
+	}
+
+	// Treasury records, plus proposal payouts stored for their receivers
+	query := storage.OperationQuery{Filter: bson.M{
+		"timestamp": bson.M{"$gte": from, "$lt": to},
+		"$or": bson.A{
+			bson.M{"account": treasury, "op_type": bson.M{"$in": bson.A{"transfer", "sps_fund"}}},
+			bson.M{"op_type": "proposal_pay"},
+		},
+	}}
+
+	type dayKey struct {
+		date   string
+		symbol string
+	}
+	days := make(map[dayKey]*models.TreasuryFlow)
+	flow := func(op *models.Operation, symbol string) *models.TreasuryFlow {
+		key := dayKey{date: op.Timestamp.UTC().Format(time.DateOnly), symbol: symbol}
+		if days[key] == nil {
+			days[key] = &models.TreasuryFlow{Date: key.date, Symbol: symbol}
+		}
+		return days[key]
+	}
+
+	err := store.StreamOperations(ctx, query, func(op *models.Operation) error {
+		amount, ok := models.OperationAmount(op.OpData)
+		if !ok {
+			return nil
+		}
+		sender, _ := op.OpData["from"].(string)
+		receiver, _ := op.OpData["to"].(string)
+
+		switch op.OpType {
+		case "sps_fund":
+			flow(op, amount.Symbol).Inflation += amount.Amount
+		case "proposal_pay":
+			// Stored once per receiver; skip copies stored for other accounts
+			if payee, _ := op.OpData["receiver"].(string); payee == op.Account {
+				flow(op, amount.Symbol).ProposalPayouts += amount.Amount
+			}
+		case "transfer":
+			switch {
+			case sender == treasury && receiver == treasury:
+				// Moves nothing
+			case sender == treasury:
+				flow(op, amount.Symbol).OtherOutflows += amount.Amount
+			case isInflow[sender]:
+				flow(op, amount.Symbol).Inflation += amount.Amount
+			default:
+				flow(op, amount.Symbol).Donations += amount.Amount
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load treasury flows: %w", err)
+	}
+
+	result := &models.TreasuryFlows{Treasury: treasury, From: from, To: to, Days: []models.TreasuryFlow{}, Totals: []models.TreasuryFlow{}}
+	totals := make(map[string]*models.TreasuryFlow)
+	for _, f := range days {
+		total := totals[f.Symbol]
+		if total == nil {
+			total = &models.TreasuryFlow{Symbol: f.Symbol}
+			totals[f.Symbol] = total
+		}
+		total.Inflation += f.Inflation
+		total.Donations += f.Donations
+		total.ProposalPayouts += f.ProposalPayouts
+		total.OtherOutflows += f.OtherOutflows
+		result.Days = append(result.Days, roundFlow(*f))
+	}
+	for _, total := range totals {
+		result.Totals = append(result.Totals, roundFlow(*total))
+	}
+
+	sort.Slice(result.Days, func(i, j int) bool {
+		if result.Days[i].Date != result.Days[j].Date {
+			return result.Days[i].Date < result.Days[j].Date
+		}
+		return result.Days[i].Symbol < result.Days[j].Symbol
+	})
+	sort.Slice(result.Totals, func(i, j int) bool { return result.Totals[i].Symbol < result.Totals[j].Symbol })
+	return result, nil
+}
+
+// roundFlow rounds every amount of a flow to the chain's precision
+func roundFlow(f models.TreasuryFlow) models.TreasuryFlow {
+	f.Inflation = round(f.Inflation)
+	f.Donations = round(f.Donations)
+	f.ProposalPayouts = round(f.ProposalPayouts)
+	f.OtherOutflows = round(f.OtherOutflows)
+	return f
+}
@@ -0,0 +1,239 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// proposalDateLayout is the format of start_date and end_date in create_proposal (UTC)
+const proposalDateLayout = "2006-01-02T15:04:05"
+
+// day is the reconciliation granularity
+const day = 24 * time.Hour
+
+// proposal is a create_proposal operation reduced to what payouts depend on
+type proposal struct {
+	key      string // creator/permlink
+	receiver string
+	start    time.Time
+	end      time.Time
+	dailyPay models.Asset
+}
+
+// payment is a recorded payout to a proposal receiver
+type payment struct {
+	receiver  string
+	amount    models.Asset
+	timestamp time.Time
+}
+
+// Proposals reconciles proposal payouts for the UTC days in [from, to)
+// Proposals are read from stored create_proposal operations, payouts from proposal_pay
+// operations and transfers sent by the treasury accounts. Both are only stored for tracked
+// accounts, so receivers must be tracked to be reconciled.
+func Proposals(ctx context.Context, store *storage.MongoDB, config models.ReconciliationConfig, from, to time.Time) (*models.ProposalReconciliation, error) {
+	from = from.UTC().Truncate(day)
+	to = to.UTC().Truncate(day)
+	if !to.After(from) {
+		return nil, fmt.Errorf("invalid range: %s is not after %s", to.Format(time.DateOnly), from.Format(time.DateOnly))
+	}
+
+	proposals, err := loadProposals(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := loadPayments(ctx, store, config.Treasury(), from, to)
+	if err != nil {
+		return nil, err
+	}
+	return reconcile(proposals, payments, from, to, config.Tolerance()), nil
+}
+
+// loadProposals returns every stored proposal, once even if stored for several accounts
+func loadProposals(ctx context.Context, store *storage.MongoDB) ([]proposal, error) {
+	seen := make(map[string]bool)
+	var proposals []proposal
+	err := store.StreamOperations(ctx, storage.OperationQuery{OpType: "create_proposal"}, func(op *models.Operation) error {
+		p, ok := parseProposal(op.OpData)
+		if ok && !seen[p.key] {
+			seen[p.key] = true
+			proposals = append(proposals, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load proposals: %w", err)
+	}
+	return proposals, nil
+}
+
+// parseProposal extracts a proposal from create_proposal op_data
+func parseProposal(opData map[string]interface{}) (proposal, bool) {
+	creator, _ := opData["creator"].(string)
+	permlink, _ := opData["permlink"].(string)
+	receiver, _ := opData["receiver"].(string)
+	startDate, _ := opData["start_date"].(string)
+	endDate, _ := opData["end_date"].(string)
+	dailyPay, _ := opData["daily_pay"].(string)
+
+	start, err := time.Parse(proposalDateLayout, startDate)
+	if err != nil {
+		return proposal{}, false
+	}
+	end, err := time.Parse(proposalDateLayout, endDate)
+	if err != nil {
+		return proposal{}, false
+	}
+	pay, err := models.ParseAsset(dailyPay)
+	if err != nil || receiver == "" {
+		return proposal{}, false
+	}
+	return proposal{key: creator + "/" + permlink, receiver: receiver, start: start, end: end, dailyPay: pay}, true
+}
+
+// loadPayments returns proposal_pay operations and treasury transfers in [from, to)
+// Each payout is counted once, from the copy stored for its receiver
+func loadPayments(ctx context.Context, store *storage.MongoDB, treasury []string, from, to time.Time) ([]payment, error) {
+	query := storage.OperationQuery{Filter: bson.M{
+		"op_type":   bson.M{"$in": bson.A{"proposal_pay", "transfer"}},
+		"timestamp": bson.M{"$gte": from, "$lt": to},
+	}}
+	isTreasury := make(map[string]bool, len(treasury))
+	for _, account := range treasury {
+		isTreasury[account] = true
+	}
+
+	var payments []payment
+	err := store.StreamOperations(ctx, query, func(op *models.Operation) error {
+		var receiver string
+		switch op.OpType {
+		case "proposal_pay":
+			receiver, _ = op.OpData["receiver"].(string)
+		case "transfer":
+			sender, _ := op.OpData["from"].(string)
+			if !isTreasury[sender] {
+				return nil
+			}
+			receiver, _ = op.OpData["to"].(string)
+		}
+		if receiver == "" || receiver != op.Account {
+			return nil
+		}
+		amount, ok := models.OperationAmount(op.OpData)
+		if !ok {
+			return nil
+		}
+		payments = append(payments, payment{receiver: receiver, amount: amount, timestamp: op.Timestamp.UTC()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load proposal payouts: %w", err)
+	}
+	return payments, nil
+}
+
+// reconcile compares expected and recorded payouts per receiver and day
+// A proposal is expected to pay daily_pay/24 for every hour of the day it is active
+func reconcile(proposals []proposal, payments []payment, from, to time.Time, tolerancePercent float64) *models.ProposalReconciliation {
+	type dayKey struct {
+		date     time.Time
+		receiver string
+		symbol   string
+	}
+	rows := make(map[dayKey]*models.ProposalPayoutDay)
+	row := func(key dayKey) *models.ProposalPayoutDay {
+		if rows[key] == nil {
+			rows[key] = &models.ProposalPayoutDay{
+				Date:      key.date.Format(time.DateOnly),
+				Receiver:  key.receiver,
+				Proposals: []string{},
+				Symbol:    key.symbol,
+			}
+		}
+		return rows[key]
+	}
+
+	for _, p := range proposals {
+		for date := from; date.Before(to); date = date.Add(day) {
+			active := overlap(p.start, p.end, date, date.Add(day))
+			if active <= 0 {
+				continue
+			}
+			r := row(dayKey{date: date, receiver: p.receiver, symbol: p.dailyPay.Symbol})
+			r.Expected += p.dailyPay.Amount * active.Hours() / 24
+			r.Proposals = append(r.Proposals, p.key)
+		}
+	}
+	for _, pay := range payments {
+		r := row(dayKey{date: pay.timestamp.Truncate(day), receiver: pay.receiver, symbol: pay.amount.Symbol})
+		r.Paid += pay.amount.Amount
+	}
+
+	report := &models.ProposalReconciliation{From: from, To: to, Days: []models.ProposalPayoutDay{}}
+	for _, r := range rows {
+		r.Expected = round(r.Expected)
+		r.Paid = round(r.Paid)
+		r.Difference = round(r.Paid - r.Expected)
+		r.Status = payoutStatus(r.Expected, r.Paid, tolerancePercent)
+		sort.Strings(r.Proposals)
+		if r.Status != models.PayoutStatusOK {
+			report.Discrepancies++
+		}
+		report.Days = append(report.Days, *r)
+	}
+	sort.Slice(report.Days, func(i, j int) bool {
+		a, b := report.Days[i], report.Days[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.Receiver != b.Receiver {
+			return a.Receiver < b.Receiver
+		}
+		return a.Symbol < b.Symbol
+	})
+	return report
+}
+
+// payoutStatus classifies the difference between expected and paid amounts
+func payoutStatus(expected, paid, tolerancePercent float64) string {
+	// Never flag less than the chain's precision
+	tolerance := math.Max(expected*tolerancePercent/100, 0.001)
+	switch {
+	case expected == 0 && paid > 0:
+		return models.PayoutStatusUnexpected
+	case expected > 0 && paid == 0:
+		return models.PayoutStatusUnpaid
+	case paid < expected-tolerance:
+		return models.PayoutStatusUnderpaid
+	case paid > expected+tolerance:
+		return models.PayoutStatusOverpaid
+	default:
+		return models.PayoutStatusOK
+	}
+}
+
+// overlap returns how long [start, end) and [from, to) overlap
+func overlap(start, end, from, to time.Time) time.Duration {
+	if start.Before(from) {
+		start = from
+	}
+	if end.After(to) {
+		end = to
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// round rounds to the chain's 3-decimal precision
+func round(amount float64) float64 {
+	return math.Round(amount*1000) / 1000
+}
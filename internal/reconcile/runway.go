@@ -0,0 +1,112 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// runwaySymbol is the asset proposals are paid in
+const runwaySymbol = "SBD"
+
+// daysPerMonth is the average length of a month
+const daysPerMonth = 30.44
+
+// ErrNoBalanceSnapshot is returned by Runway until the sync service recorded a treasury balance
+var ErrNoBalanceSnapshot = errors.New("no balance snapshot of the treasury yet")
+
+// Runway projects how long the treasury's SBD balance lasts
+// Obligations are the daily_pay of active proposals that received a payout in the last day
+// (i.e. are currently funded); inflow is the average daily inflation credited to the treasury
+// (sps_fund operations and transfers from the inflow accounts) over the lookback period
+func Runway(ctx context.Context, store *storage.MongoDB, config models.ReconciliationConfig, now time.Time) (*models.RunwayProjection, error) {
+	treasury := config.Treasury()[0]
+	snapshot, err := store.LatestBalanceSnapshot(ctx, treasury)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot == nil {
+		return nil, ErrNoBalanceSnapshot
+	}
+
+	proposals, err := loadProposals(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := loadPayments(ctx, store, config.Treasury(), now.Add(-day), now)
+	if err != nil {
+		return nil, err
+	}
+	lookback := config.RunwayLookback()
+	inflow, err := loadInflow(ctx, store, treasury, config.Inflow(), now.AddDate(0, 0, -lookback), now)
+	if err != nil {
+		return nil, err
+	}
+
+	projection := &models.RunwayProjection{
+		Treasury:        treasury,
+		Symbol:          runwaySymbol,
+		Balance:         snapshot.Balances[runwaySymbol],
+		BalanceAt:       snapshot.TakenAt,
+		DailyInflow:     round(inflow / float64(lookback)),
+		FundedProposals: []string{},
+		LookbackDays:    lookback,
+		ComputedAt:      now,
+	}
+
+	paid := make(map[string]bool)
+	for _, pay := range payments {
+		paid[pay.receiver] = true
+	}
+	for _, p := range proposals {
+		active := !now.Before(p.start) && now.Before(p.end)
+		if active && paid[p.receiver] && p.dailyPay.Symbol == runwaySymbol {
+			projection.DailyObligations += p.dailyPay.Amount
+			projection.FundedProposals = append(projection.FundedProposals, p.key)
+		}
+	}
+	sort.Strings(projection.FundedProposals)
+	projection.DailyObligations = round(projection.DailyObligations)
+	projection.NetDailyBurn = round(projection.DailyObligations - projection.DailyInflow)
+
+	if projection.NetDailyBurn > 0 {
+		days := round(projection.Balance / projection.NetDailyBurn)
+		months := round(days / daysPerMonth)
+		projection.RunwayDays = &days
+		projection.RunwayMonths = &months
+	}
+	return projection, nil
+}
+
+// loadInflow sums the SBD credited to the treasury by sps_fund operations and transfers
+// from the senders in [from, to)
+func loadInflow(ctx context.Context, store *storage.MongoDB, treasury string, senders []string, from, to time.Time) (float64, error) {
+	query := storage.OperationQuery{
+		Account: treasury,
+		Filter: bson.M{
+			"timestamp": bson.M{"$gte": from, "$lt": to},
+			"$or": bson.A{
+				bson.M{"op_type": "sps_fund"},
+				bson.M{"op_type": "transfer", "op_data.to": treasury, "op_data.from": bson.M{"$in": senders}},
+			},
+		},
+	}
+
+	var total float64
+	err := store.StreamOperations(ctx, query, func(op *models.Operation) error {
+		if amount, ok := models.OperationAmount(op.OpData); ok && amount.Symbol == runwaySymbol {
+			total += amount.Amount
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to load treasury inflow: %w", err)
+	}
+	return total, nil
+}
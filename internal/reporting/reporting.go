@@ -0,0 +1,133 @@
+package reporting
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/version"
+	"github.com/getsentry/sentry-go"
+)
+
+// Default number of consecutive failures before an error is reported
+const defaultRepeatThreshold = 3
+
+// flushTimeout bounds how long Close and Recover wait for pending events
+const flushTimeout = 5 * time.Second
+
+var (
+	enabled bool
+
+	mu        sync.Mutex
+	threshold = defaultRepeatThreshold
+	failures  = make(map[string]int) // Consecutive failures by key
+)
+
+// Setup initializes error reporting for a component of an instance; without a DSN every function is a no-op
+func Setup(config models.ErrorReportingConfig, component string, labels models.InstanceLabels) error {
+	if config.SentryDSN == "" {
+		return nil
+	}
+
+	err := sentry.Init(sentry.ClientOptions{
+		Dsn:         config.SentryDSN,
+		Environment: config.Environment,
+		Release:     "sps-fund-watcher@" + version.Version,
+		ServerName:  config.ServerName,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize Sentry: %w", err)
+	}
+	sentry.ConfigureScope(func(scope *sentry.Scope) {
+		scope.SetTag("component", component)
+		scope.SetTag("commit", version.Commit)
+		scope.SetTag("instance", labels.Instance)
+		if labels.Profile != "" {
+			scope.SetTag("profile", labels.Profile)
+		}
+	})
+
+	mu.Lock()
+	if config.RepeatThreshold > 0 {
+		threshold = config.RepeatThreshold
+	}
+	mu.Unlock()
+
+	enabled = true
+	slog.Info("Error reporting enabled", "component", component)
+	return nil
+}
+
+// Close sends pending events; call it before the process exits
+func Close() {
+	if enabled {
+		sentry.Flush(flushTimeout)
+	}
+}
+
+// Report sends an error right away
+// fields are key/value pairs like slog attributes, e.g. "block_num", 123, "account", "alice"
+func Report(err error, fields ...any) {
+	if !enabled || err == nil {
+		return
+	}
+	sentry.WithScope(func(scope *sentry.Scope) {
+		for key, value := range pairs(fields) {
+			// Tags are searchable in Sentry; keep them short strings
+			scope.SetTag(key, fmt.Sprint(value))
+		}
+		sentry.CaptureException(err)
+	})
+}
+
+// Failure records a failed attempt of a recurring task identified by key, e.g. "sync"
+// The error is reported once the task failed threshold times in a row, and then again
+// every threshold failures while it keeps failing
+func Failure(key string, err error, fields ...any) {
+	if !enabled || err == nil {
+		return
+	}
+	mu.Lock()
+	failures[key]++
+	count := failures[key]
+	mu.Unlock()
+
+	if count%threshold == 0 {
+		Report(fmt.Errorf("%s failed %d times in a row: %w", key, count, err), append(fields, "task", key)...)
+	}
+}
+
+// Success resets the failure count of a recurring task
+func Success(key string) {
+	if !enabled {
+		return
+	}
+	mu.Lock()
+	delete(failures, key)
+	mu.Unlock()
+}
+
+// Recover reports a panic and re-panics; use it as `defer reporting.Recover()` at the top of goroutines
+func Recover() {
+	if !enabled {
+		return
+	}
+	if recovered := recover(); recovered != nil {
+		sentry.CurrentHub().Recover(recovered)
+		sentry.Flush(flushTimeout)
+		panic(recovered)
+	}
+}
+
+// pairs converts slog-style key/value arguments to a map
+func pairs(fields []any) map[string]any {
+	result := make(map[string]any, len(fields)/2)
+	for i := 0; i+1 < len(fields); i += 2 {
+		if key, ok := fields[i].(string); ok {
+			result[key] = fields[i+1]
+		}
+	}
+	return result
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ListAccountMetadata returns the metadata of all accounts ordered by account
+func (m *MongoDB) ListAccountMetadata(ctx context.Context) ([]models.AccountMetadata, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	cursor, err := m.accountMetadata.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find account metadata: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var metadata []models.AccountMetadata
+	if err := cursor.All(ctx, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode account metadata: %w", err)
+	}
+	return metadata, nil
+}
+
+// GetAccountMetadata returns the metadata of an account, or ErrNotFound
+func (m *MongoDB) GetAccountMetadata(ctx context.Context, account string) (*models.AccountMetadata, error) {
+	var metadata models.AccountMetadata
+	err := m.accountMetadata.FindOne(ctx, bson.M{"_id": account}).Decode(&metadata)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account metadata: %w", err)
+	}
+	return &metadata, nil
+}
+
+// SaveAccountMetadata creates or replaces the metadata of an account
+func (m *MongoDB) SaveAccountMetadata(ctx context.Context, metadata *models.AccountMetadata) error {
+	metadata.UpdatedAt = time.Now()
+	opts := options.Replace().SetUpsert(true)
+	if _, err := m.accountMetadata.ReplaceOne(ctx, bson.M{"_id": metadata.Account}, metadata, opts); err != nil {
+		return fmt.Errorf("failed to save account metadata: %w", err)
+	}
+	return nil
+}
+
+// DeleteAccountMetadata removes the metadata of an account, or returns ErrNotFound
+func (m *MongoDB) DeleteAccountMetadata(ctx context.Context, account string) error {
+	result, err := m.accountMetadata.DeleteOne(ctx, bson.M{"_id": account})
+	if err != nil {
+		return fmt.Errorf("failed to delete account metadata: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// IncrementAggregates adds hourly operation counts
+func (m *MongoDB) IncrementAggregates(ctx context.Context, aggregates []models.OperationAggregate) error {
+	if len(aggregates) == 0 {
+		return nil
+	}
+
+	var writes []mongo.WriteModel
+	for _, aggregate := range aggregates {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"account": aggregate.Account, "op_type": aggregate.OpType, "hour": aggregate.Hour}).
+			SetUpdate(bson.M{"$inc": bson.M{"count": aggregate.Count}}).
+			SetUpsert(true))
+	}
+
+	if _, err := m.aggregates.BulkWrite(ctx, writes); err != nil {
+		return fmt.Errorf("failed to increment aggregates: %w", err)
+	}
+	return nil
+}
+
+// GetAggregates returns hourly operation counts for an account in [from, to), newest first
+// Empty opType matches all types; zero times leave the range open
+func (m *MongoDB) GetAggregates(ctx context.Context, account, opType string, from, to time.Time) ([]models.OperationAggregate, error) {
+	filter := bson.M{"account": account}
+	if opType != "" {
+		filter["op_type"] = opType
+	}
+	hour := bson.M{}
+	if !from.IsZero() {
+		hour["$gte"] = from
+	}
+	if !to.IsZero() {
+		hour["$lt"] = to
+	}
+	if len(hour) > 0 {
+		filter["hour"] = hour
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "hour", Value: -1}, {Key: "op_type", Value: 1}})
+	cursor, err := m.aggregates.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find aggregates: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var aggregates []models.OperationAggregate
+	if err := cursor.All(ctx, &aggregates); err != nil {
+		return nil, fmt.Errorf("failed to decode aggregates: %w", err)
+	}
+	return aggregates, nil
+}
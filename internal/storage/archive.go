@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SaveArchivedBlocks stores raw blocks, replacing earlier copies of the same blocks
+func (m *MongoDB) SaveArchivedBlocks(ctx context.Context, blocks []*models.ArchivedBlock) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	var writes []mongo.WriteModel
+	for _, block := range blocks {
+		block.ArchivedAt = now
+		writes = append(writes, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"block_num": block.BlockNum}).
+			SetReplacement(block).
+			SetUpsert(true))
+	}
+
+	return m.guard(func() error {
+		if _, err := m.blocks.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+			return fmt.Errorf("failed to archive blocks: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetArchivedBlocks returns the archived blocks in [startBlock, endBlock], oldest first
+func (m *MongoDB) GetArchivedBlocks(ctx context.Context, startBlock, endBlock int64) ([]models.ArchivedBlock, error) {
+	filter := bson.M{"block_num": bson.M{"$gte": startBlock, "$lte": endBlock}}
+	opts := options.Find().SetSort(bson.D{{Key: "block_num", Value: 1}})
+
+	cursor, err := m.blocks.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find archived blocks: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var blocks []models.ArchivedBlock
+	if err := cursor.All(ctx, &blocks); err != nil {
+		return nil, fmt.Errorf("failed to decode archived blocks: %w", err)
+	}
+	return blocks, nil
+}
+
+// ArchivedBlockRange returns the first and last archived block numbers, or ErrNotFound when
+// nothing is archived
+func (m *MongoDB) ArchivedBlockRange(ctx context.Context) (int64, int64, error) {
+	var first, last models.ArchivedBlock
+	opts := options.FindOne().SetProjection(bson.M{"block_num": 1})
+	if err := m.blocks.FindOne(ctx, bson.M{}, opts.SetSort(bson.D{{Key: "block_num", Value: 1}})).Decode(&first); err != nil {
+		return 0, 0, err
+	}
+	if err := m.blocks.FindOne(ctx, bson.M{}, opts.SetSort(bson.D{{Key: "block_num", Value: -1}})).Decode(&last); err != nil {
+		return 0, 0, err
+	}
+	return first.BlockNum, last.BlockNum, nil
+}
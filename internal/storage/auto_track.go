@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ListAutoTracked returns the automatically tracked proposal receivers, by account name
+func (m *MongoDB) ListAutoTracked(ctx context.Context) ([]models.AutoTrackedAccount, error) {
+	cursor, err := m.autoTracked.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find auto-tracked accounts: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var accounts []models.AutoTrackedAccount
+	if err := cursor.All(ctx, &accounts); err != nil {
+		return nil, fmt.Errorf("failed to decode auto-tracked accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+// SaveAutoTracked inserts or replaces an automatically tracked receiver
+func (m *MongoDB) SaveAutoTracked(ctx context.Context, account *models.AutoTrackedAccount) error {
+	_, err := m.autoTracked.ReplaceOne(ctx, bson.M{"_id": account.Account}, account, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to save auto-tracked account: %w", err)
+	}
+	return nil
+}
+
+// DeleteAutoTracked stops tracking a receiver automatically
+func (m *MongoDB) DeleteAutoTracked(ctx context.Context, account string) error {
+	if _, err := m.autoTracked.DeleteOne(ctx, bson.M{"_id": account}); err != nil {
+		return fmt.Errorf("failed to delete auto-tracked account: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SaveBalanceSnapshot stores a balance snapshot
+func (m *MongoDB) SaveBalanceSnapshot(ctx context.Context, snapshot *models.BalanceSnapshot) error {
+	if _, err := m.balances.InsertOne(ctx, snapshot); err != nil {
+		return fmt.Errorf("failed to save balance snapshot: %w", err)
+	}
+	return nil
+}
+
+// LatestBalanceSnapshot returns the newest snapshot of an account, or nil if there is none
+func (m *MongoDB) LatestBalanceSnapshot(ctx context.Context, account string) (*models.BalanceSnapshot, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "taken_at", Value: -1}})
+	var snapshot models.BalanceSnapshot
+	err := m.balances.FindOne(ctx, bson.M{"account": account}, opts).Decode(&snapshot)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// LastCheckedBalanceSnapshot returns the newest snapshot of an account compared by the balance
+// check, or nil if there is none
+func (m *MongoDB) LastCheckedBalanceSnapshot(ctx context.Context, account string) (*models.BalanceSnapshot, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "block_num", Value: -1}})
+	var snapshot models.BalanceSnapshot
+	err := m.balances.FindOne(ctx, bson.M{"account": account, "checked": true}, opts).Decode(&snapshot)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checked balance snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// UncheckedBalanceSnapshots returns the snapshots of an account taken at a known block in
+// (afterBlock, maxBlock] that the balance check has not compared yet, in block order
+func (m *MongoDB) UncheckedBalanceSnapshots(ctx context.Context, account string, afterBlock, maxBlock int64) ([]models.BalanceSnapshot, error) {
+	filter := bson.M{
+		"account":   account,
+		"checked":   bson.M{"$ne": true},
+		"block_num": bson.M{"$gt": afterBlock, "$lte": maxBlock},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "block_num", Value: 1}})
+	cursor, err := m.balances.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find balance snapshots: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var snapshots []models.BalanceSnapshot
+	if err := cursor.All(ctx, &snapshots); err != nil {
+		return nil, fmt.Errorf("failed to decode balance snapshots: %w", err)
+	}
+	return snapshots, nil
+}
+
+// MarkBalanceSnapshotChecked records that the balance check compared a snapshot
+func (m *MongoDB) MarkBalanceSnapshotChecked(ctx context.Context, account string, blockNum int64) error {
+	filter := bson.M{"account": account, "block_num": blockNum}
+	if _, err := m.balances.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"checked": true}}); err != nil {
+		return fmt.Errorf("failed to mark balance snapshot checked: %w", err)
+	}
+	return nil
+}
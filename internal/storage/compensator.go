@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetCompensatorJob returns the saved progress of a compensator job, or ErrNotFound
+func (m *MongoDB) GetCompensatorJob(ctx context.Context, id string) (*models.CompensatorJob, error) {
+	var job models.CompensatorJob
+	err := m.compensatorJobs.FindOne(ctx, bson.M{"_id": id}).Decode(&job)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get compensator job: %w", err)
+	}
+	return &job, nil
+}
+
+// SaveCompensatorJob stores the progress of a compensator job
+func (m *MongoDB) SaveCompensatorJob(ctx context.Context, job *models.CompensatorJob) error {
+	opts := options.Replace().SetUpsert(true)
+	if _, err := m.compensatorJobs.ReplaceOne(ctx, bson.M{"_id": job.ID}, job, opts); err != nil {
+		return fmt.Errorf("failed to save compensator job: %w", err)
+	}
+	return nil
+}
+
+// RecentCompensatorJobs returns up to limit completed jobs, most recently finished first
+func (m *MongoDB) RecentCompensatorJobs(ctx context.Context, limit int64) ([]models.CompensatorJob, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "updated_at", Value: -1}}).SetLimit(limit)
+	cursor, err := m.compensatorJobs.Find(ctx, bson.M{"completed": true}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find completed compensator jobs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var jobs []models.CompensatorJob
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, fmt.Errorf("failed to decode completed compensator jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// ScheduleCompensatorJob queues a job for compensator -scheduled unless a job with the same ID exists
+// Returns whether the job was queued
+func (m *MongoDB) ScheduleCompensatorJob(ctx context.Context, job *models.CompensatorJob) (bool, error) {
+	job.Scheduled = true
+	update := bson.M{"$setOnInsert": job}
+	result, err := m.compensatorJobs.UpdateOne(ctx, bson.M{"_id": job.ID}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return false, fmt.Errorf("failed to schedule compensator job: %w", err)
+	}
+	return result.UpsertedCount > 0, nil
+}
+
+// ScheduledCompensatorJobs returns the queued jobs that have not completed, oldest first
+func (m *MongoDB) ScheduledCompensatorJobs(ctx context.Context) ([]models.CompensatorJob, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "started_at", Value: 1}})
+	cursor, err := m.compensatorJobs.Find(ctx, bson.M{"scheduled": true, "completed": false}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find scheduled compensator jobs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var jobs []models.CompensatorJob
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, fmt.Errorf("failed to decode scheduled compensator jobs: %w", err)
+	}
+	return jobs, nil
+}
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetCoverage returns the merged block ranges scanned for an account
+// An account that was never scanned has no ranges
+func (m *MongoDB) GetCoverage(ctx context.Context, account string) ([]models.BlockRange, error) {
+	var coverage models.AccountCoverage
+	err := m.coverage.FindOne(ctx, bson.M{"_id": account}).Decode(&coverage)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get coverage: %w", err)
+	}
+	return coverage.Ranges, nil
+}
+
+// AddCoverage records that [start, end] has been scanned for the given accounts
+func (m *MongoDB) AddCoverage(ctx context.Context, accounts []string, start, end int64) error {
+	for _, account := range accounts {
+		ranges, err := m.GetCoverage(ctx, account)
+		if err != nil {
+			return err
+		}
+		ranges = models.MergeRanges(append(ranges, models.BlockRange{Start: start, End: end}))
+
+		update := bson.M{"$set": bson.M{"ranges": ranges, "updated_at": time.Now()}}
+		opts := options.Update().SetUpsert(true)
+		if _, err := m.coverage.UpdateOne(ctx, bson.M{"_id": account}, update, opts); err != nil {
+			return fmt.Errorf("failed to update coverage for %s: %w", account, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	stdsync "sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// maxRecentSlowQueries is the number of slow commands kept for the diagnostics endpoint
+const maxRecentSlowQueries = 50
+
+// maxSlowQueryDetail truncates logged command documents
+const maxSlowQueryDetail = 500
+
+// slowQueryLog captures MongoDB commands that exceed a duration threshold
+type slowQueryLog struct {
+	threshold atomic.Int64 // Nanoseconds; 0 disables capture
+	total     atomic.Int64
+	started   stdsync.Map // Request ID -> started command, kept only while capture is enabled
+
+	mu     stdsync.Mutex
+	recent []models.SlowQuery
+}
+
+// startedCommand is the part of a started event needed to describe a slow command
+type startedCommand struct {
+	collection string
+	detail     string
+}
+
+// monitor returns the driver command monitor feeding the log
+func (l *slowQueryLog) monitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, e *event.CommandStartedEvent) {
+			if l.threshold.Load() == 0 {
+				return
+			}
+			detail := e.Command.String()
+			if len(detail) > maxSlowQueryDetail {
+				detail = detail[:maxSlowQueryDetail] + "..."
+			}
+			collection, _ := e.Command.Lookup(e.CommandName).StringValueOK()
+			l.started.Store(e.RequestID, startedCommand{collection: collection, detail: detail})
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			l.finish(e.CommandFinishedEvent, false)
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			l.finish(e.CommandFinishedEvent, true)
+		},
+	}
+}
+
+// finish records a command if it was slower than the threshold
+func (l *slowQueryLog) finish(e event.CommandFinishedEvent, failed bool) {
+	value, ok := l.started.LoadAndDelete(e.RequestID)
+	threshold := time.Duration(l.threshold.Load())
+	if !ok || threshold == 0 || e.Duration < threshold {
+		return
+	}
+	started := value.(startedCommand)
+
+	query := models.SlowQuery{
+		Command:    e.CommandName,
+		Collection: started.collection,
+		DurationMS: e.Duration.Milliseconds(),
+		Failed:     failed,
+		Detail:     started.detail,
+		At:         time.Now(),
+	}
+	logger.Warn("Slow MongoDB command", "command", query.Command, "collection", query.Collection, "duration_ms", query.DurationMS, "detail", query.Detail)
+
+	l.total.Add(1)
+	l.mu.Lock()
+	l.recent = append([]models.SlowQuery{query}, l.recent...)
+	if len(l.recent) > maxRecentSlowQueries {
+		l.recent = l.recent[:maxRecentSlowQueries]
+	}
+	l.mu.Unlock()
+}
+
+// stats returns a snapshot of the captured slow commands
+func (l *slowQueryLog) stats() models.SlowQueryStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return models.SlowQueryStats{
+		ThresholdMS: time.Duration(l.threshold.Load()).Milliseconds(),
+		Total:       l.total.Load(),
+		Recent:      append([]models.SlowQuery{}, l.recent...),
+	}
+}
+
+// SetSlowQueryThreshold logs MongoDB commands slower than threshold (0 disables)
+func (m *MongoDB) SetSlowQueryThreshold(threshold time.Duration) {
+	m.slowQueries.threshold.Store(int64(threshold))
+}
+
+// queryShape is a common query issued by the API or sync service, with the index it should use
+type queryShape struct {
+	name          string
+	collection    string
+	filter        bson.M
+	sort          bson.D
+	expectedIndex string
+}
+
+// IndexReport runs explain() on the common query shapes and reports index usage
+// The sample account is used to build realistic filters
+func (m *MongoDB) IndexReport(ctx context.Context, account string) (*models.IndexReport, error) {
+	byBlock := bson.D{{Key: "block_num", Value: -1}, {Key: "timestamp", Value: -1}}
+	shapes := []queryShape{
+		{
+			name:          "account operations",
+			collection:    operationsCollection,
+			filter:        bson.M{"account": account},
+			sort:          byBlock,
+			expectedIndex: "account_1_block_num_-1",
+		},
+		{
+			name:          "account operations by type",
+			collection:    operationsCollection,
+			filter:        bson.M{"account": account, "op_type": "transfer"},
+			sort:          byBlock,
+			expectedIndex: "account_1_block_num_-1",
+		},
+		{
+			name:          "operations in blocks",
+			collection:    operationsCollection,
+			filter:        bson.M{"block_num": bson.M{"$in": bson.A{int64(1), int64(2)}}},
+			expectedIndex: "block_num_1_trx_id_1_op_in_trx_1_account_1",
+		},
+		{
+			name:          "account aggregates",
+			collection:    aggregatesCollection,
+			filter:        bson.M{"account": account},
+			sort:          bson.D{{Key: "hour", Value: -1}},
+			expectedIndex: "account_1_op_type_1_hour_1",
+		},
+		{
+			name:          "view by name",
+			collection:    viewsCollection,
+			filter:        bson.M{"name": "example"},
+			expectedIndex: "name_1",
+		},
+	}
+
+	report := &models.IndexReport{
+		GeneratedAt: time.Now(),
+		SlowQueries: m.slowQueries.stats(),
+	}
+	for _, shape := range shapes {
+		report.Queries = append(report.Queries, m.explain(ctx, shape))
+	}
+	return report, nil
+}
+
+// explainResult is the subset of explain output used for reporting
+type explainResult struct {
+	QueryPlanner struct {
+		WinningPlan bson.M `bson:"winningPlan"`
+	} `bson:"queryPlanner"`
+	ExecutionStats struct {
+		NReturned           int64 `bson:"nReturned"`
+		ExecutionTimeMillis int64 `bson:"executionTimeMillis"`
+		TotalKeysExamined   int64 `bson:"totalKeysExamined"`
+		TotalDocsExamined   int64 `bson:"totalDocsExamined"`
+	} `bson:"executionStats"`
+}
+
+// explain runs a find explain for a query shape
+func (m *MongoDB) explain(ctx context.Context, shape queryShape) models.QueryPlanReport {
+	report := models.QueryPlanReport{
+		Name:          shape.name,
+		Collection:    shape.collection,
+		Filter:        fmt.Sprintf("%v", shape.filter),
+		ExpectedIndex: shape.expectedIndex,
+	}
+
+	find := bson.D{{Key: "find", Value: shape.collection}, {Key: "filter", Value: shape.filter}, {Key: "limit", Value: 20}}
+	if len(shape.sort) > 0 {
+		find = append(find, bson.E{Key: "sort", Value: shape.sort})
+	}
+	command := bson.D{{Key: "explain", Value: find}, {Key: "verbosity", Value: "executionStats"}}
+
+	var result explainResult
+	if err := m.database.RunCommand(ctx, command).Decode(&result); err != nil {
+		report.Error = err.Error()
+		return report
+	}
+
+	collectPlan(result.QueryPlanner.WinningPlan, &report)
+	report.KeysExamined = result.ExecutionStats.TotalKeysExamined
+	report.DocsExamined = result.ExecutionStats.TotalDocsExamined
+	report.Returned = result.ExecutionStats.NReturned
+	report.ExecutionMS = result.ExecutionStats.ExecutionTimeMillis
+
+	report.UsesIndex = true
+	for _, stage := range report.Stages {
+		if stage == "COLLSCAN" {
+			report.UsesIndex = false
+		}
+	}
+	if !containsString(report.UsedIndexes, shape.expectedIndex) {
+		report.UsesIndex = false
+	}
+	return report
+}
+
+// collectPlan walks a plan tree and records stage names and used indexes
+// Newer servers wrap the classic plan in queryPlan (slot-based execution)
+func collectPlan(plan bson.M, report *models.QueryPlanReport) {
+	if plan == nil {
+		return
+	}
+	if inner, ok := plan["queryPlan"].(bson.M); ok {
+		collectPlan(inner, report)
+		return
+	}
+
+	if stage, ok := plan["stage"].(string); ok {
+		report.Stages = append(report.Stages, stage)
+	}
+	if index, ok := plan["indexName"].(string); ok && !containsString(report.UsedIndexes, index) {
+		report.UsedIndexes = append(report.UsedIndexes, index)
+	}
+	if input, ok := plan["inputStage"].(bson.M); ok {
+		collectPlan(input, report)
+	}
+	if inputs, ok := plan["inputStages"].(bson.A); ok {
+		for _, input := range inputs {
+			if child, ok := input.(bson.M); ok {
+				collectPlan(child, report)
+			}
+		}
+	}
+}
+
+// containsString reports whether values contains s
+func containsString(values []string, s string) bool {
+	for _, value := range values {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
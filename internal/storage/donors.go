@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Donors totals the transfers into the treasury in one asset by sender, largest first
+// Transfers from the excluded senders (e.g. inflation sources) are not donations; a zero
+// from leaves the range open
+func (m *MongoDB) Donors(ctx context.Context, treasury, symbol string, exclude []string, from, to time.Time) ([]models.Donor, error) {
+	timestamp := bson.M{"$lt": to}
+	if !from.IsZero() {
+		timestamp["$gte"] = from
+	}
+	match := bson.M{
+		"account":      treasury,
+		"op_type":      "transfer",
+		"timestamp":    timestamp,
+		"op_data.to":   treasury,
+		"op_data.from": bson.M{"$nin": append([]string{treasury}, exclude...)},
+	}
+
+	amountParts := bson.M{"$split": bson.A{"$op_data.amount", " "}}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$project", Value: bson.M{
+			"account":   "$op_data.from",
+			"symbol":    bson.M{"$arrayElemAt": bson.A{amountParts, 1}},
+			"amount":    bson.M{"$convert": bson.M{"input": bson.M{"$arrayElemAt": bson.A{amountParts, 0}}, "to": "double", "onError": 0, "onNull": 0}},
+			"timestamp": 1,
+		}}},
+		{{Key: "$match", Value: bson.M{"symbol": symbol}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":           "$account",
+			"amount":        bson.M{"$sum": "$amount"},
+			"count":         bson.M{"$sum": 1},
+			"last_donation": bson.M{"$max": "$timestamp"},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":           0,
+			"account":       "$_id",
+			"symbol":        symbol,
+			"amount":        1,
+			"count":         1,
+			"last_donation": 1,
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "amount", Value: -1}, {Key: "account", Value: 1}}}},
+	}
+
+	cursor, err := m.operations.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate donors: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var donors []models.Donor
+	if err := cursor.All(ctx, &donors); err != nil {
+		return nil, fmt.Errorf("failed to decode donors: %w", err)
+	}
+	for i := range donors {
+		donors[i].Amount = math.Round(donors[i].Amount*1000) / 1000
+	}
+	return donors, nil
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// OperationCursor is a position in the (block_num, _id) order of stored operations
+// The zero cursor is before every operation
+type OperationCursor struct {
+	BlockNum int64
+	ID       primitive.ObjectID
+}
+
+// CursorOf returns the position of a stored operation
+func CursorOf(op *models.Operation) (OperationCursor, error) {
+	id, err := primitive.ObjectIDFromHex(op.ID)
+	if err != nil {
+		return OperationCursor{}, fmt.Errorf("invalid operation ID %q: %w", op.ID, err)
+	}
+	return OperationCursor{BlockNum: op.BlockNum, ID: id}, nil
+}
+
+var cursorOrder = bson.D{{Key: "block_num", Value: 1}, {Key: "_id", Value: 1}}
+
+// OperationsAfter returns up to limit operations matching a query that come after cursor, in cursor order
+func (m *MongoDB) OperationsAfter(ctx context.Context, query OperationQuery, cursor OperationCursor, limit int) ([]models.Operation, error) {
+	after := bson.M{"$or": bson.A{
+		bson.M{"block_num": bson.M{"$gt": cursor.BlockNum}},
+		bson.M{"block_num": cursor.BlockNum, "_id": bson.M{"$gt": cursor.ID}},
+	}}
+	filter := bson.M{"$and": bson.A{query.filter(), after}}
+	opts := options.Find().SetSort(cursorOrder).SetLimit(int64(limit))
+
+	found, err := m.operations.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find operations: %w", err)
+	}
+	defer found.Close(ctx)
+
+	var operations []models.Operation
+	if err := found.All(ctx, &operations); err != nil {
+		return nil, fmt.Errorf("failed to decode operations: %w", err)
+	}
+	return operations, nil
+}
+
+// LatestOperationCursor returns the position of the last operation matching a query,
+// or the zero cursor when none is stored
+func (m *MongoDB) LatestOperationCursor(ctx context.Context, query OperationQuery) (OperationCursor, error) {
+	opts := options.FindOne().
+		SetSort(bson.D{{Key: "block_num", Value: -1}, {Key: "_id", Value: -1}}).
+		SetProjection(bson.M{"block_num": 1})
+
+	var latest struct {
+		ID       primitive.ObjectID `bson:"_id"`
+		BlockNum int64              `bson:"block_num"`
+	}
+	err := m.operations.FindOne(ctx, query.filter(), opts).Decode(&latest)
+	if err == mongo.ErrNoDocuments {
+		return OperationCursor{}, nil
+	}
+	if err != nil {
+		return OperationCursor{}, fmt.Errorf("failed to find latest operation: %w", err)
+	}
+	return OperationCursor{BlockNum: latest.BlockNum, ID: latest.ID}, nil
+}
@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// FundEventQuery describes which fund events to list
+type FundEventQuery struct {
+	Account string
+	Kinds   []string
+
+	// Optional block time range; From is inclusive, To exclusive (zero means unbounded)
+	From time.Time
+	To   time.Time
+}
+
+func (q FundEventQuery) filter() bson.M {
+	filter := bson.M{}
+	if q.Account != "" {
+		filter["account"] = q.Account
+	}
+	switch len(q.Kinds) {
+	case 0:
+	case 1:
+		filter["kind"] = q.Kinds[0]
+	default:
+		filter["kind"] = bson.M{"$in": q.Kinds}
+	}
+	if !q.From.IsZero() || !q.To.IsZero() {
+		timeRange := bson.M{}
+		if !q.From.IsZero() {
+			timeRange["$gte"] = q.From
+		}
+		if !q.To.IsZero() {
+			timeRange["$lt"] = q.To
+		}
+		filter["timestamp"] = timeRange
+	}
+	return filter
+}
+
+// SaveFundEvents stores derived fund events and returns the ones that were not stored before
+// Events are keyed by their ID, so deriving the same event again only refreshes it
+func (m *MongoDB) SaveFundEvents(ctx context.Context, events []models.FundEvent) ([]models.FundEvent, error) {
+	now := time.Now().UTC()
+	var created []models.FundEvent
+	for _, event := range events {
+		update := bson.M{
+			"$set": bson.M{
+				"kind":      event.Kind,
+				"account":   event.Account,
+				"block_num": event.BlockNum,
+				"timestamp": event.Timestamp,
+				"summary":   event.Summary,
+				"trx_id":    event.TrxID,
+				"op_in_trx": event.OpInTrx,
+				"op_type":   event.OpType,
+				"data":      event.Data,
+			},
+			"$setOnInsert": bson.M{"created_at": now},
+		}
+		var result *mongo.UpdateResult
+		err := m.guard(func() error {
+			var err error
+			result, err = m.fundEvents.UpdateByID(ctx, event.ID, update, options.Update().SetUpsert(true))
+			return err
+		})
+		if err != nil {
+			return created, fmt.Errorf("failed to save fund event %s: %w", event.ID, err)
+		}
+		if result.UpsertedCount > 0 {
+			event.CreatedAt = now
+			created = append(created, event)
+		}
+	}
+	return created, nil
+}
+
+// QueryFundEvents lists fund events matching a query, newest first
+func (m *MongoDB) QueryFundEvents(ctx context.Context, query FundEventQuery, page, pageSize int) (*models.FundEventResponse, error) {
+	filter := query.filter()
+
+	total, err := m.fundEvents.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count fund events: %w", err)
+	}
+
+	skip := int64((page - 1) * pageSize)
+	opts := options.Find().
+		SetSort(bson.D{{Key: "block_num", Value: -1}, {Key: "_id", Value: 1}}).
+		SetSkip(skip).
+		SetLimit(int64(pageSize))
+
+	cursor, err := m.fundEvents.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find fund events: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	events := []models.FundEvent{}
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, fmt.Errorf("failed to decode fund events: %w", err)
+	}
+
+	return &models.FundEventResponse{
+		Events:   events,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+		HasMore:  skip+int64(len(events)) < total,
+	}, nil
+}
+
+// LatestPayoutRound returns the block of the most recent stored payout round and the receivers
+// paid in it, or 0 and nil before the first one
+func (m *MongoDB) LatestPayoutRound(ctx context.Context) (int64, []string, error) {
+	var latest models.FundEvent
+	opts := options.FindOne().SetSort(bson.D{{Key: "block_num", Value: -1}})
+	err := m.fundEvents.FindOne(ctx, bson.M{"kind": models.FundEventPayoutMade}, opts).Decode(&latest)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil, nil
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to find latest payout: %w", err)
+	}
+
+	values, err := m.fundEvents.Distinct(ctx, "account", bson.M{"kind": models.FundEventPayoutMade, "block_num": latest.BlockNum})
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to list paid receivers: %w", err)
+	}
+	receivers := make([]string, 0, len(values))
+	for _, value := range values {
+		if receiver, ok := value.(string); ok {
+			receivers = append(receivers, receiver)
+		}
+	}
+	return latest.BlockNum, receivers, nil
+}
+
+// DeleteFundEventsInBlocks removes the fund events derived from the given blocks except those
+// with an ID in keep, e.g. after a fork
+func (m *MongoDB) DeleteFundEventsInBlocks(ctx context.Context, blocks []int64, keep []string) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+	filter := bson.M{"block_num": bson.M{"$in": blocks}}
+	if len(keep) > 0 {
+		filter["_id"] = bson.M{"$nin": keep}
+	}
+	return m.guard(func() error {
+		if _, err := m.fundEvents.DeleteMany(ctx, filter); err != nil {
+			return fmt.Errorf("failed to delete fund events: %w", err)
+		}
+		return nil
+	})
+}
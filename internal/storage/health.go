@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	stdsync "sync"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/reporting"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+)
+
+// ErrUnavailable is returned without contacting MongoDB while the circuit breaker is open
+var ErrUnavailable = errors.New("mongodb unavailable: circuit breaker open")
+
+// Circuit breaker tuning
+const (
+	breakerThreshold = 5                // Consecutive transient failures that open the circuit
+	breakerCooldown  = 10 * time.Second // How long the circuit stays open before a trial call
+)
+
+// IsTransient reports whether err is a connectivity problem that is expected to resolve
+// once MongoDB is reachable again (as opposed to e.g. a validation error)
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	return errors.Is(err, ErrUnavailable) ||
+		errors.Is(err, mongo.ErrClientDisconnected) ||
+		mongo.IsNetworkError(err) ||
+		mongo.IsTimeout(err) ||
+		errors.As(err, &topology.ServerSelectionError{})
+}
+
+// circuitBreaker fails calls fast after repeated transient failures
+type circuitBreaker struct {
+	mu        stdsync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// allow returns ErrUnavailable while the circuit is open
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if time.Now().Before(b.openUntil) {
+		return ErrUnavailable
+	}
+	return nil
+}
+
+// record updates the breaker with the outcome of a call
+func (b *circuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !IsTransient(err) {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= breakerThreshold {
+		if time.Now().After(b.openUntil) {
+			logger.Warn("MongoDB circuit breaker open", "failures", b.failures, "error", err)
+		}
+		b.openUntil = time.Now().Add(breakerCooldown)
+	}
+}
+
+// reset closes the circuit
+func (b *circuitBreaker) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.openUntil = time.Time{}
+}
+
+// guard runs a storage call through the circuit breaker
+func (m *MongoDB) guard(call func() error) error {
+	if err := m.breaker.allow(); err != nil {
+		return err
+	}
+	err := call()
+	m.breaker.record(err)
+	return err
+}
+
+// Healthy reports the result of the most recent health check (true before the first one)
+func (m *MongoDB) Healthy() bool {
+	return !m.unhealthy.Load()
+}
+
+// Ping checks that MongoDB answers, bypassing the circuit breaker
+func (m *MongoDB) Ping(ctx context.Context) error {
+	if err := m.client.Ping(ctx, nil); err != nil {
+		return fmt.Errorf("failed to ping MongoDB: %w", err)
+	}
+	return nil
+}
+
+// StartHealthMonitor pings MongoDB every interval until ctx is done
+// A successful ping closes the circuit breaker, so writes resume as soon as MongoDB is back
+func (m *MongoDB) StartHealthMonitor(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.checkHealth(ctx)
+			}
+		}
+	}()
+}
+
+// checkHealth pings MongoDB and logs health transitions
+func (m *MongoDB) checkHealth(ctx context.Context) {
+	pingCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	err := m.client.Ping(pingCtx, nil)
+	if err != nil {
+		if !m.unhealthy.Swap(true) {
+			logger.Warn("MongoDB health check failed", "error", err)
+		}
+		reporting.Failure("mongodb", err)
+		return
+	}
+	reporting.Success("mongodb")
+
+	m.breaker.reset()
+	if m.unhealthy.Swap(false) {
+		logger.Info("MongoDB is reachable again")
+	}
+}
@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetLastRun returns the time recorded by SetLastRun for a periodic job, or zero if it never ran
+func (m *MongoDB) GetLastRun(ctx context.Context, job string) (time.Time, error) {
+	var run struct {
+		LastRun time.Time `bson:"last_run"`
+	}
+	err := m.jobRuns.FindOne(ctx, bson.M{"_id": job}).Decode(&run)
+	if err == mongo.ErrNoDocuments {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get last run of %s: %w", job, err)
+	}
+	return run.LastRun, nil
+}
+
+// SetLastRun records that a periodic job ran for the period ending at lastRun
+func (m *MongoDB) SetLastRun(ctx context.Context, job string, lastRun time.Time) error {
+	update := bson.M{"$set": bson.M{"last_run": lastRun, "updated_at": time.Now().UTC()}}
+	if _, err := m.jobRuns.UpdateOne(ctx, bson.M{"_id": job}, update, options.Update().SetUpsert(true)); err != nil {
+		return fmt.Errorf("failed to set last run of %s: %w", job, err)
+	}
+	return nil
+}
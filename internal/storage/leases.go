@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AcquireLease takes the named lease for holder, or renews it if holder already has it
+// It returns false while another holder's lease has not expired. Expiry is evaluated with
+// the MongoDB server clock, so the clocks of competing instances do not need to agree
+func (m *MongoDB) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	filter := bson.M{
+		"_id": name,
+		"$or": bson.A{
+			bson.M{"holder": holder},
+			bson.M{"$expr": bson.M{"$lte": bson.A{"$expires_at", "$$NOW"}}},
+		},
+	}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			// Keep acquired_at across renewals by the same holder
+			"acquired_at": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$holder", holder}}, "$acquired_at", "$$NOW"}},
+			"holder":      holder,
+			"renewed_at":  "$$NOW",
+			"expires_at":  bson.M{"$add": bson.A{"$$NOW", ttl.Milliseconds()}},
+		}}},
+	}
+
+	// Without a match the upsert inserts a new lease, which collides with a lease held by someone else
+	_, err := m.leases.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lease %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// ReleaseLease gives up the named lease if holder has it, so a standby can take over right away
+func (m *MongoDB) ReleaseLease(ctx context.Context, name, holder string) error {
+	if _, err := m.leases.DeleteOne(ctx, bson.M{"_id": name, "holder": holder}); err != nil {
+		return fmt.Errorf("failed to release lease %s: %w", name, err)
+	}
+	return nil
+}
+
+// GetLease returns the named lease, or nil if nobody has taken it
+// An expired lease is still returned; check ExpiresAt
+func (m *MongoDB) GetLease(ctx context.Context, name string) (*models.Lease, error) {
+	var lease models.Lease
+	err := m.leases.FindOne(ctx, bson.M{"_id": name}).Decode(&lease)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lease %s: %w", name, err)
+	}
+	return &lease, nil
+}
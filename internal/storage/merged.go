@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// QueryMergedOperations retrieves operations matching a query with pagination, newest first,
+// listing an operation stored for several of the queried accounts (e.g. a transfer between
+// two of them) once, so pages neither repeat nor skip operations
+func (m *MongoDB) QueryMergedOperations(ctx context.Context, query OperationQuery, page, pageSize int) (*models.MergedOperationResponse, error) {
+	skip := int64((page - 1) * pageSize)
+	newestFirst := bson.D{{Key: "block_num", Value: -1}, {Key: "trx_id", Value: -1}, {Key: "op_in_trx", Value: -1}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: query.filter()}},
+		{{Key: "$sort", Value: newestFirst}},
+		// Copies of one operation share block, transaction and position; only the account differs
+		{{Key: "$group", Value: bson.M{
+			"_id":      bson.M{"block_num": "$block_num", "trx_id": "$trx_id", "op_in_trx": "$op_in_trx"},
+			"doc":      bson.M{"$first": "$$ROOT"},
+			"accounts": bson.M{"$push": "$account"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id.block_num", Value: -1}, {Key: "_id.trx_id", Value: -1}, {Key: "_id.op_in_trx", Value: -1}}}},
+		{{Key: "$facet", Value: bson.M{
+			"total": bson.A{bson.M{"$count": "count"}},
+			"operations": bson.A{
+				bson.M{"$skip": skip},
+				bson.M{"$limit": int64(pageSize)},
+				bson.M{"$replaceRoot": bson.M{"newRoot": bson.M{"$mergeObjects": bson.A{"$doc", bson.M{"accounts": "$accounts"}}}}},
+			},
+		}}},
+	}
+
+	cursor, err := m.operations.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate operations: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var facets []struct {
+		Total []struct {
+			Count int64 `bson:"count"`
+		} `bson:"total"`
+		Operations []models.MergedOperation `bson:"operations"`
+	}
+	if err := cursor.All(ctx, &facets); err != nil {
+		return nil, fmt.Errorf("failed to decode operations: %w", err)
+	}
+
+	response := &models.MergedOperationResponse{Operations: []models.MergedOperation{}, Page: page, PageSize: pageSize}
+	if len(facets) > 0 {
+		if len(facets[0].Total) > 0 {
+			response.Total = facets[0].Total[0].Count
+		}
+		if facets[0].Operations != nil {
+			response.Operations = facets[0].Operations
+		}
+	}
+	for _, op := range response.Operations {
+		sort.Strings(op.Accounts)
+	}
+	response.HasMore = skip+int64(len(response.Operations)) < response.Total
+	return response, nil
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// operationTimestampsMigration is the job_runs entry recording that the migration finished
+	operationTimestampsMigration = "migrate_operation_timestamps"
+	// migrationBatchSize is how many operations each migration step updates
+	migrationBatchSize = 1000
+	// migrationBatchTimeout bounds a single batch; the migration as a whole has no timeout
+	migrationBatchTimeout = 30 * time.Second
+)
+
+// MigrateOperationTimestamps moves legacy created_at (last write time) to first_seen_at/updated_at
+// It is the best approximation available for operations stored before first_seen_at existed.
+// Operations are migrated in batches, so the migration can be interrupted and resumes with the
+// operations still left; once none are left it is recorded in job_runs and not checked again
+func (m *MongoDB) MigrateOperationTimestamps(ctx context.Context) error {
+	done, err := m.GetLastRun(ctx, operationTimestampsMigration)
+	if err != nil {
+		return err
+	}
+	if !done.IsZero() {
+		return nil
+	}
+
+	var migrated int64
+	for {
+		n, err := m.migrateOperationTimestampsBatch(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to migrate operation timestamps after %d operations: %w", migrated, err)
+		}
+		migrated += n
+		if n == 0 {
+			break
+		}
+		logger.Debug("Migrating created_at to first_seen_at", "operations", migrated)
+	}
+	if migrated > 0 {
+		logger.Info("Migrated created_at to first_seen_at", "operations", migrated)
+	}
+	return m.SetLastRun(ctx, operationTimestampsMigration, time.Now().UTC())
+}
+
+// migrateOperationTimestampsBatch migrates up to migrationBatchSize operations and returns how many
+// it found
+func (m *MongoDB) migrateOperationTimestampsBatch(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, migrationBatchTimeout)
+	defer cancel()
+
+	filter := bson.M{
+		"created_at":    bson.M{"$exists": true},
+		"first_seen_at": bson.M{"$exists": false},
+	}
+	opts := options.Find().SetProjection(bson.M{"_id": 1}).SetLimit(migrationBatchSize)
+	cursor, err := m.operations.Find(ctx, filter, opts)
+	if err != nil {
+		return 0, err
+	}
+	var docs []struct {
+		ID interface{} `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return 0, err
+	}
+	if len(docs) == 0 {
+		return 0, nil
+	}
+
+	ids := make(bson.A, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.ID
+	}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.D{
+			{Key: "first_seen_at", Value: "$created_at"},
+			{Key: "updated_at", Value: "$created_at"},
+		}}},
+		{{Key: "$unset", Value: "created_at"}},
+	}
+	// The filter again, so an operation written since the find keeps its own timestamps
+	if _, err := m.operations.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": ids}, "first_seen_at": bson.M{"$exists": false}}, update); err != nil {
+		return 0, err
+	}
+	return int64(len(docs)), nil
+}
@@ -3,26 +3,75 @@ package storage
 import (
 	"context"
 	"fmt"
-	"log"
+	"sync/atomic"
 	"time"
 
+	"github.com/ety001/sps-fund-watcher/internal/logging"
 	"github.com/ety001/sps-fund-watcher/internal/models"
+	querydsl "github.com/ety001/sps-fund-watcher/internal/query"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 const (
-	operationsCollection = "operations"
-	syncStateCollection  = "sync_state"
+	operationsCollection              = "operations"
+	syncStateCollection               = "sync_state"
+	controlCollection                 = "control_state"
+	reversibleCollection              = "reversible_blocks"
+	webhooksCollection                = "webhooks"
+	deadLetterCollection              = "webhook_dead_letters"
+	viewsCollection                   = "views"
+	aggregatesCollection              = "operation_aggregates"
+	compensatorJobsCollection         = "compensator_jobs"
+	coverageCollection                = "account_coverage"
+	leasesCollection                  = "leases"
+	jobRunsCollection                 = "job_runs"
+	balancesCollection                = "balance_snapshots"
+	accountMetadataCollection         = "account_metadata"
+	fundEventsCollection              = "fund_events"
+	pendingNotificationsCollection    = "pending_notifications"
+	notificationDeadLettersCollection = "notification_dead_letters"
+	autoTrackedCollection             = "auto_tracked_accounts"
+	blocksCollection                  = "blocks"
+	pricesCollection                  = "prices"
 )
 
+var logger = logging.Component("storage")
+
 // MongoDB represents a MongoDB storage client
 type MongoDB struct {
-	client     *mongo.Client
-	database   *mongo.Database
-	operations *mongo.Collection
-	syncState  *mongo.Collection
+	client                  *mongo.Client
+	database                *mongo.Database
+	operations              *mongo.Collection
+	syncState               *mongo.Collection
+	control                 *mongo.Collection
+	reversible              *mongo.Collection
+	webhooks                *mongo.Collection
+	deadLetters             *mongo.Collection
+	views                   *mongo.Collection
+	aggregates              *mongo.Collection
+	compensatorJobs         *mongo.Collection
+	coverage                *mongo.Collection
+	leases                  *mongo.Collection
+	jobRuns                 *mongo.Collection
+	balances                *mongo.Collection
+	accountMetadata         *mongo.Collection
+	fundEvents              *mongo.Collection
+	pendingNotifications    *mongo.Collection
+	notificationDeadLetters *mongo.Collection
+	autoTracked             *mongo.Collection
+	blocks                  *mongo.Collection
+	prices                  *mongo.Collection
+
+	slowQueries *slowQueryLog
+
+	chain string // Label written into stored operations (see SetChain)
+
+	// Connection health (see StartHealthMonitor) and write circuit breaker
+	breaker   circuitBreaker
+	unhealthy atomic.Bool
 }
 
 // NewMongoDB creates a new MongoDB storage client
@@ -30,7 +79,8 @@ func NewMongoDB(uri, databaseName string) (*MongoDB, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	slowQueries := &slowQueryLog{}
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri).SetMonitor(slowQueries.monitor()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
 	}
@@ -43,10 +93,29 @@ func NewMongoDB(uri, databaseName string) (*MongoDB, error) {
 	db := client.Database(databaseName)
 
 	return &MongoDB{
-		client:     client,
-		database:   db,
-		operations: db.Collection(operationsCollection),
-		syncState:  db.Collection(syncStateCollection),
+		client:                  client,
+		database:                db,
+		operations:              db.Collection(operationsCollection),
+		syncState:               db.Collection(syncStateCollection),
+		control:                 db.Collection(controlCollection),
+		reversible:              db.Collection(reversibleCollection),
+		webhooks:                db.Collection(webhooksCollection),
+		deadLetters:             db.Collection(deadLetterCollection),
+		views:                   db.Collection(viewsCollection),
+		aggregates:              db.Collection(aggregatesCollection),
+		compensatorJobs:         db.Collection(compensatorJobsCollection),
+		coverage:                db.Collection(coverageCollection),
+		leases:                  db.Collection(leasesCollection),
+		jobRuns:                 db.Collection(jobRunsCollection),
+		balances:                db.Collection(balancesCollection),
+		accountMetadata:         db.Collection(accountMetadataCollection),
+		fundEvents:              db.Collection(fundEventsCollection),
+		pendingNotifications:    db.Collection(pendingNotificationsCollection),
+		notificationDeadLetters: db.Collection(notificationDeadLettersCollection),
+		autoTracked:             db.Collection(autoTrackedCollection),
+		blocks:                  db.Collection(blocksCollection),
+		prices:                  db.Collection(pricesCollection),
+		slowQueries:             slowQueries,
 	}, nil
 }
 
@@ -57,9 +126,19 @@ func (m *MongoDB) Close() error {
 	return m.client.Disconnect(ctx)
 }
 
+// SetChain labels the operations written from now on with a chain (see models.ChainConfig)
+func (m *MongoDB) SetChain(chain string) {
+	m.chain = chain
+}
+
 // InsertOperation inserts an operation into MongoDB
 func (m *MongoDB) InsertOperation(ctx context.Context, op *models.Operation) error {
-	op.CreatedAt = time.Now()
+	now := time.Now().UTC()
+	op.FirstSeenAt = now
+	op.UpdatedAt = now
+	if m.chain != "" {
+		op.Chain = m.chain
+	}
 	_, err := m.operations.InsertOne(ctx, op)
 	return err
 }
@@ -70,42 +149,141 @@ func (m *MongoDB) InsertOperations(ctx context.Context, ops []*models.Operation)
 	if len(ops) == 0 {
 		return nil
 	}
+	return m.guard(func() error { return m.insertOperations(ctx, ops) })
+}
 
-	now := time.Now()
+// insertOperations upserts operations one by one
+func (m *MongoDB) insertOperations(ctx context.Context, ops []*models.Operation) error {
+	now := time.Now().UTC()
 	for _, op := range ops {
-		op.CreatedAt = now
-
-		// Use upsert to prevent duplicates
-		filter := bson.M{
-			"block_num": op.BlockNum,
-			"trx_id":    op.TrxID,
-			"op_in_trx": op.OpInTrx,
-			"account":   op.Account,
+		if _, err := m.upsertOperation(ctx, op, now); err != nil {
+			return err
 		}
+	}
 
-		update := bson.M{
-			"$set": op,
-		}
+	return nil
+}
 
-		opts := options.Update().SetUpsert(true)
-		_, err := m.operations.UpdateOne(ctx, filter, update, opts)
-		if err != nil {
-			return fmt.Errorf("failed to upsert operation: %w", err)
-		}
+// upsertOperation writes an operation, keeping first_seen_at from the first insert
+func (m *MongoDB) upsertOperation(ctx context.Context, op *models.Operation, now time.Time) (*mongo.UpdateResult, error) {
+	// Use upsert to prevent duplicates
+	filter := bson.M{
+		"block_num": op.BlockNum,
+		"trx_id":    op.TrxID,
+		"op_in_trx": op.OpInTrx,
+		"account":   op.Account,
 	}
 
-	return nil
+	// first_seen_at, source and needs_review are omitted from $set (zero values) so re-processing
+	// never changes them
+	source, needsReview := op.Source, op.NeedsReview
+	op.FirstSeenAt = time.Time{}
+	op.Source = ""
+	op.NeedsReview = false
+	op.UpdatedAt = now
+	if m.chain != "" {
+		op.Chain = m.chain
+	}
+	onInsert := bson.M{"first_seen_at": now, "source": source}
+	if needsReview {
+		onInsert["needs_review"] = true
+	}
+	update := bson.M{
+		"$set":         op,
+		"$setOnInsert": onInsert,
+	}
+
+	opts := options.Update().SetUpsert(true)
+	result, err := m.operations.UpdateOne(ctx, filter, update, opts)
+	op.Source, op.NeedsReview = source, needsReview
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert operation: %w", err)
+	}
+	if result.UpsertedCount > 0 {
+		op.FirstSeenAt = now
+	}
+	return result, nil
+}
+
+// OperationQuery describes which operations to retrieve
+type OperationQuery struct {
+	Account  string
+	Accounts []string // Matches any of these accounts, in addition to Account
+	OpType   string
+	OpTypes  []string // Matches any of these types, in addition to OpType
+	Filter   bson.M   // Additional conditions, e.g. compiled from the query DSL
+
+	// Optional inclusive block range (0 means unbounded)
+	StartBlock int64
+	EndBlock   int64
+
+	// Optional block time range; From is inclusive, To exclusive (zero means unbounded)
+	From time.Time
+	To   time.Time
+
+	// Optional cap on the operations read by StreamOperations (0 means all)
+	Limit int64
 }
 
 // GetOperations retrieves operations with pagination
 func (m *MongoDB) GetOperations(ctx context.Context, account string, opType string, page, pageSize int) (*models.OperationResponse, error) {
+	return m.QueryOperations(ctx, OperationQuery{Account: account, OpType: opType}, page, pageSize)
+}
+
+// filter builds the MongoDB filter for the query
+func (q OperationQuery) filter() bson.M {
 	filter := bson.M{}
-	if account != "" {
-		filter["account"] = account
+	accounts := q.Accounts
+	if q.Account != "" {
+		accounts = append([]string{q.Account}, accounts...)
+	}
+	switch len(accounts) {
+	case 0:
+	case 1:
+		filter["account"] = accounts[0]
+	default:
+		filter["account"] = bson.M{"$in": accounts}
 	}
-	if opType != "" {
-		filter["op_type"] = opType
+	opTypes := q.OpTypes
+	if q.OpType != "" {
+		opTypes = append([]string{q.OpType}, opTypes...)
 	}
+	switch len(opTypes) {
+	case 0:
+	case 1:
+		filter["op_type"] = opTypes[0]
+	default:
+		filter["op_type"] = bson.M{"$in": opTypes}
+	}
+	if q.StartBlock > 0 || q.EndBlock > 0 {
+		blockRange := bson.M{}
+		if q.StartBlock > 0 {
+			blockRange["$gte"] = q.StartBlock
+		}
+		if q.EndBlock > 0 {
+			blockRange["$lte"] = q.EndBlock
+		}
+		filter["block_num"] = blockRange
+	}
+	if !q.From.IsZero() || !q.To.IsZero() {
+		timeRange := bson.M{}
+		if !q.From.IsZero() {
+			timeRange["$gte"] = q.From
+		}
+		if !q.To.IsZero() {
+			timeRange["$lt"] = q.To
+		}
+		filter["timestamp"] = timeRange
+	}
+	if len(q.Filter) > 0 {
+		filter = bson.M{"$and": bson.A{filter, q.Filter}}
+	}
+	return filter
+}
+
+// QueryOperations retrieves operations matching a query with pagination
+func (m *MongoDB) QueryOperations(ctx context.Context, query OperationQuery, page, pageSize int) (*models.OperationResponse, error) {
+	filter := query.filter()
 
 	// Count total
 	total, err := m.operations.CountDocuments(ctx, filter)
@@ -144,10 +322,88 @@ func (m *MongoDB) GetOperations(ctx context.Context, account string, opType stri
 	}, nil
 }
 
+// CountOperations returns the number of operations matching a query
+func (m *MongoDB) CountOperations(ctx context.Context, query OperationQuery) (int64, error) {
+	count, err := m.operations.CountDocuments(ctx, query.filter())
+	if err != nil {
+		return 0, fmt.Errorf("failed to count operations: %w", err)
+	}
+	return count, nil
+}
+
+// StreamOperations calls fn for every operation matching a query in block order
+// Results are read through a cursor, so memory use doesn't depend on the result size
+func (m *MongoDB) StreamOperations(ctx context.Context, query OperationQuery, fn func(*models.Operation) error) error {
+	opts := options.Find().SetSort(bson.D{{Key: "block_num", Value: 1}, {Key: "op_in_trx", Value: 1}})
+	if query.Limit > 0 {
+		opts.SetLimit(query.Limit)
+	}
+	cursor, err := m.operations.Find(ctx, query.filter(), opts)
+	if err != nil {
+		return fmt.Errorf("failed to find operations: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var op models.Operation
+		if err := cursor.Decode(&op); err != nil {
+			return fmt.Errorf("failed to decode operation: %w", err)
+		}
+		if err := fn(&op); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("failed to read operations: %w", err)
+	}
+	return nil
+}
+
+// GetOperation retrieves a stored operation by its ID, or ErrNotFound
+func (m *MongoDB) GetOperation(ctx context.Context, id string) (*models.Operation, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	var op models.Operation
+	err = m.operations.FindOne(ctx, bson.M{"_id": objectID}).Decode(&op)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get operation: %w", err)
+	}
+	return &op, nil
+}
+
+// GetOperationsInBlockRange returns the stored operations of the given accounts in [startBlock, endBlock]
+func (m *MongoDB) GetOperationsInBlockRange(ctx context.Context, accounts []string, startBlock, endBlock int64) ([]models.Operation, error) {
+	filter := bson.M{
+		"account":   bson.M{"$in": accounts},
+		"block_num": bson.M{"$gte": startBlock, "$lte": endBlock},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "block_num", Value: 1}, {Key: "op_in_trx", Value: 1}})
+
+	cursor, err := m.operations.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find operations: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var operations []models.Operation
+	if err := cursor.All(ctx, &operations); err != nil {
+		return nil, fmt.Errorf("failed to decode operations: %w", err)
+	}
+	return operations, nil
+}
+
 // GetSyncState retrieves the current sync state
 func (m *MongoDB) GetSyncState(ctx context.Context) (*models.SyncState, error) {
 	var state models.SyncState
-	err := m.syncState.FindOne(ctx, bson.M{}).Decode(&state)
+	err := m.guard(func() error {
+		return m.syncState.FindOne(ctx, bson.M{}).Decode(&state)
+	})
 	if err == mongo.ErrNoDocuments {
 		// Return default state if not found
 		return &models.SyncState{
@@ -174,7 +430,7 @@ func (m *MongoDB) UpdateSyncState(ctx context.Context, lastBlock, lastIrreversib
 	// Only update if the new lastBlock is greater than current
 	// This prevents rollback issues
 	if lastBlock <= currentState.LastBlock {
-		log.Printf("Skipping sync state update: new lastBlock %d is not greater than current %d", lastBlock, currentState.LastBlock)
+		logger.Debug("Skipping sync state update: block is not greater than current", "block_num", lastBlock, "current_block", currentState.LastBlock)
 		return nil
 	}
 
@@ -188,46 +444,68 @@ func (m *MongoDB) UpdateSyncState(ctx context.Context, lastBlock, lastIrreversib
 	filter := bson.M{}
 	update := bson.M{"$set": state}
 
-	_, err = m.syncState.UpdateOne(ctx, filter, update, opts)
-	return err
+	return m.guard(func() error {
+		_, err := m.syncState.UpdateOne(ctx, filter, update, opts)
+		return err
+	})
 }
 
+// GetControlState retrieves the operator control state (pause switches)
+func (m *MongoDB) GetControlState(ctx context.Context) (*models.ControlState, error) {
+	var state models.ControlState
+	err := m.control.FindOne(ctx, bson.M{}).Decode(&state)
+	if err == mongo.ErrNoDocuments {
+		// Nothing paused until an operator says otherwise
+		return &models.ControlState{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get control state: %w", err)
+	}
+	return &state, nil
+}
+
+// SetPauseState updates the pause switches and returns the resulting control state
+// A nil switch is left unchanged
+func (m *MongoDB) SetPauseState(ctx context.Context, syncPaused, notificationsPaused *bool, reason string) (*models.ControlState, error) {
+	set := bson.M{
+		"reason":     reason,
+		"updated_at": time.Now(),
+	}
+	if syncPaused != nil {
+		set["sync_paused"] = *syncPaused
+	}
+	if notificationsPaused != nil {
+		set["notifications_paused"] = *notificationsPaused
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var state models.ControlState
+	if err := m.control.FindOneAndUpdate(ctx, bson.M{}, bson.M{"$set": set}, opts).Decode(&state); err != nil {
+		return nil, fmt.Errorf("failed to update control state: %w", err)
+	}
+	return &state, nil
+}
 
 // SaveOperationsAndUpdateSyncState saves operations and updates sync state
 // Uses atomic update with $max to ensure last_block only increases
 // Note: This doesn't use transactions as single-node MongoDB doesn't support them
 // WARNING: Multiple processes can still write concurrently, but $max prevents rollback
 func (m *MongoDB) SaveOperationsAndUpdateSyncState(ctx context.Context, ops []*models.Operation, lastBlock, lastIrreversibleBlock int64) error {
-	log.Printf("[DEBUG] SaveOperationsAndUpdateSyncState called: opsCount=%d, lastBlock=%d, lastIrreversibleBlock=%d",
-		len(ops), lastBlock, lastIrreversibleBlock)
+	logger.Debug("Saving operations and sync state", "ops", len(ops), "block_num", lastBlock, "last_irreversible", lastIrreversibleBlock)
 
 	// Save operations first
 	if len(ops) > 0 {
-		now := time.Now()
+		now := time.Now().UTC()
 		for i, op := range ops {
-			op.CreatedAt = now
-
-			filter := bson.M{
-				"block_num": op.BlockNum,
-				"trx_id":    op.TrxID,
-				"op_in_trx": op.OpInTrx,
-				"account":   op.Account,
-			}
-
-			update := bson.M{
-				"$set": op,
-			}
-
-			opts := options.Update().SetUpsert(true)
-			result, err := m.operations.UpdateOne(ctx, filter, update, opts)
+			result, err := m.upsertOperation(ctx, op, now)
 			if err != nil {
-				log.Printf("[DEBUG] Failed to upsert operation %d/%d: %v", i+1, len(ops), err)
-				return fmt.Errorf("failed to upsert operation: %w", err)
+				logger.Debug("Failed to upsert operation", "index", i+1, "ops", len(ops), "block_num", op.BlockNum, "account", op.Account, "error", err)
+				return err
 			}
-			log.Printf("[DEBUG] Upserted operation %d/%d: Matched=%d, Modified=%d, Upserted=%d",
-				i+1, len(ops), result.MatchedCount, result.ModifiedCount, result.UpsertedCount)
+			logger.Debug("Upserted operation", "index", i+1, "ops", len(ops), "block_num", op.BlockNum, "account", op.Account,
+				"matched", result.MatchedCount, "modified", result.ModifiedCount, "upserted", result.UpsertedCount)
 		}
-		log.Printf("[DEBUG] Successfully saved %d operations", len(ops))
+		logger.Debug("Saved operations", "ops", len(ops), "block_num", lastBlock)
 	}
 
 	// Update sync state using atomic $max operator to ensure last_block only increases
@@ -236,7 +514,7 @@ func (m *MongoDB) SaveOperationsAndUpdateSyncState(ctx context.Context, ops []*m
 	update := bson.M{
 		"$set": bson.M{
 			"last_irreversible_block": lastIrreversibleBlock,
-			"updated_at":               time.Now(),
+			"updated_at":              time.Now(),
 		},
 		"$max": bson.M{
 			"last_block": lastBlock,
@@ -244,25 +522,21 @@ func (m *MongoDB) SaveOperationsAndUpdateSyncState(ctx context.Context, ops []*m
 	}
 
 	opts := options.Update().SetUpsert(true)
-	log.Printf("[DEBUG] Updating sync state atomically: LastBlock=%d (using $max), LastIrreversibleBlock=%d", lastBlock, lastIrreversibleBlock)
+	logger.Debug("Updating sync state atomically ($max)", "block_num", lastBlock, "last_irreversible", lastIrreversibleBlock)
 	result, err := m.syncState.UpdateOne(ctx, filter, update, opts)
 	if err != nil {
-		log.Printf("[DEBUG] Failed to update sync state: %v", err)
 		return fmt.Errorf("failed to update sync state: %w", err)
 	}
-	log.Printf("[DEBUG] Sync state updated: Matched=%d, Modified=%d, Upserted=%d",
-		result.MatchedCount, result.ModifiedCount, result.UpsertedCount)
+	logger.Debug("Sync state updated", "matched", result.MatchedCount, "modified", result.ModifiedCount, "upserted", result.UpsertedCount)
 
 	// Verify the update was successful by reading back
 	verifyState, err := m.GetSyncState(ctx)
 	if err != nil {
-		log.Printf("[DEBUG] Warning: failed to verify sync state after update: %v", err)
+		logger.Warn("Failed to verify sync state after update", "error", err)
 	} else {
-		log.Printf("[DEBUG] Verified sync state after update: LastBlock=%d, LastIrreversibleBlock=%d",
-			verifyState.LastBlock, verifyState.LastIrreversibleBlock)
+		logger.Debug("Verified sync state after update", "block_num", verifyState.LastBlock, "last_irreversible", verifyState.LastIrreversibleBlock)
 	}
 
-	log.Printf("[DEBUG] SaveOperationsAndUpdateSyncState completed successfully")
 	return nil
 }
 
@@ -330,11 +604,120 @@ func (m *MongoDB) CreateIndexes(ctx context.Context) error {
 		Keys: bson.D{{Key: "timestamp", Value: -1}},
 	}
 
-	_, err := m.operations.Indexes().CreateMany(ctx, []mongo.IndexModel{
+	// Text index on memos and indexes on counterparties for the search endpoint
+	memoIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "op_data.memo", Value: "text"}},
+	}
+	// Sparse index on the few operations waiting for review
+	reviewIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "needs_review", Value: 1}, {Key: "block_num", Value: -1}},
+		Options: options.Index().SetSparse(true),
+	}
+	indexes := []mongo.IndexModel{
 		uniqueIndex,
 		accountIndex,
 		opTypeIndex,
 		timestampIndex,
+		memoIndex,
+		reviewIndex,
+	}
+	for _, field := range querydsl.CounterpartyFields {
+		indexes = append(indexes, mongo.IndexModel{
+			Keys:    bson.D{{Key: "op_data." + field, Value: 1}, {Key: "block_num", Value: -1}},
+			Options: options.Index().SetSparse(true),
+		})
+	}
+
+	_, err := m.operations.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return err
+	}
+
+	// One record per reversible block processed in head sync mode
+	_, err = m.reversible.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "block_num", Value: 1}},
+		Options: options.Index().SetUnique(true),
 	})
-	return err
+	if err != nil {
+		return err
+	}
+
+	// One archived copy per block
+	_, err = m.blocks.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "block_num", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+
+	// One price per asset and day
+	_, err = m.prices.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "asset", Value: 1}, {Key: "date", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+
+	// One hourly count per account and operation type
+	_, err = m.aggregates.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "account", Value: 1},
+			{Key: "op_type", Value: 1},
+			{Key: "hour", Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Balance snapshots are read newest first per account
+	_, err = m.balances.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "account", Value: 1}, {Key: "taken_at", Value: -1}},
+	})
+	if err != nil {
+		return err
+	}
+
+	// The balance check walks the snapshots taken at a known block in block order
+	_, err = m.balances.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "account", Value: 1}, {Key: "block_num", Value: 1}},
+		Options: options.Index().SetSparse(true),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Fund events are listed newest first, overall, per account or per kind
+	_, err = m.fundEvents.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "block_num", Value: -1}}},
+		{Keys: bson.D{{Key: "account", Value: 1}, {Key: "block_num", Value: -1}}},
+		{Keys: bson.D{{Key: "kind", Value: 1}, {Key: "block_num", Value: -1}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Pending notifications are resent oldest first once due
+	_, err = m.pendingNotifications.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "next_attempt_at", Value: 1}, {Key: "created_at", Value: 1}},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Webhooks and saved views are addressed by name
+	for _, collection := range []*mongo.Collection{m.webhooks, m.views} {
+		_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.D{{Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// InsertPendingNotification stores a notification that could not be sent
+func (m *MongoDB) InsertPendingNotification(ctx context.Context, notification *models.PendingNotification) error {
+	if _, err := m.pendingNotifications.InsertOne(ctx, notification); err != nil {
+		return fmt.Errorf("failed to insert pending notification: %w", err)
+	}
+	return nil
+}
+
+// ListPendingNotifications returns up to limit pending notifications, oldest first
+func (m *MongoDB) ListPendingNotifications(ctx context.Context, limit int64) ([]models.PendingNotification, error) {
+	return m.findPendingNotifications(ctx, bson.M{}, limit)
+}
+
+// DuePendingNotifications returns up to limit pending notifications whose next attempt is due, oldest first
+func (m *MongoDB) DuePendingNotifications(ctx context.Context, now time.Time, limit int64) ([]models.PendingNotification, error) {
+	return m.findPendingNotifications(ctx, bson.M{"next_attempt_at": bson.M{"$lte": now}}, limit)
+}
+
+func (m *MongoDB) findPendingNotifications(ctx context.Context, filter bson.M, limit int64) ([]models.PendingNotification, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}).SetLimit(limit)
+	cursor, err := m.pendingNotifications.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find pending notifications: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var notifications []models.PendingNotification
+	if err := cursor.All(ctx, &notifications); err != nil {
+		return nil, fmt.Errorf("failed to decode pending notifications: %w", err)
+	}
+	return notifications, nil
+}
+
+// RecordNotificationAttempt records a failed resend and when to try again
+func (m *MongoDB) RecordNotificationAttempt(ctx context.Context, id, lastError string, next time.Time) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrNotFound
+	}
+	update := bson.M{
+		"$inc": bson.M{"attempts": 1},
+		"$set": bson.M{"last_error": lastError, "next_attempt_at": next},
+	}
+	if _, err := m.pendingNotifications.UpdateOne(ctx, bson.M{"_id": objectID}, update); err != nil {
+		return fmt.Errorf("failed to update pending notification: %w", err)
+	}
+	return nil
+}
+
+// ResendPendingNotifications makes pending notifications due now; with no IDs all of them
+// Returns how many were rescheduled
+func (m *MongoDB) ResendPendingNotifications(ctx context.Context, ids []string) (int64, error) {
+	filter := bson.M{}
+	if len(ids) > 0 {
+		objectIDs := make([]primitive.ObjectID, 0, len(ids))
+		for _, id := range ids {
+			objectID, err := primitive.ObjectIDFromHex(id)
+			if err != nil {
+				return 0, fmt.Errorf("invalid notification ID %q", id)
+			}
+			objectIDs = append(objectIDs, objectID)
+		}
+		filter["_id"] = bson.M{"$in": objectIDs}
+	}
+
+	result, err := m.pendingNotifications.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"next_attempt_at": time.Now()}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to reschedule pending notifications: %w", err)
+	}
+	return result.MatchedCount, nil
+}
+
+// DeletePendingNotification removes a pending notification, or returns ErrNotFound
+func (m *MongoDB) DeletePendingNotification(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrNotFound
+	}
+	result, err := m.pendingNotifications.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return fmt.Errorf("failed to delete pending notification: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// InsertNotificationDeadLetter records a notification that gave up retrying
+func (m *MongoDB) InsertNotificationDeadLetter(ctx context.Context, deadLetter *models.NotificationDeadLetter) error {
+	if _, err := m.notificationDeadLetters.InsertOne(ctx, deadLetter); err != nil {
+		return fmt.Errorf("failed to insert notification dead letter: %w", err)
+	}
+	return nil
+}
+
+// GetNotificationDeadLetters returns the notifications that most recently gave up retrying
+func (m *MongoDB) GetNotificationDeadLetters(ctx context.Context, limit int64) ([]models.NotificationDeadLetter, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "failed_at", Value: -1}}).SetLimit(limit)
+	cursor, err := m.notificationDeadLetters.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find notification dead letters: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var deadLetters []models.NotificationDeadLetter
+	if err := cursor.All(ctx, &deadLetters); err != nil {
+		return nil, fmt.Errorf("failed to decode notification dead letters: %w", err)
+	}
+	return deadLetters, nil
+}
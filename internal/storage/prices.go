@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SaveDailyPrices stores daily prices, replacing earlier prices of the same asset and day
+func (m *MongoDB) SaveDailyPrices(ctx context.Context, prices []models.DailyPrice) error {
+	if len(prices) == 0 {
+		return nil
+	}
+
+	var writes []mongo.WriteModel
+	for _, price := range prices {
+		writes = append(writes, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"asset": price.Asset, "date": price.Date}).
+			SetReplacement(price).
+			SetUpsert(true))
+	}
+	if _, err := m.prices.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+		return fmt.Errorf("failed to save prices: %w", err)
+	}
+	return nil
+}
+
+// GetDailyPrices returns the stored USD prices of asset from the day from to the day to
+// (YYYY-MM-DD, inclusive), by day
+func (m *MongoDB) GetDailyPrices(ctx context.Context, asset, from, to string) (map[string]float64, error) {
+	filter := bson.M{"asset": asset, "date": bson.M{"$gte": from, "$lte": to}}
+	cursor, err := m.prices.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find prices: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var prices []models.DailyPrice
+	if err := cursor.All(ctx, &prices); err != nil {
+		return nil, fmt.Errorf("failed to decode prices: %w", err)
+	}
+	byDate := make(map[string]float64, len(prices))
+	for _, price := range prices {
+		byDate[price.Date] = price.USD
+	}
+	return byDate, nil
+}
+
+// OperationTimeRange returns the block times of the first and last operation matching a query,
+// or ErrNotFound when none matches
+func (m *MongoDB) OperationTimeRange(ctx context.Context, query OperationQuery) (time.Time, time.Time, error) {
+	var first, last models.Operation
+	opts := options.FindOne().SetProjection(bson.M{"timestamp": 1})
+	filter := query.filter()
+	if err := m.operations.FindOne(ctx, filter, opts.SetSort(bson.D{{Key: "block_num", Value: 1}})).Decode(&first); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if err := m.operations.FindOne(ctx, filter, opts.SetSort(bson.D{{Key: "block_num", Value: -1}})).Decode(&last); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return first.Timestamp, last.Timestamp, nil
+}
+
+// SetEnrichmentFields sets enrichment fields of stored operations, by operation ID; other
+// enrichment fields are kept
+func (m *MongoDB) SetEnrichmentFields(ctx context.Context, fields map[string]map[string]interface{}) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	var writes []mongo.WriteModel
+	for id, opFields := range fields {
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return fmt.Errorf("invalid operation ID %q: %w", id, err)
+		}
+		set := bson.M{}
+		for name, value := range opFields {
+			set["enrichment."+name] = value
+		}
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": objectID}).
+			SetUpdate(bson.M{"$set": set}))
+	}
+	return m.guard(func() error {
+		if _, err := m.operations.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+			return fmt.Errorf("failed to update enrichment: %w", err)
+		}
+		return nil
+	})
+}
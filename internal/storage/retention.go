@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// retentionMatch builds the filter selecting the operations a retention rule applies to
+func retentionMatch(rule models.RetentionRule) bson.M {
+	match := bson.M{}
+	if len(rule.OpTypes) > 0 {
+		match["op_type"] = bson.M{"$in": rule.OpTypes}
+	}
+	if len(rule.Accounts) > 0 {
+		match["account"] = bson.M{"$in": rule.Accounts}
+	}
+	return match
+}
+
+// retentionFilters returns one filter per retention tier selecting the operations that have expired at now
+// Each operation is governed by the first matching rule, or by the default when no rule matches
+func retentionFilters(policy models.RetentionConfig, now time.Time) []bson.M {
+	var filters []bson.M
+	var earlier bson.A // Matches of the rules before the current one
+
+	expired := func(days int, match bson.M) {
+		if days <= 0 {
+			return
+		}
+		filter := bson.M{"timestamp": bson.M{"$lt": now.AddDate(0, 0, -days)}}
+		var and bson.A
+		if len(match) > 0 {
+			and = append(and, match)
+		}
+		if len(earlier) > 0 {
+			and = append(and, bson.M{"$nor": earlier})
+		}
+		if len(and) > 0 {
+			filter["$and"] = and
+		}
+		filters = append(filters, filter)
+	}
+
+	for _, rule := range policy.Rules {
+		match := retentionMatch(rule)
+		expired(rule.Days, match)
+		if len(match) == 0 {
+			// A rule matching everything shadows the rest, including the default
+			return filters
+		}
+		earlier = append(earlier, match)
+	}
+	expired(policy.Days, nil)
+
+	return filters
+}
+
+// PruneOperations deletes operations older than the retention policy allows and returns how many
+// With dryRun, matching operations are only counted
+func (m *MongoDB) PruneOperations(ctx context.Context, policy models.RetentionConfig, now time.Time, dryRun bool) (int64, error) {
+	var total int64
+	for _, filter := range retentionFilters(policy, now) {
+		if dryRun {
+			count, err := m.operations.CountDocuments(ctx, filter)
+			if err != nil {
+				return total, fmt.Errorf("failed to count expired operations: %w", err)
+			}
+			total += count
+			continue
+		}
+
+		result, err := m.operations.DeleteMany(ctx, filter)
+		if err != nil {
+			return total, fmt.Errorf("failed to prune operations: %w", err)
+		}
+		total += result.DeletedCount
+	}
+	return total, nil
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SaveReversibleBlock records the block ID of a reversible block processed in head sync mode
+func (m *MongoDB) SaveReversibleBlock(ctx context.Context, blockNum int64, blockID string) error {
+	filter := bson.M{"block_num": blockNum}
+	update := bson.M{"$set": models.ReversibleBlock{
+		BlockNum:    blockNum,
+		BlockID:     blockID,
+		ProcessedAt: time.Now(),
+	}}
+
+	opts := options.Update().SetUpsert(true)
+	if _, err := m.reversible.UpdateOne(ctx, filter, update, opts); err != nil {
+		return fmt.Errorf("failed to save reversible block %d: %w", blockNum, err)
+	}
+	return nil
+}
+
+// GetReversibleBlocks returns recorded reversible blocks up to and including maxBlock, oldest first
+func (m *MongoDB) GetReversibleBlocks(ctx context.Context, maxBlock int64) ([]models.ReversibleBlock, error) {
+	filter := bson.M{"block_num": bson.M{"$lte": maxBlock}}
+	opts := options.Find().SetSort(bson.D{{Key: "block_num", Value: 1}})
+
+	cursor, err := m.reversible.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find reversible blocks: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var blocks []models.ReversibleBlock
+	if err := cursor.All(ctx, &blocks); err != nil {
+		return nil, fmt.Errorf("failed to decode reversible blocks: %w", err)
+	}
+	return blocks, nil
+}
+
+// ConfirmBlock marks all operations of a block as confirmed and forgets the reversible record
+func (m *MongoDB) ConfirmBlock(ctx context.Context, blockNum int64) error {
+	filter := bson.M{"block_num": blockNum, "unconfirmed": true}
+	update := bson.M{"$set": bson.M{"unconfirmed": false}}
+	if _, err := m.operations.UpdateMany(ctx, filter, update); err != nil {
+		return fmt.Errorf("failed to confirm operations in block %d: %w", blockNum, err)
+	}
+
+	if _, err := m.reversible.DeleteOne(ctx, bson.M{"block_num": blockNum}); err != nil {
+		return fmt.Errorf("failed to delete reversible block %d: %w", blockNum, err)
+	}
+	return nil
+}
+
+// DropUnconfirmedOperations removes the unconfirmed operations of a block that was
+// replaced by a fork and returns what was removed
+func (m *MongoDB) DropUnconfirmedOperations(ctx context.Context, blockNum int64) ([]models.Operation, error) {
+	filter := bson.M{"block_num": blockNum, "unconfirmed": true}
+
+	cursor, err := m.operations.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find unconfirmed operations in block %d: %w", blockNum, err)
+	}
+	defer cursor.Close(ctx)
+
+	var dropped []models.Operation
+	if err := cursor.All(ctx, &dropped); err != nil {
+		return nil, fmt.Errorf("failed to decode unconfirmed operations in block %d: %w", blockNum, err)
+	}
+
+	if _, err := m.operations.DeleteMany(ctx, filter); err != nil {
+		return nil, fmt.Errorf("failed to delete unconfirmed operations in block %d: %w", blockNum, err)
+	}
+	return dropped, nil
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetOperationsForReview returns the operations flagged needs_review, newest first
+func (m *MongoDB) GetOperationsForReview(ctx context.Context, page, pageSize int) (*models.OperationResponse, error) {
+	return m.QueryOperations(ctx, OperationQuery{Filter: bson.M{"needs_review": true}}, page, pageSize)
+}
+
+// ConfirmOperation clears the needs_review flag of an operation, or returns ErrNotFound
+// when no flagged operation has the ID
+func (m *MongoDB) ConfirmOperation(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrNotFound
+	}
+
+	result, err := m.operations.UpdateOne(ctx,
+		bson.M{"_id": objectID, "needs_review": true},
+		bson.M{"$unset": bson.M{"needs_review": ""}})
+	if err != nil {
+		return fmt.Errorf("failed to confirm operation: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RejectOperation deletes an operation flagged needs_review, or returns ErrNotFound when no
+// flagged operation has the ID
+// Re-processing its block stores it again, flagged, unless the fallback fields were changed
+func (m *MongoDB) RejectOperation(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrNotFound
+	}
+
+	result, err := m.operations.DeleteOne(ctx, bson.M{"_id": objectID, "needs_review": true})
+	if err != nil {
+		return fmt.Errorf("failed to reject operation: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
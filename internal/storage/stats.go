@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// OperationStats counts the operations stored in [from, to) per operation type, account and
+// UTC day in one pass; account and opType optionally narrow the selection
+func (m *MongoDB) OperationStats(ctx context.Context, account, opType string, from, to time.Time) (*models.OperationStats, error) {
+	match := bson.M{"timestamp": bson.M{"$gte": from, "$lt": to}}
+	if account != "" {
+		match["account"] = account
+	}
+	if opType != "" {
+		match["op_type"] = opType
+	}
+
+	countBy := func(key interface{}, sort bson.D) bson.A {
+		return bson.A{
+			bson.M{"$group": bson.M{"_id": key, "count": bson.M{"$sum": 1}}},
+			bson.M{"$sort": sort},
+		}
+	}
+	largestFirst := bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$facet", Value: bson.M{
+			"by_op_type": countBy("$op_type", largestFirst),
+			"by_account": countBy("$account", largestFirst),
+			"by_day":     countBy(bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$timestamp"}}, bson.D{{Key: "_id", Value: 1}}),
+		}}},
+	}
+
+	cursor, err := m.operations.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate operation stats: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var facets []struct {
+		ByOpType  []models.StatsCount `bson:"by_op_type"`
+		ByAccount []models.StatsCount `bson:"by_account"`
+		ByDay     []models.StatsCount `bson:"by_day"`
+	}
+	if err := cursor.All(ctx, &facets); err != nil {
+		return nil, fmt.Errorf("failed to decode operation stats: %w", err)
+	}
+
+	stats := &models.OperationStats{
+		From:      from,
+		To:        to,
+		Account:   account,
+		OpType:    opType,
+		ByOpType:  []models.StatsCount{},
+		ByAccount: []models.StatsCount{},
+	}
+	perDay := make(map[string]int64)
+	if len(facets) > 0 {
+		if facets[0].ByOpType != nil {
+			stats.ByOpType = facets[0].ByOpType
+		}
+		if facets[0].ByAccount != nil {
+			stats.ByAccount = facets[0].ByAccount
+		}
+		for _, day := range facets[0].ByDay {
+			perDay[day.Key] = day.Count
+		}
+	}
+	for _, count := range stats.ByOpType {
+		stats.Total += count.Count
+	}
+
+	// Empty days are listed too so charts don't have to fill them in
+	for day := from.UTC().Truncate(24 * time.Hour); day.Before(to); day = day.AddDate(0, 0, 1) {
+		key := day.Format(time.DateOnly)
+		stats.ByDay = append(stats.ByDay, models.StatsCount{Key: key, Count: perDay[key]})
+	}
+	return stats, nil
+}
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// TransferSummary aggregates an account's stored transfers in [from, to) by counterparty,
+// day or month (UTC) and asset symbol. Zero times leave the range open
+func (m *MongoDB) TransferSummary(ctx context.Context, account, groupBy string, from, to time.Time) (*models.TransferSummary, error) {
+	var key interface{}
+	switch groupBy {
+	case models.GroupByCounterparty:
+		key = bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$op_data.from", account}}, "$op_data.to", "$op_data.from"}}
+	case models.GroupByDay:
+		key = bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$timestamp"}}
+	case models.GroupByMonth:
+		key = bson.M{"$dateToString": bson.M{"format": "%Y-%m", "date": "$timestamp"}}
+	default:
+		return nil, fmt.Errorf("unsupported grouping %q (use counterparty, day or month)", groupBy)
+	}
+
+	match := bson.M{"account": account, "op_type": "transfer"}
+	timestamp := bson.M{}
+	if !from.IsZero() {
+		timestamp["$gte"] = from
+	}
+	if !to.IsZero() {
+		timestamp["$lt"] = to
+	}
+	if len(timestamp) > 0 {
+		match["timestamp"] = timestamp
+	}
+
+	// "1000.000 STEEM" -> amount 1000, symbol STEEM
+	amountParts := bson.M{"$split": bson.A{"$op_data.amount", " "}}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$project", Value: bson.M{
+			"key":      key,
+			"symbol":   bson.M{"$arrayElemAt": bson.A{amountParts, 1}},
+			"amount":   bson.M{"$convert": bson.M{"input": bson.M{"$arrayElemAt": bson.A{amountParts, 0}}, "to": "double", "onError": 0, "onNull": 0}},
+			"incoming": bson.M{"$eq": bson.A{"$op_data.to", account}},
+			"outgoing": bson.M{"$eq": bson.A{"$op_data.from", account}},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      bson.M{"key": "$key", "symbol": "$symbol"},
+			"received": bson.M{"$sum": bson.M{"$cond": bson.A{"$incoming", "$amount", 0}}},
+			"sent":     bson.M{"$sum": bson.M{"$cond": bson.A{"$outgoing", "$amount", 0}}},
+			"count":    bson.M{"$sum": 1},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":      0,
+			"key":      "$_id.key",
+			"symbol":   "$_id.symbol",
+			"received": 1,
+			"sent":     1,
+			"count":    1,
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "key", Value: 1}, {Key: "symbol", Value: 1}}}},
+	}
+
+	cursor, err := m.operations.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate transfers: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var groups []models.TransferTotals
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, fmt.Errorf("failed to decode transfer summary: %w", err)
+	}
+
+	summary := &models.TransferSummary{Account: account, GroupBy: groupBy, Groups: []models.TransferTotals{}, Totals: []models.TransferTotals{}}
+	if !from.IsZero() {
+		summary.From = &from
+	}
+	if !to.IsZero() {
+		summary.To = &to
+	}
+
+	totals := make(map[string]*models.TransferTotals)
+	for _, group := range groups {
+		total := totals[group.Symbol]
+		if total == nil {
+			total = &models.TransferTotals{Symbol: group.Symbol}
+			totals[group.Symbol] = total
+		}
+		total.Received += group.Received
+		total.Sent += group.Sent
+		total.Count += group.Count
+		summary.Groups = append(summary.Groups, roundTotals(group))
+	}
+	for _, total := range totals {
+		summary.Totals = append(summary.Totals, roundTotals(*total))
+	}
+	sort.Slice(summary.Totals, func(i, j int) bool { return summary.Totals[i].Symbol < summary.Totals[j].Symbol })
+	return summary, nil
+}
+
+// roundTotals rounds amounts to the chain's 3-decimal precision and fills in the net amount
+func roundTotals(t models.TransferTotals) models.TransferTotals {
+	t.Received = math.Round(t.Received*1000) / 1000
+	t.Sent = math.Round(t.Sent*1000) / 1000
+	t.Net = math.Round((t.Received-t.Sent)*1000) / 1000
+	return t
+}
@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// dbStats is the subset of the dbStats command result used for reporting
+type dbStats struct {
+	DataSize    float64 `bson:"dataSize"`
+	StorageSize float64 `bson:"storageSize"`
+	IndexSize   float64 `bson:"indexSize"`
+}
+
+// collStats is the subset of $collStats storageStats used for reporting
+type collStats struct {
+	StorageStats struct {
+		Count          float64 `bson:"count"`
+		Size           float64 `bson:"size"`
+		StorageSize    float64 `bson:"storageSize"`
+		TotalIndexSize float64 `bson:"totalIndexSize"`
+		AvgObjSize     float64 `bson:"avgObjSize"`
+	} `bson:"storageStats"`
+}
+
+// DiskUsage returns the on-disk size of the database (documents and indexes) in bytes
+func (m *MongoDB) DiskUsage(ctx context.Context) (int64, error) {
+	stats, err := m.dbStats(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return int64(stats.StorageSize + stats.IndexSize), nil
+}
+
+// dbStats runs the dbStats command
+func (m *MongoDB) dbStats(ctx context.Context) (*dbStats, error) {
+	var stats dbStats
+	if err := m.database.RunCommand(ctx, bson.D{{Key: "dbStats", Value: 1}}).Decode(&stats); err != nil {
+		return nil, fmt.Errorf("failed to get database stats: %w", err)
+	}
+	return &stats, nil
+}
+
+// StorageReport collects collection sizes, per-account operation counts and a growth projection
+func (m *MongoDB) StorageReport(ctx context.Context) (*models.StorageReport, error) {
+	stats, err := m.dbStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.StorageReport{
+		GeneratedAt: time.Now(),
+		DataSize:    int64(stats.DataSize),
+		StorageSize: int64(stats.StorageSize),
+		IndexSize:   int64(stats.IndexSize),
+	}
+
+	names, err := m.database.ListCollectionNames(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+	sort.Strings(names)
+
+	var operationsAvgSize int64
+	for _, name := range names {
+		usage, err := m.collectionUsage(ctx, m.database.Collection(name))
+		if err != nil {
+			return nil, err
+		}
+		usage.Name = name
+		report.Collections = append(report.Collections, *usage)
+		if name == operationsCollection {
+			operationsAvgSize = usage.AvgObjSize
+		}
+	}
+
+	report.OperationTypes, err = m.operationCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Project growth from operations first seen during the last week
+	recent, err := m.operations.CountDocuments(ctx, bson.M{"first_seen_at": bson.M{"$gte": time.Now().UTC().AddDate(0, 0, -7)}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count recent operations: %w", err)
+	}
+	perDay := recent / 7
+	report.Growth = models.StorageGrowth{
+		OperationsLast7Days: recent,
+		OperationsPerDay:    perDay,
+		BytesPerDay:         perDay * operationsAvgSize,
+		Projected30Days:     30 * perDay * operationsAvgSize,
+	}
+
+	return report, nil
+}
+
+// collectionUsage reads storage statistics for a collection
+func (m *MongoDB) collectionUsage(ctx context.Context, collection *mongo.Collection) (*models.CollectionUsage, error) {
+	pipeline := mongo.Pipeline{{{Key: "$collStats", Value: bson.M{"storageStats": bson.M{}}}}}
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats for %s: %w", collection.Name(), err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []collStats
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, fmt.Errorf("failed to decode stats for %s: %w", collection.Name(), err)
+	}
+
+	usage := &models.CollectionUsage{}
+	// Sharded collections report one document per shard
+	for _, result := range results {
+		usage.Count += int64(result.StorageStats.Count)
+		usage.DataSize += int64(result.StorageStats.Size)
+		usage.StorageSize += int64(result.StorageStats.StorageSize)
+		usage.IndexSize += int64(result.StorageStats.TotalIndexSize)
+	}
+	if usage.Count > 0 {
+		usage.AvgObjSize = usage.DataSize / usage.Count
+	}
+	return usage, nil
+}
+
+// operationCounts counts stored operations per account and type, largest first
+func (m *MongoDB) operationCounts(ctx context.Context) ([]models.OperationCount, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"account": "$account", "op_type": "$op_type"},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":     0,
+			"account": "$_id.account",
+			"op_type": "$_id.op_type",
+			"count":   1,
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "account", Value: 1}}}},
+	}
+
+	cursor, err := m.operations.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count operations: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var counts []models.OperationCount
+	if err := cursor.All(ctx, &counts); err != nil {
+		return nil, fmt.Errorf("failed to decode operation counts: %w", err)
+	}
+	return counts, nil
+}
@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	querydsl "github.com/ety001/sps-fund-watcher/internal/query"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ViewQuery builds the operation query for a saved view
+func ViewQuery(view *models.SavedView) (OperationQuery, error) {
+	query := OperationQuery{Account: view.Account, OpType: view.OpType}
+	if view.Query != "" {
+		filter, err := querydsl.Parse(view.Query)
+		if err != nil {
+			return query, err
+		}
+		query.Filter = filter
+	}
+	return query, nil
+}
+
+// ListViews returns all saved views ordered by name
+func (m *MongoDB) ListViews(ctx context.Context) ([]models.SavedView, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := m.views.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find views: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var views []models.SavedView
+	if err := cursor.All(ctx, &views); err != nil {
+		return nil, fmt.Errorf("failed to decode views: %w", err)
+	}
+	return views, nil
+}
+
+// GetView returns a saved view by name, or ErrNotFound
+func (m *MongoDB) GetView(ctx context.Context, name string) (*models.SavedView, error) {
+	var view models.SavedView
+	err := m.views.FindOne(ctx, bson.M{"name": name}).Decode(&view)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get view: %w", err)
+	}
+	return &view, nil
+}
+
+// SaveView creates or updates a saved view by name
+func (m *MongoDB) SaveView(ctx context.Context, view *models.SavedView) error {
+	now := time.Now()
+	view.UpdatedAt = now
+
+	update := bson.M{
+		"$set": bson.M{
+			"name":        view.Name,
+			"description": view.Description,
+			"account":     view.Account,
+			"op_type":     view.OpType,
+			"query":       view.Query,
+			"feed":        view.Feed,
+			"notify":      view.Notify,
+			"updated_at":  now,
+		},
+		"$setOnInsert": bson.M{"created_at": now},
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	if err := m.views.FindOneAndUpdate(ctx, bson.M{"name": view.Name}, update, opts).Decode(view); err != nil {
+		return fmt.Errorf("failed to save view: %w", err)
+	}
+	return nil
+}
+
+// DeleteView removes a saved view by name
+func (m *MongoDB) DeleteView(ctx context.Context, name string) error {
+	result, err := m.views.DeleteOne(ctx, bson.M{"name": name})
+	if err != nil {
+		return fmt.Errorf("failed to delete view: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MatchOperationsInBlocks returns operations in the given blocks that match a query
+// Used to evaluate notification-bound views against freshly stored operations
+func (m *MongoDB) MatchOperationsInBlocks(ctx context.Context, query OperationQuery, blockNums []int64) ([]models.Operation, error) {
+	filter := bson.M{"$and": bson.A{query.filter(), bson.M{"block_num": bson.M{"$in": blockNums}}}}
+	opts := options.Find().SetSort(bson.D{{Key: "block_num", Value: 1}, {Key: "op_in_trx", Value: 1}})
+
+	cursor, err := m.operations.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find operations: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var operations []models.Operation
+	if err := cursor.All(ctx, &operations); err != nil {
+		return nil, fmt.Errorf("failed to decode operations: %w", err)
+	}
+	return operations, nil
+}
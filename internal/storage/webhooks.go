@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrNotFound is returned when a requested document does not exist
+var ErrNotFound = mongo.ErrNoDocuments
+
+// ListWebhooks returns all webhooks registered through the admin API
+func (m *MongoDB) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := m.webhooks.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhooks: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var hooks []models.Webhook
+	if err := cursor.All(ctx, &hooks); err != nil {
+		return nil, fmt.Errorf("failed to decode webhooks: %w", err)
+	}
+	return hooks, nil
+}
+
+// SaveWebhook creates or replaces a webhook by name
+// A replaced webhook keeps its created_at; hook is updated with the stored document
+func (m *MongoDB) SaveWebhook(ctx context.Context, hook *models.Webhook) error {
+	update := bson.M{
+		"$set": bson.M{
+			"url":               hook.URL,
+			"secret":            hook.Secret,
+			"accounts":          hook.Accounts,
+			"notify_operations": hook.NotifyOperations,
+			"max_attempts":      hook.MaxAttempts,
+		},
+		"$setOnInsert": bson.M{"created_at": time.Now()},
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	if err := m.webhooks.FindOneAndUpdate(ctx, bson.M{"name": hook.Name}, update, opts).Decode(hook); err != nil {
+		return fmt.Errorf("failed to save webhook: %w", err)
+	}
+	return nil
+}
+
+// DeleteWebhook removes a webhook by name
+func (m *MongoDB) DeleteWebhook(ctx context.Context, name string) error {
+	result, err := m.webhooks.DeleteOne(ctx, bson.M{"name": name})
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// InsertWebhookDeadLetter records a webhook delivery that failed permanently
+func (m *MongoDB) InsertWebhookDeadLetter(ctx context.Context, deadLetter *models.WebhookDeadLetter) error {
+	if _, err := m.deadLetters.InsertOne(ctx, deadLetter); err != nil {
+		return fmt.Errorf("failed to insert webhook dead letter: %w", err)
+	}
+	return nil
+}
+
+// GetWebhookDeadLetters returns the most recent failed webhook deliveries
+func (m *MongoDB) GetWebhookDeadLetters(ctx context.Context, limit int64) ([]models.WebhookDeadLetter, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "failed_at", Value: -1}}).SetLimit(limit)
+	cursor, err := m.deadLetters.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhook dead letters: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var deadLetters []models.WebhookDeadLetter
+	if err := cursor.All(ctx, &deadLetters); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook dead letters: %w", err)
+	}
+	return deadLetters, nil
+}
@@ -0,0 +1,97 @@
+package sync
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+)
+
+// defaultAccountCheckInterval is used when steem.account_check_interval_minutes is not set
+const defaultAccountCheckInterval = 60 * time.Minute
+
+// accountCheckInterval returns how often configured accounts are verified on-chain
+func (s *Syncer) accountCheckInterval() time.Duration {
+	if s.config.Steem.AccountCheckIntervalMinutes > 0 {
+		return time.Duration(s.config.Steem.AccountCheckIntervalMinutes) * time.Minute
+	}
+	return defaultAccountCheckInterval
+}
+
+// configuredAccounts returns every account name referenced by the configuration
+func (s *Syncer) configuredAccounts() []string {
+	seen := make(map[string]bool)
+	var accounts []string
+	add := func(names []string) {
+		for _, name := range names {
+			if name != "" && !seen[name] {
+				seen[name] = true
+				accounts = append(accounts, name)
+			}
+		}
+	}
+
+	add(s.config.Steem.Accounts)
+	users, _ := models.NormalizeTelegramConfig(&s.config.Telegram)
+	for _, user := range users {
+		add(user.Accounts)
+	}
+
+	sort.Strings(accounts)
+	return accounts
+}
+
+// findMissingAccounts returns the given accounts that don't exist on-chain
+func (s *Syncer) findMissingAccounts(accounts []string) ([]string, error) {
+	result, err := s.steemAPI.GetAccounts(accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool, len(result))
+	for _, account := range result {
+		found[account.Name] = true
+	}
+
+	var missing []string
+	for _, account := range accounts {
+		if !found[account] {
+			missing = append(missing, account)
+		}
+	}
+	return missing, nil
+}
+
+// checkAccounts verifies that every configured account exists on-chain and alerts on
+// typos or nonexistent accounts. An alert is only repeated when the set of missing accounts changes.
+func (s *Syncer) checkAccounts() {
+	accounts := s.configuredAccounts()
+	if len(accounts) == 0 {
+		return
+	}
+
+	missing, err := s.findMissingAccounts(accounts)
+	if err != nil {
+		logger.Warn("Account existence check failed", "error", err)
+		return
+	}
+
+	key := strings.Join(missing, ",")
+	if key == s.lastMissingAccounts {
+		return
+	}
+	s.lastMissingAccounts = key
+
+	if len(missing) == 0 {
+		logger.Info("All configured accounts exist on-chain", "accounts", len(accounts))
+		return
+	}
+
+	logger.Warn("Configured accounts not found on-chain (typo?)", "missing", missing)
+	if s.telegram != nil {
+		if err := s.telegram.SendMessage(s.telegram.Formatter().MissingAccountsAlert(missing)); err != nil {
+			logger.Error("Failed to send missing accounts alert", "error", err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package sync
+
+import (
+	"strings"
+)
+
+// defaultAccountPaths are nested op_data paths that always hold account names
+var defaultAccountPaths = map[string][]string{
+	// [[0, {"beneficiaries": [{"account": ..., "weight": ...}]}]]
+	"comment_options": {"extensions.*.*.beneficiaries.*.account"},
+}
+
+// SetAccountPaths configures extra op_data paths holding account names, by operation type
+// They are walked in addition to the built-in top-level fields and defaultAccountPaths
+func (bp *BlockProcessor) SetAccountPaths(paths map[string][]string) {
+	bp.accountPaths = make(map[string][][]string)
+	for _, source := range []map[string][]string{defaultAccountPaths, paths} {
+		for opType, opPaths := range source {
+			for _, path := range opPaths {
+				if path = strings.TrimSpace(path); path != "" {
+					bp.accountPaths[opType] = append(bp.accountPaths[opType], strings.Split(path, "."))
+				}
+			}
+		}
+	}
+}
+
+// nestedAccounts returns the account names found under the operation type's paths
+func (bp *BlockProcessor) nestedAccounts(opType string, opData map[string]interface{}) []string {
+	var accounts []string
+	for _, path := range bp.accountPaths[opType] {
+		accounts = collectAccounts(opData, path, accounts)
+	}
+	return accounts
+}
+
+// collectAccounts walks a path through maps and arrays and appends the strings it reaches
+// "*" matches every array element or object value; a path ending at an array of strings
+// collects all of them
+func collectAccounts(value interface{}, path []string, accounts []string) []string {
+	if len(path) == 0 {
+		switch v := value.(type) {
+		case string:
+			if v != "" {
+				accounts = append(accounts, v)
+			}
+		case []interface{}:
+			for _, item := range v {
+				if s, ok := item.(string); ok && s != "" {
+					accounts = append(accounts, s)
+				}
+			}
+		}
+		return accounts
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if path[0] == "*" {
+			for _, item := range v {
+				accounts = collectAccounts(item, path[1:], accounts)
+			}
+		} else if item, ok := v[path[0]]; ok {
+			accounts = collectAccounts(item, path[1:], accounts)
+		}
+	case []interface{}:
+		if path[0] == "*" {
+			for _, item := range v {
+				accounts = collectAccounts(item, path[1:], accounts)
+			}
+		}
+	}
+	return accounts
+}
@@ -0,0 +1,103 @@
+package sync
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/reporting"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+	"github.com/ety001/sps-fund-watcher/internal/telegram"
+)
+
+// Activity envelope checks
+const (
+	activityCheckInterval = 15 * time.Minute
+	activityWindow        = 24 * time.Hour
+	// Blocks per activity window; silence is only judged once the sync covers a full window
+	activityWindowBlocks = int64(activityWindow / (3 * time.Second))
+	// Silence isn't judged while the sync is more than an hour behind the node
+	maxActivityLag = int64(time.Hour / (3 * time.Second))
+)
+
+// Activity states of an envelope
+const (
+	activityNormal      = ""
+	activitySilent      = "silent"
+	activityHyperactive = "hyperactive"
+)
+
+// checkActivity compares the last 24 hours of operations of each activity_alerts account with its
+// envelope and alerts once when an account leaves it; the alert is re-armed when it returns
+func (s *Syncer) checkActivity(ctx context.Context) {
+	if len(s.config.ActivityAlerts) == 0 {
+		return
+	}
+	if s.activityStates == nil {
+		s.activityStates = make(map[int]string)
+	}
+
+	// Stored operations only show silence when the sync is current and has run for a full window
+	judgeSilence := false
+	if syncState, err := s.storage.GetSyncState(ctx); err == nil && syncState.LastBlock-s.config.Steem.StartBlock >= activityWindowBlocks {
+		if dgp, err := s.steemAPI.GetDynamicGlobalProperties(); err == nil {
+			lag := syncState.Lag(s.config.Steem.SyncMode, int64(dgp.HeadBlockNumber), int64(dgp.LastIrreversibleBlockNum))
+			judgeSilence = lag <= maxActivityLag
+		}
+	}
+
+	since := s.clock.Now().Add(-activityWindow)
+	for i, envelope := range s.config.ActivityAlerts {
+		count, err := s.storage.CountOperations(ctx, storage.OperationQuery{Account: envelope.Account, OpTypes: envelope.OpTypes, From: since})
+		if err != nil {
+			logger.Warn("Failed to count operations for activity check", "account", envelope.Account, "error", err)
+			continue
+		}
+
+		state := activityNormal
+		switch {
+		case envelope.MaxPerDay > 0 && count > envelope.MaxPerDay:
+			state = activityHyperactive
+		case envelope.MinPerDay > 0 && count < envelope.MinPerDay:
+			if !judgeSilence {
+				continue
+			}
+			state = activitySilent
+		}
+
+		previous := s.activityStates[i]
+		s.activityStates[i] = state
+		if state == previous {
+			continue
+		}
+		if state == activityNormal {
+			logger.Info("Account activity back within its envelope", "account", envelope.Account, "op_types", envelope.OpTypes, "count_24h", count)
+			continue
+		}
+		s.sendActivityAlert(envelope, state, count)
+	}
+}
+
+// sendActivityAlert reports an account that went silent or became hyperactive
+func (s *Syncer) sendActivityAlert(envelope models.ActivityEnvelope, state string, count int64) {
+	logger.Warn("Account activity outside its envelope", "account", envelope.Account, "state", state,
+		"op_types", strings.Join(envelope.OpTypes, ","), "count_24h", count, "min_per_day", envelope.MinPerDay, "max_per_day", envelope.MaxPerDay)
+	if s.telegram == nil {
+		return
+	}
+
+	message := s.telegram.Formatter().ActivityAlert(telegram.ActivityAlert{
+		Account:   envelope.Account,
+		OpTypes:   envelope.OpTypes,
+		Count:     count,
+		MinPerDay: envelope.MinPerDay,
+		MaxPerDay: envelope.MaxPerDay,
+	})
+	if err := s.telegram.SendMessage(message); err != nil {
+		notifyLogger.Error("Failed to send activity alert", "account", envelope.Account, "error", err)
+		reporting.Failure("telegram", err, "account", envelope.Account)
+		return
+	}
+	reporting.Success("telegram")
+}
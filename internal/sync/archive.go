@@ -0,0 +1,108 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/steemit/steemutil/protocol"
+)
+
+// getOpsInBlocks fetches the operations of the blocks in [startBlock, endBlock]
+// With archiving on, the node's answers are fetched undecoded and returned as well, since the
+// SDK can't encode decoded operations back without losses
+func (s *Syncer) getOpsInBlocks(startBlock, endBlock int64) (map[uint][]*protocol.OperationObject, map[uint]json.RawMessage, error) {
+	if !s.config.Archive.Enabled() {
+		opsMap, err := s.steemAPI.GetOpsInBlocks(uint(startBlock), uint(endBlock+1), false)
+		return opsMap, nil, err
+	}
+
+	rawMap, err := s.steemAPI.GetRawOpsInBlocks(uint(startBlock), uint(endBlock+1), false)
+	if err != nil {
+		return nil, nil, err
+	}
+	opsMap := make(map[uint][]*protocol.OperationObject, len(rawMap))
+	for blockNum, raw := range rawMap {
+		var ops []*protocol.OperationObject
+		if err := json.Unmarshal(raw, &ops); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode operations of block %d: %w", blockNum, err)
+		}
+		opsMap[blockNum] = ops
+	}
+	return opsMap, rawMap, nil
+}
+
+// archiveBlocks keeps the raw operations of a fetched batch according to archive.mode
+// A failure only loses the archived copy, so it is logged instead of holding up the sync
+func (s *Syncer) archiveBlocks(ctx context.Context, rawMap map[uint]json.RawMessage, opsMap map[uint][]*protocol.OperationObject, operations map[int64][]*models.Operation, startBlock, endBlock int64) {
+	if rawMap == nil {
+		return
+	}
+
+	var blocks []*models.ArchivedBlock
+	for blockNum := startBlock; blockNum <= endBlock; blockNum++ {
+		ops := opsMap[uint(blockNum)]
+		matched := operations[blockNum]
+		if len(ops) == 0 || (s.config.Archive.Mode == models.ArchiveModeMatched && len(matched) == 0) {
+			continue
+		}
+		blocks = append(blocks, ArchiveBlock(blockNum, rawMap[uint(blockNum)], ops, matched))
+	}
+
+	if err := s.storage.SaveArchivedBlocks(ctx, blocks); err != nil {
+		logger.Warn("Failed to archive blocks", "start_block", startBlock, "end_block", endBlock, "error", err)
+	}
+}
+
+// rearchiveBlock replaces the archived copy of a block dropped by a fork with the canonical block
+// The canonical block is kept even without matches in "matched" mode, so no copy of the forked
+// block is left behind
+func (s *Syncer) rearchiveBlock(ctx context.Context, blockNum int64, rawMap map[uint]json.RawMessage, ops []*protocol.OperationObject, operations []*models.Operation) {
+	if rawMap == nil || len(ops) == 0 {
+		return
+	}
+	block := ArchiveBlock(blockNum, rawMap[uint(blockNum)], ops, operations)
+	if err := s.storage.SaveArchivedBlocks(ctx, []*models.ArchivedBlock{block}); err != nil {
+		logger.Warn("Failed to archive block", "block_num", blockNum, "error", err)
+	}
+}
+
+// ArchiveBlock returns the archived copy of a block from the node's answer and its decoded
+// operations; the header is taken from the operations extracted from it, if any
+func ArchiveBlock(blockNum int64, raw json.RawMessage, ops []*protocol.OperationObject, extracted []*models.Operation) *models.ArchivedBlock {
+	block := &models.ArchivedBlock{
+		BlockNum:   blockNum,
+		Operations: string(raw),
+	}
+	if len(extracted) > 0 {
+		block.BlockID = extracted[0].BlockID
+		block.Witness = extracted[0].Witness
+	}
+	for _, op := range ops {
+		if op.Timestamp != nil && op.Timestamp.Time != nil {
+			block.Timestamp = *op.Timestamp.Time
+			break
+		}
+	}
+	return block
+}
+
+// ArchivedOperations decodes the raw operations of an archived block
+func ArchivedOperations(block *models.ArchivedBlock) ([]*protocol.OperationObject, error) {
+	var ops []*protocol.OperationObject
+	if err := json.Unmarshal([]byte(block.Operations), &ops); err != nil {
+		return nil, fmt.Errorf("failed to decode operations of archived block %d: %w", block.BlockNum, err)
+	}
+	return ops, nil
+}
+
+// SetArchivedBlockHeader sets the header of an archived block on the operations extracted from it
+// It reports false when the block was archived without a header, e.g. because it had no matches
+func SetArchivedBlockHeader(block *models.ArchivedBlock, operations []*models.Operation) bool {
+	if block.BlockID == "" {
+		return false
+	}
+	setBlockHeader(operations, block.BlockID, block.Witness)
+	return true
+}
@@ -0,0 +1,168 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/chain"
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/reporting"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+	"github.com/ety001/sps-fund-watcher/internal/telegram"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Automatic tracking of proposal receivers
+const (
+	autoTrackInterval = 30 * time.Minute
+	// A receiver counts as funded once the treasury paid it within this window (payouts are hourly)
+	autoTrackPaidWithin = 48 * time.Hour
+)
+
+// checkAutoTrack tracks receivers of funded proposals that aren't in steem.accounts until their
+// proposals end, with a Telegram notice when tracking starts and ends
+// The tracked receivers are stored, so they survive restarts and failovers
+func (s *Syncer) checkAutoTrack(ctx context.Context) {
+	if !s.config.Reconciliation.AutoTrackReceivers {
+		return
+	}
+	stored, err := s.storage.ListAutoTracked(ctx)
+	if err != nil {
+		logger.Warn("Failed to load auto-tracked accounts", "error", err)
+		return
+	}
+	now := s.clock.Now()
+	tracked := make(map[string]*models.AutoTrackedAccount, len(stored))
+	for i := range stored {
+		tracked[stored[i].Account] = &stored[i]
+	}
+	defer func() {
+		accounts := make([]string, 0, len(tracked))
+		for account := range tracked {
+			accounts = append(accounts, account)
+		}
+		s.processor.SetAutoTrackedAccounts(accounts)
+	}()
+
+	// Without the active proposals, tracked receivers are kept until their stored end
+	active, err := chain.ActiveProposals(s.steemAPI)
+	if err != nil {
+		logger.Warn("Failed to list active proposals for auto-tracking", "error", err)
+	}
+	byReceiver := make(map[string][]chain.Proposal)
+	for _, proposal := range active {
+		if proposal.Receiver != "" && !slices.Contains(s.config.Steem.Accounts, proposal.Receiver) {
+			byReceiver[proposal.Receiver] = append(byReceiver[proposal.Receiver], proposal)
+		}
+	}
+
+	// Extend receivers with a later proposal, stop tracking the ones whose proposals ended
+	for account, current := range tracked {
+		if ids, until := proposalSpan(byReceiver[account]); until.After(current.Until) {
+			current.Proposals, current.Until = ids, until
+			if err := s.storage.SaveAutoTracked(ctx, current); err != nil {
+				logger.Warn("Failed to extend auto-tracked account", "account", account, "error", err)
+			}
+			continue
+		}
+		if now.Before(current.Until) {
+			continue
+		}
+		if err := s.storage.DeleteAutoTracked(ctx, account); err != nil {
+			logger.Warn("Failed to stop auto-tracking account", "account", account, "error", err)
+			continue
+		}
+		delete(tracked, account)
+		logger.Info("Stopped tracking proposal receiver", "account", account, "until", current.Until)
+		s.sendAutoTrackNotice(telegram.AutoTrackNotice{Account: account, Until: current.Until, Proposals: proposalNames(current.Proposals, nil)})
+	}
+
+	// Start tracking receivers of newly funded proposals
+	receivers := make([]string, 0, len(byReceiver))
+	for receiver := range byReceiver {
+		receivers = append(receivers, receiver)
+	}
+	sort.Strings(receivers)
+	for _, receiver := range receivers {
+		if tracked[receiver] != nil {
+			continue
+		}
+		proposals := byReceiver[receiver]
+		ids, until := proposalSpan(proposals)
+		if !until.After(now) {
+			continue
+		}
+		paid, err := s.storage.CountOperations(ctx, storage.OperationQuery{
+			Account: models.TreasuryAccount,
+			OpType:  "proposal_pay",
+			From:    now.Add(-autoTrackPaidWithin),
+			Filter:  bson.M{"op_data.receiver": receiver},
+		})
+		if err != nil {
+			logger.Warn("Failed to check proposal payouts", "receiver", receiver, "error", err)
+			continue
+		}
+		if paid == 0 {
+			continue
+		}
+
+		account := &models.AutoTrackedAccount{Account: receiver, Proposals: ids, Until: until, AddedAt: now}
+		if err := s.storage.SaveAutoTracked(ctx, account); err != nil {
+			logger.Warn("Failed to auto-track proposal receiver", "account", receiver, "error", err)
+			continue
+		}
+		tracked[receiver] = account
+		logger.Info("Tracking proposal receiver", "account", receiver, "proposals", ids, "until", until)
+		s.sendAutoTrackNotice(telegram.AutoTrackNotice{Account: receiver, Started: true, Until: until, Proposals: proposalNames(ids, proposals)})
+	}
+}
+
+// proposalSpan returns the IDs of proposals and the end of the last of them
+func proposalSpan(proposals []chain.Proposal) ([]int64, time.Time) {
+	var ids []int64
+	var until time.Time
+	for _, proposal := range proposals {
+		end, err := proposal.End()
+		if err != nil {
+			continue
+		}
+		ids = append(ids, proposal.ID)
+		if end.After(until) {
+			until = end
+		}
+	}
+	return ids, until
+}
+
+// proposalNames describes proposals by ID and, where known, subject
+func proposalNames(ids []int64, proposals []chain.Proposal) []string {
+	subjects := make(map[int64]string, len(proposals))
+	for _, proposal := range proposals {
+		subjects[proposal.ID] = proposal.Subject
+	}
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		name := fmt.Sprintf("#%d", id)
+		if subject := subjects[id]; subject != "" {
+			name += " " + subject
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// sendAutoTrackNotice announces the start or end of the automatic tracking of a receiver
+func (s *Syncer) sendAutoTrackNotice(notice telegram.AutoTrackNotice) {
+	if s.telegram == nil {
+		return
+	}
+	if err := s.telegram.SendMessage(s.telegram.Formatter().AutoTrackNotice(notice)); err != nil {
+		notifyLogger.Error("Failed to send auto-tracking notice", "account", notice.Account, "error", err)
+		reporting.Failure("telegram", err, "account", notice.Account)
+		return
+	}
+	reporting.Success("telegram")
+}
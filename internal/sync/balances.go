@@ -0,0 +1,190 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/reconcile"
+	"github.com/ety001/sps-fund-watcher/internal/telegram"
+)
+
+// balanceSnapshotInterval is how often treasury balances are recorded without the balance check
+const balanceSnapshotInterval = 24 * time.Hour
+
+// balanceTickInterval is how often the syncer checks whether a balance snapshot is due
+func (s *Syncer) balanceTickInterval() time.Duration {
+	if s.config.Reconciliation.BalanceCheck {
+		return s.config.Reconciliation.BalanceCheckInterval()
+	}
+	return reconciliationCheckInterval
+}
+
+// snapshotBalances records the liquid balances of the treasury accounts once per interval
+func (s *Syncer) snapshotBalances(ctx context.Context, now time.Time) {
+	interval := balanceSnapshotInterval
+	if s.config.Reconciliation.BalanceCheck {
+		interval = s.config.Reconciliation.BalanceCheckInterval()
+	}
+
+	for _, account := range s.config.Reconciliation.Treasury() {
+		latest, err := s.storage.LatestBalanceSnapshot(ctx, account)
+		if err != nil {
+			logger.Warn("Failed to check balance snapshot", "account", account, "error", err)
+			continue
+		}
+		// Leave some slack so a snapshot isn't skipped when the ticker fires slightly early
+		if latest != nil && now.Sub(latest.TakenAt) < interval-time.Minute {
+			continue
+		}
+
+		balances, blockNum, err := s.fetchBalances(account)
+		if err != nil {
+			logger.Warn("Failed to fetch balances", "account", account, "error", err)
+			continue
+		}
+		snapshot := &models.BalanceSnapshot{Account: account, Balances: balances, TakenAt: now.UTC(), BlockNum: blockNum}
+		if err := s.storage.SaveBalanceSnapshot(ctx, snapshot); err != nil {
+			logger.Warn("Failed to save balance snapshot", "account", account, "error", err)
+			continue
+		}
+		logger.Debug("Recorded balance snapshot", "account", account, "block_num", blockNum, "balances", balances)
+	}
+}
+
+// fetchBalances returns the liquid balances of an account by asset symbol and the head block
+// they were read at, or 0 for the block when a new block arrived during the read
+func (s *Syncer) fetchBalances(account string) (map[string]float64, int64, error) {
+	before, err := s.steemAPI.GetDynamicGlobalProperties()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get dynamic global properties: %w", err)
+	}
+
+	result, err := s.steemAPI.GetAccounts([]string{account})
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(result) == 0 {
+		return nil, 0, fmt.Errorf("account %s not found", account)
+	}
+
+	balances := make(map[string]float64)
+	for _, raw := range []string{result[0].Balance, result[0].SBDBalance} {
+		asset, err := models.ParseAsset(raw)
+		if err != nil {
+			return nil, 0, err
+		}
+		balances[asset.Symbol] = asset.Amount
+	}
+
+	after, err := s.steemAPI.GetDynamicGlobalProperties()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get dynamic global properties: %w", err)
+	}
+	if after.HeadBlockNumber != before.HeadBlockNumber {
+		return balances, 0, nil
+	}
+	return balances, int64(before.HeadBlockNumber), nil
+}
+
+// checkBalances compares the balance change between consecutive treasury snapshots with the
+// stored operations once the sync has passed the later one. An unexplained change means blocks
+// or virtual operations were missed: it is reported and the block range is queued for
+// compensator -scheduled
+func (s *Syncer) checkBalances(ctx context.Context) {
+	if !s.config.Reconciliation.BalanceCheck {
+		return
+	}
+	state, err := s.storage.GetSyncState(ctx)
+	if err != nil {
+		logger.Warn("Failed to get sync state for balance check", "error", err)
+		return
+	}
+
+	for _, account := range s.config.Reconciliation.Treasury() {
+		previous, err := s.storage.LastCheckedBalanceSnapshot(ctx, account)
+		if err != nil {
+			logger.Warn("Failed to load checked balance snapshot", "account", account, "error", err)
+			continue
+		}
+		var afterBlock int64
+		if previous != nil {
+			afterBlock = previous.BlockNum
+		}
+		pending, err := s.storage.UncheckedBalanceSnapshots(ctx, account, afterBlock, state.LastBlock)
+		if err != nil {
+			logger.Warn("Failed to load balance snapshots", "account", account, "error", err)
+			continue
+		}
+
+		for i := range pending {
+			current := &pending[i]
+			if previous != nil {
+				mismatches, err := reconcile.BalanceMismatches(ctx, s.storage, previous, current)
+				if err != nil {
+					logger.Warn("Failed to check balances", "account", account, "block_num", current.BlockNum, "error", err)
+					break
+				}
+				if len(mismatches) > 0 {
+					s.reportBalanceMismatch(ctx, mismatches)
+				}
+			}
+			if err := s.storage.MarkBalanceSnapshotChecked(ctx, account, current.BlockNum); err != nil {
+				logger.Warn("Failed to record balance check", "account", account, "block_num", current.BlockNum, "error", err)
+				break
+			}
+			previous = current
+		}
+	}
+}
+
+// reportBalanceMismatch alerts about the unexplained balance changes of one block range and
+// schedules a compensator run over it
+func (s *Syncer) reportBalanceMismatch(ctx context.Context, mismatches []models.BalanceMismatch) {
+	first := mismatches[0]
+	for _, m := range mismatches {
+		logger.Warn("Balance change not explained by stored operations", "account", m.Account, "symbol", m.Symbol,
+			"start_block", m.StartBlock, "end_block", m.EndBlock, "chain", m.Chain, "stored", m.Stored)
+	}
+
+	now := s.clock.Now()
+	accounts := []string{first.Account}
+	job := &models.CompensatorJob{
+		ID:                 models.CompensatorJobID(accounts, first.StartBlock, first.EndBlock),
+		Accounts:           accounts,
+		StartBlock:         first.StartBlock,
+		EndBlock:           first.EndBlock,
+		LastProcessedBlock: first.StartBlock - 1,
+		Reason:             "balance mismatch",
+		StartedAt:          now,
+		UpdatedAt:          now,
+	}
+	repair := job.ID
+	scheduled, err := s.storage.ScheduleCompensatorJob(ctx, job)
+	if err != nil {
+		logger.Error("Failed to schedule gap repair", "job", job.ID, "error", err)
+		repair = ""
+	} else if scheduled {
+		logger.Info("Scheduled gap repair", "job", job.ID)
+	}
+
+	if s.telegram == nil {
+		return
+	}
+	alert := telegram.BalanceMismatchAlert{
+		Account:    first.Account,
+		StartBlock: first.StartBlock,
+		EndBlock:   first.EndBlock,
+		Repair:     repair,
+	}
+	for _, m := range mismatches {
+		alert.Changes = append(alert.Changes, telegram.BalanceMismatchItem{
+			Chain:  fmt.Sprintf("%+.3f %s", m.Chain, m.Symbol),
+			Stored: fmt.Sprintf("%+.3f %s", m.Stored, m.Symbol),
+		})
+	}
+	if err := s.telegram.SendMessage(s.telegram.Formatter().BalanceMismatch(alert)); err != nil {
+		logger.Error("Failed to send balance mismatch alert", "account", first.Account, "error", err)
+	}
+}
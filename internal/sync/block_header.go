@@ -0,0 +1,61 @@
+package sync
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/ety001/sps-fund-watcher/internal/chain"
+	"github.com/ety001/sps-fund-watcher/internal/models"
+)
+
+// AttachBlockHeader sets the block ID and producing witness on the operations of a block
+// The block is only fetched when there are operations, i.e. for blocks touching tracked accounts
+func AttachBlockHeader(client chain.Client, blockNum int64, operations []*models.Operation) error {
+	if len(operations) == 0 {
+		return nil
+	}
+	block, err := client.GetBlock(uint(blockNum))
+	if err != nil {
+		return fmt.Errorf("failed to get block header %d: %w", blockNum, err)
+	}
+	if block.BlockId == "" {
+		return fmt.Errorf("block %d not available from node", blockNum)
+	}
+	setBlockHeader(operations, block.BlockId, block.Witness)
+	return nil
+}
+
+// AttachBlockHeaders is AttachBlockHeader for several blocks, fetching all headers at once so
+// they can share a batch request
+func AttachBlockHeaders(client chain.Client, operations map[int64][]*models.Operation) error {
+	var blockNums []uint
+	for blockNum, ops := range operations {
+		if len(ops) > 0 {
+			blockNums = append(blockNums, uint(blockNum))
+		}
+	}
+	if len(blockNums) == 0 {
+		return nil
+	}
+	slices.Sort(blockNums)
+	blocks, err := client.GetBlocks(blockNums)
+	if err != nil {
+		return fmt.Errorf("failed to get block headers: %w", err)
+	}
+	for _, blockNum := range blockNums {
+		block := blocks[blockNum]
+		if block == nil || block.BlockId == "" {
+			return fmt.Errorf("block %d not available from node", blockNum)
+		}
+		setBlockHeader(operations[int64(blockNum)], block.BlockId, block.Witness)
+	}
+	return nil
+}
+
+// setBlockHeader sets the block ID and witness on operations
+func setBlockHeader(operations []*models.Operation, blockID, witness string) {
+	for _, op := range operations {
+		op.BlockID = blockID
+		op.Witness = witness
+	}
+}
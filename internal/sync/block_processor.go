@@ -2,17 +2,28 @@ package sync
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
+	stdsync "sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/ety001/sps-fund-watcher/internal/clock"
+	"github.com/ety001/sps-fund-watcher/internal/enrich"
+	"github.com/ety001/sps-fund-watcher/internal/logging"
 	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/plugin"
+	"github.com/ety001/sps-fund-watcher/internal/reporting"
 	"github.com/ety001/sps-fund-watcher/internal/storage"
 	"github.com/ety001/sps-fund-watcher/internal/telegram"
+	"github.com/ety001/sps-fund-watcher/internal/webhook"
 	"github.com/steemit/steemutil/protocol"
 	protocolapi "github.com/steemit/steemutil/protocol/api"
 )
 
+var notifyLogger = logging.Component("notify")
+
 // TelegramNotificationRule holds a notification rule configuration
 type TelegramNotificationRule struct {
 	Config         models.TelegramUserConfig
@@ -20,6 +31,15 @@ type TelegramNotificationRule struct {
 	NotifyAllOps   bool
 	NotifyAccounts map[string]bool
 	NotifyAllAccts bool
+	// Per-account operation types from account_operations; an empty set matches all types
+	AccountOps map[string]map[string]bool
+	// Fund event kinds announced by the rule
+	FundEvents map[string]bool
+}
+
+// sendOptions returns how the rule's messages are delivered
+func (r TelegramNotificationRule) sendOptions() telegram.SendOptions {
+	return telegram.SendOptions{ThreadID: r.Config.MessageThreadID, Silent: r.Config.DisableNotification}
 }
 
 // BlockProcessor processes blocks and extracts operations
@@ -29,6 +49,60 @@ type BlockProcessor struct {
 	notificationRules []TelegramNotificationRule
 	accounts          map[string]bool
 	globalTemplate    string
+
+	// Proposal receivers tracked in addition to accounts (see SetAutoTrackedAccounts)
+	autoTracked atomic.Pointer[map[string]bool]
+
+	// firstMatchOnly stops rule evaluation at the first matching rule (rule_evaluation: first)
+	firstMatchOnly bool
+
+	// jsonOnly disables typed extraction of common operations (see SetFastPath)
+	jsonOnly bool
+
+	// notificationsPaused is toggled by the operator through the admin API
+	notificationsPaused atomic.Bool
+
+	// Catch-up policy for stale operations (see SetCatchUpPolicy)
+	maxNotifyAge time.Duration
+	digestStale  bool
+	catchUp      catchUpDigest
+
+	// Per-rule digest accumulators, indexed like notificationRules (nil for real-time rules)
+	digests []*ruleDigest
+
+	// detectMentions records mention events for tracked accounts named in comments
+	detectMentions bool
+
+	// Nested op_data paths holding account names, by operation type (see SetAccountPaths)
+	accountPaths map[string][][]string
+
+	// Top-level fields checked in unknown operation types, and whether their matches are only
+	// stored for review (see SetFallbackExtraction)
+	fallbackFields []string
+	reviewFallback bool
+
+	// Sampling rules by account (see SetSamplingRules)
+	sampling map[string][]samplingRule
+
+	// Content storage policies (see SetStoragePolicies)
+	storagePolicies []storagePolicy
+
+	// Computed fields added before storage (see SetEnrichment and SetPlugins)
+	enrichment *enrich.Pipeline
+	plugins    *plugin.Runner
+
+	// Fund events derived after storage (see SetFundEvents)
+	fundEvents       *enrich.FundEventDeriver
+	fundEventsSeeded bool
+
+	// Saved views bound to notifications, refreshed by the syncer
+	viewsMu    stdsync.RWMutex
+	boundViews []boundView
+
+	webhooks *webhook.Dispatcher
+
+	// Time source (see SetClock)
+	clock clock.Clock
 }
 
 // NewBlockProcessor creates a new block processor
@@ -49,8 +123,9 @@ func NewBlockProcessor(
 	var rules []TelegramNotificationRule
 	for _, userConfig := range userConfigs {
 		// Create notify operations map
+		// A rule bound to fund events only matches operations it lists explicitly
 		notifyOpsMap := make(map[string]bool)
-		notifyAllOps := len(userConfig.NotifyOperations) == 0
+		notifyAllOps := len(userConfig.NotifyOperations) == 0 && len(userConfig.FundEvents) == 0
 		if !notifyAllOps {
 			for _, opType := range userConfig.NotifyOperations {
 				notifyOpsMap[opType] = true
@@ -66,22 +141,89 @@ func NewBlockProcessor(
 			}
 		}
 
+		// Create per-account operations map
+		accountOpsMap := make(map[string]map[string]bool)
+		for account, opTypes := range userConfig.AccountOperations {
+			accountOpsMap[account] = make(map[string]bool)
+			for _, opType := range opTypes {
+				accountOpsMap[account][opType] = true
+			}
+		}
+
+		fundEventsMap := make(map[string]bool)
+		for _, kind := range userConfig.FundEvents {
+			fundEventsMap[kind] = true
+		}
+
 		rules = append(rules, TelegramNotificationRule{
 			Config:         userConfig,
 			NotifyOps:      notifyOpsMap,
 			NotifyAllOps:   notifyAllOps,
 			NotifyAccounts: notifyAcctsMap,
 			NotifyAllAccts: notifyAllAccts,
+			AccountOps:     accountOpsMap,
+			FundEvents:     fundEventsMap,
 		})
 	}
 
-	return &BlockProcessor{
+	// Higher priority first; the stable sort keeps configuration order among equal priorities
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].Config.Priority > rules[j].Config.Priority
+	})
+
+	bp := &BlockProcessor{
 		storage:           storage,
 		telegramClient:    telegramClient,
 		notificationRules: rules,
 		accounts:          accountMap,
 		globalTemplate:    globalMessageTemplate,
+		digests:           newRuleDigests(rules),
+		clock:             clock.Real,
 	}
+	bp.SetAccountPaths(nil)
+	bp.SetFallbackExtraction(models.FallbackExtractionConfig{})
+	return bp
+}
+
+// SetNotificationsPaused enables or disables notification dispatch
+// Operations are still saved while notifications are paused
+func (bp *BlockProcessor) SetNotificationsPaused(paused bool) {
+	bp.notificationsPaused.Store(paused)
+}
+
+// SetRuleEvaluation selects whether an operation is notified by every matching rule ("all")
+// or only by the highest-priority one ("first")
+func (bp *BlockProcessor) SetRuleEvaluation(mode string) {
+	bp.firstMatchOnly = mode == models.RuleEvaluationFirst
+}
+
+// SetAutoTrackedAccounts replaces the accounts tracked in addition to the configured ones
+// It is safe to call while blocks are processed
+func (bp *BlockProcessor) SetAutoTrackedAccounts(accounts []string) {
+	tracked := make(map[string]bool, len(accounts))
+	for _, account := range accounts {
+		tracked[account] = true
+	}
+	bp.autoTracked.Store(&tracked)
+}
+
+// tracks reports whether operations of account are stored
+func (bp *BlockProcessor) tracks(account string) bool {
+	if bp.accounts[account] {
+		return true
+	}
+	extra := bp.autoTracked.Load()
+	return extra != nil && (*extra)[account]
+}
+
+// SetClock replaces the time source used for catch-up ages, digest windows and missing timestamps
+func (bp *BlockProcessor) SetClock(c clock.Clock) {
+	bp.clock = c
+}
+
+// SetWebhookDispatcher enables delivery of matched operations to webhooks
+func (bp *BlockProcessor) SetWebhookDispatcher(dispatcher *webhook.Dispatcher) {
+	bp.webhooks = dispatcher
 }
 
 // ProcessBlock processes a block and extracts operations for tracked accounts
@@ -91,7 +233,7 @@ func (bp *BlockProcessor) ProcessBlock(ctx context.Context, block *protocolapi.B
 	if block.Timestamp != nil && block.Timestamp.Time != nil {
 		blockTime = *block.Timestamp.Time
 	} else {
-		blockTime = time.Now()
+		blockTime = bp.clock.Now()
 	}
 
 	var operations []*models.Operation
@@ -101,46 +243,42 @@ func (bp *BlockProcessor) ProcessBlock(ctx context.Context, block *protocolapi.B
 			// Get operation type and data from protocol.Operation interface
 			opType := string(protocolOp.Type())
 
-			// Convert operation data to map[string]interface{}
-			opDataRaw := protocolOp.Data()
-			var opData map[string]interface{}
-
-			// Try to convert to map
-			if dataMap, ok := opDataRaw.(map[string]interface{}); ok {
-				opData = dataMap
-			} else {
-				// If it's a typed operation, marshal and unmarshal to get map
-				dataJSON, err := json.Marshal(opDataRaw)
-				if err != nil {
-					continue
-				}
-				if err := json.Unmarshal(dataJSON, &opData); err != nil {
-					continue
-				}
+			// Mention events for tracked accounts named in posts and comments
+			for _, m := range bp.mentionOperations(opType, protocolOp.Data()) {
+				operations = append(operations, &models.Operation{
+					BlockNum:  blockNum,
+					TrxID:     tx.TransactionId,
+					OpInTrx:   opIndex,
+					Account:   m.account,
+					OpType:    models.OpTypeMention,
+					OpData:    m.opData,
+					Timestamp: blockTime,
+				})
 			}
 
-			// Extract accounts from operation data
-			accounts := bp.extractAccounts(opType, opData)
-			if len(accounts) == 0 {
+			// Convert operation data to a map and extract the involved accounts
+			opData, accounts, review, ok := bp.decodeOperation(opType, protocolOp.Data())
+			if !ok {
 				continue
 			}
 
 			// Create operation for each tracked account
 			for _, account := range accounts {
 				// Check if account is tracked
-				if !bp.accounts[account] {
+				if !bp.tracks(account) {
 					continue
 				}
 
 				// Create operation model
 				op := &models.Operation{
-					BlockNum:  blockNum,
-					TrxID:     tx.TransactionId,
-					OpInTrx:   opIndex,
-					Account:   account,
-					OpType:    opType,
-					OpData:    opData,
-					Timestamp: blockTime,
+					BlockNum:    blockNum,
+					TrxID:       tx.TransactionId,
+					OpInTrx:     opIndex,
+					Account:     account,
+					OpType:      opType,
+					OpData:      opData,
+					Timestamp:   blockTime,
+					NeedsReview: review[account],
 				}
 
 				operations = append(operations, op)
@@ -148,6 +286,7 @@ func (bp *BlockProcessor) ProcessBlock(ctx context.Context, block *protocolapi.B
 		}
 	}
 
+	setBlockHeader(operations, block.BlockId, block.Witness)
 	return operations, nil
 }
 
@@ -162,68 +301,50 @@ func (bp *BlockProcessor) ProcessOperations(ctx context.Context, ops []*protocol
 		if opObj.Timestamp != nil && opObj.Timestamp.Time != nil {
 			opTime = *opObj.Timestamp.Time
 		} else {
-			opTime = time.Now()
+			opTime = bp.clock.Now()
 		}
 
 		// Get operation type and data
 		opType := string(opObj.Operation.Type())
 
-		// Convert operation data to map[string]interface{}
-		opDataRaw := opObj.Operation.Data()
-		var opData map[string]interface{}
-
-		// Try to convert to map
-		if dataMap, ok := opDataRaw.(map[string]interface{}); ok {
-			opData = dataMap
-		} else {
-			// If it's a typed operation, marshal and unmarshal to get map
-			dataJSON, err := json.Marshal(opDataRaw)
-			if err != nil {
-				continue
-			}
-			if err := json.Unmarshal(dataJSON, &opData); err != nil {
-				continue
-			}
+		// Mention events for tracked accounts named in posts and comments
+		for _, m := range bp.mentionOperations(opType, opObj.Operation.Data()) {
+			operations = append(operations, &models.Operation{
+				BlockNum:  int64(opObj.BlockNumber),
+				TrxID:     operationTrxID(opObj),
+				OpInTrx:   opIndex,
+				Account:   m.account,
+				OpType:    models.OpTypeMention,
+				OpData:    m.opData,
+				Timestamp: opTime,
+			})
 		}
 
-		// Extract accounts from operation data
-		accounts := bp.extractAccounts(opType, opData)
-		if len(accounts) == 0 {
+		// Convert operation data to a map and extract the involved accounts
+		opData, accounts, review, ok := bp.decodeOperation(opType, opObj.Operation.Data())
+		if !ok {
 			continue
 		}
 
 		// Create operation for each tracked account
 		for _, account := range accounts {
 			// Check if account is tracked
-			if !bp.accounts[account] {
+			if !bp.tracks(account) {
 				continue
 			}
 
-			// For virtual operations, TransactionID is usually empty
-			// Use a combination of block number and virtual op number as unique identifier
-			trxID := opObj.TransactionID
-			if trxID == "" {
-				if opObj.VirtualOperation > 0 {
-					// Virtual operation: use virtual op number as identifier
-					trxID = fmt.Sprintf("virtual_%d_%d", opObj.BlockNumber, opObj.VirtualOperation)
-				} else {
-					// Regular operation with empty TransactionID (should not happen, but handle gracefully)
-					// Use transaction_in_block and op_in_trx as fallback identifier
-					trxID = fmt.Sprintf("regular_%d_%d_%d", opObj.BlockNumber, opObj.TransactionInBlock, opObj.OperationInTransaction)
-				}
-			}
-
 			// Create operation model
 			// Use opIndex instead of OperationInTransaction because the latter is always 0
 			// when using get_ops_in_block API
 			op := &models.Operation{
-				BlockNum:  int64(opObj.BlockNumber),
-				TrxID:     trxID,
-				OpInTrx:   opIndex,
-				Account:   account,
-				OpType:    opType,
-				OpData:    opData,
-				Timestamp: opTime,
+				BlockNum:    int64(opObj.BlockNumber),
+				TrxID:       operationTrxID(opObj),
+				OpInTrx:     opIndex,
+				Account:     account,
+				OpType:      opType,
+				OpData:      opData,
+				Timestamp:   opTime,
+				NeedsReview: review[account],
 			}
 
 			operations = append(operations, op)
@@ -233,6 +354,21 @@ func (bp *BlockProcessor) ProcessOperations(ctx context.Context, ops []*protocol
 	return operations, nil
 }
 
+// operationTrxID returns the transaction ID used to identify a stored operation
+func operationTrxID(opObj *protocol.OperationObject) string {
+	if opObj.TransactionID != "" {
+		return opObj.TransactionID
+	}
+	// For virtual operations, TransactionID is usually empty
+	// Use a combination of block number and virtual op number as unique identifier
+	if opObj.VirtualOperation > 0 {
+		return fmt.Sprintf("virtual_%d_%d", opObj.BlockNumber, opObj.VirtualOperation)
+	}
+	// Regular operation with empty TransactionID (should not happen, but handle gracefully)
+	// Use transaction_in_block and op_in_trx as fallback identifier
+	return fmt.Sprintf("regular_%d_%d_%d", opObj.BlockNumber, opObj.TransactionInBlock, opObj.OperationInTransaction)
+}
+
 // ProcessVirtualOperations is kept for backward compatibility
 // It now delegates to ProcessOperations
 func (bp *BlockProcessor) ProcessVirtualOperations(ctx context.Context, ops []*protocol.OperationObject, blockNum int64) ([]*models.Operation, error) {
@@ -240,10 +376,11 @@ func (bp *BlockProcessor) ProcessVirtualOperations(ctx context.Context, ops []*p
 }
 
 // extractAccounts extracts account names from operation data
-// Returns a slice of accounts involved in the operation
+// Returns a slice of accounts involved in the operation, and in review mode the ones found only
+// by the fallback for unknown operation types
 // Based on operation definitions in steemutil/protocol/operations.go
-func (bp *BlockProcessor) extractAccounts(opType string, opData map[string]interface{}) []string {
-	var accounts []string
+func (bp *BlockProcessor) extractAccounts(opType string, opData map[string]interface{}) ([]string, map[string]bool) {
+	var accounts, fallback []string
 
 	// Helper function to safely extract string field
 	extractString := func(field string) string {
@@ -253,6 +390,18 @@ func (bp *BlockProcessor) extractAccounts(opType string, opData map[string]inter
 		return ""
 	}
 
+	// Helper function to extract every account of a string array field
+	extractStrings := func(field string) []string {
+		var values []string
+		items, _ := opData[field].([]interface{})
+		for _, item := range items {
+			if val, ok := item.(string); ok && val != "" {
+				values = append(values, val)
+			}
+		}
+		return values
+	}
+
 	// Extract accounts based on operation type
 	switch opType {
 	case "vote":
@@ -504,6 +653,11 @@ func (bp *BlockProcessor) extractAccounts(opType string, opData map[string]inter
 			accounts = append(accounts, resetAccount)
 		}
 
+	case "custom_json":
+		// Signers, e.g. community admin actions or rc delegations
+		accounts = append(accounts, extractStrings("required_auths")...)
+		accounts = append(accounts, extractStrings("required_posting_auths")...)
+
 	case "claim_reward_balance":
 		if account := extractString("account"); account != "" {
 			accounts = append(accounts, account)
@@ -614,6 +768,17 @@ func (bp *BlockProcessor) extractAccounts(opType string, opData map[string]inter
 		if receiver := extractString("receiver"); receiver != "" {
 			accounts = append(accounts, receiver)
 		}
+		// Paid from the SPS fund, which is implicit like for sps_fund
+		accounts = append(accounts, models.TreasuryAccount)
+
+	case "sps_fund":
+		// Inflation credited to the SPS fund; the fund account is implicit
+		accounts = append(accounts, models.TreasuryAccount)
+
+	case "sps_convert":
+		if fundAccount := extractString("fund_account"); fundAccount != "" {
+			accounts = append(accounts, fundAccount)
+		}
 
 	case "author_reward":
 		if author := extractString("author"); author != "" {
@@ -662,21 +827,19 @@ func (bp *BlockProcessor) extractAccounts(opType string, opData map[string]inter
 		}
 
 	default:
-		// Fallback: try common account fields for unknown operation types
-		if account := extractString("account"); account != "" {
-			accounts = append(accounts, account)
-		}
-		if owner := extractString("owner"); owner != "" {
-			accounts = append(accounts, owner)
-		}
-		if from := extractString("from"); from != "" {
-			accounts = append(accounts, from)
-		}
-		if to := extractString("to"); to != "" {
-			accounts = append(accounts, to)
+		// Fallback: try the configured account fields for unknown operation types
+		for _, field := range bp.fallbackFields {
+			if account := extractString(field); account != "" {
+				fallback = append(fallback, account)
+			}
 		}
+		accounts = append(accounts, fallback...)
 	}
 
+	// Accounts inside nested structures such as beneficiaries
+	nested := bp.nestedAccounts(opType, opData)
+	accounts = append(accounts, nested...)
+
 	// Remove duplicates
 	accountMap := make(map[string]bool)
 	var uniqueAccounts []string
@@ -687,27 +850,34 @@ func (bp *BlockProcessor) extractAccounts(opType string, opData map[string]inter
 		}
 	}
 
-	return uniqueAccounts
+	return uniqueAccounts, bp.reviewAccounts(fallback, nested)
 }
 
 // shouldNotifyForRule checks if an operation should be notified for a specific rule
 func (bp *BlockProcessor) shouldNotifyForRule(rule TelegramNotificationRule, op *models.Operation) bool {
-	// Check if operation type matches
-	opTypeMatches := rule.NotifyAllOps
-	if !opTypeMatches {
-		opTypeMatches = rule.NotifyOps[op.OpType]
-	}
-	if !opTypeMatches {
-		return false
-	}
+	// Accounts with their own operation list are matched on that list alone
+	if accountOps, ok := rule.AccountOps[op.Account]; ok {
+		if len(accountOps) > 0 && !accountOps[op.OpType] {
+			return false
+		}
+	} else {
+		// Check if operation type matches
+		opTypeMatches := rule.NotifyAllOps
+		if !opTypeMatches {
+			opTypeMatches = rule.NotifyOps[op.OpType]
+		}
+		if !opTypeMatches {
+			return false
+		}
 
-	// Check if account matches
-	accountMatches := rule.NotifyAllAccts
-	if !accountMatches {
-		accountMatches = rule.NotifyAccounts[op.Account]
-	}
-	if !accountMatches {
-		return false
+		// Check if account matches
+		accountMatches := rule.NotifyAllAccts
+		if !accountMatches {
+			accountMatches = rule.NotifyAccounts[op.Account]
+		}
+		if !accountMatches {
+			return false
+		}
 	}
 
 	// Check operation-level filters
@@ -715,6 +885,11 @@ func (bp *BlockProcessor) shouldNotifyForRule(rule TelegramNotificationRule, op
 		return false
 	}
 
+	// Check amount threshold
+	if !passesAmountThreshold(rule.Config, op) {
+		return false
+	}
+
 	return true
 }
 
@@ -733,10 +908,42 @@ func (bp *BlockProcessor) passesOperationFilters(filters map[string]models.Opera
 	// Apply different filter logic based on opType
 	switch op.OpType {
 	case "transfer":
-		return bp.passesTransferFilter(filter, op.OpData)
-	default:
+		if !bp.passesTransferFilter(filter, op.OpData) {
+			return false
+		}
+	}
+
+	return passesConditions(filter.Conditions, op.OpData)
+}
+
+// passesAmountThreshold checks the rule's min_amount/amount_symbol against the operation's amount
+// A rule with a threshold never passes operations without an amount, such as votes
+func passesAmountThreshold(rule models.TelegramUserConfig, op *models.Operation) bool {
+	if rule.MinAmount <= 0 && rule.AmountSymbol == "" {
 		return true
 	}
+
+	amount, ok := models.OperationAmount(op.OpData)
+	if !ok {
+		return false
+	}
+	if rule.AmountSymbol != "" && !strings.EqualFold(amount.Symbol, rule.AmountSymbol) {
+		return false
+	}
+	return amount.Amount >= rule.MinAmount
+}
+
+// shouldPin reports whether the notification of an operation is pinned by the rule's pin_min_amount
+// Like min_amount, operations without an amount are never pinned
+func shouldPin(rule models.TelegramUserConfig, op *models.Operation) bool {
+	if rule.PinMinAmount <= 0 {
+		return false
+	}
+	amount, ok := models.OperationAmount(op.OpData)
+	if !ok || (rule.AmountSymbol != "" && !strings.EqualFold(amount.Symbol, rule.AmountSymbol)) {
+		return false
+	}
+	return amount.Amount >= rule.PinMinAmount
 }
 
 // passesTransferFilter checks if a transfer operation passes the filter
@@ -764,64 +971,170 @@ func (bp *BlockProcessor) passesTransferFilter(filter models.OperationFilter, op
 
 // SaveOperations saves operations to storage and sends notifications
 func (bp *BlockProcessor) SaveOperations(ctx context.Context, operations []*models.Operation) error {
+	operations, err := bp.ApplySampling(ctx, operations)
+	if err != nil {
+		return fmt.Errorf("failed to apply sampling: %w", err)
+	}
 	if len(operations) == 0 {
 		return nil
 	}
+	bp.Enrich(ctx, operations)
 
-	// Save all operations to MongoDB
-	if err := bp.storage.InsertOperations(ctx, operations); err != nil {
+	// Save all operations to MongoDB; notifications still see the full content
+	if err := bp.storage.InsertOperations(ctx, bp.ApplyStoragePolicy(operations)); err != nil {
 		return fmt.Errorf("failed to insert operations: %w", err)
 	}
 
-	// Send Telegram notifications for each configured rule
-	if bp.telegramClient != nil {
-		for _, rule := range bp.notificationRules {
-			for _, op := range operations {
-				// Check if should notify for this rule
-				if !bp.shouldNotifyForRule(rule, op) {
-					continue
-				}
+	// Operations waiting for review or vetoed by a plugin are stored but not announced
+	confirmed := withoutVetoed(confirmedOperations(operations))
+	bp.sendNotifications(confirmed)
+	bp.notifyBoundViews(ctx, confirmed)
+	bp.dispatchWebhooks(confirmed)
+	bp.notifyFundEvents(bp.deriveFundEvents(ctx, operations))
 
-				// Format message
-				var message string
-				if rule.Config.MessageTemplate != "" {
-					// Use rule-specific template
-					message = telegram.FormatOperationMessageWithTemplate(
-						rule.Config.MessageTemplate,
-						op.Account,
-						op.OpType,
-						op.OpData,
-						op.BlockNum,
-						op.Timestamp,
-					)
-				} else if bp.globalTemplate != "" {
-					// Use global template
-					message = telegram.FormatOperationMessageWithTemplate(
-						bp.globalTemplate,
-						op.Account,
-						op.OpType,
-						op.OpData,
-						op.BlockNum,
-						op.Timestamp,
-					)
-				} else {
-					// Use default format
-					message = telegram.FormatOperationMessage(
-						op.Account,
-						op.OpType,
-						op.OpData,
-						op.BlockNum,
-						op.Timestamp,
-					)
-				}
+	return nil
+}
 
-				if err := bp.telegramClient.SendMessage(message); err != nil {
-					fmt.Printf("Failed to send Telegram notification for rule %s: %v\n",
-						rule.Config.Name, err)
+// ReplaceForkedOperations saves operations re-extracted from the canonical block after a fork
+// Operations that were already announced from the dropped fork are not notified again
+func (bp *BlockProcessor) ReplaceForkedOperations(ctx context.Context, operations []*models.Operation, dropped []models.Operation) error {
+	operations, err := bp.ApplySampling(ctx, operations)
+	if err != nil {
+		return fmt.Errorf("failed to apply sampling: %w", err)
+	}
+	if len(operations) == 0 {
+		return nil
+	}
+	bp.Enrich(ctx, operations)
+
+	if err := bp.storage.InsertOperations(ctx, bp.ApplyStoragePolicy(operations)); err != nil {
+		return fmt.Errorf("failed to insert operations: %w", err)
+	}
+
+	announced := make(map[string]bool, len(dropped))
+	for _, op := range dropped {
+		announced[operationKey(&op)] = true
+	}
+
+	var fresh []*models.Operation
+	for _, op := range withoutVetoed(confirmedOperations(operations)) {
+		if !announced[operationKey(op)] {
+			fresh = append(fresh, op)
+		}
+	}
+	bp.sendNotifications(fresh)
+	bp.notifyBoundViews(ctx, fresh)
+	bp.dispatchWebhooks(fresh)
+	// Events already derived from the dropped fork keep their IDs and are not announced again
+	bp.notifyFundEvents(bp.replaceForkedFundEvents(ctx, operations, dropped))
+
+	return nil
+}
+
+// dispatchWebhooks queues operations for webhook delivery
+// Webhooks follow the notification pause switch but not the catch-up policy,
+// since downstream systems usually want every operation
+func (bp *BlockProcessor) dispatchWebhooks(operations []*models.Operation) {
+	if bp.webhooks == nil || bp.notificationsPaused.Load() || len(operations) == 0 {
+		return
+	}
+	bp.webhooks.Dispatch(operations)
+}
+
+// sendNotifications sends Telegram notifications for each configured rule
+func (bp *BlockProcessor) sendNotifications(operations []*models.Operation) {
+	if bp.telegramClient != nil && !bp.notificationsPaused.Load() {
+		operations = bp.holdBackStale(operations)
+		now := bp.clock.Now()
+		// Operations are announced in block order; rules are evaluated in priority order and,
+		// unless a rule stops evaluation, an operation matched by several rules is sent once per rule
+		// Matches are grouped by block so bulk thresholds apply per block
+		for start := 0; start < len(operations); {
+			end := start + 1
+			for end < len(operations) && operations[end].BlockNum == operations[start].BlockNum {
+				end++
+			}
+			bp.notifyBlock(operations[start:end], now)
+			start = end
+		}
+	}
+}
+
+// notifyBlock announces the matched operations of one block
+func (bp *BlockProcessor) notifyBlock(block []*models.Operation, now time.Time) {
+	matches := make([][]int, len(block))
+	for j, op := range block {
+		matches[j] = bp.matchingRules(op)
+	}
+	bulk := bp.newBlockBulk(block, matches)
+
+	for j, op := range block {
+		for _, i := range matches[j] {
+			switch {
+			case bulk.summaries[i] != nil:
+				// The whole block is summarized once, where the rule first matched
+				if !bulk.sent[i] {
+					bp.sendBulkDigest(bp.notificationRules[i], bulk.summaries[i])
+					bulk.sent[i] = true
 				}
+			case bp.digests[i] != nil:
+				// Digest rules collect matches and report them once per window
+				bp.digests[i].add(op, now)
+			default:
+				bp.sendRuleMessage(bp.notificationRules[i], op)
 			}
 		}
 	}
+}
 
-	return nil
+// ruleMessage formats the notification of an operation with the rule's template, the global
+// template or the default format, in the channel's parse mode
+func (bp *BlockProcessor) ruleMessage(rule TelegramNotificationRule, op *models.Operation) string {
+	formatter := bp.telegramClient.Formatter()
+	var message string
+	if rule.Config.MessageTemplate != "" {
+		// Use rule-specific template
+		message = formatter.OperationMessageWithTemplate(
+			rule.Config.MessageTemplate,
+			op.Account,
+			op.OpType,
+			op.OpData,
+			op.BlockNum,
+			op.Timestamp,
+		)
+	} else if bp.globalTemplate != "" {
+		// Use global template
+		message = formatter.OperationMessageWithTemplate(
+			bp.globalTemplate,
+			op.Account,
+			op.OpType,
+			op.OpData,
+			op.BlockNum,
+			op.Timestamp,
+		)
+	} else {
+		// Use default format
+		message = formatter.OperationMessage(
+			op.Account,
+			op.OpType,
+			op.OpData,
+			op.BlockNum,
+			op.Timestamp,
+		)
+	}
+	return message
+}
+
+// sendRuleMessage sends the notification for an operation matched by a rule
+func (bp *BlockProcessor) sendRuleMessage(rule TelegramNotificationRule, op *models.Operation) {
+	message := bp.ruleMessage(rule, op)
+	buttons := bp.telegramClient.Explorer().Buttons(op.BlockNum, op.TrxID, op.Account)
+	opts := rule.sendOptions()
+	opts.Pin = shouldPin(rule.Config, op)
+	if err := bp.telegramClient.SendMessageWithOptions(message, buttons, opts); err != nil {
+		notifyLogger.Error("Failed to send Telegram notification", "rule", rule.Config.Name, "account", op.Account, "block_num", op.BlockNum, "error", err)
+		reporting.Failure("telegram", err, "rule", rule.Config.Name, "account", op.Account, "block_num", op.BlockNum)
+		return
+	}
+	reporting.Success("telegram")
 }
@@ -0,0 +1,241 @@
+package sync
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ety001/sps-fund-watcher/internal/chain"
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/telegram"
+)
+
+func transfer(from, to, amount string) *models.Operation {
+	return &models.Operation{Account: from, OpType: "transfer", BlockNum: 1, TrxID: "t1",
+		OpData: map[string]interface{}{"from": from, "to": to, "amount": amount}}
+}
+
+func vote(voter string) *models.Operation {
+	return &models.Operation{Account: voter, OpType: "vote", BlockNum: 1, TrxID: "t2",
+		OpData: map[string]interface{}{"voter": voter, "author": "carol", "weight": 10000}}
+}
+
+// matchedRuleNames returns the names of the rules an operation is notified by, in order
+func matchedRuleNames(bp *BlockProcessor, op *models.Operation) []string {
+	var names []string
+	for _, i := range bp.matchingRules(op) {
+		names = append(names, bp.notificationRules[i].Config.Name)
+	}
+	return names
+}
+
+func newTestProcessor(rules []models.TelegramUserConfig, globalTemplate string) *BlockProcessor {
+	return NewBlockProcessor(nil, telegram.NewClient("token", "@channel"), rules, []string{"alice", "bob", "carol"}, globalTemplate)
+}
+
+func TestMatchingRules(t *testing.T) {
+	byAccountAndOperation := []models.TelegramUserConfig{
+		{Name: "alice-transfers", Accounts: []string{"alice"}, NotifyOperations: []string{"transfer"}},
+		{Name: "bob-all", Accounts: []string{"bob"}},
+		{Name: "all-votes", NotifyOperations: []string{"vote"}},
+	}
+	byFilter := []models.TelegramUserConfig{
+		{Name: "not-to-exchange", OperationFilters: map[string]models.OperationFilter{
+			"transfer": {IgnoreToAddresses: []string{"binance"}},
+		}},
+		{Name: "large-steem", MinAmount: 1000, AmountSymbol: "STEEM"},
+		{Name: "to-dao", OperationFilters: map[string]models.OperationFilter{
+			"transfer": {Conditions: []models.FilterCondition{{Field: "to", Op: models.FilterOpEquals, Value: "steem.dao"}}},
+		}},
+	}
+	byAccountOperations := []models.TelegramUserConfig{
+		{Name: "mixed", Accounts: []string{"alice"}, AccountOperations: map[string][]string{"carol": {"transfer"}}},
+	}
+
+	tests := []struct {
+		name  string
+		rules []models.TelegramUserConfig
+		op    *models.Operation
+		want  []string
+	}{
+		{name: "account and operation match", rules: byAccountAndOperation, op: transfer("alice", "bob", "1.000 STEEM"), want: []string{"alice-transfers"}},
+		{name: "account with all operations", rules: byAccountAndOperation, op: transfer("bob", "alice", "1.000 STEEM"), want: []string{"bob-all"}},
+		{name: "operation of all accounts", rules: byAccountAndOperation, op: vote("alice"), want: []string{"all-votes"}},
+		{name: "every matching rule notifies", rules: byAccountAndOperation, op: vote("bob"), want: []string{"bob-all", "all-votes"}},
+		{name: "no rule matches", rules: byAccountAndOperation, op: transfer("carol", "bob", "1.000 STEEM")},
+
+		{name: "ignored receiver", rules: byFilter, op: transfer("alice", "binance", "5000.000 STEEM"), want: []string{"large-steem"}},
+		{name: "below min amount", rules: byFilter, op: transfer("alice", "bob", "10.000 STEEM"), want: []string{"not-to-exchange"}},
+		{name: "above min amount", rules: byFilter, op: transfer("alice", "bob", "2000.000 STEEM"), want: []string{"not-to-exchange", "large-steem"}},
+		{name: "other amount symbol", rules: byFilter, op: transfer("alice", "bob", "2000.000 SBD"), want: []string{"not-to-exchange"}},
+		{name: "condition holds", rules: byFilter, op: transfer("alice", "steem.dao", "1.000 SBD"), want: []string{"not-to-exchange", "to-dao"}},
+		{name: "filters of other operations don't apply", rules: byFilter, op: vote("alice"), want: []string{"not-to-exchange", "to-dao"}},
+		{name: "threshold without an amount", rules: []models.TelegramUserConfig{{Name: "min", MinAmount: 1}, {Name: "symbol", AmountSymbol: "SBD"}, {Name: "none"}}, op: vote("alice"), want: []string{"none"}},
+
+		{name: "account listed with its operations", rules: byAccountOperations, op: transfer("carol", "bob", "1.000 STEEM"), want: []string{"mixed"}},
+		{name: "account listed without the operation", rules: byAccountOperations, op: vote("carol")},
+		{name: "rule account with any operation", rules: byAccountOperations, op: vote("alice"), want: []string{"mixed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matchedRuleNames(newTestProcessor(tt.rules, ""), tt.op)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("matched rules = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRulePriority(t *testing.T) {
+	tests := []struct {
+		name       string
+		rules      []models.TelegramUserConfig
+		evaluation string
+		want       []string
+	}{
+		{
+			name:  "higher priority first",
+			rules: []models.TelegramUserConfig{{Name: "low"}, {Name: "high", Priority: 10}, {Name: "mid", Priority: 5}},
+			want:  []string{"high", "mid", "low"},
+		},
+		{
+			name:  "equal priorities keep configuration order",
+			rules: []models.TelegramUserConfig{{Name: "a", Priority: 1}, {Name: "b", Priority: 1}, {Name: "c", Priority: 1}},
+			want:  []string{"a", "b", "c"},
+		},
+		{
+			name:  "stop on match skips lower rules",
+			rules: []models.TelegramUserConfig{{Name: "low"}, {Name: "high", Priority: 10, StopOnMatch: true}},
+			want:  []string{"high"},
+		},
+		{
+			name:  "stop on match only when the rule matches",
+			rules: []models.TelegramUserConfig{{Name: "low"}, {Name: "high", Priority: 10, StopOnMatch: true, Accounts: []string{"bob"}}},
+			want:  []string{"low"},
+		},
+		{
+			name:  "higher rules still notify before a stop",
+			rules: []models.TelegramUserConfig{{Name: "a", Priority: 10}, {Name: "b", Priority: 5, StopOnMatch: true}, {Name: "c"}},
+			want:  []string{"a", "b"},
+		},
+		{
+			name:       "first evaluation stops at the first match",
+			rules:      []models.TelegramUserConfig{{Name: "a", Accounts: []string{"bob"}, Priority: 10}, {Name: "b", Priority: 5}, {Name: "c"}},
+			evaluation: models.RuleEvaluationFirst,
+			want:       []string{"b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bp := newTestProcessor(tt.rules, "")
+			bp.SetRuleEvaluation(tt.evaluation)
+			got := matchedRuleNames(bp, transfer("alice", "bob", "1.000 STEEM"))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("matched rules = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleTemplates(t *testing.T) {
+	rules := []models.TelegramUserConfig{
+		{Name: "own", MessageTemplate: "own {{.Account}} {{.OpType}}"},
+		{Name: "fallback"},
+	}
+	tests := []struct {
+		name           string
+		globalTemplate string
+		want           map[string]string // Rule name to expected message prefix
+	}{
+		{name: "rule and global template", globalTemplate: "global {{.Account}}", want: map[string]string{"own": "own alice transfer", "fallback": "global alice"}},
+		{name: "rule template and default format", want: map[string]string{"own": "own alice transfer", "fallback": ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bp := newTestProcessor(rules, tt.globalTemplate)
+			op := transfer("alice", "bob", "1.000 STEEM")
+			matched := bp.matchingRules(op)
+			if len(matched) != len(rules) {
+				t.Fatalf("matched %d rules, want %d", len(matched), len(rules))
+			}
+			for _, i := range matched {
+				rule := bp.notificationRules[i]
+				message := bp.ruleMessage(rule, op)
+				want := tt.want[rule.Config.Name]
+				if want == "" {
+					// The default format names the account and operation
+					if !strings.Contains(message, "alice") || !strings.Contains(message, "transfer") {
+						t.Errorf("rule %s: default message %q lacks the operation", rule.Config.Name, message)
+					}
+					continue
+				}
+				if !strings.HasPrefix(message, want) {
+					t.Errorf("rule %s: message = %q, want prefix %q", rule.Config.Name, message, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewSyncerWiresRules(t *testing.T) {
+	tests := []struct {
+		name      string
+		telegram  models.TelegramConfig
+		wantRules []string // Rule order after sorting by priority
+		op        *models.Operation
+		want      []string
+	}{
+		{
+			name: "rules with priorities",
+			telegram: models.TelegramConfig{Users: []models.TelegramUserConfig{
+				{Name: "votes", NotifyOperations: []string{"vote"}},
+				{Name: "large", MinAmount: 100, Priority: 10, StopOnMatch: true},
+				{Name: "alice", Accounts: []string{"alice"}, Priority: 5},
+			}},
+			wantRules: []string{"large", "alice", "votes"},
+			op:        transfer("alice", "bob", "500.000 STEEM"),
+			want:      []string{"large"},
+		},
+		{
+			name: "first evaluation",
+			telegram: models.TelegramConfig{RuleEvaluation: models.RuleEvaluationFirst, Users: []models.TelegramUserConfig{
+				{Name: "alice", Accounts: []string{"alice"}},
+				{Name: "all"},
+			}},
+			wantRules: []string{"alice", "all"},
+			op:        vote("alice"),
+			want:      []string{"alice"},
+		},
+		{
+			name:      "legacy format",
+			telegram:  models.TelegramConfig{Accounts: []string{"bob"}, NotifyOperations: []string{"transfer"}},
+			wantRules: []string{"default"},
+			op:        transfer("bob", "alice", "1.000 STEEM"),
+			want:      []string{"default"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &models.Config{Telegram: tt.telegram}
+			config.Steem.Accounts = []string{"alice", "bob"}
+			config.Telegram.Enabled = true
+			config.Telegram.BotToken = "token"
+			config.Telegram.ChannelID = "@channel"
+			s, err := NewSyncerWith(config, chain.NewFake(), nil)
+			if err != nil {
+				t.Fatalf("NewSyncerWith: %v", err)
+			}
+
+			var rules []string
+			for _, rule := range s.processor.notificationRules {
+				rules = append(rules, rule.Config.Name)
+			}
+			if !slices.Equal(rules, tt.wantRules) {
+				t.Errorf("rules = %v, want %v", rules, tt.wantRules)
+			}
+			if got := matchedRuleNames(s.processor, tt.op); !slices.Equal(got, tt.want) {
+				t.Errorf("matched rules = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
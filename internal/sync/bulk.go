@@ -0,0 +1,91 @@
+package sync
+
+import (
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/reporting"
+	"github.com/ety001/sps-fund-watcher/internal/telegram"
+)
+
+// blockBulk collects the matches of rules with a bulk_threshold in a single block
+// A rule whose matches exceed its threshold is announced with one summary instead of a message per operation
+type blockBulk struct {
+	summaries []*telegram.BulkSummary // Indexed like the rules; nil when the rule is announced normally
+	sent      []bool
+}
+
+// newBlockBulk counts the matches per rule in one block's operations
+// matches holds the rules matched by each operation, as returned by matchingRules
+func (bp *BlockProcessor) newBlockBulk(block []*models.Operation, matches [][]int) *blockBulk {
+	bulk := &blockBulk{
+		summaries: make([]*telegram.BulkSummary, len(bp.notificationRules)),
+		sent:      make([]bool, len(bp.notificationRules)),
+	}
+
+	counts := make([]int, len(bp.notificationRules))
+	for _, rules := range matches {
+		for _, i := range rules {
+			counts[i]++
+		}
+	}
+
+	for i, rule := range bp.notificationRules {
+		// Periodic digests already collapse matches
+		threshold := rule.Config.BulkThreshold
+		if threshold <= 0 || bp.digests[i] != nil || counts[i] <= threshold {
+			continue
+		}
+		bulk.summaries[i] = &telegram.BulkSummary{
+			Rule:      rule.Config.Name,
+			BlockNum:  block[0].BlockNum,
+			Timestamp: block[0].Timestamp,
+			Counts:    make(map[string]int),
+			Amounts:   make(map[string]map[string]float64),
+		}
+	}
+
+	for j, op := range block {
+		for _, i := range matches[j] {
+			summary := bulk.summaries[i]
+			if summary == nil {
+				continue
+			}
+			summary.Total++
+			summary.Counts[op.OpType]++
+			if amount, ok := models.OperationAmount(op.OpData); ok {
+				if summary.Amounts[op.OpType] == nil {
+					summary.Amounts[op.OpType] = make(map[string]float64)
+				}
+				summary.Amounts[op.OpType][amount.Symbol] += amount.Amount
+			}
+		}
+	}
+	return bulk
+}
+
+// matchingRules returns the indexes of the rules that notify an operation, in evaluation order
+func (bp *BlockProcessor) matchingRules(op *models.Operation) []int {
+	var matched []int
+	for i, rule := range bp.notificationRules {
+		if !bp.shouldNotifyForRule(rule, op) {
+			continue
+		}
+		matched = append(matched, i)
+		if rule.Config.StopOnMatch || bp.firstMatchOnly {
+			break
+		}
+	}
+	return matched
+}
+
+// sendBulkDigest sends a rule's bulk summary for a block
+func (bp *BlockProcessor) sendBulkDigest(rule TelegramNotificationRule, summary *telegram.BulkSummary) {
+	message := bp.telegramClient.Formatter().BulkDigest(*summary)
+	buttons := bp.telegramClient.Explorer().BlockButtons(summary.BlockNum)
+	if err := bp.telegramClient.SendMessageWithOptions(message, buttons, rule.sendOptions()); err != nil {
+		notifyLogger.Error("Failed to send bulk digest", "rule", summary.Rule, "block_num", summary.BlockNum, "error", err)
+		reporting.Failure("telegram", err, "rule", summary.Rule, "block_num", summary.BlockNum)
+		return
+	}
+	reporting.Success("telegram")
+	notifyLogger.Info("Collapsed block matches into a bulk digest", "rule", summary.Rule, "block_num", summary.BlockNum, "operations", summary.Total)
+}
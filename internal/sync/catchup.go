@@ -0,0 +1,120 @@
+package sync
+
+import (
+	stdsync "sync"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+)
+
+// Stale notification modes
+const (
+	StaleNotifySuppress = "suppress" // Drop notifications for stale operations
+	StaleNotifyDigest   = "digest"   // Summarize stale operations in one message once caught up
+)
+
+// catchUpDigest accumulates stale operations that were not notified individually
+type catchUpDigest struct {
+	mu        stdsync.Mutex
+	counts    map[string]int
+	total     int
+	fromBlock int64
+	toBlock   int64
+}
+
+// add counts an operation in the digest
+func (d *catchUpDigest) add(op *models.Operation) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.counts == nil {
+		d.counts = make(map[string]int)
+	}
+	d.counts[op.OpType]++
+	d.total++
+	if d.fromBlock == 0 || op.BlockNum < d.fromBlock {
+		d.fromBlock = op.BlockNum
+	}
+	if op.BlockNum > d.toBlock {
+		d.toBlock = op.BlockNum
+	}
+}
+
+// take returns the accumulated digest and resets it
+func (d *catchUpDigest) take() (counts map[string]int, total int, fromBlock, toBlock int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	counts, total, fromBlock, toBlock = d.counts, d.total, d.fromBlock, d.toBlock
+	d.counts, d.total, d.fromBlock, d.toBlock = nil, 0, 0, 0
+	return
+}
+
+// SetCatchUpPolicy configures how notifications for old operations are handled
+// Operations older than maxAge (relative to processing time) are still stored but not
+// notified individually; with StaleNotifyDigest they are summarized once caught up
+// A zero maxAge disables the policy
+func (bp *BlockProcessor) SetCatchUpPolicy(maxAge time.Duration, mode string) {
+	bp.maxNotifyAge = maxAge
+	bp.digestStale = mode == StaleNotifyDigest
+}
+
+// holdBackStale removes stale operations from a notification batch
+// When fresh operations arrive, the pending catch-up digest is flushed first
+func (bp *BlockProcessor) holdBackStale(operations []*models.Operation) []*models.Operation {
+	if bp.maxNotifyAge <= 0 {
+		return operations
+	}
+
+	cutoff := bp.clock.Now().Add(-bp.maxNotifyAge)
+	var fresh []*models.Operation
+	suppressed := 0
+	for _, op := range operations {
+		if !op.Timestamp.Before(cutoff) {
+			fresh = append(fresh, op)
+			continue
+		}
+		if !bp.matchesAnyRule(op) {
+			continue
+		}
+		suppressed++
+		if bp.digestStale {
+			bp.catchUp.add(op)
+		}
+	}
+
+	if suppressed > 0 {
+		notifyLogger.Debug("Held back notifications for stale operations", "ops", suppressed, "max_age", bp.maxNotifyAge)
+	}
+	if len(fresh) > 0 {
+		bp.FlushCatchUpDigest()
+	}
+	return fresh
+}
+
+// matchesAnyRule reports whether at least one notification rule matches the operation
+func (bp *BlockProcessor) matchesAnyRule(op *models.Operation) bool {
+	for _, rule := range bp.notificationRules {
+		if bp.shouldNotifyForRule(rule, op) {
+			return true
+		}
+	}
+	return false
+}
+
+// FlushCatchUpDigest sends the accumulated catch-up digest, if any
+func (bp *BlockProcessor) FlushCatchUpDigest() {
+	if bp.telegramClient == nil {
+		return
+	}
+
+	counts, total, fromBlock, toBlock := bp.catchUp.take()
+	if total == 0 {
+		return
+	}
+
+	message := bp.telegramClient.Formatter().CatchUpDigest(counts, total, fromBlock, toBlock)
+	if err := bp.telegramClient.SendMessage(message); err != nil {
+		notifyLogger.Error("Failed to send catch-up digest", "error", err)
+	}
+}
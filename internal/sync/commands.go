@@ -0,0 +1,199 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/clock"
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/reporting"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+	"github.com/ety001/sps-fund-watcher/internal/telegram"
+	"github.com/ety001/sps-fund-watcher/internal/version"
+)
+
+// Bot command limits
+const (
+	commandPollTimeout = 30 * time.Second
+	commandRetryDelay  = 5 * time.Second
+	commandTimeout     = 15 * time.Second
+	commandMaxAge      = 5 * time.Minute // Commands sent while the watcher was down are not answered late
+	defaultLastCount   = 5
+	maxLastCount       = 20
+)
+
+// serveCommands answers bot commands from the allowed chats until ctx is done
+// Only the active instance runs it, so each command is answered once
+func (s *Syncer) serveCommands(ctx context.Context) {
+	allowed := make(map[string]bool)
+	for _, chat := range s.config.Telegram.Commands.AllowedChats {
+		allowed[chat] = true
+	}
+	logger.Info("Answering Telegram bot commands", "allowed_chats", len(allowed))
+
+	var offset int64
+	for ctx.Err() == nil {
+		updates, err := s.telegram.GetUpdates(ctx, offset, commandPollTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logger.Warn("Failed to get Telegram updates", "error", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.clock.After(commandRetryDelay):
+			}
+			continue
+		}
+
+		for _, update := range updates {
+			offset = update.UpdateID + 1
+			message := update.Message
+			if message == nil {
+				continue
+			}
+			name, args, ok := telegram.ParseCommand(message.Text)
+			if !ok || clock.Since(s.clock, message.Time()) > commandMaxAge {
+				continue
+			}
+			if !allowed[message.ChatID()] {
+				logger.Warn("Ignoring bot command from a chat not in telegram.commands.allowed_chats", "chat_id", message.ChatID(), "command", name)
+				continue
+			}
+
+			reply := s.answerCommand(ctx, name, args)
+			// Replies go to the topic the command was sent in
+			if err := s.telegram.SendMessageTo(message.ChatID(), message.ThreadID, reply); err != nil {
+				logger.Error("Failed to answer bot command", "command", name, "chat_id", message.ChatID(), "error", err)
+				reporting.Failure("telegram", err)
+				continue
+			}
+			reporting.Success("telegram")
+		}
+	}
+}
+
+// answerCommand runs a command and returns the reply
+func (s *Syncer) answerCommand(ctx context.Context, name string, args []string) string {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	f := s.telegram.Formatter()
+	var reply string
+	var err error
+	switch name {
+	case "last":
+		reply, err = s.lastCommand(ctx, f, args)
+	case "balance":
+		reply, err = s.balanceCommand(f, args)
+	case "status":
+		reply, err = s.statusCommand(ctx, f)
+	case "resend":
+		reply, err = s.resendCommand(ctx, f)
+	default:
+		return f.CommandHelp()
+	}
+	if err != nil {
+		return f.Escape("⚠️ " + err.Error())
+	}
+	return reply
+}
+
+// lastCommand lists the latest stored operations of an account: /last <account> [n]
+func (s *Syncer) lastCommand(ctx context.Context, f telegram.Formatter, args []string) (string, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return "", errors.New("usage: /last <account> [n]")
+	}
+	account := args[0]
+	if err := models.ValidateAccountName(account); err != nil {
+		return "", err
+	}
+	count := defaultLastCount
+	if len(args) == 2 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 || n > maxLastCount {
+			return "", fmt.Errorf("n must be a number from 1 to %d", maxLastCount)
+		}
+		count = n
+	}
+
+	result, err := s.storage.QueryOperations(ctx, storage.OperationQuery{Account: account}, 1, count)
+	if err != nil {
+		logger.Warn("Bot command query failed", "command", "last", "error", err)
+		return "", errors.New("operations are unavailable right now")
+	}
+
+	ops := make([]telegram.CommandOperation, 0, len(result.Operations))
+	for _, op := range result.Operations {
+		from, to := models.OperationParties(op.OpData)
+		item := telegram.CommandOperation{OpType: op.OpType, From: from, To: to, BlockNum: op.BlockNum, Timestamp: op.Timestamp}
+		if asset, ok := models.OperationAmount(op.OpData); ok {
+			item.Amount = asset.String()
+		}
+		ops = append(ops, item)
+	}
+	return f.LastOperations(account, ops), nil
+}
+
+// balanceCommand reports an account's liquid balances from the node: /balance <account>
+func (s *Syncer) balanceCommand(f telegram.Formatter, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", errors.New("usage: /balance <account>")
+	}
+	account := args[0]
+	if err := models.ValidateAccountName(account); err != nil {
+		return "", err
+	}
+
+	accounts, err := s.steemAPI.GetAccounts([]string{account})
+	if err != nil {
+		logger.Warn("Bot command query failed", "command", "balance", "error", err)
+		return "", errors.New("the Steem node is unreachable right now")
+	}
+	if len(accounts) == 0 {
+		return "", fmt.Errorf("account %s does not exist", account)
+	}
+	return f.AccountBalance(account, accounts[0].Balance, accounts[0].SBDBalance), nil
+}
+
+// resendCommand makes all pending notifications due, so the sync loop resends them shortly: /resend
+func (s *Syncer) resendCommand(ctx context.Context, f telegram.Formatter) (string, error) {
+	count, err := s.storage.ResendPendingNotifications(ctx, nil)
+	if err != nil {
+		logger.Warn("Bot command failed", "command", "resend", "error", err)
+		return "", errors.New("pending notifications are unavailable right now")
+	}
+	if count == 0 {
+		return f.Escape("No pending notifications"), nil
+	}
+	return f.Escape(fmt.Sprintf("%d pending notification(s) will be resent within %s", count, pendingCheckInterval)), nil
+}
+
+// statusCommand reports the sync progress: /status
+func (s *Syncer) statusCommand(ctx context.Context, f telegram.Formatter) (string, error) {
+	syncState, err := s.storage.GetSyncState(ctx)
+	if err != nil {
+		logger.Warn("Bot command query failed", "command", "status", "error", err)
+		return "", errors.New("the sync state is unavailable right now")
+	}
+
+	status := telegram.SyncStatus{
+		Version:   version.Version,
+		LastBlock: syncState.LastBlock,
+		Lag:       -1,
+		UpdatedAt: syncState.UpdatedAt,
+		Accounts:  len(s.config.Steem.Accounts),
+	}
+	if dgp, err := s.steemAPI.GetDynamicGlobalProperties(); err == nil {
+		status.Lag = syncState.Lag(s.config.Steem.SyncMode, int64(dgp.HeadBlockNumber), int64(dgp.LastIrreversibleBlockNum))
+	}
+	if control, err := s.storage.GetControlState(ctx); err == nil {
+		status.SyncPaused = control.SyncPaused
+		status.NotificationsPaused = control.NotificationsPaused
+	}
+	return f.Status(status), nil
+}
@@ -0,0 +1,82 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/chart"
+	"github.com/ety001/sps-fund-watcher/internal/reconcile"
+	"github.com/ety001/sps-fund-watcher/internal/reporting"
+	"github.com/ety001/sps-fund-watcher/internal/telegram"
+)
+
+// dailyChartJob records the end of the last charted day
+const dailyChartJob = "daily_treasury_chart"
+
+// dailyChartSymbol is the asset charted; proposals are paid in SBD
+const dailyChartSymbol = "SBD"
+
+// sendDailyChart sends a bar chart of the treasury's daily outflows up to the previous day (UTC)
+// once a day, even across restarts
+// A chart that fails to send is not stored for resending; the next hourly check retries it
+func (s *Syncer) sendDailyChart(ctx context.Context, now time.Time) {
+	if !s.config.Reconciliation.DailyChart || s.telegram == nil {
+		return
+	}
+
+	to := now.UTC().Truncate(24 * time.Hour)
+	lastRun, err := s.storage.GetLastRun(ctx, dailyChartJob)
+	if err != nil {
+		logger.Warn("Failed to check daily chart", "error", err)
+		return
+	}
+	if !lastRun.Before(to) {
+		return
+	}
+
+	days := s.config.Reconciliation.ChartDays()
+	from := to.AddDate(0, 0, -days)
+	flows, err := reconcile.Flows(ctx, s.storage, s.config.Reconciliation, from, to)
+	if err != nil {
+		logger.Error("Failed to load treasury flows for the daily chart", "error", err)
+		return
+	}
+
+	// Days without treasury activity are drawn as empty bars
+	summary := telegram.DailyFlowsSummary{Date: to.AddDate(0, 0, -1).Format(time.DateOnly), Days: days, Symbol: dailyChartSymbol}
+	outflows := make(map[string]float64)
+	for _, flow := range flows.Days {
+		if flow.Symbol != dailyChartSymbol {
+			continue
+		}
+		outflows[flow.Date] = flow.ProposalPayouts + flow.OtherOutflows
+		summary.Total += flow.ProposalPayouts + flow.OtherOutflows
+		if flow.Date == summary.Date {
+			summary.ProposalPayouts = flow.ProposalPayouts
+			summary.OtherOutflows = flow.OtherOutflows
+			summary.Inflows = flow.Inflation + flow.Donations
+		}
+	}
+	bars := make([]chart.Bar, 0, days)
+	for date := from; date.Before(to); date = date.AddDate(0, 0, 1) {
+		bars = append(bars, chart.Bar{Label: date.Format("01-02"), Value: outflows[date.Format(time.DateOnly)]})
+	}
+
+	png, err := chart.Bars(fmt.Sprintf("Treasury outflows per day (%s)", dailyChartSymbol), bars)
+	if err != nil {
+		logger.Error("Failed to render daily chart", "error", err)
+		return
+	}
+	if err := s.telegram.SendPhoto(png, s.telegram.Formatter().DailyFlows(summary), telegram.SendOptions{}); err != nil {
+		notifyLogger.Error("Failed to send daily chart", "error", err)
+		reporting.Failure("telegram", err)
+		return
+	}
+	reporting.Success("telegram")
+	logger.Info("Daily chart sent", "from", from, "to", to)
+
+	if err := s.storage.SetLastRun(ctx, dailyChartJob, to); err != nil {
+		logger.Warn("Failed to record daily chart", "error", err)
+	}
+}
@@ -0,0 +1,124 @@
+package sync
+
+import (
+	"sort"
+	stdsync "sync"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/telegram"
+)
+
+// maxDigestNotable is the number of largest operations listed in a digest
+const maxDigestNotable = 5
+
+// ruleDigest aggregates operations matched by one rule over a digest window
+type ruleDigest struct {
+	mu        stdsync.Mutex
+	interval  time.Duration
+	windowEnd time.Time
+	summary   telegram.DigestSummary
+	notable   []notableOperation
+}
+
+// notableOperation is a candidate for the digest's largest-operations list
+type notableOperation struct {
+	op     *models.Operation
+	amount models.Asset
+}
+
+// newRuleDigests creates digest accumulators for rules with a digest interval
+// The result is indexed like the rules; rules without a digest get nil
+func newRuleDigests(rules []TelegramNotificationRule) []*ruleDigest {
+	digests := make([]*ruleDigest, len(rules))
+	for i, rule := range rules {
+		if rule.Config.DigestIntervalMinutes > 0 {
+			digests[i] = &ruleDigest{interval: time.Duration(rule.Config.DigestIntervalMinutes) * time.Minute}
+		}
+	}
+	return digests
+}
+
+// add counts an operation in the current window
+// Windows are aligned to multiples of the interval (UTC), so hourly digests cover whole hours
+func (d *ruleDigest) add(op *models.Operation, now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.summary.Total == 0 {
+		d.summary.From = now.Truncate(d.interval)
+		d.windowEnd = d.summary.From.Add(d.interval)
+		d.summary.Counts = make(map[string]int)
+		d.summary.Amounts = make(map[string]float64)
+	}
+
+	d.summary.Total++
+	d.summary.Counts[op.OpType]++
+
+	amount, ok := models.OperationAmount(op.OpData)
+	if !ok {
+		return
+	}
+	d.summary.Amounts[amount.Symbol] += amount.Amount
+
+	d.notable = append(d.notable, notableOperation{op: op, amount: amount})
+	sort.SliceStable(d.notable, func(i, j int) bool {
+		return d.notable[i].amount.Amount > d.notable[j].amount.Amount
+	})
+	if len(d.notable) > maxDigestNotable {
+		d.notable = d.notable[:maxDigestNotable]
+	}
+}
+
+// takeDue returns the window's summary and resets it if the window has ended (or force is set)
+func (d *ruleDigest) takeDue(now time.Time, force bool) (telegram.DigestSummary, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.summary.Total == 0 || (!force && now.Before(d.windowEnd)) {
+		return telegram.DigestSummary{}, false
+	}
+
+	summary := d.summary
+	summary.To = d.windowEnd
+	if force && now.Before(d.windowEnd) {
+		summary.To = now
+	}
+	for _, n := range d.notable {
+		summary.Notable = append(summary.Notable, telegram.DigestItem{
+			Account:  n.op.Account,
+			OpType:   n.op.OpType,
+			Amount:   n.amount.String(),
+			BlockNum: n.op.BlockNum,
+		})
+	}
+
+	d.summary = telegram.DigestSummary{}
+	d.notable = nil
+	return summary, true
+}
+
+// FlushDigests sends digest messages for rules whose window has ended
+// With force set, partially filled windows are sent as well (used on shutdown)
+func (bp *BlockProcessor) FlushDigests(now time.Time, force bool) {
+	if bp.telegramClient == nil || bp.notificationsPaused.Load() {
+		return
+	}
+
+	for i, digest := range bp.digests {
+		if digest == nil {
+			continue
+		}
+		summary, ok := digest.takeDue(now, force)
+		if !ok {
+			continue
+		}
+
+		rule := bp.notificationRules[i]
+		summary.Rule = rule.Config.Name
+		message := bp.telegramClient.Formatter().Digest(summary)
+		if err := bp.telegramClient.SendMessageWithOptions(message, nil, rule.sendOptions()); err != nil {
+			notifyLogger.Error("Failed to send digest", "rule", summary.Rule, "error", err)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package sync
+
+import (
+	"context"
+
+	"github.com/ety001/sps-fund-watcher/internal/enrich"
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/plugin"
+)
+
+// SetEnrichment sets the enrichers run on operations before they are stored; nil disables enrichment
+func (bp *BlockProcessor) SetEnrichment(pipeline *enrich.Pipeline) {
+	bp.enrichment = pipeline
+}
+
+// SetPlugins sets the exec plugins run after the enrichers; nil runs none
+func (bp *BlockProcessor) SetPlugins(runner *plugin.Runner) {
+	bp.plugins = runner
+}
+
+// Enrich adds the configured computed fields to operations in place, then runs the plugins and the
+// registered processors
+// It runs before storage policies, so notifications and webhooks see the enriched operations too
+func (bp *BlockProcessor) Enrich(ctx context.Context, operations []*models.Operation) {
+	bp.enrichment.Apply(ctx, operations)
+	bp.plugins.Apply(ctx, operations)
+	runProcessors(operations)
+}
+
+// withoutVetoed returns the operations no plugin vetoed
+func withoutVetoed(operations []*models.Operation) []*models.Operation {
+	kept := operations[:0:0]
+	for _, op := range operations {
+		if op.VetoedBy == "" {
+			kept = append(kept, op)
+		}
+	}
+	return kept
+}
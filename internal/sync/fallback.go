@@ -0,0 +1,45 @@
+package sync
+
+import (
+	"github.com/ety001/sps-fund-watcher/internal/models"
+)
+
+// SetFallbackExtraction configures which top-level fields name accounts in operation types
+// without built-in extraction, and whether such matches are only stored for review
+func (bp *BlockProcessor) SetFallbackExtraction(config models.FallbackExtractionConfig) {
+	bp.fallbackFields = config.FieldsOrDefault()
+	bp.reviewFallback = config.Review()
+}
+
+// reviewAccounts returns the accounts found by the fallback fields but not by a configured
+// account path, when fallback matches need review
+func (bp *BlockProcessor) reviewAccounts(fallback, nested []string) map[string]bool {
+	if !bp.reviewFallback || len(fallback) == 0 {
+		return nil
+	}
+	review := make(map[string]bool, len(fallback))
+	for _, account := range fallback {
+		review[account] = true
+	}
+	for _, account := range nested {
+		delete(review, account)
+	}
+	return review
+}
+
+// confirmedOperations returns the operations that don't need review
+func confirmedOperations(operations []*models.Operation) []*models.Operation {
+	for i, op := range operations {
+		if op.NeedsReview {
+			// Copy only when something is filtered out
+			confirmed := append([]*models.Operation{}, operations[:i]...)
+			for _, op := range operations[i+1:] {
+				if !op.NeedsReview {
+					confirmed = append(confirmed, op)
+				}
+			}
+			return confirmed
+		}
+	}
+	return operations
+}
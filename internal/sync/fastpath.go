@@ -0,0 +1,166 @@
+package sync
+
+import (
+	"bytes"
+	"encoding/json"
+	stdsync "sync"
+
+	"github.com/steemit/steemutil/protocol"
+)
+
+// jsonBuffers reuses encode buffers for operations without a fast path
+var jsonBuffers = stdsync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// SetFastPath enables or disables typed extraction of common operations (enabled by default)
+// Disabling it forces the JSON round trip for every operation, e.g. to compare throughput
+func (bp *BlockProcessor) SetFastPath(enabled bool) {
+	bp.jsonOnly = !enabled
+}
+
+// decodeOperation returns the op_data map and involved accounts of an operation, and the accounts
+// whose match needs review (see extractAccounts)
+// ok is false when the operation can't be decoded or involves no tracked account
+func (bp *BlockProcessor) decodeOperation(opType string, raw any) (opData map[string]interface{}, accounts []string, review map[string]bool, ok bool) {
+	// Operations with configured nested paths need the generic walk
+	if !bp.jsonOnly && len(bp.accountPaths[opType]) == 0 {
+		if accounts, found := typedAccounts(raw); found {
+			// Skip untracked operations before building any map
+			if !bp.tracksAny(accounts) {
+				return nil, nil, nil, false
+			}
+			return typedOpData(raw), accounts, nil, true
+		}
+	}
+
+	if dataMap, isMap := raw.(map[string]interface{}); isMap {
+		opData = dataMap
+	} else {
+		// Other typed operations: marshal and unmarshal to get a map
+		buf := jsonBuffers.Get().(*bytes.Buffer)
+		buf.Reset()
+		err := json.NewEncoder(buf).Encode(raw)
+		if err == nil {
+			err = json.Unmarshal(buf.Bytes(), &opData)
+		}
+		jsonBuffers.Put(buf)
+		if err != nil {
+			return nil, nil, nil, false
+		}
+	}
+
+	accounts, review = bp.extractAccounts(opType, opData)
+	if !bp.tracksAny(accounts) {
+		return nil, nil, nil, false
+	}
+	return opData, accounts, review, true
+}
+
+// tracksAny reports whether any of the accounts is tracked
+func (bp *BlockProcessor) tracksAny(accounts []string) bool {
+	for _, account := range accounts {
+		if bp.tracks(account) {
+			return true
+		}
+	}
+	return false
+}
+
+// typedAccounts returns the accounts of the most common typed operations
+// The result matches extractAccounts on the JSON form of the same operation
+func typedAccounts(raw any) ([]string, bool) {
+	switch op := raw.(type) {
+	case *protocol.TransferOperation:
+		return nonEmpty(op.From, op.To), true
+	case *protocol.VoteOperation:
+		return nonEmpty(op.Voter, op.Author), true
+	case *protocol.CommentOperation:
+		return nonEmpty(op.ParentAuthor, op.Author), true
+	case *protocol.CustomJSONOperation:
+		return signers(op.RequiredAuths, op.RequiredPostingAuths), true
+	default:
+		return nil, false
+	}
+}
+
+// typedOpData builds op_data for operations handled by typedAccounts
+// Keys and value types are what a JSON round trip would produce (numbers are float64)
+func typedOpData(raw any) map[string]interface{} {
+	switch op := raw.(type) {
+	case *protocol.TransferOperation:
+		return map[string]interface{}{
+			"from":   op.From,
+			"to":     op.To,
+			"amount": op.Amount,
+			"memo":   op.Memo,
+		}
+	case *protocol.VoteOperation:
+		return map[string]interface{}{
+			"voter":    op.Voter,
+			"author":   op.Author,
+			"permlink": op.Permlink,
+			"weight":   float64(op.Weight),
+		}
+	case *protocol.CommentOperation:
+		return map[string]interface{}{
+			"parent_author":   op.ParentAuthor,
+			"parent_permlink": op.ParentPermlink,
+			"author":          op.Author,
+			"permlink":        op.Permlink,
+			"title":           op.Title,
+			"body":            op.Body,
+			"json_metadata":   op.JsonMetadata,
+		}
+	case *protocol.CustomJSONOperation:
+		return map[string]interface{}{
+			"required_auths":         jsonStrings(op.RequiredAuths),
+			"required_posting_auths": jsonStrings(op.RequiredPostingAuths),
+			"id":                     op.ID,
+			"json":                   op.JSON,
+		}
+	default:
+		return nil
+	}
+}
+
+// nonEmpty returns the distinct non-empty values in order, like extractAccounts
+func nonEmpty(first, second string) []string {
+	switch {
+	case first == "" && second == "":
+		return nil
+	case first == "" || first == second:
+		return []string{second}
+	case second == "":
+		return []string{first}
+	default:
+		return []string{first, second}
+	}
+}
+
+// signers returns the distinct non-empty accounts of custom_json auth lists in order
+func signers(auths, postingAuths []string) []string {
+	var accounts []string
+	seen := make(map[string]bool, len(auths)+len(postingAuths))
+	for _, list := range [][]string{auths, postingAuths} {
+		for _, account := range list {
+			if account != "" && !seen[account] {
+				seen[account] = true
+				accounts = append(accounts, account)
+			}
+		}
+	}
+	return accounts
+}
+
+// jsonStrings converts a string slice the way a JSON round trip would (nil stays nil)
+func jsonStrings(values []string) interface{} {
+	if values == nil {
+		return nil
+	}
+	items := make([]interface{}, len(values))
+	for i, value := range values {
+		items[i] = value
+	}
+	return items
+}
@@ -0,0 +1,123 @@
+package sync
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+)
+
+// passesConditions checks that every condition holds for the operation data
+func passesConditions(conditions []models.FilterCondition, opData map[string]interface{}) bool {
+	for _, condition := range conditions {
+		if !evaluateCondition(condition, opData) {
+			return false
+		}
+	}
+	return true
+}
+
+// evaluateCondition evaluates a single condition
+// A missing field only satisfies the negated operators
+func evaluateCondition(condition models.FilterCondition, opData map[string]interface{}) bool {
+	value, ok := lookupField(opData, condition.Field)
+
+	switch op := condition.NormalizedOp(); op {
+	case models.FilterOpEquals:
+		return ok && value == condition.Value
+	case models.FilterOpNotEquals:
+		return !ok || value != condition.Value
+	case models.FilterOpContains:
+		return ok && strings.Contains(strings.ToLower(value), strings.ToLower(condition.Value))
+	case models.FilterOpNotContains:
+		return !ok || !strings.Contains(strings.ToLower(value), strings.ToLower(condition.Value))
+	case models.FilterOpGreater, models.FilterOpGreaterOrEq, models.FilterOpLess, models.FilterOpLessOrEq:
+		return ok && compareNumbers(op, value, condition.Value)
+	default:
+		// Unknown operators never match, so a typo can't widen a rule
+		notifyLogger.Warn("Unknown filter operator", "op", condition.Op, "field", condition.Field)
+		return false
+	}
+}
+
+// compareNumbers compares a field value with a condition value numerically
+// Non-numeric values never match
+func compareNumbers(op, value, want string) bool {
+	got, ok := parseNumber(value)
+	if !ok {
+		return false
+	}
+	limit, ok := parseNumber(want)
+	if !ok {
+		notifyLogger.Warn("Filter value is not a number", "value", want)
+		return false
+	}
+
+	switch op {
+	case models.FilterOpGreater:
+		return got > limit
+	case models.FilterOpGreaterOrEq:
+		return got >= limit
+	case models.FilterOpLess:
+		return got < limit
+	default:
+		return got <= limit
+	}
+}
+
+// parseNumber reads a plain number or the amount of an asset string
+func parseNumber(value string) (float64, bool) {
+	if n, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+		return n, true
+	}
+	if asset, err := models.ParseAsset(value); err == nil {
+		return asset.Amount, true
+	}
+	return 0, false
+}
+
+// lookupField resolves a dotted field path in operation data and formats the value as a string
+// "<field>_asset" resolves to the symbol of an asset field when op_data has no such field
+func lookupField(opData map[string]interface{}, field string) (string, bool) {
+	if value, ok := lookupPath(opData, field); ok {
+		return value, true
+	}
+
+	if base, found := strings.CutSuffix(field, "_asset"); found {
+		if value, ok := lookupPath(opData, base); ok {
+			if asset, err := models.ParseAsset(value); err == nil {
+				return asset.Symbol, true
+			}
+		}
+	}
+	return "", false
+}
+
+// lookupPath resolves a dotted field path and formats the value as a string
+func lookupPath(opData map[string]interface{}, field string) (string, bool) {
+	var current interface{} = opData
+	for _, part := range strings.Split(field, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		current, ok = object[part]
+		if !ok {
+			return "", false
+		}
+	}
+
+	if current == nil {
+		return "", false
+	}
+	switch v := current.(type) {
+	case string:
+		return v, true
+	case float64:
+		// JSON numbers; avoid exponent notation for large values
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	default:
+		return fmt.Sprintf("%v", v), true
+	}
+}
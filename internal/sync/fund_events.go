@@ -0,0 +1,112 @@
+package sync
+
+import (
+	"context"
+
+	"github.com/ety001/sps-fund-watcher/internal/clock"
+	"github.com/ety001/sps-fund-watcher/internal/enrich"
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/reporting"
+)
+
+// SetFundEvents sets the deriver of fund events; nil disables them
+func (bp *BlockProcessor) SetFundEvents(deriver *enrich.FundEventDeriver) {
+	bp.fundEvents = deriver
+	bp.fundEventsSeeded = false
+}
+
+// deriveFundEvents derives and stores the fund events of operations and returns the new ones
+// Failures are logged; fund events never hold back sync, the raw operations are already stored
+func (bp *BlockProcessor) deriveFundEvents(ctx context.Context, operations []*models.Operation) []models.FundEvent {
+	if bp.fundEvents == nil {
+		return nil
+	}
+	bp.seedFundEvents(ctx)
+
+	events := bp.fundEvents.Derive(operations)
+	if len(events) == 0 {
+		return nil
+	}
+	created, err := bp.storage.SaveFundEvents(ctx, events)
+	if err != nil {
+		logger.Warn("Failed to store fund events", "events", len(events), "error", err)
+	}
+	return created
+}
+
+// replaceForkedFundEvents re-derives the fund events of blocks replaced after a fork
+// Events of the dropped blocks that the canonical blocks don't repeat are removed
+func (bp *BlockProcessor) replaceForkedFundEvents(ctx context.Context, operations []*models.Operation, dropped []models.Operation) []models.FundEvent {
+	if bp.fundEvents == nil {
+		return nil
+	}
+	bp.seedFundEvents(ctx)
+
+	events := bp.fundEvents.Derive(operations)
+	keep := make([]string, 0, len(events))
+	for _, event := range events {
+		keep = append(keep, event.ID)
+	}
+	blocks := make(map[int64]bool)
+	var droppedBlocks []int64
+	for _, op := range dropped {
+		if !blocks[op.BlockNum] {
+			blocks[op.BlockNum] = true
+			droppedBlocks = append(droppedBlocks, op.BlockNum)
+		}
+	}
+	if err := bp.storage.DeleteFundEventsInBlocks(ctx, droppedBlocks, keep); err != nil {
+		logger.Warn("Failed to remove fund events of forked blocks", "blocks", len(droppedBlocks), "error", err)
+	}
+
+	if len(events) == 0 {
+		return nil
+	}
+	created, err := bp.storage.SaveFundEvents(ctx, events)
+	if err != nil {
+		logger.Warn("Failed to store fund events", "events", len(events), "error", err)
+	}
+	return created
+}
+
+// seedFundEvents loads the latest payout round once, so funding changes are noticed across restarts
+func (bp *BlockProcessor) seedFundEvents(ctx context.Context) {
+	if bp.fundEventsSeeded {
+		return
+	}
+	block, receivers, err := bp.storage.LatestPayoutRound(ctx)
+	if err != nil {
+		logger.Warn("Failed to load the latest payout round; retrying with the next block", "error", err)
+		return
+	}
+	bp.fundEvents.Seed(block, receivers)
+	bp.fundEventsSeeded = true
+}
+
+// notifyFundEvents announces new fund events to the rules bound to their kind
+// Like operations, events older than max_notify_age_minutes are not announced
+func (bp *BlockProcessor) notifyFundEvents(events []models.FundEvent) {
+	if bp.telegramClient == nil || bp.notificationsPaused.Load() || len(events) == 0 {
+		return
+	}
+
+	formatter := bp.telegramClient.Formatter()
+	for _, event := range events {
+		if bp.maxNotifyAge > 0 && clock.Since(bp.clock, event.Timestamp) > bp.maxNotifyAge {
+			continue
+		}
+		for _, rule := range bp.notificationRules {
+			if !rule.FundEvents[event.Kind] || !(rule.NotifyAllAccts || rule.NotifyAccounts[event.Account]) {
+				continue
+			}
+			message := formatter.FundEventMessage(event.Kind, event.Account, event.Summary, event.BlockNum, event.Timestamp)
+			buttons := bp.telegramClient.Explorer().Buttons(event.BlockNum, event.TrxID, event.Account)
+			if err := bp.telegramClient.SendMessageWithOptions(message, buttons, rule.sendOptions()); err != nil {
+				notifyLogger.Error("Failed to send fund event notification", "rule", rule.Config.Name, "kind", event.Kind, "account", event.Account, "error", err)
+				reporting.Failure("telegram", err, "rule", rule.Config.Name, "kind", event.Kind, "account", event.Account)
+				continue
+			}
+			reporting.Success("telegram")
+		}
+	}
+}
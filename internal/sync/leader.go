@@ -0,0 +1,112 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/clock"
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+)
+
+// leaderElector keeps the sync lease in MongoDB so only one of several instances syncs
+type leaderElector struct {
+	storage *storage.MongoDB
+	holder  string
+	ttl     time.Duration
+	clock   clock.Clock
+}
+
+func newLeaderElector(storage *storage.MongoDB, config models.LeaderElectionConfig) *leaderElector {
+	holder := config.InstanceID
+	if holder == "" {
+		hostname, _ := os.Hostname()
+		holder = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	return &leaderElector{storage: storage, holder: holder, ttl: config.LeaseDuration(), clock: clock.Real}
+}
+
+// interval is how often the leader renews the lease and a standby tries to take it
+func (e *leaderElector) interval() time.Duration {
+	return e.ttl / 3
+}
+
+// await blocks until the lease is acquired; it returns false if ctx or stop ends first
+func (e *leaderElector) await(ctx context.Context, stop <-chan struct{}) bool {
+	standby := false
+	for {
+		ok, err := e.storage.AcquireLease(ctx, models.SyncLeaseName, e.holder, e.ttl)
+		switch {
+		case err != nil:
+			logger.Warn("Failed to acquire sync lease", "instance", e.holder, "error", err)
+		case ok:
+			logger.Info("Acquired sync lease, this instance is the leader", "instance", e.holder)
+			return true
+		case !standby:
+			standby = true
+			leader := "unknown"
+			if lease, err := e.storage.GetLease(ctx, models.SyncLeaseName); err == nil && lease != nil {
+				leader = lease.Holder
+			}
+			logger.Info("Standing by, another instance holds the sync lease", "instance", e.holder, "leader", leader)
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-stop:
+			return false
+		case <-e.clock.After(e.interval()):
+		}
+	}
+}
+
+// keep renews the lease until ctx ends
+// lost is called when another instance took the lease, or when renewals kept failing and the
+// lease is about to expire; stopping before expiry keeps two leaders from syncing at once
+func (e *leaderElector) keep(ctx context.Context, lost func()) {
+	ticker := e.clock.NewTicker(e.interval())
+	defer ticker.Stop()
+
+	expires := e.clock.Now().Add(e.ttl)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+
+		attempt := e.clock.Now()
+		ok, err := e.storage.AcquireLease(ctx, models.SyncLeaseName, e.holder, e.ttl)
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil:
+			logger.Warn("Failed to renew sync lease", "instance", e.holder, "error", err)
+			if expires.Sub(e.clock.Now()) < e.interval() {
+				logger.Error("Sync lease is about to expire, stepping down", "instance", e.holder)
+				lost()
+				return
+			}
+		case !ok:
+			logger.Error("Sync lease was taken over by another instance, stepping down", "instance", e.holder)
+			lost()
+			return
+		default:
+			expires = attempt.Add(e.ttl)
+		}
+	}
+}
+
+// release gives up the lease on shutdown so a standby takes over without waiting for expiry
+func (e *leaderElector) release() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := e.storage.ReleaseLease(ctx, models.SyncLeaseName, e.holder); err != nil {
+		logger.Warn("Failed to release sync lease", "instance", e.holder, "error", err)
+		return
+	}
+	logger.Info("Released sync lease", "instance", e.holder)
+}
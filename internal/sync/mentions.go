@@ -0,0 +1,126 @@
+package sync
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/steemit/steemutil/protocol"
+)
+
+// Where a mention was found
+const (
+	mentionInBody     = "body"
+	mentionInMetadata = "json_metadata"
+)
+
+// mention is a tracked account named by a comment that isn't addressed to it
+type mention struct {
+	account string
+	opData  map[string]interface{}
+}
+
+// SetMentionDetection enables mention events for tracked accounts named in comments
+func (bp *BlockProcessor) SetMentionDetection(enabled bool) {
+	bp.detectMentions = enabled
+}
+
+// mentionOperations returns the mention events of a comment operation
+// The author and parent author are skipped: they already get the comment itself
+func (bp *BlockProcessor) mentionOperations(opType string, raw any) []mention {
+	if !bp.detectMentions || opType != "comment" {
+		return nil
+	}
+
+	var author, permlink, parentAuthor, parentPermlink, title, body, metadata string
+	switch op := raw.(type) {
+	case *protocol.CommentOperation:
+		author, permlink, parentAuthor, parentPermlink = op.Author, op.Permlink, op.ParentAuthor, op.ParentPermlink
+		title, body, metadata = op.Title, op.Body, op.JsonMetadata
+	case map[string]interface{}:
+		field := func(name string) string {
+			value, _ := op[name].(string)
+			return value
+		}
+		author, permlink, parentAuthor, parentPermlink = field("author"), field("permlink"), field("parent_author"), field("parent_permlink")
+		title, body, metadata = field("title"), field("body"), field("json_metadata")
+	default:
+		return nil
+	}
+
+	// Condenser lists mentioned users in json_metadata.users
+	var users map[string]bool
+	if strings.Contains(metadata, `"users"`) {
+		var meta struct {
+			Users []string `json:"users"`
+		}
+		if json.Unmarshal([]byte(metadata), &meta) == nil {
+			users = make(map[string]bool, len(meta.Users))
+			for _, user := range meta.Users {
+				users[user] = true
+			}
+		}
+	}
+	if users == nil && !strings.Contains(body, "@") {
+		return nil
+	}
+
+	var mentions []mention
+	for account := range bp.accounts {
+		if account == author || account == parentAuthor {
+			continue
+		}
+		foundIn := ""
+		if mentionsAccount(body, account) {
+			foundIn = mentionInBody
+		} else if users[account] {
+			foundIn = mentionInMetadata
+		}
+		if foundIn == "" {
+			continue
+		}
+		mentions = append(mentions, mention{
+			account: account,
+			opData: map[string]interface{}{
+				"author":          author,
+				"permlink":        permlink,
+				"parent_author":   parentAuthor,
+				"parent_permlink": parentPermlink,
+				"title":           title,
+				"found_in":        foundIn,
+			},
+		})
+	}
+	// Stable order regardless of map iteration
+	sort.Slice(mentions, func(i, j int) bool { return mentions[i].account < mentions[j].account })
+	return mentions
+}
+
+// mentionsAccount reports whether text contains @account as a whole name
+// Profile links such as steemit.com/@account are not mentions
+func mentionsAccount(text, account string) bool {
+	needle := "@" + account
+	for offset := 0; ; {
+		i := strings.Index(text[offset:], needle)
+		if i < 0 {
+			return false
+		}
+		start := offset + i
+		end := start + len(needle)
+		offset = end
+
+		if start > 0 && (isNameChar(text[start-1]) || text[start-1] == '/' || text[start-1] == '@') {
+			continue
+		}
+		// "@alice." ends a sentence, "@alice.bob" is another account
+		if end < len(text) && (isNameChar(text[end]) || text[end] == '.' && end+1 < len(text) && isNameChar(text[end+1])) {
+			continue
+		}
+		return true
+	}
+}
+
+// isNameChar reports whether c can appear inside an account name (besides dots)
+func isNameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,82 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/reporting"
+)
+
+// RunOnce catches up to the block the sync mode follows (the last irreversible block by default),
+// persists the sync state and returns, for deployments that run the sync as a scheduled job
+// Bot commands and the periodic checks (accounts, storage, retention, balances, reconciliation,
+// activity) only run in the long-running service
+func (s *Syncer) RunOnce(ctx context.Context) error {
+	if s.leader != nil {
+		ok, err := s.storage.AcquireLease(ctx, models.SyncLeaseName, s.leader.holder, s.leader.ttl)
+		if err != nil {
+			return fmt.Errorf("failed to acquire sync lease: %w", err)
+		}
+		if !ok {
+			logger.Info("Another instance holds the sync lease, nothing to do", "instance", s.leader.holder)
+			return nil
+		}
+		defer s.leader.release()
+
+		leaderCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		var lost atomic.Bool
+		go s.leader.keep(leaderCtx, func() {
+			lost.Store(true)
+			cancel()
+		})
+		err = s.runOnce(leaderCtx)
+		if lost.Load() {
+			return errors.New("lost the sync lease before catching up")
+		}
+		return err
+	}
+	return s.runOnce(ctx)
+}
+
+func (s *Syncer) runOnce(ctx context.Context) error {
+	logger.Info("Running single sync pass", "api_url", s.config.Steem.APIURL, "sync_mode", s.config.Steem.SyncMode)
+
+	if s.applyControlState(ctx) {
+		logger.Info("Block processing is paused, skipping sync")
+		return nil
+	}
+	s.refreshWebhooks(ctx)
+	s.refreshViews(ctx)
+
+	// Receivers tracked automatically are stored with sync state, so a run keeps tracking them
+	s.checkAutoTrack(ctx)
+
+	// Resend notifications that failed in earlier runs
+	s.resendPending(ctx)
+
+	if err := s.replaySpool(ctx); err != nil {
+		return fmt.Errorf("failed to replay spool: %w", err)
+	}
+
+	syncState, err := s.storage.GetSyncState(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get sync state: %w", err)
+	}
+	startBlock := s.config.Steem.StartBlock
+	if syncState.LastBlock > 0 && syncState.LastBlock >= startBlock {
+		startBlock = syncState.LastBlock + 1
+	}
+
+	if err := s.syncBlocks(ctx, startBlock); err != nil {
+		reporting.Failure("sync", err, "block_num", startBlock, "node_url", s.config.Steem.APIURL)
+		return fmt.Errorf("failed to sync blocks from %d: %w", startBlock, err)
+	}
+	reporting.Success("sync")
+
+	// Notifications that failed during this pass are resent by the next run
+	return ctx.Err()
+}
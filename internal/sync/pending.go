@@ -0,0 +1,133 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/reporting"
+	"github.com/ety001/sps-fund-watcher/internal/telegram"
+)
+
+// Resend limits for notifications that failed to send
+// Retries are due after the backoff of retry.telegram, but only found every pendingCheckInterval
+const (
+	pendingCheckInterval = 30 * time.Second
+	pendingBatchSize     = 20
+	pendingSaveTimeout   = 5 * time.Second
+)
+
+// savePendingNotification stores a channel message that failed to send for a later resend, or
+// gives it up at once when retry.telegram allows a single attempt
+func (s *Syncer) savePendingNotification(failed telegram.FailedMessage) {
+	policy := s.config.Retry.Telegram
+	now := s.clock.Now()
+	notification := &models.PendingNotification{
+		Text:          failed.Text,
+		ParseMode:     failed.ParseMode,
+		ThreadID:      failed.Options.ThreadID,
+		Silent:        failed.Options.Silent,
+		Pin:           failed.Options.Pin,
+		Attempts:      1,
+		LastError:     failed.Err.Error(),
+		CreatedAt:     now,
+		NextAttemptAt: now.Add(policy.Backoff(1, rand.Float64())),
+	}
+	for _, button := range failed.Buttons {
+		notification.Buttons = append(notification.Buttons, models.NotificationButton{Text: button.Text, URL: button.URL})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pendingSaveTimeout)
+	defer cancel()
+	if policy.Exhausted(notification.Attempts) {
+		s.giveUpNotification(ctx, notification, models.GiveUpMaxAttempts)
+		return
+	}
+	if err := s.storage.InsertPendingNotification(ctx, notification); err != nil {
+		notifyLogger.Error("Failed to store notification for resend, it is lost", "error", err)
+	}
+}
+
+// giveUpNotification ends the retries of a notification: it is moved to the dead letters, or
+// dropped with retry.telegram.give_up "drop", and removed from the pending notifications
+func (s *Syncer) giveUpNotification(ctx context.Context, notification *models.PendingNotification, reason string) {
+	policy := s.config.Retry.Telegram
+	notifyLogger.Warn("Giving up on notification", "id", notification.ID, "reason", reason, "attempts", notification.Attempts,
+		"give_up", policy.GiveUp, "last_error", notification.LastError)
+	if policy.DeadLetters() {
+		deadLetter := &models.NotificationDeadLetter{PendingNotification: *notification, Reason: reason, FailedAt: s.clock.Now()}
+		if err := s.storage.InsertNotificationDeadLetter(ctx, deadLetter); err != nil {
+			notifyLogger.Error("Failed to record notification dead letter, it is lost", "id", notification.ID, "error", err)
+		}
+	}
+	if notification.ID == "" {
+		return
+	}
+	if err := s.storage.DeletePendingNotification(ctx, notification.ID); err != nil {
+		notifyLogger.Warn("Failed to delete pending notification", "id", notification.ID, "error", err)
+	}
+}
+
+// resendPending resends the pending notifications that are due, oldest first, and returns how
+// many were sent
+// A failure ends the round: the channel is most likely still unreachable
+func (s *Syncer) resendPending(ctx context.Context) int {
+	if s.telegram == nil || s.processor.notificationsPaused.Load() {
+		return 0
+	}
+
+	now := s.clock.Now()
+	due, err := s.storage.DuePendingNotifications(ctx, now, pendingBatchSize)
+	if err != nil {
+		notifyLogger.Warn("Failed to load pending notifications", "error", err)
+		return 0
+	}
+
+	sent := 0
+	policy := s.config.Retry.Telegram
+	maxAge := s.config.Telegram.ResendMaxAge()
+	for _, notification := range due {
+		if now.Sub(notification.CreatedAt) > maxAge {
+			s.giveUpNotification(ctx, &notification, models.GiveUpExpired)
+			continue
+		}
+
+		buttons := make([]telegram.InlineButton, 0, len(notification.Buttons))
+		for _, button := range notification.Buttons {
+			buttons = append(buttons, telegram.InlineButton{Text: button.Text, URL: button.URL})
+		}
+		opts := telegram.SendOptions{ThreadID: notification.ThreadID, Silent: notification.Silent, Pin: notification.Pin}
+		err := s.telegram.ResendMessage(notification.Text, notification.ParseMode, buttons, opts)
+		if errors.Is(err, telegram.ErrNotPinned) {
+			// Delivered; sending it again would only duplicate it
+			notifyLogger.Warn("Resent notification could not be pinned", "id", notification.ID, "error", err)
+			err = nil
+		}
+		if err != nil {
+			reporting.Failure("telegram", err)
+			attempts := notification.Attempts + 1
+			if policy.Exhausted(attempts) {
+				notification.Attempts, notification.LastError = attempts, err.Error()
+				s.giveUpNotification(ctx, &notification, models.GiveUpMaxAttempts)
+				break
+			}
+			next := now.Add(policy.Backoff(attempts, rand.Float64()))
+			if err := s.storage.RecordNotificationAttempt(ctx, notification.ID, err.Error(), next); err != nil {
+				notifyLogger.Warn("Failed to update pending notification", "id", notification.ID, "error", err)
+			}
+			notifyLogger.Warn("Failed to resend notification", "id", notification.ID, "attempts", attempts, "next_attempt_at", next, "error", err)
+			break
+		}
+		reporting.Success("telegram")
+		sent++
+		if err := s.storage.DeletePendingNotification(ctx, notification.ID); err != nil {
+			notifyLogger.Warn("Failed to delete resent notification, it may be sent again", "id", notification.ID, "error", err)
+		}
+	}
+	if sent > 0 {
+		notifyLogger.Info("Resent notifications that failed earlier", "count", sent)
+	}
+	return sent
+}
@@ -0,0 +1,207 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	stdsync "sync"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/clock"
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/reporting"
+	"github.com/steemit/steemutil/protocol"
+)
+
+// fetchedBatch holds the extracted operations of a contiguous block range
+type fetchedBatch struct {
+	index      int
+	startBlock int64
+	endBlock   int64
+	operations map[int64][]*models.Operation // Keyed by block number
+	trxIDs     map[int64][]string            // Transaction IDs seen in each block, head mode only
+	err        error
+}
+
+// fetchBatch fetches all operations (regular + virtual) for a block range and extracts
+// the ones relevant to tracked accounts
+func (s *Syncer) fetchBatch(ctx context.Context, startBlock, endBlock int64) *fetchedBatch {
+	batch := &fetchedBatch{
+		startBlock: startBlock,
+		endBlock:   endBlock,
+	}
+
+	// Get all operations (both regular and virtual) in batch using GetOpsInBlocks
+	// This is more efficient than calling GetBlocks + GetOpsInBlocks separately
+	logger.Debug("Fetching operations", "start_block", startBlock, "end_block", endBlock)
+	opsMap, rawMap, err := s.getOpsInBlocks(startBlock, endBlock)
+	if err != nil {
+		batch.err = fmt.Errorf("failed to get operations for blocks %d to %d: %w", startBlock, endBlock, err)
+		return batch
+	}
+	logger.Debug("Fetched operations", "start_block", startBlock, "end_block", endBlock, "blocks", len(opsMap))
+
+	operations, err := s.processBlocks(ctx, opsMap, startBlock, endBlock)
+	if err != nil {
+		batch.err = err
+		return batch
+	}
+	if err := AttachBlockHeaders(s.steemAPI, operations); err != nil {
+		batch.err = err
+		return batch
+	}
+	batch.operations = operations
+	if s.headMode() {
+		// Kept so reversible blocks can be checked against the block whose ID is recorded
+		batch.trxIDs = make(map[int64][]string, len(opsMap))
+		for blockNum, ops := range opsMap {
+			batch.trxIDs[int64(blockNum)] = transactionIDs(ops)
+		}
+	}
+	s.archiveBlocks(ctx, rawMap, opsMap, operations, startBlock, endBlock)
+
+	// Small delay to avoid overwhelming the API
+	clock.Sleep(ctx, s.clock, 100*time.Millisecond)
+
+	return batch
+}
+
+// processWorkers returns how many blocks of a batch are processed concurrently
+func (s *Syncer) processWorkers() int {
+	if s.config.Steem.ProcessWorkers > 0 {
+		return s.config.Steem.ProcessWorkers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// processBlocks extracts the operations of tracked accounts from every block in the range
+// Blocks are processed by a pool of workers; the result is keyed by block number, so
+// committing it in block order is left to the caller. The error of the lowest failing
+// block is returned.
+func (s *Syncer) processBlocks(ctx context.Context, opsMap map[uint][]*protocol.OperationObject, startBlock, endBlock int64) (map[int64][]*models.Operation, error) {
+	count := int(endBlock - startBlock + 1)
+	results := make([][]*models.Operation, count)
+	errs := make([]error, count)
+
+	workers := min(s.processWorkers(), count)
+	next := make(chan int)
+	var wg stdsync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer reporting.Recover()
+			for offset := range next {
+				// Each worker writes only its own slots
+				results[offset], errs[offset] = s.processor.ProcessOperations(ctx, opsMap[uint(startBlock+int64(offset))])
+			}
+		}()
+	}
+	for offset := 0; offset < count; offset++ {
+		if len(opsMap[uint(startBlock+int64(offset))]) > 0 {
+			next <- offset
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	operations := make(map[int64][]*models.Operation)
+	for offset := 0; offset < count; offset++ {
+		blockNum := startBlock + int64(offset)
+		if errs[offset] != nil {
+			return nil, fmt.Errorf("failed to process operations for block %d: %w", blockNum, errs[offset])
+		}
+		if len(opsMap[uint(blockNum)]) > 0 {
+			operations[blockNum] = results[offset]
+		}
+	}
+	return operations, nil
+}
+
+// fetchBatches fetches and processes batches of blocks with a pool of workers and
+// delivers them strictly in block order, so the caller can commit operations and
+// sync state sequentially. At most 2*workers batches are in flight at a time.
+// Delivery stops after the first batch carrying an error or when ctx is cancelled.
+func (s *Syncer) fetchBatches(ctx context.Context, startBlock, endBlock, batchSize int64, workers int) <-chan *fetchedBatch {
+	type job struct {
+		index      int
+		startBlock int64
+		endBlock   int64
+	}
+
+	jobs := make(chan job)
+	results := make(chan *fetchedBatch)
+	out := make(chan *fetchedBatch)
+	slots := make(chan struct{}, workers*2)
+
+	// Dispatcher: split the range into batches
+	go func() {
+		defer close(jobs)
+		index := 0
+		for start := startBlock; start <= endBlock; start += batchSize {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- job{index: index, startBlock: start, endBlock: min(start+batchSize-1, endBlock)}:
+			case <-ctx.Done():
+				return
+			}
+			index++
+		}
+	}()
+
+	// Workers: fetch and process batches concurrently
+	var wg stdsync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer reporting.Recover()
+			for j := range jobs {
+				batch := s.fetchBatch(ctx, j.startBlock, j.endBlock)
+				batch.index = j.index
+				select {
+				case results <- batch:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Reorderer: release batches in block order
+	go func() {
+		defer close(out)
+		pending := make(map[int]*fetchedBatch)
+		next := 0
+		for batch := range results {
+			pending[batch.index] = batch
+			for {
+				ready, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				select {
+				case out <- ready:
+				case <-ctx.Done():
+					return
+				}
+				<-slots
+				next++
+				if ready.err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,41 @@
+package sync
+
+import (
+	stdsync "sync"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+)
+
+// Processor is custom logic run on each extracted operation after enrichment and plugins, before
+// storage; it may change op, e.g. to add enrichment fields, or export it elsewhere
+// Operations vetoed by a plugin are passed too (VetoedBy is set); a returned error is logged and
+// the operation is stored anyway
+type Processor func(op *models.Operation) error
+
+var (
+	processorsMu stdsync.RWMutex
+	processors   []Processor
+)
+
+// RegisterProcessor adds a processor run by every block processor, in registration order
+// Register processors before the sync service starts, e.g. from an init function
+func RegisterProcessor(processor Processor) {
+	processorsMu.Lock()
+	defer processorsMu.Unlock()
+	processors = append(processors, processor)
+}
+
+// runProcessors runs the registered processors on operations
+func runProcessors(operations []*models.Operation) {
+	processorsMu.RLock()
+	registered := processors
+	processorsMu.RUnlock()
+
+	for _, op := range operations {
+		for i, processor := range registered {
+			if err := processor(op); err != nil {
+				logger.Warn("Processor failed", "processor", i, "block", op.BlockNum, "trx_id", op.TrxID, "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/reconcile"
+	"github.com/ety001/sps-fund-watcher/internal/telegram"
+)
+
+// reconciliationCheckInterval is how often the syncer checks whether a weekly report is due
+const reconciliationCheckInterval = time.Hour
+
+// weeklyReconciliationJob records the end of the last reported week
+const weeklyReconciliationJob = "weekly_proposal_reconciliation"
+
+// sendWeeklyReconciliation sends the proposal payout report of the previous week (Monday to
+// Sunday, UTC) once, even across restarts
+func (s *Syncer) sendWeeklyReconciliation(ctx context.Context, now time.Time) {
+	if !s.config.Reconciliation.WeeklyReport || s.telegram == nil {
+		return
+	}
+
+	to := startOfWeek(now)
+	lastRun, err := s.storage.GetLastRun(ctx, weeklyReconciliationJob)
+	if err != nil {
+		logger.Warn("Failed to check weekly reconciliation", "error", err)
+		return
+	}
+	if !lastRun.Before(to) {
+		return
+	}
+
+	from := to.AddDate(0, 0, -7)
+	report, err := reconcile.Proposals(ctx, s.storage, s.config.Reconciliation, from, to)
+	if err != nil {
+		logger.Error("Failed to reconcile proposal payouts", "error", err)
+		return
+	}
+	logger.Info("Proposal payouts reconciled", "from", from, "to", to, "days", len(report.Days), "discrepancies", report.Discrepancies)
+
+	summary := reconciliationSummary(report)
+	if runway, err := reconcile.Runway(ctx, s.storage, s.config.Reconciliation, s.clock.Now()); err == nil {
+		summary.Runway = runwaySummary(runway)
+	} else {
+		logger.Warn("Failed to project treasury runway", "error", err)
+	}
+
+	message := s.telegram.Formatter().ProposalReconciliation(summary)
+	if err := s.telegram.SendMessage(message); err != nil {
+		logger.Error("Failed to send reconciliation report", "error", err)
+		return
+	}
+	if err := s.storage.SetLastRun(ctx, weeklyReconciliationJob, to); err != nil {
+		logger.Warn("Failed to record weekly reconciliation", "error", err)
+	}
+}
+
+// reconciliationSummary converts a report for the Telegram formatter
+func reconciliationSummary(report *models.ProposalReconciliation) telegram.ReconciliationSummary {
+	summary := telegram.ReconciliationSummary{From: report.From, To: report.To, Checked: len(report.Days)}
+	for _, day := range report.Days {
+		if day.Status == models.PayoutStatusOK {
+			continue
+		}
+		summary.Discrepancies = append(summary.Discrepancies, telegram.ReconciliationItem{
+			Date:     day.Date,
+			Receiver: day.Receiver,
+			Status:   day.Status,
+			Expected: fmt.Sprintf("%.3f %s", day.Expected, day.Symbol),
+			Paid:     fmt.Sprintf("%.3f %s", day.Paid, day.Symbol),
+		})
+	}
+	return summary
+}
+
+// runwaySummary describes a runway projection in one line
+func runwaySummary(runway *models.RunwayProjection) string {
+	if runway.RunwayMonths == nil {
+		return fmt.Sprintf("%.3f %s, inflow covers obligations of %.3f %s/day",
+			runway.Balance, runway.Symbol, runway.DailyObligations, runway.Symbol)
+	}
+	return fmt.Sprintf("%.1f months (%.3f %s at a net burn of %.3f %s/day)",
+		*runway.RunwayMonths, runway.Balance, runway.Symbol, runway.NetDailyBurn, runway.Symbol)
+}
+
+// startOfWeek returns the Monday 00:00 UTC on or before t
+func startOfWeek(t time.Time) time.Time {
+	date := t.UTC().Truncate(24 * time.Hour)
+	return date.AddDate(0, 0, -((int(date.Weekday()) + 6) % 7))
+}
@@ -0,0 +1,136 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/steemit/steemutil/protocol"
+)
+
+// headMode reports whether the syncer follows the head block instead of the last irreversible block
+func (s *Syncer) headMode() bool {
+	return s.config.Steem.SyncMode == models.SyncModeHead
+}
+
+// trackReversibleBlock marks operations from a reversible block as unconfirmed and
+// remembers the block ID so the block can be checked against the chain later
+// The block is fetched separately from its operations, so its transactions must match
+// the ones the operations came from; otherwise the node switched forks in between and
+// the block is rejected so the batch is fetched again
+func (s *Syncer) trackReversibleBlock(ctx context.Context, blockNum int64, trxIDs []string, operations []*models.Operation) error {
+	block, err := s.steemAPI.GetBlock(uint(blockNum))
+	if err != nil {
+		return fmt.Errorf("failed to get block header %d: %w", blockNum, err)
+	}
+	if !sameTransactions(block.TransactionIds, trxIDs) {
+		return fmt.Errorf("block %d changed while it was being fetched (block_id %s), retrying", blockNum, block.BlockId)
+	}
+
+	// Take the header from the same block whose ID is recorded
+	setBlockHeader(operations, block.BlockId, block.Witness)
+	for _, op := range operations {
+		op.Unconfirmed = true
+	}
+
+	return s.storage.SaveReversibleBlock(ctx, blockNum, block.BlockId)
+}
+
+// reconcileReversibleBlocks checks every recorded reversible block that has become irreversible
+// Blocks whose ID still matches are confirmed; blocks dropped by a fork have their
+// unconfirmed operations removed and are re-processed from the canonical chain
+func (s *Syncer) reconcileReversibleBlocks(ctx context.Context, latestIrreversible int64) error {
+	blocks, err := s.storage.GetReversibleBlocks(ctx, latestIrreversible)
+	if err != nil {
+		return err
+	}
+
+	if len(blocks) == 0 {
+		return nil
+	}
+	blockNums := make([]uint, len(blocks))
+	for i, rb := range blocks {
+		blockNums[i] = uint(rb.BlockNum)
+	}
+	irreversible, err := s.steemAPI.GetBlocks(blockNums)
+	if err != nil {
+		return fmt.Errorf("failed to get irreversible blocks: %w", err)
+	}
+
+	for _, rb := range blocks {
+		block := irreversible[uint(rb.BlockNum)]
+		if block == nil {
+			return fmt.Errorf("irreversible block %d not available from node", rb.BlockNum)
+		}
+
+		if block.BlockId == rb.BlockID {
+			if err := s.storage.ConfirmBlock(ctx, rb.BlockNum); err != nil {
+				return err
+			}
+			logger.Debug("Block confirmed irreversible", "block_num", rb.BlockNum, "block_id", rb.BlockID)
+			continue
+		}
+
+		logger.Warn("Fork detected", "block_num", rb.BlockNum, "processed_id", rb.BlockID, "irreversible_id", block.BlockId)
+
+		dropped, err := s.storage.DropUnconfirmedOperations(ctx, rb.BlockNum)
+		if err != nil {
+			return err
+		}
+
+		opsMap, rawMap, err := s.getOpsInBlocks(rb.BlockNum, rb.BlockNum)
+		if err != nil {
+			return fmt.Errorf("failed to get operations for block %d: %w", rb.BlockNum, err)
+		}
+		ops := opsMap[uint(rb.BlockNum)]
+		operations, err := s.processor.ProcessOperations(ctx, ops)
+		if err != nil {
+			return fmt.Errorf("failed to process operations for block %d: %w", rb.BlockNum, err)
+		}
+
+		models.SetSource(operations, models.SourceSync)
+		setBlockHeader(operations, block.BlockId, block.Witness)
+		s.rearchiveBlock(ctx, rb.BlockNum, rawMap, ops, operations)
+		if err := s.processor.ReplaceForkedOperations(ctx, operations, dropped); err != nil {
+			return fmt.Errorf("failed to save operations for block %d: %w", rb.BlockNum, err)
+		}
+
+		if err := s.storage.ConfirmBlock(ctx, rb.BlockNum); err != nil {
+			return err
+		}
+		logger.Info("Block reconciled after fork", "block_num", rb.BlockNum, "dropped", len(dropped), "stored", len(operations))
+	}
+
+	return nil
+}
+
+// transactionIDs returns the distinct transaction IDs of the non-virtual operations in a block
+func transactionIDs(ops []*protocol.OperationObject) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, op := range ops {
+		if op.VirtualOperation != 0 || seen[op.TransactionID] {
+			continue
+		}
+		seen[op.TransactionID] = true
+		ids = append(ids, op.TransactionID)
+	}
+	return ids
+}
+
+// sameTransactions reports whether a block contains exactly the given transactions
+func sameTransactions(blockIDs, trxIDs []string) bool {
+	if len(blockIDs) != len(trxIDs) {
+		return false
+	}
+	want := make(map[string]bool, len(trxIDs))
+	for _, id := range trxIDs {
+		want[id] = true
+	}
+	for _, id := range blockIDs {
+		if !want[id] {
+			return false
+		}
+	}
+	return true
+}
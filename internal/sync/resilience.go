@@ -0,0 +1,59 @@
+package sync
+
+import (
+	"context"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+)
+
+// defaultMaxOutage is used when mongodb.max_outage_seconds is not set
+const defaultMaxOutage = 2 * time.Minute
+
+// storageHealthInterval is how often the sync service pings MongoDB
+const storageHealthInterval = 5 * time.Second
+
+// maxOutage returns how long a commit waits for MongoDB to come back before giving up
+func (s *Syncer) maxOutage() time.Duration {
+	if s.config.MongoDB.MaxOutageSeconds > 0 {
+		return time.Duration(s.config.MongoDB.MaxOutageSeconds) * time.Second
+	}
+	return defaultMaxOutage
+}
+
+// retryStorage runs a storage write, waiting out brief MongoDB outages
+// Transient errors are retried with backoff for up to maxOutage; meanwhile the fetch
+// pipeline keeps its bounded buffer of fetched batches, so no block data is lost or refetched
+func (s *Syncer) retryStorage(ctx context.Context, what string, write func() error) error {
+	err := write()
+	if !storage.IsTransient(err) {
+		return err
+	}
+
+	deadline := s.clock.Now().Add(s.maxOutage())
+	backoff := time.Second
+	logger.Warn("MongoDB unavailable, buffering until it recovers", "while", what, "max_outage", s.maxOutage(), "error", err)
+
+	for s.clock.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.clock.After(backoff):
+		}
+
+		err = write()
+		if !storage.IsTransient(err) {
+			if err == nil {
+				logger.Info("MongoDB recovered", "resumed", what)
+			}
+			return err
+		}
+
+		backoff *= 2
+		if backoff > 10*time.Second {
+			backoff = 10 * time.Second
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,30 @@
+package sync
+
+import (
+	"context"
+	"time"
+)
+
+// pruneInterval returns how often expired operations are deleted
+func (s *Syncer) pruneInterval() time.Duration {
+	if s.config.Retention.PruneIntervalMinutes > 0 {
+		return time.Duration(s.config.Retention.PruneIntervalMinutes) * time.Minute
+	}
+	return time.Hour
+}
+
+// pruneOperations deletes operations that are older than the retention policy allows
+func (s *Syncer) pruneOperations(ctx context.Context) {
+	if !s.config.Retention.Enabled() {
+		return
+	}
+
+	deleted, err := s.storage.PruneOperations(ctx, s.config.Retention, s.clock.Now(), false)
+	if err != nil {
+		logger.Warn("Failed to prune operations", "error", err)
+		return
+	}
+	if deleted > 0 {
+		logger.Info("Pruned operations past their retention period", "deleted", deleted)
+	}
+}
@@ -0,0 +1,124 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+)
+
+// samplingRule is a compiled models.SamplingRule
+type samplingRule struct {
+	mode    string
+	everyN  int
+	opTypes map[string]bool // Empty matches all operation types
+}
+
+// SetSamplingRules configures storage reduction for noisy accounts
+// At most one rule applies per account and operation type; the first match wins
+func (bp *BlockProcessor) SetSamplingRules(rules []models.SamplingRule) {
+	bp.sampling = make(map[string][]samplingRule)
+	for _, rule := range rules {
+		if rule.Mode != models.SamplingModeSample && rule.Mode != models.SamplingModeAggregate {
+			logger.Warn("Ignoring sampling rule with unknown mode", "account", rule.Account, "mode", rule.Mode)
+			continue
+		}
+		if rule.Mode == models.SamplingModeSample && rule.EveryN < 2 {
+			logger.Warn("Ignoring sampling rule: every_n must be at least 2", "account", rule.Account)
+			continue
+		}
+
+		compiled := samplingRule{mode: rule.Mode, everyN: rule.EveryN, opTypes: make(map[string]bool)}
+		for _, opType := range rule.OpTypes {
+			compiled.opTypes[opType] = true
+		}
+		bp.sampling[rule.Account] = append(bp.sampling[rule.Account], compiled)
+	}
+}
+
+// samplingRuleFor returns the sampling rule for an operation, if any
+func (bp *BlockProcessor) samplingRuleFor(op *models.Operation) (samplingRule, bool) {
+	for _, rule := range bp.sampling[op.Account] {
+		if len(rule.opTypes) == 0 || rule.opTypes[op.OpType] {
+			return rule, true
+		}
+	}
+	return samplingRule{}, false
+}
+
+// ApplySampling drops operations filtered by sampling rules and records hourly counts
+// for aggregated ones. The returned operations are the ones to store and notify
+// Sampling is deterministic per operation, so reprocessing a block keeps the same sample
+func (bp *BlockProcessor) ApplySampling(ctx context.Context, operations []*models.Operation) ([]*models.Operation, error) {
+	if len(bp.sampling) == 0 {
+		return operations, nil
+	}
+
+	kept, counts := bp.sample(operations)
+	if len(counts) > 0 {
+		aggregates := make([]models.OperationAggregate, 0, len(counts))
+		for key, count := range counts {
+			key.Count = count
+			aggregates = append(aggregates, key)
+		}
+		if err := bp.storage.IncrementAggregates(ctx, aggregates); err != nil {
+			return nil, err
+		}
+	}
+
+	return kept, nil
+}
+
+// SampleOperations returns the operations ApplySampling keeps without recording hourly counts
+// It is meant for blocks whose counts were recorded when they were first processed
+func (bp *BlockProcessor) SampleOperations(operations []*models.Operation) []*models.Operation {
+	if len(bp.sampling) == 0 {
+		return operations
+	}
+	kept, _ := bp.sample(operations)
+	return kept
+}
+
+// sample splits operations into the ones to store and hourly counts of aggregated ones
+func (bp *BlockProcessor) sample(operations []*models.Operation) ([]*models.Operation, map[models.OperationAggregate]int64) {
+	var kept []*models.Operation
+	counts := make(map[models.OperationAggregate]int64)
+	for _, op := range operations {
+		rule, ok := bp.samplingRuleFor(op)
+		if !ok {
+			kept = append(kept, op)
+			continue
+		}
+
+		switch rule.mode {
+		case models.SamplingModeAggregate:
+			key := models.OperationAggregate{Account: op.Account, OpType: op.OpType, Hour: op.Timestamp.UTC().Truncate(time.Hour)}
+			counts[key]++
+		case models.SamplingModeSample:
+			if sampleHash(op)%uint32(rule.everyN) == 0 {
+				op.SampleRate = rule.everyN
+				kept = append(kept, op)
+			}
+		}
+	}
+	return kept, counts
+}
+
+// sampleHash maps an operation to a stable pseudo-random value
+func sampleHash(op *models.Operation) uint32 {
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%d/%s/%d", op.BlockNum, op.TrxID, op.OpInTrx)
+	return h.Sum32()
+}
+
+// SamplingKeeps reports whether ApplySampling stores op as an individual record
+// Unlike ApplySampling it has no side effects, so it can be used to predict what is in the database
+func (bp *BlockProcessor) SamplingKeeps(op *models.Operation) bool {
+	rule, ok := bp.samplingRuleFor(op)
+	if !ok {
+		return true
+	}
+	return rule.mode == models.SamplingModeSample && sampleHash(op)%uint32(rule.everyN) == 0
+}
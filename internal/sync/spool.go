@@ -0,0 +1,299 @@
+package sync
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	stdsync "sync"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+)
+
+// spoolRecord is one committed block written to the spool while MongoDB is down
+type spoolRecord struct {
+	BlockNum         int64               `json:"block_num"`
+	LastIrreversible int64               `json:"last_irreversible"`
+	Operations       []*models.Operation `json:"operations"`
+}
+
+// spool is an append-only on-disk log of blocks that could not be written to MongoDB
+// Each outage starts a new segment file; segments are replayed in order and removed
+type spool struct {
+	mu        stdsync.Mutex
+	dir       string
+	maxBytes  int64
+	file      *os.File
+	size      int64 // Total size of all segments
+	lastBlock int64 // Highest spooled block, 0 when the spool is empty
+}
+
+// openSpool opens (or creates) a spool directory and picks up segments left by a previous run
+func openSpool(dir string, maxMB int64) (*spool, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create spool directory: %w", err)
+	}
+
+	sp := &spool{dir: dir, maxBytes: maxMB * 1024 * 1024}
+	segments, err := sp.segments()
+	if err != nil {
+		return nil, err
+	}
+	for _, segment := range segments {
+		info, err := os.Stat(segment)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat spool segment: %w", err)
+		}
+		sp.size += info.Size()
+
+		err = readSegment(segment, func(record spoolRecord) error {
+			if record.BlockNum > sp.lastBlock {
+				sp.lastBlock = record.BlockNum
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(segments) > 0 {
+		logger.Info("Found spool segments, will replay when MongoDB is available", "segments", len(segments), "block_num", sp.lastBlock, "dir", dir)
+	}
+	return sp, nil
+}
+
+// segments returns the segment files in replay order
+func (sp *spool) segments() ([]string, error) {
+	segments, err := filepath.Glob(filepath.Join(sp.dir, "spool-*.jsonl"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list spool segments: %w", err)
+	}
+	// Names embed the zero-padded first block, so lexical order is block order
+	sort.Strings(segments)
+	return segments, nil
+}
+
+// pending reports whether spooled blocks are waiting to be replayed
+// Once spooling has started, later blocks must be spooled too to keep them in order
+func (sp *spool) pending() bool {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	return sp.lastBlock > 0
+}
+
+// last returns the highest spooled block (0 when empty)
+func (sp *spool) last() int64 {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	return sp.lastBlock
+}
+
+// append writes a block to the current segment and syncs it to disk
+func (sp *spool) append(record spoolRecord) error {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode spool record: %w", err)
+	}
+	data = append(data, '\n')
+
+	if sp.maxBytes > 0 && sp.size+int64(len(data)) > sp.maxBytes {
+		return fmt.Errorf("spool is full (%d MB)", sp.maxBytes/(1024*1024))
+	}
+
+	if sp.file == nil {
+		name := filepath.Join(sp.dir, fmt.Sprintf("spool-%012d.jsonl", record.BlockNum))
+		sp.file, err = os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to open spool segment: %w", err)
+		}
+	}
+
+	if _, err := sp.file.Write(data); err != nil {
+		return fmt.Errorf("failed to write spool record: %w", err)
+	}
+	if err := sp.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync spool segment: %w", err)
+	}
+
+	sp.size += int64(len(data))
+	sp.lastBlock = record.BlockNum
+	return nil
+}
+
+// replay feeds every spooled block to apply in order, removing each segment once it is fully applied
+// On error the remaining segments are kept for the next attempt; re-applying a block is idempotent
+func (sp *spool) replay(ctx context.Context, apply func(spoolRecord) error) error {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	if sp.file != nil {
+		sp.file.Close()
+		sp.file = nil
+	}
+
+	segments, err := sp.segments()
+	if err != nil {
+		return err
+	}
+	for _, segment := range segments {
+		info, err := os.Stat(segment)
+		if err != nil {
+			return fmt.Errorf("failed to stat spool segment: %w", err)
+		}
+
+		err = readSegment(segment, func(record spoolRecord) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return apply(record)
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := os.Remove(segment); err != nil {
+			return fmt.Errorf("failed to remove replayed spool segment: %w", err)
+		}
+		sp.size -= info.Size()
+		logger.Info("Replayed spool segment", "segment", filepath.Base(segment))
+	}
+
+	sp.size = 0
+	sp.lastBlock = 0
+	return nil
+}
+
+// readSegment decodes the records of a segment file
+// A truncated last line (crash during append) is ignored
+func readSegment(path string, fn func(spoolRecord) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open spool segment: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var record spoolRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			logger.Warn("Skipping unreadable record in spool segment", "segment", filepath.Base(path), "error", err)
+			continue
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read spool segment: %w", err)
+	}
+	return nil
+}
+
+// commitBlock persists a block's operations and advances the sync state
+// When a spool is configured, blocks are written to it instead while MongoDB is unreachable
+func (s *Syncer) commitBlock(ctx context.Context, blockNum int64, operations []*models.Operation, latestIrreversible int64) error {
+	models.SetSource(operations, models.SourceSync)
+
+	// Keep block order: once blocks are spooled, everything after them is spooled too until replayed
+	if s.spool != nil && s.spool.pending() {
+		return s.spoolBlock(blockNum, operations, latestIrreversible)
+	}
+
+	// Without a spool, ride out outages by waiting; with one, fall back to it right away
+	retry := s.retryStorage
+	if s.spool != nil {
+		retry = func(_ context.Context, _ string, write func() error) error { return write() }
+	}
+
+	err := s.storeBlock(ctx, blockNum, operations, latestIrreversible, retry)
+	if s.spool != nil && storage.IsTransient(err) {
+		logger.Warn("MongoDB unavailable, spooling", "block_num", blockNum, "dir", s.config.MongoDB.SpoolDir, "error", err)
+		return s.spoolBlock(blockNum, operations, latestIrreversible)
+	}
+	return err
+}
+
+// storeBlock writes a block to MongoDB
+func (s *Syncer) storeBlock(ctx context.Context, blockNum int64, operations []*models.Operation, latestIrreversible int64,
+	retry func(context.Context, string, func() error) error) error {
+	// Save operations (this will also send Telegram notifications if enabled)
+	if len(operations) > 0 {
+		logger.Debug("Saving operations", "block_num", blockNum, "ops", len(operations))
+		err := retry(ctx, fmt.Sprintf("saving block %d", blockNum), func() error {
+			return s.processor.SaveOperations(ctx, operations)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save operations for block %d: %w", blockNum, err)
+		}
+		logger.Debug("Saved operations", "block_num", blockNum)
+	}
+
+	// Update sync state
+	// Uses atomic $max operator to ensure last_block only increases (no transactions needed)
+	logger.Debug("Updating sync state", "block_num", blockNum, "last_irreversible", latestIrreversible)
+	err := retry(ctx, fmt.Sprintf("updating sync state for block %d", blockNum), func() error {
+		return s.storage.UpdateSyncState(ctx, blockNum, latestIrreversible)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update sync state for block %d: %w", blockNum, err)
+	}
+	logger.Debug("Updated sync state", "block_num", blockNum)
+	return nil
+}
+
+// spoolBlock appends a block to the spool; a full spool falls back to waiting for MongoDB
+func (s *Syncer) spoolBlock(blockNum int64, operations []*models.Operation, latestIrreversible int64) error {
+	err := s.spool.append(spoolRecord{
+		BlockNum:         blockNum,
+		LastIrreversible: latestIrreversible,
+		Operations:       operations,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to spool block %d: %w", blockNum, err)
+	}
+	logger.Debug("Spooled operations", "block_num", blockNum, "ops", len(operations))
+	return nil
+}
+
+// replaySpool writes spooled blocks to MongoDB once it is reachable again
+// Notifications for replayed operations are sent at this point, subject to the catch-up policy
+func (s *Syncer) replaySpool(ctx context.Context) error {
+	if s.spool == nil || !s.spool.pending() || !s.storage.Healthy() {
+		return nil
+	}
+
+	logger.Info("MongoDB is available again, replaying spool", "block_num", s.spool.last())
+	var firstBlock, lastBlock int64
+	err := s.spool.replay(ctx, func(record spoolRecord) error {
+		models.SetSource(record.Operations, models.SourceReplay)
+		if err := s.storeBlock(ctx, record.BlockNum, record.Operations, record.LastIrreversible, s.retryStorage); err != nil {
+			return err
+		}
+		if firstBlock == 0 {
+			firstBlock = record.BlockNum
+		}
+		lastBlock = record.BlockNum
+		return nil
+	})
+
+	// Coverage could not be recorded while spooling
+	if lastBlock > 0 {
+		if err := s.storage.AddCoverage(ctx, s.config.Steem.Accounts, firstBlock, lastBlock); err != nil {
+			logger.Warn("Failed to record coverage", "start_block", firstBlock, "end_block", lastBlock, "error", err)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to replay spool: %w", err)
+	}
+
+	logger.Info("Spool replayed", "start_block", firstBlock, "end_block", lastBlock)
+	return nil
+}
@@ -0,0 +1,48 @@
+package sync
+
+import (
+	"context"
+	"time"
+)
+
+// storageCheckInterval is how often disk usage is compared with mongodb.storage_warn_mb
+const storageCheckInterval = time.Hour
+
+// checkStorage warns once when database disk usage crosses the configured threshold
+// The warning is re-armed when usage drops back below it
+func (s *Syncer) checkStorage(ctx context.Context) {
+	thresholdMB := s.config.MongoDB.StorageWarnMB
+	if thresholdMB <= 0 {
+		return
+	}
+
+	used, err := s.storage.DiskUsage(ctx)
+	if err != nil {
+		logger.Warn("Failed to check storage usage", "error", err)
+		return
+	}
+	usedMB := used / (1024 * 1024)
+
+	if usedMB < thresholdMB {
+		s.storageAlerted = false
+		return
+	}
+	if s.storageAlerted {
+		return
+	}
+
+	var growthMB int64
+	if report, err := s.storage.StorageReport(ctx); err == nil {
+		growthMB = report.Growth.BytesPerDay / (1024 * 1024)
+	}
+	logger.Warn("Database size above storage_warn_mb", "used_mb", usedMB, "threshold_mb", thresholdMB, "growth_mb_per_day", growthMB)
+
+	if s.telegram != nil {
+		message := s.telegram.Formatter().StorageAlert(usedMB, thresholdMB, growthMB)
+		if err := s.telegram.SendMessage(message); err != nil {
+			logger.Error("Failed to send storage alert", "error", err)
+			return
+		}
+	}
+	s.storageAlerted = true
+}
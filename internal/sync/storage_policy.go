@@ -0,0 +1,108 @@
+package sync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+)
+
+// contentFields are the op_data fields that storage policies drop or hash
+// Everything else (author, permlink, amounts, weights, ...) is always stored
+var contentFields = []string{"body", "json_metadata", "json", "memo"}
+
+// storagePolicy is a compiled models.StoragePolicy
+type storagePolicy struct {
+	policy   string
+	opTypes  map[string]bool
+	accounts map[string]bool // Empty matches all accounts
+}
+
+// SetStoragePolicies configures how much content is stored per operation type and account
+// The first matching policy wins; operations without one are stored in full
+func (bp *BlockProcessor) SetStoragePolicies(policies []models.StoragePolicy) {
+	bp.storagePolicies = nil
+	for _, policy := range policies {
+		switch policy.Policy {
+		case models.StoragePolicyFull, models.StoragePolicyMetadata, models.StoragePolicyHash:
+		default:
+			logger.Warn("Ignoring storage policy with unknown mode", "policy", policy.Policy, "op_types", policy.OpTypes)
+			continue
+		}
+
+		compiled := storagePolicy{policy: policy.Policy, opTypes: make(map[string]bool), accounts: make(map[string]bool)}
+		for _, opType := range policy.OpTypes {
+			compiled.opTypes[opType] = true
+		}
+		for _, account := range policy.Accounts {
+			compiled.accounts[account] = true
+		}
+		bp.storagePolicies = append(bp.storagePolicies, compiled)
+	}
+}
+
+// storagePolicyFor returns the storage policy of an operation
+func (bp *BlockProcessor) storagePolicyFor(op *models.Operation) string {
+	for _, policy := range bp.storagePolicies {
+		if policy.opTypes[op.OpType] && (len(policy.accounts) == 0 || policy.accounts[op.Account]) {
+			return policy.policy
+		}
+	}
+	return models.StoragePolicyFull
+}
+
+// ApplyStoragePolicy returns the operations as they should be stored
+// Operations whose policy reduces content are replaced by copies, so the originals keep
+// the full content for notifications and webhooks
+func (bp *BlockProcessor) ApplyStoragePolicy(operations []*models.Operation) []*models.Operation {
+	if len(bp.storagePolicies) == 0 {
+		return operations
+	}
+
+	stored := make([]*models.Operation, len(operations))
+	for i, op := range operations {
+		policy := bp.storagePolicyFor(op)
+		if policy == models.StoragePolicyFull {
+			stored[i] = op
+			continue
+		}
+		reduced := *op
+		reduced.OpData = reduceContent(op.OpData, policy)
+		reduced.StoragePolicy = policy
+		stored[i] = &reduced
+	}
+	return stored
+}
+
+// reduceContent returns a copy of opData with content fields dropped ("metadata") or
+// replaced by "sha256:<hex>" ("hash")
+func reduceContent(opData map[string]interface{}, policy string) map[string]interface{} {
+	reduced := make(map[string]interface{}, len(opData))
+	for key, value := range opData {
+		reduced[key] = value
+	}
+	for _, field := range contentFields {
+		value, ok := reduced[field]
+		if !ok {
+			continue
+		}
+		if policy == models.StoragePolicyHash {
+			reduced[field] = contentHash(value)
+		} else {
+			delete(reduced, field)
+		}
+	}
+	return reduced
+}
+
+// contentHash hashes a string as is and any other value as JSON
+func contentHash(value interface{}) string {
+	data, ok := value.(string)
+	if !ok {
+		encoded, _ := json.Marshal(value)
+		data = string(encoded)
+	}
+	sum := sha256.Sum256([]byte(data))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -3,48 +3,96 @@ package sync
 import (
 	"context"
 	"fmt"
-	"log"
+	"sync/atomic"
 	"time"
 
+	"github.com/ety001/sps-fund-watcher/internal/chain"
+	"github.com/ety001/sps-fund-watcher/internal/clock"
+	"github.com/ety001/sps-fund-watcher/internal/enrich"
+	"github.com/ety001/sps-fund-watcher/internal/logging"
 	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/plugin"
+	"github.com/ety001/sps-fund-watcher/internal/reporting"
 	"github.com/ety001/sps-fund-watcher/internal/storage"
 	"github.com/ety001/sps-fund-watcher/internal/telegram"
-	"github.com/steemit/steemgosdk"
+	"github.com/ety001/sps-fund-watcher/internal/webhook"
 )
 
+var logger = logging.Component("sync")
+
 // Syncer handles the synchronization process
 type Syncer struct {
-	steemAPI  *steemgosdk.API
+	steemAPI  chain.Client
 	storage   *storage.MongoDB
 	telegram  *telegram.Client
 	processor *BlockProcessor
 	config    *models.Config
 	stopChan  chan struct{}
+	paused    bool // Last observed sync pause switch, used to log transitions
+
+	lastMissingAccounts string         // Last reported set of nonexistent accounts
+	storageAlerted      bool           // Disk usage is above storage_warn_mb and was reported
+	activityStates      map[int]string // Reported state of each activity_alerts envelope, by index
+
+	webhooks           *webhook.Dispatcher
+	lastWebhookRefresh time.Time
+
+	plugins *plugin.Runner // Exec plugins, stopped on Close
+
+	lastViewRefresh time.Time
+
+	spool *spool // Optional on-disk buffer used while MongoDB is unreachable
+
+	leader *leaderElector // Set when leader election is enabled
+
+	clock clock.Clock // Time source of the sync loop (see SetClock)
 }
 
 // NewSyncer creates a new syncer
 func NewSyncer(config *models.Config) (*Syncer, error) {
-	// Initialize Steem client using steemgosdk
-	client := steemgosdk.GetClient(config.Steem.APIURL)
-	steemAPI := client.GetAPI()
+	// Initialize the node client selected by steem.client
+	steemAPI, err := chain.NewClient(config.Steem)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize chain client: %w", err)
+	}
 
 	// Initialize MongoDB storage
 	mongoStorage, err := storage.NewMongoDB(config.MongoDB.URI, config.MongoDB.Database)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize MongoDB: %w", err)
 	}
+	mongoStorage.SetSlowQueryThreshold(time.Duration(config.MongoDB.SlowQueryMS) * time.Millisecond)
+	mongoStorage.SetChain(config.Steem.Chain)
 
 	// Create indexes
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	if err := mongoStorage.CreateIndexes(ctx); err != nil {
-		log.Printf("Warning: failed to create indexes: %v", err)
+		logger.Warn("Failed to create indexes", "error", err)
 	}
 
+	return NewSyncerWith(config, steemAPI, mongoStorage)
+}
+
+// NewSyncerWith creates a syncer on an existing node client and storage, e.g. a chain.Fake in
+// tests; NewSyncer connects both from the config
+// Running the syncer needs storage; tests without a database pass nil and only fetch blocks and
+// match rules, since notifications, webhooks and sync state all write to storage
+func NewSyncerWith(config *models.Config, steemAPI chain.Client, mongoStorage *storage.MongoDB) (*Syncer, error) {
 	// Initialize Telegram client if enabled (using global config)
 	var tgClient *telegram.Client
 	if config.Telegram.Enabled && config.Telegram.BotToken != "" && config.Telegram.ChannelID != "" {
 		tgClient = telegram.NewClient(config.Telegram.BotToken, config.Telegram.ChannelID)
+		tgClient.SetParseMode(config.Telegram.ParseMode)
+		tgClient.SetMessageThreadID(config.Telegram.MessageThreadID)
+		if err := tgClient.SetProxy(config.Telegram.Proxy); err != nil {
+			return nil, fmt.Errorf("failed to configure Telegram proxy: %w", err)
+		}
+		blockURL, txURL, accountURL := config.Telegram.Explorer.URLs()
+		tgClient.SetExplorer(telegram.Explorer{BlockURL: blockURL, TxURL: txURL, AccountURL: accountURL})
+		tgClient.SetChainLabel(config.Steem.Chain)
+	} else if config.Telegram.Enabled {
+		logger.Warn("telegram.enabled is true but bot_token or channel_id is empty, notifications are disabled")
 	}
 
 	// Normalize Telegram config (convert old format to new format if needed)
@@ -58,95 +106,313 @@ func NewSyncer(config *models.Config) (*Syncer, error) {
 		config.Steem.Accounts,
 		config.Telegram.MessageTemplate, // Global fallback template
 	)
+	processor.SetCatchUpPolicy(
+		time.Duration(config.Telegram.MaxNotifyAgeMinutes)*time.Minute,
+		config.Telegram.StaleNotifyMode,
+	)
+
+	processor.SetRuleEvaluation(config.Telegram.RuleEvaluation)
+	processor.SetSamplingRules(config.Steem.Sampling)
+	processor.SetAccountPaths(config.Steem.AccountPaths)
+	processor.SetFallbackExtraction(config.Steem.FallbackExtraction)
+	processor.SetMentionDetection(config.Steem.DetectMentions)
+	processor.SetStoragePolicies(config.Steem.StoragePolicies)
+	enrichment, err := enrich.NewPipeline(config.Enrichment, steemAPI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize enrichment: %w", err)
+	}
+	processor.SetEnrichment(enrichment)
+	processor.SetFundEvents(enrich.NewFundEventDeriver(config.Enrichment.FundEvents))
+	plugins := plugin.NewRunner(config.Plugins)
+	processor.SetPlugins(plugins)
+
+	// Webhooks from config are active immediately; admin-registered ones are loaded by the sync loop
+	webhooks := webhook.NewDispatcher(mongoStorage)
+	webhooks.SetRetryPolicy(config.Retry.Webhooks)
+	webhooks.SetHooks(config.Webhooks)
+	processor.SetWebhookDispatcher(webhooks)
+
+	var blockSpool *spool
+	if config.MongoDB.SpoolDir != "" {
+		blockSpool, err = openSpool(config.MongoDB.SpoolDir, config.MongoDB.SpoolMaxMB)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open spool: %w", err)
+		}
+	}
 
-	return &Syncer{
+	var leader *leaderElector
+	if config.LeaderElection.Enabled {
+		leader = newLeaderElector(mongoStorage, config.LeaderElection)
+	}
+
+	s := &Syncer{
 		steemAPI:  steemAPI,
 		storage:   mongoStorage,
 		telegram:  tgClient,
 		processor: processor,
 		config:    config,
 		stopChan:  make(chan struct{}),
-	}, nil
+		webhooks:  webhooks,
+		plugins:   plugins,
+		spool:     blockSpool,
+		leader:    leader,
+		clock:     clock.Real,
+	}
+	if tgClient != nil {
+		// Channel messages that fail are stored and resent by the sync loop
+		tgClient.SetFailureHandler(s.savePendingNotification)
+	}
+	return s, nil
+}
+
+// SetClock replaces the time source of the sync loop, the block processor, webhook retries and
+// leader election, so their scheduling can be driven by a fake clock; call it before Start
+func (s *Syncer) SetClock(c clock.Clock) {
+	s.clock = c
+	s.processor.SetClock(c)
+	s.webhooks.SetClock(c)
+	if s.leader != nil {
+		s.leader.clock = c
+	}
 }
 
 // Start starts the synchronization process
+// With leader election it first stands by until this instance holds the sync lease,
+// and goes back to standby whenever the lease is lost
 func (s *Syncer) Start(ctx context.Context) error {
-	log.Println("[DEBUG] Starting sync service...")
-	log.Printf("[DEBUG] Configuration: API URL=%s, StartBlock=%d, BatchSize=%d, Accounts=%v",
-		s.config.Steem.APIURL, s.config.Steem.StartBlock, s.config.Steem.BatchSize, s.config.Steem.Accounts)
+	if s.leader == nil {
+		return s.run(ctx)
+	}
+	for {
+		if !s.leader.await(ctx, s.stopChan) {
+			return ctx.Err()
+		}
+
+		leaderCtx, cancel := context.WithCancel(ctx)
+		var lost atomic.Bool
+		go s.leader.keep(leaderCtx, func() {
+			lost.Store(true)
+			cancel()
+		})
+		err := s.run(leaderCtx)
+		cancel()
+		if !lost.Load() {
+			s.leader.release()
+			return err
+		}
+		logger.Warn("Lost the sync lease, returning to standby", "instance", s.leader.holder)
+	}
+}
+
+// run is the sync loop of the active instance
+func (s *Syncer) run(ctx context.Context) error {
+	logger.Info("Starting sync service", "chain", s.config.Steem.Chain, "api_url", s.config.Steem.APIURL, "start_block", s.config.Steem.StartBlock,
+		"batch_size", s.config.Steem.BatchSize, "sync_mode", s.config.Steem.SyncMode, "accounts", s.config.Steem.Accounts)
 
 	// Get current sync state
 	syncState, err := s.storage.GetSyncState(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get sync state: %w", err)
 	}
-	log.Printf("[DEBUG] Current sync state from DB: LastBlock=%d, LastIrreversibleBlock=%d, UpdatedAt=%v",
-		syncState.LastBlock, syncState.LastIrreversibleBlock, syncState.UpdatedAt)
+	if s.spool != nil && s.spool.last() > syncState.LastBlock {
+		syncState.LastBlock = s.spool.last()
+	}
+	logger.Debug("Current sync state", "block_num", syncState.LastBlock, "last_irreversible", syncState.LastIrreversibleBlock, "updated_at", syncState.UpdatedAt)
 
 	// Determine start block
 	startBlock := s.config.Steem.StartBlock
 	if syncState.LastBlock > 0 && syncState.LastBlock >= startBlock {
 		startBlock = syncState.LastBlock + 1
-		log.Printf("[DEBUG] Resuming from block %d (DB LastBlock=%d, Config StartBlock=%d)", startBlock, syncState.LastBlock, s.config.Steem.StartBlock)
+		logger.Info("Resuming from stored sync state", "block_num", startBlock, "last_block", syncState.LastBlock, "start_block", s.config.Steem.StartBlock)
 	} else {
-		log.Printf("[DEBUG] Starting from configured block %d (DB LastBlock=%d)", startBlock, syncState.LastBlock)
+		logger.Info("Starting from configured block", "block_num", startBlock, "last_block", syncState.LastBlock)
+	}
+
+	// Watch MongoDB connectivity; recovery closes the storage circuit breaker
+	s.storage.StartHealthMonitor(ctx, storageHealthInterval)
+	// Migrate operations stored by older versions while syncing
+	go s.migrateStorage(ctx)
+
+	// Answer bot commands while this instance is active
+	if s.telegram != nil && s.config.Telegram.Commands.Enabled {
+		commandCtx, stopCommands := context.WithCancel(ctx)
+		defer stopCommands()
+		go s.serveCommands(commandCtx)
 	}
 
+	// Verify configured accounts exist on-chain
+	s.checkAccounts()
+	// Warn when the database outgrows mongodb.storage_warn_mb
+	s.checkStorage(ctx)
+	// Delete operations past their retention period
+	s.pruneOperations(ctx)
+	// Record treasury balances (daily, or per balance check interval) and compare them with the stored operations
+	s.snapshotBalances(ctx, s.clock.Now())
+	s.checkBalances(ctx)
+	// Report last week's proposal payouts once a week and chart the treasury's outflows once a day
+	s.sendWeeklyReconciliation(ctx, s.clock.Now())
+	s.sendDailyChart(ctx, s.clock.Now())
+	// Alert on accounts that go silent or become hyperactive
+	s.checkActivity(ctx)
+	// Track receivers of funded proposals while their proposals run
+	s.checkAutoTrack(ctx)
+
+	// Repeat the checks above on their own goroutine, so a long catch-up doesn't hold them back
+	// The goroutine has ended when run returns, so a later run can't overlap with it
+	periodicCtx, stopPeriodic := context.WithCancel(ctx)
+	periodicDone := make(chan struct{})
+	defer func() {
+		stopPeriodic()
+		<-periodicDone
+	}()
+	go func() {
+		defer close(periodicDone)
+		s.runPeriodicChecks(periodicCtx)
+	}()
+
 	// Sync loop
-	ticker := time.NewTicker(3 * time.Second) // Check every 3 seconds
+	ticker := s.clock.NewTicker(3 * time.Second) // Check every 3 seconds
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
-			log.Println("Sync service stopped by context")
+			logger.Info("Sync service stopped by context")
 			return ctx.Err()
 		case <-s.stopChan:
-			log.Println("Sync service stopped")
+			logger.Info("Sync service stopped")
 			return nil
-		case <-ticker.C:
+		case <-ticker.C():
+			s.processor.FlushDigests(s.clock.Now(), false)
+			if s.applyControlState(ctx) {
+				continue
+			}
+			s.refreshWebhooks(ctx)
+			s.refreshViews(ctx)
+
+			// Write blocks spooled during a MongoDB outage before syncing new ones
+			if err := s.replaySpool(ctx); err != nil {
+				logger.Warn("Spool replay failed", "error", err)
+			}
+
 			// Get current sync state before each sync cycle to ensure we start from the correct block
 			currentState, err := s.storage.GetSyncState(ctx)
 			if err != nil {
-				log.Printf("[DEBUG] Error getting sync state: %v", err)
-				time.Sleep(5 * time.Second)
-				continue
+				if s.spool == nil || !s.spool.pending() {
+					logger.Warn("Failed to get sync state", "error", err)
+					clock.Sleep(ctx, s.clock, 5*time.Second)
+					continue
+				}
+				// MongoDB is down but blocks can go to the spool; continue from what it already holds
+				logger.Warn("Failed to get sync state, continuing from spool", "error", err)
+				currentState = &models.SyncState{}
+			}
+			if s.spool != nil && s.spool.last() > currentState.LastBlock {
+				currentState.LastBlock = s.spool.last()
 			}
-			log.Printf("[DEBUG] Sync cycle: Current DB state - LastBlock=%d, LastIrreversibleBlock=%d",
-				currentState.LastBlock, currentState.LastIrreversibleBlock)
 
 			// Determine the actual start block from database state
 			actualStartBlock := s.config.Steem.StartBlock
 			if currentState.LastBlock > 0 && currentState.LastBlock >= s.config.Steem.StartBlock {
 				actualStartBlock = currentState.LastBlock + 1
 			}
-			log.Printf("[DEBUG] Sync cycle: Calculated startBlock=%d (Config StartBlock=%d, DB LastBlock=%d)",
-				actualStartBlock, s.config.Steem.StartBlock, currentState.LastBlock)
+			logger.Debug("Sync cycle", "block_num", actualStartBlock, "last_block", currentState.LastBlock,
+				"last_irreversible", currentState.LastIrreversibleBlock, "start_block", s.config.Steem.StartBlock)
 
 			if err := s.syncBlocks(ctx, actualStartBlock); err != nil {
-				log.Printf("[DEBUG] Error syncing blocks: %v", err)
+				logger.Error("Failed to sync blocks", "block_num", actualStartBlock, "error", err)
+				reporting.Failure("sync", err, "block_num", actualStartBlock, "node_url", s.config.Steem.APIURL)
 				// Continue syncing despite errors
-				time.Sleep(5 * time.Second)
+				clock.Sleep(ctx, s.clock, 5*time.Second)
+				continue
 			}
+			reporting.Success("sync")
+		}
+	}
+}
+
+// migrateStorage runs the data migrations, which take long on large collections and resume where
+// they stopped on the next start
+func (s *Syncer) migrateStorage(ctx context.Context) {
+	defer reporting.Recover()
+	if err := s.storage.MigrateOperationTimestamps(ctx); err != nil && ctx.Err() == nil {
+		logger.Warn("Failed to migrate operation timestamps", "error", err)
+	}
+}
+
+// runPeriodicChecks runs the account, storage, retention, balance, report, activity,
+// auto-tracking and resend checks on their tickers until ctx is cancelled
+// It runs beside the sync loop, which can spend hours in one syncBlocks call while catching up;
+// the checks only share state with each other or through the processor's atomic fields
+func (s *Syncer) runPeriodicChecks(ctx context.Context) {
+	defer reporting.Recover()
+
+	accountTicker := s.clock.NewTicker(s.accountCheckInterval())
+	defer accountTicker.Stop()
+	storageTicker := s.clock.NewTicker(storageCheckInterval)
+	defer storageTicker.Stop()
+	pruneTicker := s.clock.NewTicker(s.pruneInterval())
+	defer pruneTicker.Stop()
+	balanceTicker := s.clock.NewTicker(s.balanceTickInterval())
+	defer balanceTicker.Stop()
+	reconcileTicker := s.clock.NewTicker(reconciliationCheckInterval)
+	defer reconcileTicker.Stop()
+	activityTicker := s.clock.NewTicker(activityCheckInterval)
+	defer activityTicker.Stop()
+	autoTrackTicker := s.clock.NewTicker(autoTrackInterval)
+	defer autoTrackTicker.Stop()
+	pendingTicker := s.clock.NewTicker(pendingCheckInterval)
+	defer pendingTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-accountTicker.C():
+			s.checkAccounts()
+		case <-storageTicker.C():
+			s.checkStorage(ctx)
+		case <-pruneTicker.C():
+			s.pruneOperations(ctx)
+		case <-balanceTicker.C():
+			s.snapshotBalances(ctx, s.clock.Now())
+			s.checkBalances(ctx)
+		case <-reconcileTicker.C():
+			s.sendWeeklyReconciliation(ctx, s.clock.Now())
+			s.sendDailyChart(ctx, s.clock.Now())
+		case <-activityTicker.C():
+			s.checkActivity(ctx)
+		case <-autoTrackTicker.C():
+			s.checkAutoTrack(ctx)
+		case <-pendingTicker.C():
+			s.resendPending(ctx)
 		}
 	}
 }
 
 // syncBlocks syncs blocks from startBlock to latest irreversible block
 func (s *Syncer) syncBlocks(ctx context.Context, startBlock int64) error {
-	log.Printf("[DEBUG] syncBlocks called with startBlock=%d", startBlock)
-
 	// Get latest irreversible block
 	dgp, err := s.steemAPI.GetDynamicGlobalProperties()
 	if err != nil {
 		return fmt.Errorf("failed to get dynamic global properties: %w", err)
 	}
 	latestIrreversible := int64(dgp.LastIrreversibleBlockNum)
-	log.Printf("[DEBUG] Latest irreversible block: %d", latestIrreversible)
+	logger.Debug("Latest irreversible block", "last_irreversible", latestIrreversible)
+
+	// In head mode we follow the head block and reconcile blocks that became irreversible
+	targetBlock := latestIrreversible
+	if s.headMode() {
+		if err := s.reconcileReversibleBlocks(ctx, latestIrreversible); err != nil {
+			return fmt.Errorf("failed to reconcile reversible blocks: %w", err)
+		}
+		targetBlock = int64(dgp.HeadBlockNumber)
+		logger.Debug("Head mode", "head_block", targetBlock)
+	}
 
-	if startBlock > latestIrreversible {
+	if startBlock > targetBlock {
 		// No new blocks to sync
-		log.Printf("[DEBUG] No new blocks to sync (startBlock=%d > latestIrreversible=%d)", startBlock, latestIrreversible)
+		logger.Debug("No new blocks to sync", "block_num", startBlock, "target_block", targetBlock)
 		return nil
 	}
 
@@ -155,94 +421,141 @@ func (s *Syncer) syncBlocks(ctx context.Context, startBlock int64) error {
 	if batchSize <= 0 {
 		batchSize = 10 // Default batch size
 	}
-	log.Printf("[DEBUG] Using batchSize=%d", batchSize)
-	currentBlock := startBlock
+	workers := s.config.Steem.FetchWorkers
+	if workers <= 0 {
+		workers = 1 // Sequential by default
+	}
+	logger.Debug("Syncing in batches", "batch_size", batchSize, "fetch_workers", workers)
 	lastSyncedBlock := startBlock - 1
 
-	for currentBlock <= latestIrreversible {
-		// Process batch
-		endBlock := currentBlock + batchSize - 1
-		if endBlock > latestIrreversible {
-			endBlock = latestIrreversible
+	// Batches are fetched concurrently but committed here strictly in block order
+	fetchCtx, cancelFetch := context.WithCancel(ctx)
+	defer cancelFetch()
+
+	for batch := range s.fetchBatches(fetchCtx, startBlock, targetBlock, batchSize, workers) {
+		if batch.err != nil {
+			return batch.err
 		}
-		log.Printf("[DEBUG] Processing batch: blocks %d to %d (total %d blocks)", currentBlock, endBlock, endBlock-currentBlock+1)
 
-		// Get all operations (both regular and virtual) in batch using GetOpsInBlocks
-		// This is more efficient than calling GetBlocks + GetOpsInBlocks separately
-		log.Printf("[DEBUG] Calling GetOpsInBlocks(%d, %d, onlyVirtual=false)", currentBlock, endBlock+1)
-		opsMap, err := s.steemAPI.GetOpsInBlocks(uint(currentBlock), uint(endBlock+1), false)
-		if err != nil {
-			return fmt.Errorf("failed to get operations for blocks %d to %d: %w", currentBlock, endBlock, err)
+		// Stop between batches if an operator paused syncing; progress is already persisted
+		if s.applyControlState(ctx) {
+			logger.Info("Sync paused", "block_num", lastSyncedBlock)
+			return nil
 		}
-		log.Printf("[DEBUG] GetOpsInBlocks returned operations for %d blocks", len(opsMap))
 
-		// Process each block in the batch
-		for i := currentBlock; i <= endBlock; i++ {
-			blockNum := int64(i)
-			log.Printf("[DEBUG] Processing block %d in batch", blockNum)
+		logger.Debug("Committing batch", "start_block", batch.startBlock, "end_block", batch.endBlock)
 
+		// Commit each block in the batch
+		for blockNum := batch.startBlock; blockNum <= batch.endBlock; blockNum++ {
 			// Check current state before processing to avoid processing blocks we've already synced
 			currentState, err := s.storage.GetSyncState(ctx)
 			if err != nil {
-				log.Printf("[DEBUG] Warning: failed to get sync state before processing block %d: %v", blockNum, err)
+				logger.Warn("Failed to get sync state before processing block", "block_num", blockNum, "error", err)
 			} else {
 				if blockNum <= currentState.LastBlock {
-					log.Printf("[DEBUG] Skipping block %d: already synced (current LastBlock=%d)", blockNum, currentState.LastBlock)
+					logger.Debug("Skipping already synced block", "block_num", blockNum, "last_block", currentState.LastBlock)
 					lastSyncedBlock = blockNum
 					continue
 				}
 			}
 
-			// Process all operations (regular + virtual) for this block
-			var operations []*models.Operation
-			if ops, ok := opsMap[uint(blockNum)]; ok && len(ops) > 0 {
-				operations, err = s.processor.ProcessOperations(ctx, ops)
-				if err != nil {
-					return fmt.Errorf("failed to process operations for block %d: %w", blockNum, err)
-				}
-				log.Printf("[DEBUG] Block %d: extracted %d operations (regular + virtual)", blockNum, len(operations))
-			} else {
-				log.Printf("[DEBUG] Block %d: no operations found", blockNum)
-			}
-
-			lastSyncedBlock = blockNum
+			// Operations (regular + virtual) extracted for this block
+			operations := batch.operations[blockNum]
 
-			// Save operations (this will also send Telegram notifications if enabled)
-			if len(operations) > 0 {
-				log.Printf("[DEBUG] Saving %d operations (regular + virtual) for block %d", len(operations), blockNum)
-				if err := s.processor.SaveOperations(ctx, operations); err != nil {
-					return fmt.Errorf("failed to save operations for block %d: %w", blockNum, err)
+			// Reversible blocks are stored as unconfirmed until reconciled
+			if blockNum > latestIrreversible {
+				if err := s.trackReversibleBlock(ctx, blockNum, batch.trxIDs[blockNum], operations); err != nil {
+					return err
 				}
-				log.Printf("[DEBUG] Successfully saved operations for block %d", blockNum)
 			}
 
-			// Update sync state
-			// Uses atomic $max operator to ensure last_block only increases (no transactions needed)
-			log.Printf("[DEBUG] Updating sync state for block %d (lastSyncedBlock=%d, latestIrreversible=%d)",
-				blockNum, lastSyncedBlock, latestIrreversible)
-			if err := s.storage.UpdateSyncState(ctx, lastSyncedBlock, latestIrreversible); err != nil {
-				return fmt.Errorf("failed to update sync state for block %d: %w", blockNum, err)
+			if err := s.commitBlock(ctx, blockNum, operations, latestIrreversible); err != nil {
+				return err
 			}
-			log.Printf("[DEBUG] Successfully updated sync state for block %d", blockNum)
+			lastSyncedBlock = blockNum
 
 			if len(operations) > 0 {
-				log.Printf("[INFO] Block %d: saved %d operations", blockNum, len(operations))
+				logger.Info("Saved operations", "block_num", blockNum, "ops", len(operations))
 			} else {
-				log.Printf("[DEBUG] Block %d: no operations to save", blockNum)
+				logger.Debug("No operations to save", "block_num", blockNum)
 			}
 		}
 
-		currentBlock = endBlock + 1
-		log.Printf("[DEBUG] Batch completed. Next currentBlock=%d", currentBlock)
+		// Record scanned ranges so the compensator's -auto mode can find gaps
+		if err := s.storage.AddCoverage(ctx, s.config.Steem.Accounts, batch.startBlock, batch.endBlock); err != nil {
+			logger.Warn("Failed to record coverage", "start_block", batch.startBlock, "end_block", batch.endBlock, "error", err)
+		}
 
-		// Small delay to avoid overwhelming the API
-		time.Sleep(100 * time.Millisecond)
+		logger.Debug("Batch completed", "start_block", batch.startBlock, "end_block", batch.endBlock)
 	}
 
-	log.Printf("[INFO] Synced blocks %d to %d", startBlock, lastSyncedBlock)
+	// A cancelled context ends delivery early without an error batch
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Caught up: summarize whatever was held back during catch-up
+	s.processor.FlushCatchUpDigest()
+
+	logger.Info("Synced blocks", "start_block", startBlock, "end_block", lastSyncedBlock)
 	return nil
 }
 
+// applyControlState reads the operator control state and applies it
+// Returns true when block processing is paused
+func (s *Syncer) applyControlState(ctx context.Context) bool {
+	state, err := s.storage.GetControlState(ctx)
+	if err != nil {
+		logger.Warn("Failed to read control state", "error", err)
+		return s.paused
+	}
+
+	s.processor.SetNotificationsPaused(state.NotificationsPaused)
+
+	if state.SyncPaused != s.paused {
+		if state.SyncPaused {
+			logger.Info("Block processing paused by operator", "reason", state.Reason)
+		} else {
+			logger.Info("Block processing resumed by operator")
+		}
+		s.paused = state.SyncPaused
+	}
+
+	return s.paused
+}
+
+// refreshWebhooks reloads webhooks registered through the admin API (at most every 30 seconds)
+func (s *Syncer) refreshWebhooks(ctx context.Context) {
+	if clock.Since(s.clock, s.lastWebhookRefresh) < 30*time.Second {
+		return
+	}
+	s.lastWebhookRefresh = s.clock.Now()
+
+	registered, err := s.storage.ListWebhooks(ctx)
+	if err != nil {
+		logger.Warn("Failed to load webhooks", "error", err)
+		return
+	}
+
+	hooks := append(append([]models.Webhook{}, s.config.Webhooks...), registered...)
+	s.webhooks.SetHooks(hooks)
+}
+
+// refreshViews reloads notification-bound saved views (at most every 30 seconds)
+func (s *Syncer) refreshViews(ctx context.Context) {
+	if clock.Since(s.clock, s.lastViewRefresh) < 30*time.Second {
+		return
+	}
+	s.lastViewRefresh = s.clock.Now()
+
+	views, err := s.storage.ListViews(ctx)
+	if err != nil {
+		logger.Warn("Failed to load views", "error", err)
+		return
+	}
+	s.processor.SetBoundViews(views)
+}
+
 // Stop stops the syncer
 func (s *Syncer) Stop() {
 	close(s.stopChan)
@@ -250,5 +563,9 @@ func (s *Syncer) Stop() {
 
 // Close closes all connections
 func (s *Syncer) Close() error {
+	// Don't lose partially collected digests on shutdown
+	s.processor.FlushDigests(s.clock.Now(), true)
+	s.webhooks.Close()
+	s.plugins.Close()
 	return s.storage.Close()
 }
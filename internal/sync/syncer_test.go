@@ -0,0 +1,129 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ety001/sps-fund-watcher/internal/chain"
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	protocolapi "github.com/steemit/steemutil/protocol/api"
+)
+
+// transferOp renders a transfer as get_ops_in_block returns it
+func transferOp(blockNum int, trxID, from, to, amount string) string {
+	return fmt.Sprintf(`{"trx_id":%q,"block":%d,"trx_in_block":0,"op_in_trx":0,"virtual_op":0,"timestamp":"2024-01-01T00:00:00","op":["transfer",{"from":%q,"to":%q,"amount":%q,"memo":""}]}`,
+		trxID, blockNum, from, to, amount)
+}
+
+// newTestSyncer creates a syncer without storage on a fake node
+func newTestSyncer(t *testing.T, node *chain.Fake, configure func(*models.Config)) *Syncer {
+	t.Helper()
+	config := &models.Config{}
+	config.Steem.Accounts = []string{"alice"}
+	if configure != nil {
+		configure(config)
+	}
+	s, err := NewSyncerWith(config, node, nil)
+	if err != nil {
+		t.Fatalf("NewSyncerWith: %v", err)
+	}
+	return s
+}
+
+func TestFetchBatch(t *testing.T) {
+	node := chain.NewFake()
+	node.SetBlock(10, &protocolapi.Block{BlockId: "0000000a", Witness: "w1", TransactionIds: []string{"t1"}},
+		"["+transferOp(10, "t1", "alice", "bob", "1.000 STEEM")+"]")
+	node.SetBlock(11, &protocolapi.Block{BlockId: "0000000b", Witness: "w2", TransactionIds: []string{"t2"}},
+		"["+transferOp(11, "t2", "carol", "dave", "2.000 STEEM")+"]")
+	node.SetBlock(12, &protocolapi.Block{BlockId: "0000000c", Witness: "w3", TransactionIds: []string{"t3", "t4"}},
+		"["+transferOp(12, "t3", "bob", "alice", "3.000 SBD")+","+transferOp(12, "t4", "alice", "carol", "4.000 STEEM")+"]")
+
+	tests := []struct {
+		name     string
+		blockNum int64
+		want     []string // account:trx_id of the extracted operations
+		blockID  string
+		witness  string
+	}{
+		{name: "sender is tracked", blockNum: 10, want: []string{"alice:t1"}, blockID: "0000000a", witness: "w1"},
+		{name: "untracked accounts only", blockNum: 11},
+		{name: "several operations", blockNum: 12, want: []string{"alice:t3", "alice:t4"}, blockID: "0000000c", witness: "w3"},
+	}
+
+	batch := newTestSyncer(t, node, nil).fetchBatch(context.Background(), 10, 12)
+	if batch.err != nil {
+		t.Fatalf("fetchBatch: %v", batch.err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, op := range batch.operations[tt.blockNum] {
+				got = append(got, op.Account+":"+op.TrxID)
+				if op.BlockID != tt.blockID || op.Witness != tt.witness {
+					t.Errorf("header = %s/%s, want %s/%s", op.BlockID, op.Witness, tt.blockID, tt.witness)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("operations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if batch.trxIDs != nil {
+		t.Errorf("trxIDs recorded outside head mode: %v", batch.trxIDs)
+	}
+}
+
+func TestTrackReversibleBlockRejectsFork(t *testing.T) {
+	node := chain.NewFake()
+	node.SetBlock(20, &protocolapi.Block{BlockId: "00000014", Witness: "w1", TransactionIds: []string{"t1"}},
+		"["+transferOp(20, "t1", "alice", "bob", "1.000 STEEM")+"]")
+
+	s := newTestSyncer(t, node, func(config *models.Config) {
+		config.Steem.SyncMode = models.SyncModeHead
+	})
+	batch := s.fetchBatch(context.Background(), 20, 20)
+	if batch.err != nil {
+		t.Fatalf("fetchBatch: %v", batch.err)
+	}
+	if got := batch.trxIDs[20]; len(got) != 1 || got[0] != "t1" {
+		t.Fatalf("trxIDs = %v, want [t1]", got)
+	}
+
+	// The node switches to a fork whose block 20 carries other transactions
+	node.SetBlock(20, &protocolapi.Block{BlockId: "00000014f", Witness: "w2", TransactionIds: []string{"t9"}},
+		"["+transferOp(20, "t9", "alice", "carol", "5.000 STEEM")+"]")
+
+	operations := batch.operations[20]
+	err := s.trackReversibleBlock(context.Background(), 20, batch.trxIDs[20], operations)
+	if err == nil || !strings.Contains(err.Error(), "changed while it was being fetched") {
+		t.Fatalf("trackReversibleBlock error = %v, want a fork error", err)
+	}
+	if operations[0].Unconfirmed || operations[0].BlockID != "00000014" {
+		t.Errorf("operations changed by a rejected block: unconfirmed=%v block_id=%s", operations[0].Unconfirmed, operations[0].BlockID)
+	}
+}
+
+func TestSameTransactions(t *testing.T) {
+	tests := []struct {
+		name   string
+		block  []string
+		trxIDs []string
+		want   bool
+	}{
+		{name: "empty block", want: true},
+		{name: "same order", block: []string{"a", "b"}, trxIDs: []string{"a", "b"}, want: true},
+		{name: "other order", block: []string{"b", "a"}, trxIDs: []string{"a", "b"}, want: true},
+		{name: "missing transaction", block: []string{"a", "b"}, trxIDs: []string{"a"}},
+		{name: "other transaction", block: []string{"a", "c"}, trxIDs: []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sameTransactions(tt.block, tt.trxIDs); got != tt.want {
+				t.Errorf("sameTransactions(%v, %v) = %v, want %v", tt.block, tt.trxIDs, got, tt.want)
+			}
+		})
+	}
+}
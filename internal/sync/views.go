@@ -0,0 +1,103 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+)
+
+// boundView is a saved view with notifications enabled and its compiled query
+type boundView struct {
+	view  models.SavedView
+	query storage.OperationQuery
+}
+
+// SetBoundViews replaces the saved views that send Telegram alerts
+// Views without notify set, or whose query no longer compiles, are skipped
+func (bp *BlockProcessor) SetBoundViews(views []models.SavedView) {
+	var bound []boundView
+	for _, view := range views {
+		if !view.Notify {
+			continue
+		}
+		query, err := storage.ViewQuery(&view)
+		if err != nil {
+			notifyLogger.Warn("Skipping view", "view", view.Name, "error", err)
+			continue
+		}
+		bound = append(bound, boundView{view: view, query: query})
+	}
+
+	bp.viewsMu.Lock()
+	bp.boundViews = bound
+	bp.viewsMu.Unlock()
+}
+
+// notifyBoundViews sends alerts for stored operations matched by notification-bound views
+// Matching runs in MongoDB so views behave exactly like the API endpoint
+func (bp *BlockProcessor) notifyBoundViews(ctx context.Context, operations []*models.Operation) {
+	if bp.telegramClient == nil || bp.notificationsPaused.Load() || len(operations) == 0 {
+		return
+	}
+
+	bp.viewsMu.RLock()
+	views := bp.boundViews
+	bp.viewsMu.RUnlock()
+	if len(views) == 0 {
+		return
+	}
+
+	// Stale operations are never alerted individually, see SetCatchUpPolicy
+	var cutoff time.Time
+	if bp.maxNotifyAge > 0 {
+		cutoff = bp.clock.Now().Add(-bp.maxNotifyAge)
+	}
+
+	stored := make(map[string]bool, len(operations))
+	blocks := make(map[int64]bool)
+	var blockNums []int64
+	for _, op := range operations {
+		if op.Timestamp.Before(cutoff) {
+			continue
+		}
+		stored[operationKey(op)] = true
+		if !blocks[op.BlockNum] {
+			blocks[op.BlockNum] = true
+			blockNums = append(blockNums, op.BlockNum)
+		}
+	}
+	if len(blockNums) == 0 {
+		return
+	}
+
+	formatter := bp.telegramClient.Formatter()
+	for _, bound := range views {
+		matches, err := bp.storage.MatchOperationsInBlocks(ctx, bound.query, blockNums)
+		if err != nil {
+			notifyLogger.Warn("Failed to evaluate view", "view", bound.view.Name, "error", err)
+			continue
+		}
+
+		for i := range matches {
+			op := &matches[i]
+			// Only operations from this batch; earlier ones were already handled
+			if !stored[operationKey(op)] {
+				continue
+			}
+
+			message := fmt.Sprintf("%s\n", formatter.Bold("👁 View: "+bound.view.Name)) +
+				formatter.OperationMessage(op.Account, op.OpType, op.OpData, op.BlockNum, op.Timestamp)
+			if err := bp.telegramClient.SendMessage(message); err != nil {
+				notifyLogger.Error("Failed to send Telegram notification", "view", bound.view.Name, "account", op.Account, "block_num", op.BlockNum, "error", err)
+			}
+		}
+	}
+}
+
+// operationKey identifies a stored operation
+func operationKey(op *models.Operation) string {
+	return fmt.Sprintf("%s/%d/%s", op.TrxID, op.OpInTrx, op.Account)
+}
@@ -0,0 +1,72 @@
+package telegram
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// InlineKeyboardMarkup is the reply_markup of a message with inline buttons
+type InlineKeyboardMarkup struct {
+	InlineKeyboard [][]InlineButton `json:"inline_keyboard"`
+}
+
+// InlineButton is an inline keyboard button opening a URL
+type InlineButton struct {
+	Text string `json:"text"`
+	URL  string `json:"url"`
+}
+
+// Explorer builds links to a block explorer from URL templates with the placeholders
+// {block}, {trx_id} and {account}; a template left empty drops its button
+type Explorer struct {
+	BlockURL   string
+	TxURL      string
+	AccountURL string
+}
+
+// BlockButtons returns the button linking to a block
+func (e Explorer) BlockButtons(blockNum int64) []InlineButton {
+	return e.Buttons(blockNum, "", "")
+}
+
+// Buttons returns the buttons linking to the block, transaction and account of an operation
+// Virtual operations have no transaction of their own, so they get no transaction button
+func (e Explorer) Buttons(blockNum int64, trxID, account string) []InlineButton {
+	var buttons []InlineButton
+	if e.BlockURL != "" && blockNum > 0 {
+		buttons = append(buttons, InlineButton{
+			Text: "Block " + strconv.FormatInt(blockNum, 10),
+			URL:  e.expand(e.BlockURL, blockNum, trxID, account),
+		})
+	}
+	if e.TxURL != "" && isTransactionID(trxID) {
+		buttons = append(buttons, InlineButton{Text: "Transaction", URL: e.expand(e.TxURL, blockNum, trxID, account)})
+	}
+	if e.AccountURL != "" && account != "" {
+		buttons = append(buttons, InlineButton{Text: "@" + account, URL: e.expand(e.AccountURL, blockNum, trxID, account)})
+	}
+	return buttons
+}
+
+func (e Explorer) expand(template string, blockNum int64, trxID, account string) string {
+	return strings.NewReplacer(
+		"{block}", strconv.FormatInt(blockNum, 10),
+		"{trx_id}", url.PathEscape(trxID),
+		"{account}", url.PathEscape(account),
+	).Replace(template)
+}
+
+// isTransactionID reports whether id is a real transaction ID rather than the all-zero ID of
+// virtual operations or a synthetic "virtual_..." identifier
+func isTransactionID(id string) bool {
+	if len(id) != 40 || strings.Trim(id, "0") == "" {
+		return false
+	}
+	for _, r := range id {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
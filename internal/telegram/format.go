@@ -0,0 +1,617 @@
+package telegram
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Supported Telegram parse modes
+const (
+	ParseModeHTML       = "HTML"
+	ParseModeMarkdownV2 = "MarkdownV2"
+)
+
+// Formatter renders notification messages for a Telegram parse mode
+type Formatter struct {
+	parseMode string
+}
+
+// NewFormatter creates a formatter for the given parse mode (HTML when empty or unknown)
+func NewFormatter(parseMode string) Formatter {
+	if strings.EqualFold(parseMode, ParseModeMarkdownV2) {
+		return Formatter{parseMode: ParseModeMarkdownV2}
+	}
+	return Formatter{parseMode: ParseModeHTML}
+}
+
+// ParseMode returns the Telegram parse mode the formatter renders for
+func (f Formatter) ParseMode() string {
+	return f.parseMode
+}
+
+// Escape escapes text so it is rendered literally
+func (f Formatter) Escape(s string) string {
+	if f.parseMode == ParseModeMarkdownV2 {
+		return EscapeMarkdownV2(s)
+	}
+	return escapeHTML(s)
+}
+
+// Bold renders escaped bold text
+func (f Formatter) Bold(s string) string {
+	if f.parseMode == ParseModeMarkdownV2 {
+		return "*" + EscapeMarkdownV2(s) + "*"
+	}
+	return "<b>" + escapeHTML(s) + "</b>"
+}
+
+// Code renders escaped inline code
+func (f Formatter) Code(s string) string {
+	if f.parseMode == ParseModeMarkdownV2 {
+		return "`" + EscapeMarkdownV2Code(s) + "`"
+	}
+	return "<code>" + escapeHTML(s) + "</code>"
+}
+
+// OperationMessage formats an operation as a Telegram message
+func (f Formatter) OperationMessage(account, opType string, opData map[string]interface{}, blockNum int64, timestamp time.Time) string {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "%s\n\n", f.Bold("🔔 New Operation"))
+	fmt.Fprintf(&builder, "%s %s\n", f.Bold("Account:"), f.Code(account))
+	fmt.Fprintf(&builder, "%s %s\n", f.Bold("Type:"), f.Code(opType))
+	fmt.Fprintf(&builder, "%s %s\n", f.Bold("Block:"), f.Code(fmt.Sprintf("%d", blockNum)))
+	fmt.Fprintf(&builder, "%s %s\n\n", f.Bold("Time:"), f.Code(timestamp.Format("2006-01-02 15:04:05 UTC")))
+
+	// Format operation-specific data
+	fmt.Fprintf(&builder, "%s\n", f.Bold("Details:"))
+	builder.WriteString(f.details(opData))
+
+	return builder.String()
+}
+
+// OperationMessageWithTemplate formats an operation using a custom template
+// Template variables:
+//   - {{.Account}} - Account name
+//   - {{.OpType}} - Operation type
+//   - {{.BlockNum}} - Block number
+//   - {{.Timestamp}} - Timestamp (formatted as "2006-01-02 15:04:05 UTC")
+//   - {{.Details}} - Operation details (formatted as key: value pairs)
+//
+// The template itself must be written in the formatter's parse mode; substituted values are escaped
+func (f Formatter) OperationMessageWithTemplate(template string, account, opType string, opData map[string]interface{}, blockNum int64, timestamp time.Time) string {
+	details := f.details(opData)
+	if details == "" {
+		details = f.Escape("  (no details)")
+	}
+
+	// Replace template variables
+	result := template
+	result = strings.ReplaceAll(result, "{{.Account}}", f.Escape(account))
+	result = strings.ReplaceAll(result, "{{.OpType}}", f.Escape(opType))
+	result = strings.ReplaceAll(result, "{{.BlockNum}}", fmt.Sprintf("%d", blockNum))
+	result = strings.ReplaceAll(result, "{{.Timestamp}}", f.Escape(timestamp.Format("2006-01-02 15:04:05 UTC")))
+	result = strings.ReplaceAll(result, "{{.Details}}", details)
+
+	return result
+}
+
+// details formats operation data as a bullet list of key: value pairs
+func (f Formatter) details(opData map[string]interface{}) string {
+	var builder strings.Builder
+	for key, value := range opData {
+		// Skip internal fields
+		if key == "memo" || key == "json_metadata" {
+			continue
+		}
+		valueStr := fmt.Sprintf("%v", value)
+		if len(valueStr) > 100 {
+			valueStr = valueStr[:100] + "..."
+		}
+		fmt.Fprintf(&builder, "  %s %s %s\n", f.Escape("•"), f.Bold(key+":"), f.Code(valueStr))
+	}
+	return builder.String()
+}
+
+// CatchUpDigest formats a summary of operations that were stored without
+// individual notifications while the watcher was catching up
+func (f Formatter) CatchUpDigest(counts map[string]int, total int, fromBlock, toBlock int64) string {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "%s\n\n", f.Bold("⏪ Catch-up Summary"))
+	fmt.Fprintf(&builder, "%s %s %s %s %s\n\n",
+		f.Bold(fmt.Sprintf("%d", total)),
+		f.Escape("operations in blocks"),
+		f.Code(fmt.Sprintf("%d", fromBlock)),
+		f.Escape("-"),
+		f.Code(fmt.Sprintf("%d", toBlock))+f.Escape(" were stored without individual notifications."))
+
+	opTypes := make([]string, 0, len(counts))
+	for opType := range counts {
+		opTypes = append(opTypes, opType)
+	}
+	sort.Strings(opTypes)
+
+	for _, opType := range opTypes {
+		fmt.Fprintf(&builder, "  %s %s %s\n", f.Escape("•"), f.Bold(opType+":"), f.Code(fmt.Sprintf("%d", counts[opType])))
+	}
+
+	return builder.String()
+}
+
+// DigestSummary aggregates the operations matched by a rule over a digest window
+type DigestSummary struct {
+	Rule    string
+	From    time.Time
+	To      time.Time
+	Total   int
+	Counts  map[string]int     // Operations per type
+	Amounts map[string]float64 // Total moved amount per asset symbol
+	Notable []DigestItem       // Largest operations by amount
+}
+
+// DigestItem is a single notable operation listed in a digest
+type DigestItem struct {
+	Account  string
+	OpType   string
+	Amount   string
+	BlockNum int64
+}
+
+// Digest formats a periodic summary of matched operations
+func (f Formatter) Digest(summary DigestSummary) string {
+	var builder strings.Builder
+
+	title := "📊 Digest"
+	if summary.Rule != "" {
+		title += ": " + summary.Rule
+	}
+	fmt.Fprintf(&builder, "%s\n", f.Bold(title))
+	fmt.Fprintf(&builder, "%s\n\n", f.Escape(fmt.Sprintf("%s - %s",
+		summary.From.UTC().Format("2006-01-02 15:04"), summary.To.UTC().Format("2006-01-02 15:04 UTC"))))
+	fmt.Fprintf(&builder, "%s %s\n\n", f.Bold(fmt.Sprintf("%d", summary.Total)), f.Escape("matched operations"))
+
+	opTypes := make([]string, 0, len(summary.Counts))
+	for opType := range summary.Counts {
+		opTypes = append(opTypes, opType)
+	}
+	sort.Strings(opTypes)
+
+	fmt.Fprintf(&builder, "%s\n", f.Bold("By type:"))
+	for _, opType := range opTypes {
+		fmt.Fprintf(&builder, "  %s %s %s\n", f.Escape("•"), f.Bold(opType+":"), f.Code(fmt.Sprintf("%d", summary.Counts[opType])))
+	}
+
+	if len(summary.Amounts) > 0 {
+		symbols := make([]string, 0, len(summary.Amounts))
+		for symbol := range summary.Amounts {
+			symbols = append(symbols, symbol)
+		}
+		sort.Strings(symbols)
+
+		fmt.Fprintf(&builder, "\n%s\n", f.Bold("Total amounts:"))
+		for _, symbol := range symbols {
+			fmt.Fprintf(&builder, "  %s %s\n", f.Escape("•"), f.Code(fmt.Sprintf("%.3f %s", summary.Amounts[symbol], symbol)))
+		}
+	}
+
+	if len(summary.Notable) > 0 {
+		fmt.Fprintf(&builder, "\n%s\n", f.Bold("Largest:"))
+		for _, item := range summary.Notable {
+			fmt.Fprintf(&builder, "  %s %s %s %s %s\n", f.Escape("•"), f.Code(item.Amount),
+				f.Escape(item.OpType+" /"), f.Code(item.Account), f.Escape(fmt.Sprintf("(block %d)", item.BlockNum)))
+		}
+	}
+
+	return builder.String()
+}
+
+// BulkSummary aggregates the operations of one block matched by a rule
+type BulkSummary struct {
+	Rule      string
+	BlockNum  int64
+	Timestamp time.Time
+	Total     int
+	Counts    map[string]int                // Operations per type
+	Amounts   map[string]map[string]float64 // Total moved amount per type and asset symbol
+}
+
+// BulkDigest formats a single message for a block that matched a rule many times, e.g. mass payouts
+func (f Formatter) BulkDigest(summary BulkSummary) string {
+	var builder strings.Builder
+
+	title := "📦 Bulk Activity"
+	if summary.Rule != "" {
+		title += ": " + summary.Rule
+	}
+	fmt.Fprintf(&builder, "%s\n", f.Bold(title))
+	fmt.Fprintf(&builder, "%s %s %s\n\n", f.Escape("Block"), f.Code(fmt.Sprintf("%d", summary.BlockNum)),
+		f.Escape(fmt.Sprintf("(%s)", summary.Timestamp.UTC().Format("2006-01-02 15:04:05 UTC"))))
+	fmt.Fprintf(&builder, "%s %s\n\n", f.Bold(fmt.Sprintf("%d", summary.Total)), f.Escape("matched operations"))
+
+	opTypes := make([]string, 0, len(summary.Counts))
+	for opType := range summary.Counts {
+		opTypes = append(opTypes, opType)
+	}
+	sort.Strings(opTypes)
+
+	for _, opType := range opTypes {
+		fmt.Fprintf(&builder, "  %s %s %s\n", f.Escape("•"), f.Bold(opType+":"), f.Code(fmt.Sprintf("%d", summary.Counts[opType])))
+
+		amounts := summary.Amounts[opType]
+		symbols := make([]string, 0, len(amounts))
+		for symbol := range amounts {
+			symbols = append(symbols, symbol)
+		}
+		sort.Strings(symbols)
+		for _, symbol := range symbols {
+			fmt.Fprintf(&builder, "      %s %s\n", f.Escape("total"), f.Code(fmt.Sprintf("%.3f %s", amounts[symbol], symbol)))
+		}
+	}
+
+	return builder.String()
+}
+
+// fundEventTitles are the message headings of fund event kinds
+var fundEventTitles = map[string]string{
+	"payout_made":       "💸 Proposal Payout",
+	"proposal_funded":   "✅ Proposal Funded",
+	"proposal_defunded": "⛔ Proposal Defunded",
+	"key_changed":       "🔑 Keys Changed",
+	"large_withdrawal":  "🚨 Large Withdrawal",
+}
+
+// FundEventMessage formats a derived fund event
+func (f Formatter) FundEventMessage(kind, account, summary string, blockNum int64, timestamp time.Time) string {
+	title, ok := fundEventTitles[kind]
+	if !ok {
+		title = "📌 " + kind
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%s\n\n", f.Bold(title))
+	fmt.Fprintf(&builder, "%s\n\n", f.Escape(summary))
+	fmt.Fprintf(&builder, "%s %s\n", f.Bold("Account:"), f.Code(account))
+	fmt.Fprintf(&builder, "%s %s\n", f.Bold("Block:"), f.Code(fmt.Sprintf("%d", blockNum)))
+	fmt.Fprintf(&builder, "%s %s\n", f.Bold("Time:"), f.Code(timestamp.Format("2006-01-02 15:04:05 UTC")))
+	return builder.String()
+}
+
+// MissingAccountsAlert formats an alert about configured accounts that don't exist on-chain
+func (f Formatter) MissingAccountsAlert(missing []string) string {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "%s\n\n", f.Bold("⚠️ Configured Accounts Not Found"))
+	builder.WriteString(f.Escape("The following accounts do not exist on-chain and are not being watched. Please check the configuration for typos:"))
+	builder.WriteString("\n\n")
+	for _, account := range missing {
+		fmt.Fprintf(&builder, "  %s %s\n", f.Escape("•"), f.Code(account))
+	}
+
+	return builder.String()
+}
+
+// StorageAlert formats a warning that database disk usage crossed the configured threshold
+func (f Formatter) StorageAlert(usedMB, thresholdMB, growthMBPerDay int64) string {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "%s\n\n", f.Bold("💾 Storage Threshold Exceeded"))
+	fmt.Fprintf(&builder, "%s %s\n", f.Bold("Used:"), f.Code(fmt.Sprintf("%d MB", usedMB)))
+	fmt.Fprintf(&builder, "%s %s\n", f.Bold("Threshold:"), f.Code(fmt.Sprintf("%d MB", thresholdMB)))
+	fmt.Fprintf(&builder, "%s %s\n\n", f.Bold("Growth:"), f.Code(fmt.Sprintf("~%d MB/day", growthMBPerDay)))
+	builder.WriteString(f.Escape("Consider pruning old data, enabling sampling for noisy accounts or adding disk space. See GET /api/v1/admin/storage for details."))
+
+	return builder.String()
+}
+
+// ActivityAlert describes an account whose daily activity left its expected envelope
+type ActivityAlert struct {
+	Account   string
+	OpTypes   []string // Empty means all operation types
+	Count     int64    // Operations in the last 24 hours
+	MinPerDay int64
+	MaxPerDay int64
+}
+
+// ActivityAlert formats a warning that an account went silent or became hyperactive
+func (f Formatter) ActivityAlert(alert ActivityAlert) string {
+	var builder strings.Builder
+
+	title, hint := "🔇 Account Went Silent", "This may mean a lost key or a stopped service."
+	if alert.MaxPerDay > 0 && alert.Count > alert.MaxPerDay {
+		title, hint = "🚨 Account Unusually Active", "This may mean the account is compromised."
+	}
+	opTypes := "all"
+	if len(alert.OpTypes) > 0 {
+		opTypes = strings.Join(alert.OpTypes, ", ")
+	}
+	expected := fmt.Sprintf("%d-%d", alert.MinPerDay, alert.MaxPerDay)
+	switch {
+	case alert.MaxPerDay == 0:
+		expected = fmt.Sprintf("at least %d", alert.MinPerDay)
+	case alert.MinPerDay == 0:
+		expected = fmt.Sprintf("at most %d", alert.MaxPerDay)
+	}
+
+	fmt.Fprintf(&builder, "%s\n\n", f.Bold(title))
+	fmt.Fprintf(&builder, "%s %s\n", f.Bold("Account:"), f.Code(alert.Account))
+	fmt.Fprintf(&builder, "%s %s\n", f.Bold("Operations:"), f.Escape(opTypes))
+	fmt.Fprintf(&builder, "%s %s\n", f.Bold("Last 24h:"), f.Code(fmt.Sprint(alert.Count)))
+	fmt.Fprintf(&builder, "%s %s\n\n", f.Bold("Expected:"), f.Code(expected+" per day"))
+	builder.WriteString(f.Escape(hint))
+
+	return builder.String()
+}
+
+// AutoTrackNotice describes a proposal receiver that started or stopped being tracked automatically
+type AutoTrackNotice struct {
+	Account   string
+	Started   bool
+	Proposals []string // "#id subject" of the proposals paying it
+	Until     time.Time
+}
+
+// AutoTrackNotice formats the start or end of the automatic tracking of a proposal receiver
+func (f Formatter) AutoTrackNotice(notice AutoTrackNotice) string {
+	var builder strings.Builder
+
+	if notice.Started {
+		fmt.Fprintf(&builder, "%s\n\n", f.Bold("👀 Tracking Proposal Receiver"))
+	} else {
+		fmt.Fprintf(&builder, "%s\n\n", f.Bold("👋 Stopped Tracking Proposal Receiver"))
+	}
+	fmt.Fprintf(&builder, "%s %s\n", f.Bold("Account:"), f.Code(notice.Account))
+	for _, proposal := range notice.Proposals {
+		fmt.Fprintf(&builder, "%s %s\n", f.Bold("Proposal:"), f.Escape(proposal))
+	}
+	if notice.Started {
+		fmt.Fprintf(&builder, "%s %s\n\n", f.Bold("Until:"), f.Code(notice.Until.UTC().Format("2006-01-02 15:04 UTC")))
+		builder.WriteString(f.Escape("The receiver of a funded proposal is not in steem.accounts; its operations are stored while the proposal runs."))
+	} else {
+		fmt.Fprintf(&builder, "%s %s\n\n", f.Bold("Ended:"), f.Code(notice.Until.UTC().Format("2006-01-02 15:04 UTC")))
+		builder.WriteString(f.Escape("Its proposals ended; operations stored so far are kept."))
+	}
+
+	return builder.String()
+}
+
+// maxReconciliationItems is the number of discrepancies listed in a reconciliation report
+const maxReconciliationItems = 20
+
+// ReconciliationSummary is the outcome of a proposal payout reconciliation
+type ReconciliationSummary struct {
+	From          time.Time
+	To            time.Time // Exclusive
+	Checked       int       // Receiver-days compared
+	Discrepancies []ReconciliationItem
+	Runway        string // Optional treasury runway projection
+}
+
+// ReconciliationItem is a receiver-day whose payouts did not match daily_pay
+type ReconciliationItem struct {
+	Date     string
+	Receiver string
+	Status   string
+	Expected string
+	Paid     string
+}
+
+// ProposalReconciliation formats a proposal payout reconciliation report
+func (f Formatter) ProposalReconciliation(summary ReconciliationSummary) string {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "%s\n", f.Bold("🧾 Proposal Payout Reconciliation"))
+	fmt.Fprintf(&builder, "%s\n\n", f.Escape(fmt.Sprintf("%s - %s",
+		summary.From.UTC().Format("2006-01-02"), summary.To.UTC().AddDate(0, 0, -1).Format("2006-01-02"))))
+	fmt.Fprintf(&builder, "%s %s\n", f.Bold("Checked:"), f.Code(fmt.Sprintf("%d receiver-days", summary.Checked)))
+	fmt.Fprintf(&builder, "%s %s\n", f.Bold("Discrepancies:"), f.Code(fmt.Sprintf("%d", len(summary.Discrepancies))))
+	if summary.Runway != "" {
+		fmt.Fprintf(&builder, "%s %s\n", f.Bold("Runway:"), f.Escape(summary.Runway))
+	}
+
+	if len(summary.Discrepancies) == 0 {
+		builder.WriteString("\n")
+		builder.WriteString(f.Escape("All payouts match the daily pay of the active proposals."))
+		return builder.String()
+	}
+
+	builder.WriteString("\n")
+	for i, item := range summary.Discrepancies {
+		if i == maxReconciliationItems {
+			fmt.Fprintf(&builder, "  %s\n", f.Escape(fmt.Sprintf("… and %d more", len(summary.Discrepancies)-i)))
+			break
+		}
+		fmt.Fprintf(&builder, "  %s %s %s %s %s %s %s\n", f.Escape("• "+item.Date), f.Code(item.Receiver), f.Bold(item.Status),
+			f.Escape("expected"), f.Code(item.Expected), f.Escape("paid"), f.Code(item.Paid))
+	}
+	builder.WriteString("\n")
+	builder.WriteString(f.Escape("See GET /api/v1/reconciliation/proposals for details."))
+
+	return builder.String()
+}
+
+// DailyFlowsSummary is the caption of the daily treasury outflow chart
+type DailyFlowsSummary struct {
+	Date            string // Last day shown, YYYY-MM-DD
+	Days            int
+	Symbol          string
+	ProposalPayouts float64 // Of Date
+	OtherOutflows   float64 // Of Date
+	Inflows         float64 // Of Date, inflation and donations
+	Total           float64 // Outflows of all days shown
+}
+
+// DailyFlows formats the caption of the daily treasury outflow chart
+func (f Formatter) DailyFlows(summary DailyFlowsSummary) string {
+	var builder strings.Builder
+
+	amount := func(v float64) string { return f.Code(fmt.Sprintf("%.3f %s", v, summary.Symbol)) }
+	fmt.Fprintf(&builder, "%s\n", f.Bold("📊 Treasury Outflows"))
+	fmt.Fprintf(&builder, "%s\n\n", f.Escape(summary.Date))
+	fmt.Fprintf(&builder, "%s %s\n", f.Bold("Proposal payouts:"), amount(summary.ProposalPayouts))
+	fmt.Fprintf(&builder, "%s %s\n", f.Bold("Other outflows:"), amount(summary.OtherOutflows))
+	fmt.Fprintf(&builder, "%s %s\n", f.Bold("Inflows:"), amount(summary.Inflows))
+	fmt.Fprintf(&builder, "%s %s", f.Bold(fmt.Sprintf("Outflows, last %d days:", summary.Days)), amount(summary.Total))
+
+	return builder.String()
+}
+
+// BalanceMismatchAlert describes treasury balance changes not explained by the stored operations
+type BalanceMismatchAlert struct {
+	Account    string
+	StartBlock int64
+	EndBlock   int64
+	Changes    []BalanceMismatchItem
+	Repair     string // Scheduled compensator job, empty if scheduling failed
+}
+
+// BalanceMismatchItem compares the chain and stored balance change of one asset
+type BalanceMismatchItem struct {
+	Chain  string
+	Stored string
+}
+
+// BalanceMismatch formats an alert that an account's balance moved without matching stored operations
+func (f Formatter) BalanceMismatch(alert BalanceMismatchAlert) string {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "%s\n\n", f.Bold("⚖️ Unexplained Balance Change"))
+	fmt.Fprintf(&builder, "%s %s\n", f.Bold("Account:"), f.Code(alert.Account))
+	fmt.Fprintf(&builder, "%s %s\n", f.Bold("Blocks:"), f.Code(fmt.Sprintf("%d - %d", alert.StartBlock, alert.EndBlock)))
+	for _, change := range alert.Changes {
+		fmt.Fprintf(&builder, "  %s %s %s %s\n", f.Escape("• chain"), f.Code(change.Chain), f.Escape("stored"), f.Code(change.Stored))
+	}
+	builder.WriteString("\n")
+	if alert.Repair == "" {
+		builder.WriteString(f.Escape("Blocks or virtual operations may have been missed. Scheduling a repair failed; run the compensator over the range."))
+		return builder.String()
+	}
+	builder.WriteString(f.Escape("Blocks or virtual operations may have been missed. A repair was scheduled as job "))
+	builder.WriteString(f.Code(alert.Repair))
+	builder.WriteString(f.Escape("; run compensator -scheduled to fill it."))
+
+	return builder.String()
+}
+
+// CommandOperation is a stored operation listed in reply to /last
+type CommandOperation struct {
+	OpType    string
+	From      string
+	To        string
+	Amount    string
+	BlockNum  int64
+	Timestamp time.Time
+}
+
+// LastOperations formats the reply to /last: the latest stored operations of an account
+func (f Formatter) LastOperations(account string, ops []CommandOperation) string {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "%s %s\n\n", f.Bold("🕒 Latest operations of"), f.Code(account))
+	if len(ops) == 0 {
+		builder.WriteString(f.Escape("No operations stored."))
+		return builder.String()
+	}
+	for _, op := range ops {
+		fmt.Fprintf(&builder, "%s %s %s", f.Escape("•"), f.Code(op.Timestamp.UTC().Format("2006-01-02 15:04")), f.Bold(op.OpType))
+		if op.From != "" || op.To != "" {
+			fmt.Fprintf(&builder, " %s", f.Escape(strings.TrimSpace(op.From+" → "+op.To)))
+		}
+		if op.Amount != "" {
+			fmt.Fprintf(&builder, " %s", f.Code(op.Amount))
+		}
+		fmt.Fprintf(&builder, " %s\n", f.Escape(fmt.Sprintf("(block %d)", op.BlockNum)))
+	}
+	return builder.String()
+}
+
+// AccountBalance formats the reply to /balance
+func (f Formatter) AccountBalance(account, steem, sbd string) string {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "%s %s\n\n", f.Bold("💰 Balance of"), f.Code(account))
+	fmt.Fprintf(&builder, "%s %s\n", f.Bold("STEEM:"), f.Code(steem))
+	fmt.Fprintf(&builder, "%s %s\n", f.Bold("SBD:"), f.Code(sbd))
+	return builder.String()
+}
+
+// SyncStatus is the sync state reported in reply to /status
+type SyncStatus struct {
+	Version             string
+	LastBlock           int64
+	Lag                 int64 // Blocks behind the node; -1 when the node is unreachable
+	UpdatedAt           time.Time
+	SyncPaused          bool
+	NotificationsPaused bool
+	Accounts            int
+}
+
+// Status formats the reply to /status
+func (f Formatter) Status(status SyncStatus) string {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "%s\n\n", f.Bold("📡 Watcher Status"))
+	fmt.Fprintf(&builder, "%s %s\n", f.Bold("Last block:"), f.Code(fmt.Sprintf("%d", status.LastBlock)))
+	lag := "node unreachable"
+	if status.Lag >= 0 {
+		lag = fmt.Sprintf("%d blocks", status.Lag)
+	}
+	fmt.Fprintf(&builder, "%s %s\n", f.Bold("Behind:"), f.Code(lag))
+	fmt.Fprintf(&builder, "%s %s\n", f.Bold("Updated:"), f.Code(status.UpdatedAt.UTC().Format("2006-01-02 15:04:05 UTC")))
+	fmt.Fprintf(&builder, "%s %s\n", f.Bold("Accounts:"), f.Code(fmt.Sprintf("%d", status.Accounts)))
+	if status.SyncPaused {
+		fmt.Fprintf(&builder, "%s\n", f.Escape("⏸ Sync is paused"))
+	}
+	if status.NotificationsPaused {
+		fmt.Fprintf(&builder, "%s\n", f.Escape("🔕 Notifications are paused"))
+	}
+	fmt.Fprintf(&builder, "%s %s\n", f.Bold("Version:"), f.Code(status.Version))
+	return builder.String()
+}
+
+// CommandHelp formats the list of bot commands
+func (f Formatter) CommandHelp() string {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "%s\n\n", f.Bold("Commands"))
+	for _, line := range [][2]string{
+		{"/last <account> [n]", "latest stored operations (default 5, at most 20)"},
+		{"/balance <account>", "liquid STEEM and SBD balance"},
+		{"/status", "sync progress"},
+		{"/resend", "resend notifications that failed to send"},
+	} {
+		fmt.Fprintf(&builder, "%s %s\n", f.Code(line[0]), f.Escape("- "+line[1]))
+	}
+	return builder.String()
+}
+
+// markdownV2Special lists characters that must be escaped in MarkdownV2 text
+const markdownV2Special = "_*[]()~`>#+-=|{}.!\\"
+
+// EscapeMarkdownV2 escapes text for Telegram MarkdownV2 outside of code entities
+func EscapeMarkdownV2(s string) string {
+	var builder strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(markdownV2Special, r) {
+			builder.WriteByte('\\')
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
+
+// EscapeMarkdownV2Code escapes text for Telegram MarkdownV2 inside pre and code entities
+func EscapeMarkdownV2Code(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	return strings.ReplaceAll(s, "`", "\\`")
+}
+
+// escapeHTML escapes HTML special characters
+func escapeHTML(s string) string {
+	s = strings.ReplaceAll(s, "&", "&amp;")
+	s = strings.ReplaceAll(s, "<", "&lt;")
+	s = strings.ReplaceAll(s, ">", "&gt;")
+	return s
+}
@@ -0,0 +1,78 @@
+package telegram
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Update is an incoming update from getUpdates; only messages are requested
+type Update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *Message `json:"message,omitempty"`
+}
+
+// Message is a chat message sent to the bot
+type Message struct {
+	MessageID int64  `json:"message_id"`
+	ThreadID  int64  `json:"message_thread_id,omitempty"` // Forum topic the message was sent in
+	Date      int64  `json:"date"`                        // Unix time
+	Chat      Chat   `json:"chat"`
+	From      *User  `json:"from,omitempty"`
+	Text      string `json:"text"`
+}
+
+// Chat is the chat a message was sent in
+type Chat struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"`
+}
+
+// User is the sender of a message
+type User struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username,omitempty"`
+}
+
+// ChatID returns the chat ID in the form used by configuration and sendMessage
+func (m *Message) ChatID() string {
+	return strconv.FormatInt(m.Chat.ID, 10)
+}
+
+// Time returns when the message was sent
+func (m *Message) Time() time.Time {
+	return time.Unix(m.Date, 0)
+}
+
+type getUpdatesRequest struct {
+	Offset         int64    `json:"offset,omitempty"`
+	Timeout        int      `json:"timeout"`
+	AllowedUpdates []string `json:"allowed_updates"`
+}
+
+// GetUpdates long-polls for messages after offset, waiting up to timeout for one to arrive
+// Passing the last seen update ID + 1 as offset confirms the earlier updates
+func (c *Client) GetUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]Update, error) {
+	// The regular client's timeout is shorter than a long poll
+	poll := &http.Client{Timeout: timeout + 10*time.Second, Transport: c.httpClient.Transport}
+	req := getUpdatesRequest{Offset: offset, Timeout: int(timeout / time.Second), AllowedUpdates: []string{"message"}}
+
+	var updates []Update
+	if err := c.call(ctx, poll, "getUpdates", req, &updates); err != nil {
+		return nil, err
+	}
+	return updates, nil
+}
+
+// ParseCommand splits a command message like "/last@watcher_bot steem.dao 5" into its name
+// ("last") and arguments; ok is false for messages that are not commands
+func ParseCommand(text string) (name string, args []string, ok bool) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return "", nil, false
+	}
+	name, _, _ = strings.Cut(strings.TrimPrefix(fields[0], "/"), "@")
+	return strings.ToLower(name), fields[1:], name != ""
+}
@@ -0,0 +1,50 @@
+package version
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+)
+
+// Build information, injected at build time via ldflags:
+//
+//	go build -ldflags "-X github.com/ety001/sps-fund-watcher/internal/version.Version=v1.2.3 \
+//	  -X github.com/ety001/sps-fund-watcher/internal/version.Commit=$(git rev-parse --short HEAD) \
+//	  -X github.com/ety001/sps-fund-watcher/internal/version.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+// Info represents build information exposed by the API
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build information of the running binary
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+}
+
+// String returns a one-line description of the binary, e.g. for --version
+func String(component string) string {
+	return fmt.Sprintf("sps-fund-watcher %s %s (commit %s, built %s, %s)",
+		component, Version, Commit, BuildTime, runtime.Version())
+}
+
+// LogBanner logs the startup banner followed by the effective configuration summary
+func LogBanner(component string, configSummary []string) {
+	log.Printf("Starting %s", String(component))
+	for _, line := range configSummary {
+		log.Printf("  %s", line)
+	}
+}
@@ -0,0 +1,297 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/clock"
+	"github.com/ety001/sps-fund-watcher/internal/logging"
+	"github.com/ety001/sps-fund-watcher/internal/models"
+	"github.com/ety001/sps-fund-watcher/internal/storage"
+)
+
+const (
+	// SignatureHeader carries the hex HMAC-SHA256 of the request body, prefixed with "sha256="
+	SignatureHeader = "X-Signature-256"
+	// EventHeader carries the event type of the payload
+	EventHeader = "X-Webhook-Event"
+
+	queueSize = 1000 // Per webhook
+)
+
+var logger = logging.Component("webhook")
+
+// delivery is a single payload queued for a webhook
+type delivery struct {
+	hook models.Webhook
+	body []byte
+}
+
+// failedDelivery is a delivery waiting to be dead-lettered
+type failedDelivery struct {
+	delivery
+	attempts int
+	cause    error
+}
+
+// Dispatcher delivers matched operations to webhook endpoints in the background
+// Each webhook has its own queue and worker, so retries of a failing endpoint only hold up
+// that endpoint. Failed deliveries are retried under the retry.webhooks policy and then
+// dead-lettered in MongoDB
+type Dispatcher struct {
+	storage    *storage.MongoDB
+	httpClient *http.Client
+
+	mu      sync.RWMutex
+	hooks   []models.Webhook
+	queues  map[string]chan delivery // By webhook name
+	failed  chan failedDelivery      // Deliveries Dispatch found no room for
+	closed  bool
+	workers sync.WaitGroup
+
+	// insertDeadLetter records a dead letter; tests replace it
+	insertDeadLetter func(ctx context.Context, deadLetter *models.WebhookDeadLetter) error
+
+	clock  clock.Clock // Time source of the retry backoff
+	policy models.RetryPolicy
+}
+
+// NewDispatcher creates a dispatcher; delivery workers start with SetHooks
+func NewDispatcher(storage *storage.MongoDB) *Dispatcher {
+	d := &Dispatcher{
+		storage: storage,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		queues:           make(map[string]chan delivery),
+		failed:           make(chan failedDelivery, queueSize),
+		insertDeadLetter: storage.InsertWebhookDeadLetter,
+		clock:            clock.Real,
+		policy:           models.DefaultWebhookRetry,
+	}
+	d.workers.Add(1)
+	go d.recordFailed()
+	return d
+}
+
+// SetClock replaces the time source of the retry backoff; call it before the first Dispatch
+func (d *Dispatcher) SetClock(c clock.Clock) {
+	d.clock = c
+}
+
+// SetRetryPolicy sets how failed deliveries are retried; call it before the first Dispatch
+func (d *Dispatcher) SetRetryPolicy(policy models.RetryPolicy) {
+	d.policy = policy
+}
+
+// SetHooks replaces the set of active webhooks, starting a worker for each new one
+// Workers of removed webhooks finish their queued deliveries and exit
+func (d *Dispatcher) SetHooks(hooks []models.Webhook) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return
+	}
+	d.hooks = hooks
+
+	active := make(map[string]bool, len(hooks))
+	for _, hook := range hooks {
+		active[hook.Name] = true
+		if _, ok := d.queues[hook.Name]; !ok {
+			queue := make(chan delivery, queueSize)
+			d.queues[hook.Name] = queue
+			d.workers.Add(1)
+			go d.run(queue)
+		}
+	}
+	for name, queue := range d.queues {
+		if !active[name] {
+			close(queue)
+			delete(d.queues, name)
+		}
+	}
+}
+
+// Dispatch queues matched operations for every webhook they match
+// It never blocks: if a webhook's queue is full the delivery is handed to the background
+// dead-letter writer, or dropped with an error log when that is full too
+func (d *Dispatcher) Dispatch(operations []*models.Operation) {
+	// Held while sending, so SetHooks and Close don't close a queue under us
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return
+	}
+
+	for _, hook := range d.hooks {
+		for _, op := range operations {
+			if !Matches(hook, op) {
+				continue
+			}
+
+			body, err := json.Marshal(models.WebhookPayload{
+				Event:     "operation",
+				Webhook:   hook.Name,
+				Operation: op,
+				SentAt:    d.clock.Now().UTC(),
+			})
+			if err != nil {
+				logger.Error("Failed to marshal webhook payload", "webhook", hook.Name, "account", op.Account, "block_num", op.BlockNum, "error", err)
+				continue
+			}
+
+			item := delivery{hook: hook, body: body}
+			select {
+			case d.queues[hook.Name] <- item:
+			default:
+				select {
+				case d.failed <- failedDelivery{delivery: item, cause: fmt.Errorf("delivery queue full")}:
+				default:
+					logger.Error("Dropping webhook delivery, dead-letter queue full", "webhook", hook.Name, "account", op.Account, "block_num", op.BlockNum)
+				}
+			}
+		}
+	}
+}
+
+// Close stops accepting deliveries and waits for queued ones to finish
+func (d *Dispatcher) Close() {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return
+	}
+	d.closed = true
+	for name, queue := range d.queues {
+		close(queue)
+		delete(d.queues, name)
+	}
+	close(d.failed)
+	d.mu.Unlock()
+
+	d.workers.Wait()
+}
+
+// Matches reports whether an operation matches a webhook's account and operation filters
+func Matches(hook models.Webhook, op *models.Operation) bool {
+	return matchesList(hook.Accounts, op.Account) && matchesList(hook.NotifyOperations, op.OpType)
+}
+
+// matchesList reports whether value is in list; an empty list matches everything
+func matchesList(list []string, value string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign returns the signature header value for a body and secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// run delivers the queued payloads of one webhook one at a time, in order
+func (d *Dispatcher) run(queue <-chan delivery) {
+	defer d.workers.Done()
+	for item := range queue {
+		d.deliver(item)
+	}
+}
+
+// recordFailed dead-letters the deliveries Dispatch had no room for, off the sync path
+func (d *Dispatcher) recordFailed() {
+	defer d.workers.Done()
+	for item := range d.failed {
+		d.deadLetter(item.hook, item.body, item.attempts, item.cause)
+	}
+}
+
+// deliver posts a payload, retrying with the policy's backoff before giving it up
+func (d *Dispatcher) deliver(item delivery) {
+	maxAttempts := item.hook.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = d.policy.MaxAttempts
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = models.DefaultWebhookRetry.MaxAttempts
+	}
+
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if err = d.post(item.hook, item.body); err == nil {
+			return
+		}
+		logger.Warn("Webhook delivery attempt failed", "webhook", item.hook.Name, "attempt", attempt, "max_attempts", maxAttempts, "error", err)
+		if attempt < maxAttempts {
+			<-d.clock.After(d.policy.Backoff(attempt, rand.Float64()))
+		}
+	}
+
+	d.deadLetter(item.hook, item.body, maxAttempts, err)
+}
+
+// post sends a single signed request
+func (d *Dispatcher) post(hook models.Webhook, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, "operation")
+	if hook.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(hook.Secret, body))
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// deadLetter records a delivery that could not be completed, or only logs it when the policy
+// drops deliveries that give up
+func (d *Dispatcher) deadLetter(hook models.Webhook, body []byte, attempts int, cause error) {
+	if !d.policy.DeadLetters() {
+		logger.Warn("Dropping webhook delivery", "webhook", hook.Name, "attempts", attempts, "error", cause)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := d.insertDeadLetter(ctx, &models.WebhookDeadLetter{
+		Webhook:   hook.Name,
+		URL:       hook.URL,
+		Payload:   string(body),
+		Attempts:  attempts,
+		LastError: cause.Error(),
+		FailedAt:  d.clock.Now(),
+	})
+	if err != nil {
+		logger.Error("Failed to record webhook dead letter", "webhook", hook.Name, "error", err)
+	}
+}
@@ -0,0 +1,309 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ety001/sps-fund-watcher/internal/clock"
+	"github.com/ety001/sps-fund-watcher/internal/models"
+)
+
+// endpoint is a test webhook endpoint answering with the statuses in order, then 200
+type endpoint struct {
+	*httptest.Server
+	statuses []int
+	release  chan struct{} // When set, requests wait for it to close
+
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newEndpoint(t *testing.T, statuses ...int) *endpoint {
+	e := &endpoint{statuses: statuses}
+	e.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if e.release != nil {
+			<-e.release
+		}
+		body, _ := io.ReadAll(r.Body)
+		e.mu.Lock()
+		status := http.StatusOK
+		if n := len(e.requests); n < len(e.statuses) {
+			status = e.statuses[n]
+		}
+		e.requests = append(e.requests, r)
+		e.bodies = append(e.bodies, body)
+		e.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(e.Close)
+	return e
+}
+
+func (e *endpoint) count() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.requests)
+}
+
+// deadLetters collects the dead letters a dispatcher records
+type deadLetters struct {
+	mu      sync.Mutex
+	letters []*models.WebhookDeadLetter
+}
+
+func (l *deadLetters) insert(_ context.Context, deadLetter *models.WebhookDeadLetter) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.letters = append(l.letters, deadLetter)
+	return nil
+}
+
+func (l *deadLetters) all() []*models.WebhookDeadLetter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]*models.WebhookDeadLetter(nil), l.letters...)
+}
+
+// newTestDispatcher creates a dispatcher on a fake clock that records dead letters in memory
+func newTestDispatcher(t *testing.T, policy models.RetryPolicy) (*Dispatcher, *clock.Fake, *deadLetters) {
+	letters := &deadLetters{}
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	d := NewDispatcher(nil)
+	d.insertDeadLetter = letters.insert
+	d.SetClock(fake)
+	d.SetRetryPolicy(policy)
+	t.Cleanup(func() {
+		// Workers still retrying wait for the clock, so it runs on until they are done
+		closed := make(chan struct{})
+		go func() {
+			d.Close()
+			close(closed)
+		}()
+		for {
+			select {
+			case <-closed:
+				return
+			case <-time.After(time.Millisecond):
+				fake.Advance(time.Hour)
+			}
+		}
+	})
+	return d, fake, letters
+}
+
+// waitFor polls cond until it holds or a few seconds passed
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func transfer(account string) *models.Operation {
+	return &models.Operation{Account: account, OpType: "transfer", BlockNum: 7, TrxID: "t1",
+		OpData: map[string]interface{}{"from": account, "to": "bob", "amount": "1.000 SBD"}}
+}
+
+var testPolicy = models.RetryPolicy{MaxAttempts: 3, BackoffSeconds: 10, Multiplier: 2}
+
+func TestDeliverySigned(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret string
+	}{
+		{name: "with secret", secret: "shared-secret"},
+		{name: "without secret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newEndpoint(t)
+			d, _, _ := newTestDispatcher(t, testPolicy)
+			d.SetHooks([]models.Webhook{{Name: "hook", URL: server.URL, Secret: tt.secret}})
+
+			d.Dispatch([]*models.Operation{transfer("alice")})
+			waitFor(t, "the delivery", func() bool { return server.count() == 1 })
+
+			server.mu.Lock()
+			req, body := server.requests[0], server.bodies[0]
+			server.mu.Unlock()
+			if got := req.Header.Get(EventHeader); got != "operation" {
+				t.Errorf("%s = %q, want operation", EventHeader, got)
+			}
+			want := ""
+			if tt.secret != "" {
+				want = Sign(tt.secret, body)
+			}
+			if got := req.Header.Get(SignatureHeader); got != want {
+				t.Errorf("%s = %q, want %q", SignatureHeader, got, want)
+			}
+			var payload models.WebhookPayload
+			if err := json.Unmarshal(body, &payload); err != nil {
+				t.Fatalf("invalid payload: %v", err)
+			}
+			if payload.Webhook != "hook" || payload.Operation.Account != "alice" || payload.Operation.TrxID != "t1" {
+				t.Errorf("payload = %+v", payload)
+			}
+		})
+	}
+}
+
+func TestDeliveryRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		maxAttempts  int
+		wantRequests int
+		wantError    string // Of the dead letter; empty when delivered
+	}{
+		{name: "first attempt succeeds", wantRequests: 1},
+		{name: "succeeds on retry", statuses: []int{500, 502}, wantRequests: 3},
+		{name: "dead-lettered after the policy's attempts", statuses: []int{500, 500, 503}, wantRequests: 3, wantError: "unexpected status 503"},
+		{name: "webhook's own attempts", statuses: []int{500, 500}, maxAttempts: 2, wantRequests: 2, wantError: "unexpected status 500"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newEndpoint(t, tt.statuses...)
+			d, fake, letters := newTestDispatcher(t, testPolicy)
+			d.SetHooks([]models.Webhook{{Name: "hook", URL: server.URL, MaxAttempts: tt.maxAttempts}})
+
+			d.Dispatch([]*models.Operation{transfer("alice")})
+			for attempt := 1; attempt < tt.wantRequests; attempt++ {
+				// Each retry waits for the backoff on the clock
+				waitFor(t, "the backoff", func() bool { return fake.Waiters() == 1 })
+				if got := server.count(); got != attempt {
+					t.Fatalf("%d requests before backoff %d, want %d", got, attempt, attempt)
+				}
+				fake.Advance(testPolicy.Backoff(attempt, 0.5))
+			}
+			d.Close()
+
+			if got := server.count(); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+			dead := letters.all()
+			if tt.wantError == "" {
+				if len(dead) != 0 {
+					t.Errorf("dead letters = %d, want none", len(dead))
+				}
+				return
+			}
+			if len(dead) != 1 {
+				t.Fatalf("dead letters = %d, want 1", len(dead))
+			}
+			if dead[0].Webhook != "hook" || dead[0].Attempts != tt.wantRequests || dead[0].LastError != tt.wantError || !strings.Contains(dead[0].Payload, `"account":"alice"`) {
+				t.Errorf("dead letter = %+v", dead[0])
+			}
+		})
+	}
+}
+
+func TestDeliveryDropPolicy(t *testing.T) {
+	server := newEndpoint(t, 500)
+	policy := testPolicy
+	policy.MaxAttempts = 1
+	policy.GiveUp = models.GiveUpDrop
+	d, _, letters := newTestDispatcher(t, policy)
+	d.SetHooks([]models.Webhook{{Name: "hook", URL: server.URL}})
+
+	d.Dispatch([]*models.Operation{transfer("alice")})
+	d.Close()
+	if server.count() != 1 || len(letters.all()) != 0 {
+		t.Errorf("requests = %d, dead letters = %d; want 1 and none", server.count(), len(letters.all()))
+	}
+}
+
+func TestFailingWebhookDoesNotBlockOthers(t *testing.T) {
+	failing := newEndpoint(t, 500, 500, 500)
+	healthy := newEndpoint(t)
+	d, fake, _ := newTestDispatcher(t, testPolicy)
+	d.SetHooks([]models.Webhook{
+		{Name: "failing", URL: failing.URL},
+		{Name: "healthy", URL: healthy.URL},
+	})
+
+	d.Dispatch([]*models.Operation{transfer("alice"), transfer("bob")})
+	// The failing webhook waits out its backoff while the healthy one delivers everything
+	waitFor(t, "the failing webhook's backoff", func() bool { return fake.Waiters() == 1 })
+	waitFor(t, "the healthy webhook's deliveries", func() bool { return healthy.count() == 2 })
+	if got := failing.count(); got != 1 {
+		t.Errorf("failing webhook requests = %d, want 1", got)
+	}
+
+	// Close waits for the failing webhook, which gives up the first operation and then
+	// delivers the second
+	for attempt := 1; attempt < testPolicy.MaxAttempts; attempt++ {
+		waitFor(t, "the backoff", func() bool { return fake.Waiters() == 1 })
+		fake.Advance(testPolicy.Backoff(attempt, 1))
+	}
+	d.Close()
+	if got := failing.count(); got != 4 {
+		t.Errorf("failing webhook requests = %d, want 4", got)
+	}
+}
+
+func TestFullQueueDeadLettersInBackground(t *testing.T) {
+	server := newEndpoint(t)
+	server.release = make(chan struct{})
+	d, _, letters := newTestDispatcher(t, testPolicy)
+	block := make(chan struct{})
+	var inserted atomic.Int32
+	d.insertDeadLetter = func(ctx context.Context, deadLetter *models.WebhookDeadLetter) error {
+		<-block // A slow database
+		inserted.Add(1)
+		return letters.insert(ctx, deadLetter)
+	}
+	d.SetHooks([]models.Webhook{{Name: "hook", URL: server.URL}})
+
+	// One delivery is in flight, queueSize wait and the rest don't fit
+	operations := make([]*models.Operation, queueSize+10)
+	for i := range operations {
+		operations[i] = transfer("alice")
+	}
+	d.Dispatch(operations[:1])
+	waitFor(t, "the first delivery to be in flight", func() bool {
+		d.mu.RLock()
+		defer d.mu.RUnlock()
+		return len(d.queues["hook"]) == 0
+	})
+
+	dispatched := make(chan struct{})
+	go func() {
+		d.Dispatch(operations[1:])
+		close(dispatched)
+	}()
+	select {
+	case <-dispatched:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Dispatch waited for the dead-letter store")
+	}
+	if got := inserted.Load(); got != 0 {
+		t.Fatalf("dead letters inserted before the store answered: %d", got)
+	}
+
+	close(block)
+	close(server.release)
+	d.Close()
+	dead := letters.all()
+	if len(dead) != 9 {
+		t.Fatalf("dead letters = %d, want 9", len(dead))
+	}
+	if dead[0].LastError != "delivery queue full" || dead[0].Attempts != 0 {
+		t.Errorf("dead letter = %+v", dead[0])
+	}
+	if got := server.count(); got != queueSize+1 {
+		t.Errorf("requests = %d, want %d", got, queueSize+1)
+	}
+}
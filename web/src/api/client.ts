@@ -16,8 +16,11 @@ export type Operation = {
   account: string;
   op_type: string;
   op_data: Record<string, any>;
+  enrichment?: Record<string, any>;
   timestamp: string;
-  created_at: string;
+  first_seen_at: string;
+  updated_at: string;
+  source?: string;
 }
 
 export type OperationResponse = {
@@ -96,6 +96,11 @@ export function OperationTable({
                   <pre className="whitespace-pre-wrap break-words font-mono text-xs max-w-md">
                     {formatOpData(op.op_data)}
                   </pre>
+                  {op.enrichment && Object.keys(op.enrichment).length > 0 && (
+                    <pre className="mt-2 whitespace-pre-wrap break-words font-mono text-xs max-w-md text-primary">
+                      {JSON.stringify(op.enrichment, null, 2)}
+                    </pre>
+                  )}
                 </TableCell>
               </TableRow>
             ))}